@@ -0,0 +1,122 @@
+package community
+
+import (
+	"database/sql"
+	"log"
+
+	discuitCLI "github.com/discuitnet/discuit/cli"
+	"github.com/discuitnet/discuit/config"
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/meilisearch"
+	"github.com/urfave/cli/v2"
+)
+
+var communityFlag = &cli.StringFlag{
+	Name:     "community",
+	Usage:    "Community name",
+	Required: true,
+}
+
+var Command = &cli.Command{
+	Name:  "community",
+	Usage: "Community commands",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "delete",
+			Usage: "Delete a community",
+			Flags: []cli.Flag{communityFlag},
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sql.DB)
+				conf := ctx.Context.Value("config").(*config.Config)
+				community, err := core.GetCommunityByName(ctx.Context, db, ctx.String("community"), nil)
+				if err != nil {
+					return err
+				}
+				if ok := discuitCLI.YesConfirmCommand(); !ok {
+					log.Fatal("Cannot continue without a YES.")
+				}
+				if err := community.SetDeletedCLI(ctx.Context, true); err != nil {
+					return err
+				}
+				meilisearch.CommunityDeleteDocumentIfEnabled(ctx.Context, conf, community.ID.String())
+				log.Printf("%s successfully deleted\n", community.Name)
+				return nil
+			},
+		},
+		{
+			Name:  "restore",
+			Usage: "Restore a deleted community",
+			Flags: []cli.Flag{communityFlag},
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sql.DB)
+				conf := ctx.Context.Value("config").(*config.Config)
+				community, err := core.GetCommunityByName(ctx.Context, db, ctx.String("community"), nil)
+				if err != nil {
+					return err
+				}
+				if err := community.SetDeletedCLI(ctx.Context, false); err != nil {
+					return err
+				}
+				meilisearch.CommunityUpdateOrCreateDocumentIfEnabled(ctx.Context, conf, community)
+				log.Printf("%s successfully restored\n", community.Name)
+				return nil
+			},
+		},
+		{
+			Name:  "archive",
+			Usage: "Archive a community (make it read-only)",
+			Flags: []cli.Flag{
+				communityFlag,
+				&cli.BoolFlag{
+					Name:  "undo",
+					Usage: "Unarchive the community",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sql.DB)
+				community, err := core.GetCommunityByName(ctx.Context, db, ctx.String("community"), nil)
+				if err != nil {
+					return err
+				}
+				archive := !ctx.Bool("undo")
+				if err := community.SetArchivedCLI(ctx.Context, archive); err != nil {
+					return err
+				}
+				if archive {
+					log.Printf("%s is now archived\n", community.Name)
+				} else {
+					log.Printf("%s is no longer archived\n", community.Name)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "rename",
+			Usage: "Rename a community (the old name redirects to the new one)",
+			Flags: []cli.Flag{
+				communityFlag,
+				&cli.StringFlag{
+					Name:     "name",
+					Usage:    "New name",
+					Required: true,
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sql.DB)
+				conf := ctx.Context.Value("config").(*config.Config)
+				community, err := core.GetCommunityByName(ctx.Context, db, ctx.String("community"), nil)
+				if err != nil {
+					return err
+				}
+				oldName := community.Name
+				if err := community.RenameCLI(ctx.Context, ctx.String("name")); err != nil {
+					return err
+				}
+				meilisearch.CommunityUpdateOrCreateDocumentIfEnabled(ctx.Context, conf, community)
+				meilisearch.CommunityPostsUpdateDocumentsIfEnabled(ctx.Context, conf, db, community)
+				log.Printf("%s successfully renamed to %s\n", oldName, community.Name)
+				return nil
+			},
+		},
+	},
+}
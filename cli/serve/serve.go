@@ -15,6 +15,7 @@ import (
 	"github.com/discuitnet/discuit/config"
 	"github.com/discuitnet/discuit/core"
 	"github.com/discuitnet/discuit/internal/images"
+	"github.com/discuitnet/discuit/internal/mailer"
 	"github.com/discuitnet/discuit/internal/uid"
 	"github.com/discuitnet/discuit/server"
 	"github.com/urfave/cli/v2"
@@ -70,6 +71,11 @@ func serve(ctx *cli.Context) error {
 	}
 	defer site.Close()
 
+	m, err := newMailer(conf)
+	if err != nil {
+		log.Fatal("Error creating mailer: ", err)
+	}
+
 	go func() {
 		// This go-routine runs a set of periodic functions every hour.
 		time.Sleep(time.Second * 5) // Just so the first console output isn't from this goroutine.
@@ -82,10 +88,41 @@ func serve(ctx *cli.Context) error {
 			} else {
 				log.Printf("Removed %d temp images\n", n)
 			}
+			if n, err := core.PurgePostReads(context.TODO(), db, time.Hour*24*time.Duration(conf.ReadPostsRetentionDays)); err != nil {
+				log.Printf("Failed to purge read posts: %v\n", err)
+			} else if n > 0 {
+				log.Printf("Purged %d read posts records\n", n)
+			}
+			if m != nil {
+				if n, err := core.SendEmailDigests(context.TODO(), db, m, &core.DigestOptions{
+					SiteName:        conf.SiteName,
+					PublicURL:       conf.PublicUrl,
+					CommunityPrefix: conf.CommunityPrefix,
+					From:            conf.EmailFrom,
+					HMACSecret:      conf.HMACSecret,
+				}); err != nil {
+					log.Printf("Failed to send email digests: %v\n", err)
+				} else if n > 0 {
+					log.Printf("Sent %d email digests\n", n)
+				}
+			}
 			time.Sleep(time.Hour)
 		}
 	}()
 
+	go func() {
+		// This go-routine lifts the expired site and community bans every
+		// minute.
+		for {
+			if n, err := core.LiftExpiredBans(context.TODO(), db); err != nil {
+				log.Printf("Failed to lift expired bans: %v\n", err)
+			} else if n > 0 {
+				log.Printf("Lifted %d expired bans\n", n)
+			}
+			time.Sleep(time.Minute)
+		}
+	}()
+
 	if !config.AddressValid(conf.Addr) {
 		log.Fatal("Address needs to be a valid address of the form 'host:port' (host can be empty)")
 	}
@@ -147,6 +184,21 @@ func serve(ctx *cli.Context) error {
 	return nil
 }
 
+// newMailer returns the mailer set in conf, or nil if emails are disabled.
+func newMailer(conf *config.Config) (mailer.Mailer, error) {
+	switch conf.Mailer {
+	case "smtp":
+		return mailer.NewSMTPMailer(conf.SMTPAddr, conf.SMTPUsername, conf.SMTPPassword), nil
+	case "file":
+		p, err := filepath.Abs(conf.MailerFolder)
+		if err != nil {
+			return nil, err
+		}
+		return mailer.NewFileMailer(p)
+	}
+	return nil, nil
+}
+
 // createGhostUser creates the ghost user only if migrations have been run. If
 // migrations have not yet been run, the function exists silently without
 // returning an error
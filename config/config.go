@@ -47,6 +47,10 @@ type Config struct {
 	PaginationLimitMax int           `yaml:"paginationLimitMax"`
 	DefaultFeedSort    core.FeedSort `yaml:"defaultFeedSort"`
 
+	// The number of days for which the posts read by users (who have read
+	// tracking turned on) are remembered.
+	ReadPostsRetentionDays int `yaml:"readPostsRetentionDays"`
+
 	// Captcha verification is skipped if empty.
 	CaptchaSecret string `yaml:"captchaSecret"`
 
@@ -71,6 +75,15 @@ type Config struct {
 
 	MaxImagesPerPost int `yaml:"maxImagesPerPost"`
 
+	// Mailer is either "smtp", "file" (emails are written to MailerFolder
+	// instead of being sent), or empty (no emails are sent).
+	Mailer       string `yaml:"mailer"`
+	MailerFolder string `yaml:"mailerFolder"`
+	SMTPAddr     string `yaml:"smtpAddr"` // Of the form "host:port".
+	SMTPUsername string `yaml:"smtpUsername"`
+	SMTPPassword string `yaml:"smtpPassword"`
+	EmailFrom    string `yaml:"emailFrom"` // The address emails are sent from.
+
 	// For the front-end:
 	CaptchaSiteKey string `yaml:"captchaSiteKey"`
 	EmailContact   string `yaml:"emailContact"`
@@ -98,6 +111,9 @@ func Parse(path string) (*Config, error) {
 		MaxImageSize:       25 * (1 << 20),
 		MeiliEnabled:       false,
 		MaxImagesPerPost:   10,
+		MailerFolder:       "emails",
+
+		ReadPostsRetentionDays: 30,
 
 		// Required fields:
 		ForumCreationReqPoints: -1,
@@ -136,6 +152,8 @@ func Parse(path string) (*Config, error) {
 		"DISCUIT_PAGINATION_LIMIT_MAX": &c.PaginationLimitMax,
 		"DISCUIT_DEFAULT_FEED_SORT":    &c.DefaultFeedSort,
 
+		"DISCUIT_READ_POSTS_RETENTION_DAYS": &c.ReadPostsRetentionDays,
+
 		// Captcha verification is skipped if empty.
 		"DISCUIT_CAPTCHA_SECRET": &c.CaptchaSecret,
 		"DISCUIT_CERT_FILE":      &c.CertFile,
@@ -162,6 +180,13 @@ func Parse(path string) (*Config, error) {
 		// The location where images are saved on disk.
 		"DISCUIT_IMAGES_FOLDER_PATH": &c.ImagesFolderPath,
 
+		"DISCUIT_MAILER":        &c.Mailer,
+		"DISCUIT_MAILER_FOLDER": &c.MailerFolder,
+		"DISCUIT_SMTP_ADDR":     &c.SMTPAddr,
+		"DISCUIT_SMTP_USERNAME": &c.SMTPUsername,
+		"DISCUIT_SMTP_PASSWORD": &c.SMTPPassword,
+		"DISCUIT_EMAIL_FROM":    &c.EmailFrom,
+
 		// For the front-end:
 		"DISCUIT_CAPTCHA_SITEKEY": &c.CaptchaSiteKey,
 		"DISCUIT_EMAIL_CONTACT":   &c.EmailContact,
@@ -220,6 +245,18 @@ func Parse(path string) (*Config, error) {
 	if c.MaxForumsPerUser == -1 {
 		return nil, errors.New("MaxForumsPerUser cannot be (-1)")
 	}
+	switch c.Mailer {
+	case "", "file":
+	case "smtp":
+		if !AddressValid(c.SMTPAddr) {
+			return nil, errors.New("SMTPAddr is not valid")
+		}
+	default:
+		return nil, errors.New("Mailer has to be either smtp, file, or empty")
+	}
+	if c.Mailer != "" && c.EmailFrom == "" {
+		return nil, errors.New("EmailFrom cannot be empty if Mailer is set")
+	}
 	c.PublicUrl = strings.TrimRight(c.PublicUrl, "/")
 	_, err = url.ParseRequestURI(c.PublicUrl)
 	if err != nil {
@@ -0,0 +1,672 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+	"github.com/discuitnet/discuit/internal/utils"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	maxAutomodConfigLength = 32 * 1024 // in bytes.
+	maxAutomodRules        = 100
+	maxAutomodRuleName     = 128 // in runes.
+	maxAutomodMessage      = 2000
+)
+
+// automodReportReason is the report reason (the first one, "Breaks community
+// rules") used by the report action of automod rules.
+const automodReportReason = 1
+
+// AutomodEvent is an event on which automod rules are run.
+type AutomodEvent string
+
+const (
+	AutomodEventCreate = AutomodEvent("create") // A post or a comment is created.
+	AutomodEventEdit   = AutomodEvent("edit")   // A post or a comment is edited.
+	AutomodEventReport = AutomodEvent("report") // A post or a comment is reported.
+)
+
+// Valid reports whether e is a valid AutomodEvent.
+func (e AutomodEvent) Valid() bool {
+	return e == AutomodEventCreate || e == AutomodEventEdit || e == AutomodEventReport
+}
+
+// AutomodConditions are the conditions of an automod rule. All the conditions
+// that are set have to match for the rule to match. Of the conditions that
+// take a list, any one item of the list has to match.
+type AutomodConditions struct {
+	TitleContains []string `yaml:"titleContains" json:"titleContains,omitempty"` // Words or phrases (case-insensitive).
+	TitleRegex    string   `yaml:"titleRegex" json:"titleRegex,omitempty"`
+	BodyContains  []string `yaml:"bodyContains" json:"bodyContains,omitempty"` // Words or phrases (case-insensitive).
+	BodyRegex     string   `yaml:"bodyRegex" json:"bodyRegex,omitempty"`
+
+	// Matched against the link of posts and the links in the body of posts
+	// and comments. Subdomains are matched too.
+	Domains []string `yaml:"domains" json:"domains,omitempty"`
+
+	AccountAgeBelow int `yaml:"accountAgeBelow" json:"accountAgeBelow,omitempty"` // In days, of the author.
+	PointsBelow     int `yaml:"pointsBelow" json:"pointsBelow,omitempty"`         // Of the author.
+	MinReports      int `yaml:"minReports" json:"minReports,omitempty"`
+
+	// Compiled conditions.
+	title, body, domains contentFilters
+}
+
+// empty reports whether no condition is set.
+func (c *AutomodConditions) empty() bool {
+	return len(c.TitleContains) == 0 && c.TitleRegex == "" && len(c.BodyContains) == 0 && c.BodyRegex == "" &&
+		len(c.Domains) == 0 && c.AccountAgeBelow == 0 && c.PointsBelow == 0 && c.MinReports == 0
+}
+
+// compile validates and compiles c.
+func (c *AutomodConditions) compile() error {
+	filters := func(t ContentFilterType, patterns ...string) (contentFilters, error) {
+		var fs contentFilters
+		for _, p := range patterns {
+			if t == ContentFilterRegex && p == "" {
+				continue
+			}
+			f := &ContentFilter{Type: t, Pattern: p}
+			if err := f.validate(); err != nil {
+				return nil, err
+			}
+			fs = append(fs, f)
+		}
+		return fs, nil
+	}
+
+	var err error
+	if c.title, err = filters(ContentFilterKeyword, c.TitleContains...); err != nil {
+		return err
+	}
+	if re, err := filters(ContentFilterRegex, c.TitleRegex); err != nil {
+		return err
+	} else if len(re) > 0 {
+		c.title = append(c.title, re...)
+	}
+	if c.body, err = filters(ContentFilterKeyword, c.BodyContains...); err != nil {
+		return err
+	}
+	if re, err := filters(ContentFilterRegex, c.BodyRegex); err != nil {
+		return err
+	} else if len(re) > 0 {
+		c.body = append(c.body, re...)
+	}
+	if c.domains, err = filters(ContentFilterDomain, c.Domains...); err != nil {
+		return err
+	}
+
+	if c.AccountAgeBelow < 0 || c.PointsBelow < 0 || c.MinReports < 0 {
+		return httperr.NewBadRequest("automod/negative-condition", "Conditions cannot be negative.")
+	}
+	return nil
+}
+
+// matchAny reports whether any of the filters in fs matches any of texts.
+func matchAny(fs contentFilters, texts ...string) bool {
+	for _, f := range fs {
+		if f.matchText(texts...) {
+			return true
+		}
+	}
+	return false
+}
+
+// AutomodActions are the actions taken on the posts and comments matched by
+// an automod rule.
+type AutomodActions struct {
+	Remove  bool   `yaml:"remove" json:"remove,omitempty"`
+	Filter  bool   `yaml:"filter" json:"filter,omitempty"`   // Removes and holds for approval in the mod queue.
+	Lock    bool   `yaml:"lock" json:"lock,omitempty"`       // Locks the post (of a comment, the post it's on).
+	Approve bool   `yaml:"approve" json:"approve,omitempty"` // Dismisses all reports.
+	Comment string `yaml:"comment" json:"comment,omitempty"` // Replies with this comment as a mod.
+	Notify  string `yaml:"notify" json:"notify,omitempty"`   // Sends this message to the author as a notification.
+	Report  bool   `yaml:"report" json:"report,omitempty"`   // Reports to the mods.
+}
+
+// names returns the names of the actions that are set, in the order that
+// they are taken.
+func (a *AutomodActions) names() []string {
+	var names []string
+	if a.Approve {
+		names = append(names, "approve")
+	}
+	if a.Report {
+		names = append(names, "report")
+	}
+	if a.Comment != "" {
+		names = append(names, "comment")
+	}
+	if a.Notify != "" {
+		names = append(names, "notify")
+	}
+	if a.Lock {
+		names = append(names, "lock")
+	}
+	if a.Filter {
+		names = append(names, "filter")
+	} else if a.Remove {
+		names = append(names, "remove")
+	}
+	return names
+}
+
+// AutomodRule is a rule, set by the mods of a community, that is
+// automatically run on the posts and comments of the community.
+type AutomodRule struct {
+	Name string `yaml:"name" json:"name"`
+
+	// Type is one of "post", "comment", or "any". If empty, it's "any". Title
+	// conditions never match comments.
+	Type string `yaml:"type" json:"type"`
+
+	// The events on which the rule is run. If empty, it's create and edit.
+	On []AutomodEvent `yaml:"on" json:"on"`
+
+	// If true, the hits of the rule are logged but no action is taken.
+	DryRun bool `yaml:"dryRun" json:"dryRun"`
+
+	Conditions AutomodConditions `yaml:"conditions" json:"conditions"`
+	Actions    AutomodActions    `yaml:"actions" json:"actions"`
+}
+
+// validate checks that r is a valid rule, sets its defaults, and compiles its
+// conditions.
+func (r *AutomodRule) validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return httperr.NewBadRequest("automod/no-rule-name", "Every rule must have a name.")
+	}
+	if utf8.RuneCountInString(r.Name) > maxAutomodRuleName {
+		return httperr.NewBadRequest("automod/rule-name-too-long", fmt.Sprintf("Rule name %q is too long.", r.Name))
+	}
+	bad := func(msg string) error {
+		return httperr.NewBadRequest("automod/invalid-rule", fmt.Sprintf("Rule %q: %s", r.Name, msg))
+	}
+
+	switch r.Type {
+	case "":
+		r.Type = "any"
+	case "any", "post", "comment":
+	default:
+		return bad("type must be one of post, comment, or any.")
+	}
+	if len(r.On) == 0 {
+		r.On = []AutomodEvent{AutomodEventCreate, AutomodEventEdit}
+	}
+	for _, e := range r.On {
+		if !e.Valid() {
+			return bad(fmt.Sprintf("invalid event %q.", e))
+		}
+	}
+
+	if r.Conditions.empty() {
+		return bad("at least one condition is required.")
+	}
+	if err := r.Conditions.compile(); err != nil {
+		if e, ok := err.(*httperr.Error); ok {
+			return bad(e.Message)
+		}
+		return err
+	}
+
+	if len(r.Actions.names()) == 0 {
+		return bad("at least one action is required.")
+	}
+	if utf8.RuneCountInString(r.Actions.Comment) > maxCommentBodyLength {
+		return bad("comment is too long.")
+	}
+	if utf8.RuneCountInString(r.Actions.Notify) > maxAutomodMessage {
+		return bad("notify message is too long.")
+	}
+	return nil
+}
+
+// automodSubject is a post or a comment that automod rules are run on.
+type automodSubject struct {
+	contentType ContentType
+	title       string // Empty for comments.
+	body        string
+	hostname    string // Of the link of link posts.
+	author      *User
+	reports     int
+}
+
+// matches reports whether r matches s on event.
+func (r *AutomodRule) matches(event AutomodEvent, s *automodSubject, now time.Time) bool {
+	if (r.Type == "post" && s.contentType != ContentTypePost) || (r.Type == "comment" && s.contentType != ContentTypeComment) {
+		return false
+	}
+	if !slices.Contains(r.On, event) {
+		return false
+	}
+
+	c := &r.Conditions
+	if len(c.title) > 0 && (s.contentType != ContentTypePost || !matchAny(c.title, s.title)) {
+		return false
+	}
+	if len(c.body) > 0 && !matchAny(c.body, s.body) {
+		return false
+	}
+	if len(c.domains) > 0 {
+		matched := matchAny(c.domains, s.body)
+		for _, f := range c.domains {
+			matched = matched || (s.hostname != "" && f.matchesHostname(s.hostname))
+		}
+		if !matched {
+			return false
+		}
+	}
+	if c.AccountAgeBelow > 0 && now.Sub(s.author.CreatedAt) >= time.Duration(c.AccountAgeBelow)*24*time.Hour {
+		return false
+	}
+	if c.PointsBelow > 0 && s.author.Points >= c.PointsBelow {
+		return false
+	}
+	if c.MinReports > 0 && s.reports < c.MinReports {
+		return false
+	}
+	return true
+}
+
+// automodRules is the parsed form of the config text of a community.
+type automodRules struct {
+	Rules []*AutomodRule `yaml:"rules" json:"rules"`
+}
+
+// parseAutomodRules parses and validates config, which is either YAML or JSON
+// (which is valid YAML).
+func parseAutomodRules(config string) ([]*AutomodRule, error) {
+	if len(config) > maxAutomodConfigLength {
+		return nil, httperr.NewBadRequest("automod/config-too-long", "Automod config is too long.")
+	}
+	var rules automodRules
+	if err := yaml.UnmarshalStrict([]byte(config), &rules); err != nil {
+		return nil, httperr.NewBadRequest("automod/invalid-config", "Invalid automod config: "+err.Error())
+	}
+	if len(rules.Rules) > maxAutomodRules {
+		return nil, httperr.NewBadRequest("automod/too-many-rules", fmt.Sprintf("A community can have at most %d automod rules.", maxAutomodRules))
+	}
+	names := make(map[string]bool)
+	for _, r := range rules.Rules {
+		if r == nil {
+			return nil, httperr.NewBadRequest("automod/invalid-config", "Invalid automod config: empty rule.")
+		}
+		if err := r.validate(); err != nil {
+			return nil, err
+		}
+		if names[r.Name] {
+			return nil, httperr.NewBadRequest("automod/duplicate-rule-name", fmt.Sprintf("More than one rule is named %q.", r.Name))
+		}
+		names[r.Name] = true
+	}
+	if rules.Rules == nil {
+		rules.Rules = []*AutomodRule{}
+	}
+	return rules.Rules, nil
+}
+
+// AutomodConfig is the automod config of a community.
+type AutomodConfig struct {
+	CommunityID uid.ID         `json:"communityId"`
+	Config      string         `json:"config"` // YAML or JSON.
+	Rules       []*AutomodRule `json:"rules"`
+	UpdatedBy   uid.NullID     `json:"updatedBy"`
+	UpdatedAt   msql.NullTime  `json:"updatedAt"`
+}
+
+// GetAutomodConfig returns the automod config of community. If community has
+// no config, a config with no rules is returned.
+func GetAutomodConfig(ctx context.Context, db *sql.DB, community uid.ID) (*AutomodConfig, error) {
+	c := &AutomodConfig{CommunityID: community, Rules: []*AutomodRule{}}
+	row := db.QueryRowContext(ctx, "SELECT config, updated_by, updated_at FROM automod_configs WHERE community_id = ?", community)
+	if err := row.Scan(&c.Config, &c.UpdatedBy, &c.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return c, nil
+		}
+		return nil, err
+	}
+	rules, err := parseAutomodRules(c.Config)
+	if err != nil {
+		return nil, err
+	}
+	c.Rules = rules
+	return c, nil
+}
+
+// SaveAutomodConfig validates and saves config as the automod config of c.
+// Actions of the rules are taken on behalf of mod, who must be a mod of c or
+// an admin, for as long as mod remains one (and on behalf of the top mod of c
+// afterwards). An empty config deletes all the rules.
+func (c *Community) SaveAutomodConfig(ctx context.Context, mod uid.ID, config string) (*AutomodConfig, error) {
+	if is, err := c.UserModOrAdmin(ctx, mod); err != nil {
+		return nil, err
+	} else if !is {
+		return nil, errNotMod
+	}
+
+	if strings.TrimSpace(config) == "" {
+		if _, err := c.db.ExecContext(ctx, "DELETE FROM automod_configs WHERE community_id = ?", c.ID); err != nil {
+			return nil, err
+		}
+		return GetAutomodConfig(ctx, c.db, c.ID)
+	}
+
+	if _, err := parseAutomodRules(config); err != nil {
+		return nil, err
+	}
+	entry, err := communityModLogEntry(ctx, c.db, c.ID, mod, ModActionUpdateAutomod)
+	if err != nil {
+		return nil, err
+	}
+	entry.TargetType, entry.TargetID = "community", c.ID.String()
+	err = msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO automod_configs (community_id, config, updated_by, updated_at) VALUES (?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE config = VALUES(config), updated_by = VALUES(updated_by), updated_at = VALUES(updated_at)`
+		if _, err := tx.ExecContext(ctx, query, c.ID, config, mod, time.Now()); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetAutomodConfig(ctx, c.db, c.ID)
+}
+
+// AutomodHit is a log entry of an automod rule matching a post or a comment.
+type AutomodHit struct {
+	ID         int          `json:"id"`
+	RuleName   string       `json:"ruleName"`
+	Event      AutomodEvent `json:"event"`
+	TargetType ContentType  `json:"targetType"`
+	TargetID   uid.ID       `json:"targetId"`
+	PostID     uid.ID       `json:"postId"`
+	Actions    []string     `json:"actions"`
+	DryRun     bool         `json:"dryRun"`
+	Error      string       `json:"error,omitempty"` // If taking an action failed.
+	CreatedAt  time.Time    `json:"createdAt"`
+	Post       *Post        `json:"post,omitempty"`
+	Comment    *Comment     `json:"comment,omitempty"`
+}
+
+// GetAutomodHits returns the automod hits of community, most recent first. If
+// rule is non-empty, only the hits of the rule with that name are returned.
+func GetAutomodHits(ctx context.Context, db *sql.DB, community uid.ID, rule string, limit, page int) ([]*AutomodHit, error) {
+	where, args := "WHERE community_id = ?", []any{community}
+	if rule != "" {
+		where += " AND rule_name = ?"
+		args = append(args, rule)
+	}
+	args = append(args, limit, (page-1)*limit)
+	query := "SELECT id, rule_name, event, target_type, target_id, post_id, actions, dry_run, error, created_at FROM automod_hits " + where + " ORDER BY id DESC LIMIT ? OFFSET ?"
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []*AutomodHit{}
+	for rows.Next() {
+		var (
+			hit     AutomodHit
+			actions string
+			hitErr  msql.NullString
+		)
+		if err := rows.Scan(&hit.ID, &hit.RuleName, &hit.Event, &hit.TargetType, &hit.TargetID, &hit.PostID, &actions, &hit.DryRun, &hitErr, &hit.CreatedAt); err != nil {
+			return nil, err
+		}
+		hit.Actions = strings.Split(actions, ",")
+		hit.Error = hitErr.String
+		hits = append(hits, &hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, hit := range hits {
+		if hit.TargetType == ContentTypePost {
+			if hit.Post, err = GetPost(ctx, db, &hit.TargetID, "", nil, true); err != nil && err != errPostNotFound {
+				return nil, err
+			}
+		} else {
+			if hit.Comment, err = GetComment(ctx, db, hit.TargetID, nil); err != nil && err != errCommentNotFound {
+				return nil, err
+			}
+		}
+	}
+	return hits, nil
+}
+
+// runAutomod runs the automod rules of the community of the post (or, if
+// comment is not nil, of the comment) on event. It's meant to be run in a
+// goroutine; errors are logged.
+func runAutomod(ctx context.Context, db *sql.DB, event AutomodEvent, post *Post, comment *Comment) {
+	if err := runAutomodRules(ctx, db, event, post, comment); err != nil {
+		log.Printf("Error running automod rules (event: %s, post: %v): %v\n", event, post.ID, err)
+	}
+}
+
+func runAutomodRules(ctx context.Context, db *sql.DB, event AutomodEvent, post *Post, comment *Comment) error {
+	config, err := GetAutomodConfig(ctx, db, post.CommunityID)
+	if err != nil {
+		return err
+	}
+	if len(config.Rules) == 0 || !config.UpdatedBy.Valid {
+		return nil
+	}
+
+	s := &automodSubject{contentType: ContentTypePost}
+	authorID, targetID, deleted := post.AuthorID, post.ID, post.Deleted
+	if comment != nil {
+		s.contentType = ContentTypeComment
+		s.body = comment.Body
+		authorID, targetID, deleted = comment.AuthorID, comment.ID, comment.Deleted
+	} else {
+		s.title, s.body = post.Title, post.Body.String
+		if post.Link != nil {
+			s.hostname = post.Link.Hostname
+		}
+	}
+	if deleted {
+		return nil
+	}
+
+	// Mods and admins are exempt from automod rules.
+	if exempt, err := UserModOrAdmin(ctx, db, post.CommunityID, authorID); err != nil {
+		return err
+	} else if exempt {
+		return nil
+	}
+	if s.author, err = GetUser(ctx, db, authorID, nil); err != nil {
+		return err
+	}
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reports WHERE target_id = ? AND report_type = ? AND "+modReportsCond, targetID, s.contentType).Scan(&s.reports); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var actor *uid.ID // Resolved on the first rule that matches.
+	for _, rule := range config.Rules {
+		if !rule.matches(event, s, now) {
+			continue
+		}
+		var actionErr error
+		if !rule.DryRun {
+			if actor == nil {
+				if id, err := automodActor(ctx, db, post.CommunityID, config.UpdatedBy.ID); err != nil {
+					actionErr = err
+				} else {
+					actor = &id
+				}
+			}
+			if actor != nil {
+				actionErr = takeAutomodActions(ctx, db, *actor, event, rule, post, comment)
+			}
+		}
+		if err := logAutomodHit(ctx, db, rule, event, post, comment, actionErr); err != nil {
+			return err
+		}
+		if actionErr == nil && !rule.DryRun && (rule.Actions.Remove || rule.Actions.Filter) {
+			break
+		}
+	}
+	return nil
+}
+
+// automodActor returns the user on whose behalf automod acts in community: the
+// user who last saved the rules, savedBy, if they're still a mod of community
+// (or an admin), and otherwise the top mod of community.
+func automodActor(ctx context.Context, db *sql.DB, community, savedBy uid.ID) (uid.ID, error) {
+	if is, err := UserModOrAdmin(ctx, db, community, savedBy); err != nil {
+		return uid.ID{}, err
+	} else if is {
+		return savedBy, nil
+	}
+	var top uid.ID
+	row := db.QueryRowContext(ctx, `
+		SELECT community_mods.user_id FROM community_mods
+		INNER JOIN users ON users.id = community_mods.user_id
+		WHERE community_mods.community_id = ? AND users.deleted_at IS NULL
+		ORDER BY community_mods.position LIMIT 1`, community)
+	if err := row.Scan(&top); err != nil {
+		if err == sql.ErrNoRows {
+			return uid.ID{}, errors.New("community has no mods to run automod on behalf of")
+		}
+		return uid.ID{}, err
+	}
+	return top, nil
+}
+
+// takeAutomodActions takes the actions of rule on post (or, if comment is not
+// nil, on comment) on behalf of mod. The report action is skipped on report
+// events.
+func takeAutomodActions(ctx context.Context, db *sql.DB, mod uid.ID, event AutomodEvent, rule *AutomodRule, post *Post, comment *Comment) error {
+	actions := &rule.Actions
+	// An admin who isn't a mod of the community may have saved the rules.
+	g, err := modOrAdminGroup(ctx, db, post.CommunityID, mod)
+	if err != nil {
+		return err
+	}
+
+	if actions.Approve {
+		var err error
+		if comment != nil {
+			err = RemoveAllReportsOfComment(ctx, db, comment.ID)
+		} else {
+			err = RemoveAllReportsOfPost(ctx, db, post.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if actions.Report && event != AutomodEventReport {
+		reportType, target := ReportTypePost, post.ID
+		if comment != nil {
+			reportType, target = ReportTypeComment, comment.ID
+		}
+		ni := uid.NullID{ID: post.ID, Valid: true}
+		if _, err := NewReport(ctx, db, post.CommunityID, ni, reportType, automodReportReason, target, mod); err != nil {
+			return err
+		}
+	}
+	if actions.Comment != "" {
+		var parent *uid.ID
+		if comment != nil {
+			parent = &comment.ID
+		}
+		if _, err := post.AddComment(ctx, mod, g, parent, actions.Comment); err != nil {
+			return err
+		}
+	}
+	if actions.Notify != "" {
+		authorID, targetID := post.AuthorID, post.ID
+		if comment != nil {
+			authorID, targetID = comment.AuthorID, comment.ID
+		}
+		if err := CreateAutomodMessageNotification(ctx, db, authorID, post.CommunityName, actions.Notify, comment == nil, targetID); err != nil {
+			return err
+		}
+	}
+	if actions.Lock && !post.Locked {
+		if err := post.Lock(ctx, mod, g); err != nil {
+			return err
+		}
+	}
+	if actions.Filter {
+		// The author is not notified until the mods act on the mod queue.
+		if comment != nil {
+			if err := comment.Delete(ctx, mod, g, nil); err != nil {
+				return err
+			}
+			return queueForModeration(ctx, db, post.CommunityID, ContentTypeComment, comment.ID, post.ID, ModQueueReasonFiltered, rule.Name)
+		}
+		if err := post.Delete(ctx, mod, g, false, false, nil); err != nil {
+			return err
+		}
+		return queueForModeration(ctx, db, post.CommunityID, ContentTypePost, post.ID, post.ID, ModQueueReasonFiltered, rule.Name)
+	}
+	if actions.Remove {
+		if comment != nil {
+			return comment.Delete(ctx, mod, g, nil)
+		}
+		return post.Delete(ctx, mod, g, false, true, nil)
+	}
+	return nil
+}
+
+func logAutomodHit(ctx context.Context, db *sql.DB, rule *AutomodRule, event AutomodEvent, post *Post, comment *Comment, actionErr error) error {
+	targetType, targetID := ContentTypePost, post.ID
+	if comment != nil {
+		targetType, targetID = ContentTypeComment, comment.ID
+	}
+	var errText any
+	if actionErr != nil {
+		errText = utils.TruncateUnicodeString(actionErr.Error(), 255)
+	}
+	query := "INSERT INTO automod_hits (community_id, rule_name, event, target_type, target_id, post_id, actions, dry_run, error) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	_, err := db.ExecContext(ctx, query, post.CommunityID, rule.Name, event, targetType, targetID, post.ID, strings.Join(rule.Actions.names(), ","), rule.DryRun, errText)
+	return err
+}
+
+// runAutomodOnPost is run in a goroutine with the ID of a post that's created,
+// edited, or reported.
+func runAutomodOnPost(db *sql.DB, event AutomodEvent, postID uid.ID) {
+	ctx := context.Background()
+	post, err := GetPost(ctx, db, &postID, "", nil, false)
+	if err != nil {
+		log.Printf("Error getting post %v for automod: %v\n", postID, err)
+		return
+	}
+	runAutomod(ctx, db, event, post, nil)
+}
+
+// runAutomodOnComment is run in a goroutine with the ID of a comment that's
+// created, edited, or reported.
+func runAutomodOnComment(db *sql.DB, event AutomodEvent, commentID uid.ID) {
+	ctx := context.Background()
+	comment, err := GetComment(ctx, db, commentID, nil)
+	if err != nil {
+		log.Printf("Error getting comment %v for automod: %v\n", commentID, err)
+		return
+	}
+	post, err := GetPost(ctx, db, &comment.PostID, "", nil, false)
+	if err != nil {
+		log.Printf("Error getting post %v for automod: %v\n", comment.PostID, err)
+		return
+	}
+	runAutomod(ctx, db, event, post, comment)
+}
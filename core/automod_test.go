@@ -0,0 +1,129 @@
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseAutomodRules(t *testing.T) {
+	yamlConfig := `
+rules:
+  - name: No link shorteners
+    type: post
+    conditions:
+      domains: [bit.ly, tinyurl.com]
+    actions:
+      remove: true
+      notify: Link shorteners are not allowed.
+  - name: Reported a lot
+    on: [report]
+    dryRun: true
+    conditions:
+      minReports: 3
+    actions:
+      lock: true
+`
+	rules, err := parseAutomodRules(yamlConfig)
+	if err != nil {
+		t.Fatalf("parseAutomodRules(yaml) error = %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("parseAutomodRules(yaml) returned %d rules, want 2", len(rules))
+	}
+	if r := rules[0]; r.Type != "post" || !slices.Equal(r.On, []AutomodEvent{AutomodEventCreate, AutomodEventEdit}) {
+		t.Errorf("rule 0 = %+v, want type post on create and edit", r)
+	}
+	if r := rules[1]; r.Type != "any" || !r.DryRun || !slices.Equal(r.Actions.names(), []string{"lock"}) {
+		t.Errorf("rule 1 = %+v, want type any, dry run, and lock", r)
+	}
+
+	jsonConfig := `{"rules": [{"name": "Spam", "conditions": {"bodyContains": ["buy now"]}, "actions": {"report": true}}]}`
+	if rules, err := parseAutomodRules(jsonConfig); err != nil {
+		t.Errorf("parseAutomodRules(json) error = %v", err)
+	} else if len(rules) != 1 || rules[0].Name != "Spam" {
+		t.Errorf("parseAutomodRules(json) = %+v", rules)
+	}
+
+	filterConfig := `rules: [{name: New users, conditions: {accountAgeBelow: 1}, actions: {filter: true, remove: true}}]`
+	if rules, err := parseAutomodRules(filterConfig); err != nil {
+		t.Errorf("parseAutomodRules(filter) error = %v", err)
+	} else if names := rules[0].Actions.names(); !slices.Equal(names, []string{"filter"}) {
+		t.Errorf("filter rule actions = %v, want [filter]", names)
+	}
+
+	invalid := []string{
+		`rules: [{name: A, actions: {remove: true}}]`,                     // No conditions.
+		`rules: [{name: A, conditions: {minReports: 1}}]`,                 // No actions.
+		`rules: [{conditions: {minReports: 1}, actions: {remove: true}}]`, // No name.
+		`rules: [{name: A, type: user, conditions: {minReports: 1}, actions: {remove: true}}]`,
+		`rules: [{name: A, on: [vote], conditions: {minReports: 1}, actions: {remove: true}}]`,
+		`rules: [{name: A, conditions: {bodyRegex: "(unclosed"}, actions: {remove: true}}]`,
+		`rules: [{name: A, conditions: {pointsBelow: -1}, actions: {remove: true}}]`,
+		`rules: [{name: A, conditions: {unknown: 1}, actions: {remove: true}}]`,
+		`rules: [{name: A, conditions: {minReports: 1}, actions: {remove: true}}, {name: A, conditions: {minReports: 2}, actions: {lock: true}}]`,
+	}
+	for _, config := range invalid {
+		if _, err := parseAutomodRules(config); err == nil {
+			t.Errorf("parseAutomodRules(%q) succeeded, want error", config)
+		}
+	}
+}
+
+func TestAutomodRuleMatches(t *testing.T) {
+	config := `
+rules:
+  - name: New accounts posting links
+    type: post
+    conditions:
+      titleContains: [free]
+      bodyRegex: "(?i)crypto"
+      domains: [example.com]
+      accountAgeBelow: 7
+      pointsBelow: 10
+    actions:
+      remove: true
+  - name: Reported comments
+    type: comment
+    on: [report]
+    conditions:
+      minReports: 2
+    actions:
+      lock: true
+`
+	rules, err := parseAutomodRules(config)
+	if err != nil {
+		t.Fatalf("parseAutomodRules() error = %v", err)
+	}
+
+	now := time.Now()
+	newUser := &User{CreatedAt: now.Add(-24 * time.Hour), Points: 1}
+	oldUser := &User{CreatedAt: now.Add(-30 * 24 * time.Hour), Points: 1}
+	post := func(title, body, hostname string, author *User) *automodSubject {
+		return &automodSubject{contentType: ContentTypePost, title: title, body: body, hostname: hostname, author: author}
+	}
+
+	cases := []struct {
+		rule    int
+		event   AutomodEvent
+		subject *automodSubject
+		want    bool
+	}{
+		{0, AutomodEventCreate, post("Free coins", "Crypto!", "www.example.com", newUser), true},
+		{0, AutomodEventEdit, post("Free coins", "Crypto! https://sub.example.com/x", "", newUser), true},
+		{0, AutomodEventReport, post("Free coins", "Crypto!", "example.com", newUser), false},
+		{0, AutomodEventCreate, post("Freedom", "Crypto!", "example.com", newUser), false},
+		{0, AutomodEventCreate, post("Free coins", "Nothing", "example.com", newUser), false},
+		{0, AutomodEventCreate, post("Free coins", "Crypto!", "example.org", newUser), false},
+		{0, AutomodEventCreate, post("Free coins", "Crypto!", "example.com", oldUser), false},
+		{0, AutomodEventCreate, &automodSubject{contentType: ContentTypeComment, body: "Free crypto example.com", author: newUser}, false},
+		{1, AutomodEventReport, &automodSubject{contentType: ContentTypeComment, author: oldUser, reports: 2}, true},
+		{1, AutomodEventReport, &automodSubject{contentType: ContentTypeComment, author: oldUser, reports: 1}, false},
+		{1, AutomodEventReport, &automodSubject{contentType: ContentTypePost, author: oldUser, reports: 2}, false},
+	}
+	for i, c := range cases {
+		if got := rules[c.rule].matches(c.event, c.subject, now); got != c.want {
+			t.Errorf("case %d: matches() = %v, want %v", i, got, c.want)
+		}
+	}
+}
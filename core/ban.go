@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+const maxBanReasonLength = 1024
+
+// BannedUser is a user banned from a community.
+type BannedUser struct {
+	User     *User         `json:"user"`
+	BannedAt time.Time     `json:"bannedAt"`
+	Expires  msql.NullTime `json:"expires"` // Null for permanent bans.
+
+	// ExpiresIn is the remaining duration of the ban, in seconds, if the ban
+	// is not permanent.
+	ExpiresIn *int64 `json:"expiresIn"`
+}
+
+// LiftExpiredBans lifts all the site bans and the community bans that have
+// expired. The users are notified, and the lifts are recorded in the mod log
+// (on behalf of the user that gave the ban). It returns the number of bans
+// lifted.
+func LiftExpiredBans(ctx context.Context, db *sql.DB) (int, error) {
+	now := time.Now()
+
+	type ban struct {
+		community uid.NullID
+		user      uid.ID
+		bannedBy  uid.NullID
+	}
+	var bans []ban
+	rows, err := db.QueryContext(ctx, "SELECT id, banned_by FROM users WHERE banned_at IS NOT NULL AND ban_expires <= ?", now)
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var b ban
+		if err := rows.Scan(&b.user, &b.bannedBy); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		bans = append(bans, b)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	rows.Close()
+
+	if rows, err = db.QueryContext(ctx, "SELECT community_id, user_id, banned_by FROM community_banned WHERE expires <= ?", now); err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var b ban
+		if err := rows.Scan(&b.community, &b.user, &b.bannedBy); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		bans = append(bans, b)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	rows.Close()
+
+	n := 0
+	for _, b := range bans {
+		var lifted bool
+		if b.community.Valid {
+			lifted, err = liftCommunityBan(ctx, db, b.community.ID, b.user, b.bannedBy.ID, now)
+		} else {
+			lifted, err = liftSiteBan(ctx, db, b.user, b.bannedBy, now)
+		}
+		if err != nil {
+			return n, err
+		}
+		if lifted {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// liftSiteBan lifts the site ban of user, given by bannedBy, if it expired
+// before now. It reports whether the ban was lifted (it may already have
+// been).
+func liftSiteBan(ctx context.Context, db *sql.DB, user uid.ID, bannedBy uid.NullID, now time.Time) (bool, error) {
+	var lifted bool
+	err := msql.Transact(ctx, db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "UPDATE users SET banned_at = NULL, ban_expires = NULL, ban_reason = NULL, banned_by = NULL WHERE id = ? AND ban_expires <= ?", user, now)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil || n == 0 {
+			return err
+		}
+		lifted = true
+		if !bannedBy.Valid {
+			return nil
+		}
+		return insertModLogEntry(ctx, tx, &ModLogEntry{
+			ActorID:      bannedBy.ID,
+			ActorGroup:   UserGroupAdmins,
+			Action:       ModActionSiteBanExpired,
+			TargetType:   "user",
+			TargetID:     user.String(),
+			TargetUserID: uid.NullID{ID: user, Valid: true},
+		})
+	})
+	if err != nil || !lifted {
+		return false, err
+	}
+	if err := CreateBanExpiredNotification(ctx, db, user, ""); err != nil {
+		log.Printf("Error creating ban expired notification (user id: %v): %v\n", user, err)
+	}
+	return true, nil
+}
+
+// liftCommunityBan lifts the ban of user from community, given by bannedBy, if
+// it expired before now. It reports whether the ban was lifted (it may already
+// have been).
+func liftCommunityBan(ctx context.Context, db *sql.DB, community, user, bannedBy uid.ID, now time.Time) (bool, error) {
+	entry, err := communityModLogEntry(ctx, db, community, bannedBy, ModActionBanExpired)
+	if err != nil {
+		return false, err
+	}
+	entry.TargetType, entry.TargetID = "user", user.String()
+	entry.TargetUserID = uid.NullID{ID: user, Valid: true}
+
+	var lifted bool
+	err = msql.Transact(ctx, db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "DELETE FROM community_banned WHERE community_id = ? AND user_id = ? AND expires <= ?", community, user, now)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil || n == 0 {
+			return err
+		}
+		lifted = true
+		return insertModLogEntry(ctx, tx, entry)
+	})
+	if err != nil || !lifted {
+		return false, err
+	}
+
+	var name string
+	if err := db.QueryRowContext(ctx, "SELECT name FROM communities WHERE id = ?", community).Scan(&name); err != nil {
+		return true, err
+	}
+	if err := CreateBanExpiredNotification(ctx, db, user, name); err != nil {
+		log.Printf("Error creating ban expired notification (user id: %v): %v\n", user, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,390 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+const (
+	maxBanAppealLength         = 5000
+	maxBanAppealResponseLength = 2000
+)
+
+// BanAppealStatus is the status of a ban appeal.
+type BanAppealStatus string
+
+const (
+	BanAppealStatusOpen      = BanAppealStatus("open")
+	BanAppealStatusAccepted  = BanAppealStatus("accepted")  // The user was unbanned.
+	BanAppealStatusRejected  = BanAppealStatus("rejected")  // The ban was left as is.
+	BanAppealStatusShortened = BanAppealStatus("shortened") // The ban was made to expire sooner.
+)
+
+// Valid reports whether s is a valid BanAppealStatus.
+func (s BanAppealStatus) Valid() bool {
+	switch s {
+	case BanAppealStatusOpen, BanAppealStatusAccepted, BanAppealStatusRejected, BanAppealStatusShortened:
+		return true
+	}
+	return false
+}
+
+// BanAppeal is an appeal, by a banned user, of their ban from a community
+// (which is reviewed by the mods of the community), or of their site ban
+// (which is reviewed by the admins).
+type BanAppeal struct {
+	db *sql.DB
+
+	ID            uint            `json:"id"`
+	UserID        uid.ID          `json:"userId"`
+	Username      string          `json:"username"`
+	CommunityID   uid.NullID      `json:"communityId"` // Null for site bans.
+	CommunityName msql.NullString `json:"communityName"`
+	Body          string          `json:"body"`
+	Status        BanAppealStatus `json:"status"`
+	Response      msql.NullString `json:"response"` // The message of the reviewer.
+	ReviewedBy    uid.NullID      `json:"reviewedBy"`
+	ReviewedAt    msql.NullTime   `json:"reviewedAt"`
+	CreatedAt     time.Time       `json:"createdAt"`
+
+	// The expiry of the ban, if the ban is not permanent.
+	BanExpires msql.NullTime `json:"banExpires"`
+}
+
+func getBanAppeals(ctx context.Context, db *sql.DB, where string, args ...any) ([]*BanAppeal, error) {
+	cols := []string{
+		"ban_appeals.id",
+		"ban_appeals.user_id",
+		"users.username",
+		"ban_appeals.community_id",
+		"communities.name",
+		"ban_appeals.body",
+		"ban_appeals.status",
+		"ban_appeals.response",
+		"ban_appeals.reviewed_by",
+		"ban_appeals.reviewed_at",
+		"ban_appeals.created_at",
+		"IF(ban_appeals.community_id IS NULL, users.ban_expires, community_banned.expires)",
+	}
+	joins := []string{
+		"INNER JOIN users ON users.id = ban_appeals.user_id",
+		"LEFT JOIN communities ON communities.id = ban_appeals.community_id",
+		"LEFT JOIN community_banned ON community_banned.community_id = ban_appeals.community_id AND community_banned.user_id = ban_appeals.user_id",
+	}
+	rows, err := db.QueryContext(ctx, msql.BuildSelectQuery("ban_appeals", cols, joins, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appeals := []*BanAppeal{}
+	for rows.Next() {
+		a := &BanAppeal{db: db}
+		err := rows.Scan(
+			&a.ID,
+			&a.UserID,
+			&a.Username,
+			&a.CommunityID,
+			&a.CommunityName,
+			&a.Body,
+			&a.Status,
+			&a.Response,
+			&a.ReviewedBy,
+			&a.ReviewedAt,
+			&a.CreatedAt,
+			&a.BanExpires,
+		)
+		if err != nil {
+			return nil, err
+		}
+		appeals = append(appeals, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return appeals, nil
+}
+
+// GetBanAppeal returns the ban appeal with the given id.
+func GetBanAppeal(ctx context.Context, db *sql.DB, id uint) (*BanAppeal, error) {
+	appeals, err := getBanAppeals(ctx, db, "WHERE ban_appeals.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(appeals) == 0 {
+		return nil, httperr.NewNotFound("ban-appeal-not-found", "Ban appeal not found.")
+	}
+	return appeals[0], nil
+}
+
+// GetBanAppeals returns the appeals of the bans from community (or, if
+// community is null, of the site bans), the oldest ones first. If status is
+// not empty, only the appeals of that status are returned. The results are
+// paginated.
+func GetBanAppeals(ctx context.Context, db *sql.DB, community uid.NullID, status BanAppealStatus, limit, page int) ([]*BanAppeal, error) {
+	where, args := "WHERE ban_appeals.community_id IS NULL", []any{}
+	if community.Valid {
+		where, args = "WHERE ban_appeals.community_id = ?", append(args, community.ID)
+	}
+	if status != "" {
+		if !status.Valid() {
+			return nil, httperr.NewBadRequest("invalid-ban-appeal-status", "Invalid ban appeal status.")
+		}
+		where += " AND ban_appeals.status = ?"
+		args = append(args, status)
+	}
+	where += " ORDER BY ban_appeals.created_at LIMIT ? OFFSET ?"
+	args = append(args, limit, limit*(page-1))
+	return getBanAppeals(ctx, db, where, args...)
+}
+
+// GetUserBanAppeals returns all the ban appeals made by user, the latest ones
+// first.
+func GetUserBanAppeals(ctx context.Context, db *sql.DB, user uid.ID) ([]*BanAppeal, error) {
+	return getBanAppeals(ctx, db, "WHERE ban_appeals.user_id = ? ORDER BY ban_appeals.created_at DESC", user)
+}
+
+// NewBanAppeal creates an appeal by user of their ban from community (or, if
+// community is null, of their site ban). A ban can only have one open appeal
+// at a time.
+func NewBanAppeal(ctx context.Context, db *sql.DB, user uid.ID, community uid.NullID, body string) (*BanAppeal, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, httperr.NewBadRequest("ban-appeal-empty", "Ban appeal cannot be empty.")
+	}
+	if utf8.RuneCountInString(body) > maxBanAppealLength {
+		return nil, httperr.NewBadRequest("ban-appeal-too-long", fmt.Sprintf("Ban appeal cannot exceed %d characters.", maxBanAppealLength))
+	}
+
+	var banned bool
+	if community.Valid {
+		var err error
+		if banned, err = IsUserBannedFromCommunity(ctx, db, community.ID, user); err != nil {
+			return nil, err
+		}
+	} else {
+		u, err := GetUser(ctx, db, user, nil)
+		if err != nil {
+			return nil, err
+		}
+		banned = u.Banned
+	}
+	if !banned {
+		return nil, httperr.NewBadRequest("not-banned", "User is not banned.")
+	}
+
+	query := "SELECT COUNT(*) > 0 FROM ban_appeals WHERE user_id = ? AND community_id IS NULL AND status = ?"
+	args := []any{user, BanAppealStatusOpen}
+	if community.Valid {
+		query = "SELECT COUNT(*) > 0 FROM ban_appeals WHERE user_id = ? AND community_id = ? AND status = ?"
+		args = []any{user, community.ID, BanAppealStatusOpen}
+	}
+	var open bool
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&open); err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, &httperr.Error{HTTPStatus: http.StatusConflict, Code: "ban-appeal-exists", Message: "The ban already has an open appeal."}
+	}
+
+	result, err := db.ExecContext(ctx, "INSERT INTO ban_appeals (user_id, community_id, body) VALUES (?, ?, ?)", user, community, body)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return GetBanAppeal(ctx, db, uint(id))
+}
+
+// reviewerGroup returns the group in which reviewer reviews a. Only the mods
+// of the community (and admins) can review the appeals of community bans, and
+// only admins can review the appeals of site bans.
+func (a *BanAppeal) reviewerGroup(ctx context.Context, reviewer uid.ID) (UserGroup, error) {
+	if a.Status != BanAppealStatusOpen {
+		return UserGroupNaN, &httperr.Error{HTTPStatus: http.StatusConflict, Code: "ban-appeal-closed", Message: "Ban appeal is already reviewed."}
+	}
+	if a.CommunityID.Valid {
+		if is, err := UserModOrAdmin(ctx, a.db, a.CommunityID.ID, reviewer); err != nil {
+			return UserGroupNaN, err
+		} else if !is {
+			return UserGroupNaN, errNotMod
+		}
+		return modOrAdminGroup(ctx, a.db, a.CommunityID.ID, reviewer)
+	}
+	if is, err := IsAdmin(a.db, &reviewer); err != nil {
+		return UserGroupNaN, err
+	} else if !is {
+		return UserGroupNaN, errNotAdmin
+	}
+	return UserGroupAdmins, nil
+}
+
+// modLogEntry returns a mod log entry of action taken on a by reviewer.
+func (a *BanAppeal) modLogEntry(reviewer uid.ID, g UserGroup, action ModAction, message string) *ModLogEntry {
+	return &ModLogEntry{
+		CommunityID:  a.CommunityID,
+		ActorID:      reviewer,
+		ActorGroup:   g,
+		Action:       action,
+		TargetType:   "ban_appeal",
+		TargetID:     strconv.FormatUint(uint64(a.ID), 10),
+		TargetUserID: uid.NullID{ID: a.UserID, Valid: true},
+		Reason:       message,
+	}
+}
+
+// close marks a as reviewed with status by reviewer, and records entry in the
+// mod log. The update function, if not nil, is run in the same transaction.
+func (a *BanAppeal) close(ctx context.Context, reviewer uid.ID, status BanAppealStatus, message string, entry *ModLogEntry, update func(*sql.Tx) error) error {
+	now := time.Now()
+	err := msql.Transact(ctx, a.db, func(tx *sql.Tx) error {
+		if update != nil {
+			if err := update(tx); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE ban_appeals SET status = ?, response = ?, reviewed_by = ?, reviewed_at = ? WHERE id = ?",
+			status, msql.NilIfEmptyString(message), reviewer, now, a.ID); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.Status = status
+	a.Response = msql.NewNullString(msql.NilIfEmptyString(message))
+	a.ReviewedBy = uid.NullID{ID: reviewer, Valid: true}
+	a.ReviewedAt = msql.NewNullTime(now)
+	return nil
+}
+
+// notifyUser notifies the user of a of the outcome of its review.
+func (a *BanAppeal) notifyUser() {
+	go func() {
+		if err := CreateBanAppealNotification(context.Background(), a.db, a); err != nil {
+			log.Printf("Error creating ban appeal notification (appeal id: %v): %v\n", a.ID, err)
+		}
+	}()
+}
+
+// validateBanAppealResponse trims and validates message, the response of a reviewer.
+func validateBanAppealResponse(message string) (string, error) {
+	message = strings.TrimSpace(message)
+	if utf8.RuneCountInString(message) > maxBanAppealResponseLength {
+		return "", httperr.NewBadRequest("ban-appeal-response-too-long", fmt.Sprintf("Response cannot exceed %d characters.", maxBanAppealResponseLength))
+	}
+	return message, nil
+}
+
+// Accept accepts the appeal on behalf of reviewer, and unbans the user. The
+// message, which is sent to the user, is optional.
+func (a *BanAppeal) Accept(ctx context.Context, reviewer uid.ID, message string) error {
+	g, err := a.reviewerGroup(ctx, reviewer)
+	if err != nil {
+		return err
+	}
+	if message, err = validateBanAppealResponse(message); err != nil {
+		return err
+	}
+
+	if a.CommunityID.Valid {
+		comm, err := GetCommunityByID(ctx, a.db, a.CommunityID.ID, nil)
+		if err != nil {
+			return err
+		}
+		if err := comm.UnbanUser(ctx, reviewer, a.UserID); err != nil {
+			return err
+		}
+	} else {
+		user, err := GetUser(ctx, a.db, a.UserID, nil)
+		if err != nil {
+			return err
+		}
+		if err := user.Unban(ctx, reviewer); err != nil {
+			return err
+		}
+	}
+	if err := a.close(ctx, reviewer, BanAppealStatusAccepted, message, a.modLogEntry(reviewer, g, ModActionAcceptBanAppeal, message), nil); err != nil {
+		return err
+	}
+	a.BanExpires = msql.NullTime{}
+	a.notifyUser()
+	return nil
+}
+
+// Reject rejects the appeal on behalf of reviewer, leaving the ban as is. The
+// message, which is sent to the user, is optional.
+func (a *BanAppeal) Reject(ctx context.Context, reviewer uid.ID, message string) error {
+	g, err := a.reviewerGroup(ctx, reviewer)
+	if err != nil {
+		return err
+	}
+	if message, err = validateBanAppealResponse(message); err != nil {
+		return err
+	}
+	if err := a.close(ctx, reviewer, BanAppealStatusRejected, message, a.modLogEntry(reviewer, g, ModActionRejectBanAppeal, message), nil); err != nil {
+		return err
+	}
+	a.notifyUser()
+	return nil
+}
+
+// Shorten makes the ban expire at expires, on behalf of reviewer, and closes
+// the appeal. The new expiry must be in the future, and before the current
+// expiry of the ban (if any). The message, which is sent to the user, is
+// optional.
+func (a *BanAppeal) Shorten(ctx context.Context, reviewer uid.ID, expires time.Time, message string) error {
+	g, err := a.reviewerGroup(ctx, reviewer)
+	if err != nil {
+		return err
+	}
+	if message, err = validateBanAppealResponse(message); err != nil {
+		return err
+	}
+	if !expires.After(time.Now()) {
+		return httperr.NewBadRequest("ban-expiry-past", "Ban expiry must be in the future.")
+	}
+	if a.BanExpires.Valid && !expires.Before(a.BanExpires.Time) {
+		return httperr.NewBadRequest("ban-expiry-not-shorter", "Ban expiry must be before the current expiry.")
+	}
+
+	entry := a.modLogEntry(reviewer, g, ModActionShortenBan, message)
+	entry.Metadata = map[string]any{"expires": expires}
+	update := func(tx *sql.Tx) error {
+		query, args := "UPDATE users SET ban_expires = ? WHERE id = ? AND banned_at IS NOT NULL", []any{expires, a.UserID}
+		if a.CommunityID.Valid {
+			query, args = "UPDATE community_banned SET expires = ? WHERE community_id = ? AND user_id = ?", []any{expires, a.CommunityID.ID, a.UserID}
+		}
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return httperr.NewBadRequest("not-banned", "User is not banned.")
+		}
+		return nil
+	}
+	if err := a.close(ctx, reviewer, BanAppealStatusShortened, message, entry, update); err != nil {
+		return err
+	}
+	a.BanExpires = msql.NewNullTime(expires)
+	a.notifyUser()
+	return nil
+}
@@ -97,9 +97,16 @@ type Comment struct {
 
 	Author *User `json:"author,omitempty"`
 
+	// Whether the author of this comment is shadowbanned.
+	authorShadowbanned bool
+
 	// Reports whether the author of this comment is muted by the viewer.
 	IsAuthorMuted bool `json:"isAuthorMuted,omitempty"`
 
+	// The action to take on the comment if it's matched by the content filters
+	// of the viewer.
+	ViewerFiltered ContentFilterAction `json:"filtered,omitempty"`
+
 	ViewerVoted   msql.NullBool `json:"userVoted"`
 	ViewerVotedUp msql.NullBool `json:"userVotedUp"`
 
@@ -132,10 +139,11 @@ func buildSelectCommentsQuery(loggedIn bool, where string) string {
 		"comments.edited_at",
 		"comments.deleted_at",
 		"comments.deleted_as",
+		"comments.user_id IN (SELECT id FROM users WHERE shadowbanned_at IS NOT NULL)",
 	}
 	var joins []string
 	if loggedIn {
-		cols := append(cols, "comment_votes.id IS NOT NULL", "comment_votes.up")
+		cols := append(cols, "comment_votes.id IS NOT NULL", "comment_votes.up", "IFNULL(comment_votes.shadowbanned, FALSE)")
 		joins = []string{"LEFT OUTER JOIN comment_votes ON comments.id = comment_votes.comment_id AND comment_votes.user_id = ?"}
 		return msql.BuildSelectQuery("comments", cols, joins, where)
 	}
@@ -219,15 +227,29 @@ func scanComments(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *uid.I
 			&comment.EditedAt,
 			&comment.DeletedAt,
 			&comment.DeletedAs,
+			&comment.authorShadowbanned,
 		}
+		var viewerVoteShadowbanned bool
 		if loggedIn {
-			dest = append(dest, &comment.ViewerVoted, &comment.ViewerVotedUp)
+			dest = append(dest, &comment.ViewerVoted, &comment.ViewerVotedUp, &viewerVoteShadowbanned)
 		}
 
 		if err := rows.Scan(dest...); err != nil {
 			return nil, err
 		}
 
+		// The viewer's vote is not counted if it was cast while the viewer was
+		// shadowbanned. Count it for the viewer alone.
+		if viewerVoteShadowbanned {
+			if comment.ViewerVotedUp.Bool {
+				comment.Upvotes++
+				comment.Points++
+			} else {
+				comment.Downvotes++
+				comment.Points--
+			}
+		}
+
 		comment.Deleted = comment.DeletedAt.Valid
 		if comment.Deleted {
 			comment.setStrippedContent(false)
@@ -245,6 +267,9 @@ func scanComments(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *uid.I
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	if loggedIn && !viewerAdmin {
+		comments = removeShadowbannedComments(comments, viewer)
+	}
 	if len(comments) == 0 {
 		return nil, errCommentNotFound
 	}
@@ -262,6 +287,9 @@ func scanComments(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *uid.I
 				}
 			}
 		}
+		if err := setFilteredComments(ctx, db, *viewer, comments); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := populateCommentAuthors(ctx, db, comments, viewerAdmin); err != nil {
@@ -312,8 +340,9 @@ func scanComments(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *uid.I
 }
 
 // addComment adds a record to the comments table. It does not check if the post
-// is deleted or locked.
-func addComment(ctx context.Context, db *sql.DB, post *Post, author *User, parentID *uid.ID, commentBody string) (*Comment, error) {
+// is deleted or locked. If replyToDeleted is true, the parent comment may be
+// deleted.
+func addComment(ctx context.Context, db *sql.DB, post *Post, author *User, parentID *uid.ID, commentBody string, replyToDeleted bool) (*Comment, error) {
 	commentBody = utils.TruncateUnicodeString(commentBody, maxCommentBodyLength)
 	var (
 		parent    *Comment
@@ -326,7 +355,7 @@ func addComment(ctx context.Context, db *sql.DB, post *Post, author *User, paren
 		if err != nil {
 			return nil, err
 		}
-		if parent.Deleted {
+		if parent.Deleted && !replyToDeleted {
 			return nil, httperr.NewBadRequest("comment-reply-to-deleted", "Cannot reply to a deleted comment.")
 		}
 		if parent.Depth == maxCommentDepth {
@@ -426,23 +455,26 @@ func addComment(ctx context.Context, db *sql.DB, post *Post, author *User, paren
 	}
 
 	// Send notifications.
-	if parent != nil && !parent.AuthorID.EqualsTo(author.ID) {
-		go func() {
-			if err := CreateCommentReplyNotification(context.Background(), db, parent.AuthorID, parent.ID, id, author, post); err != nil {
-				log.Printf("Create reply notification failed: %v\n", err)
-			}
-		}()
+	go sendNewCommentNotifications(context.Background(), db, post, parent, id, ancestors, author)
+	if mentions := extractMentions(commentBody); len(mentions) > 0 {
+		// The post author and the parent comment author are notified of the
+		// comment above.
+		skip := []uid.ID{post.AuthorID}
+		if parent != nil {
+			skip = append(skip, parent.AuthorID)
+		}
+		go sendMentionNotifications(context.Background(), db, mentions, post, &id, author.ID, skip...)
+	}
 
+	comment, err := GetComment(ctx, db, id, nil)
+	if err != nil {
+		return nil, err
 	}
-	if !post.AuthorID.EqualsTo(author.ID) && (parent == nil || !(parent.AuthorID.EqualsTo(post.AuthorID))) {
-		go func() {
-			if err := CreateNewCommentNotification(context.Background(), db, post, id, author); err != nil {
-				log.Printf("Create new_comment notification failed: %v\n", err)
-			}
-		}()
+	if !comment.authorShadowbanned {
+		publishEvent(PostEventsTopic(post.ID), EventTypeNewComment, comment)
 	}
-
-	return GetComment(ctx, db, id, nil)
+	go runAutomodOnComment(db, AutomodEventCreate, comment.ID)
+	return comment, nil
 }
 
 // Save updates comment's body.
@@ -453,22 +485,48 @@ func (c *Comment) Save(ctx context.Context, user uid.ID) error {
 	if !c.AuthorID.EqualsTo(user) {
 		return errNotAuthor
 	}
+	if err := checkCommunityWritable(ctx, c.db, c.CommunityID); err != nil {
+		return err
+	}
 
 	c.Body = utils.TruncateUnicodeString(c.Body, maxCommentBodyLength)
 
+	var oldBody msql.NullString
+	if err := c.db.QueryRowContext(ctx, "SELECT body FROM comments WHERE id = ?", c.ID).Scan(&oldBody); err != nil {
+		return err
+	}
+
 	now := time.Now()
 	query := "UPDATE comments SET body = ?, edited_at = ? WHERE id = ? AND deleted_at IS NULL"
-	_, err := c.db.ExecContext(ctx, query, c.Body, now, c.ID)
-	if err == nil {
-		c.EditedAt.Valid = true
-		c.EditedAt.Time = now
+	if _, err := c.db.ExecContext(ctx, query, c.Body, now, c.ID); err != nil {
+		return err
 	}
-	return err
+	c.EditedAt.Valid = true
+	c.EditedAt.Time = now
+
+	if mentions := newMentions(oldBody.String, c.Body); len(mentions) > 0 {
+		go func() {
+			ctx := context.Background()
+			post, err := GetPost(ctx, c.db, &c.PostID, "", nil, true)
+			if err != nil {
+				log.Printf("Error getting post of edited comment: %v\n", err)
+				return
+			}
+			sendMentionNotifications(ctx, c.db, mentions, post, &c.ID, c.AuthorID)
+		}()
+	}
+	if err := queueIfReported(ctx, c.db, c.CommunityID, ContentTypeComment, c.ID, c.PostID); err != nil {
+		return err
+	}
+	go runAutomodOnComment(c.db, AutomodEventEdit, c.ID)
+	return nil
 }
 
 // Delete returns an error if user, who's deleting the comment, has no
-// permissions in his capacity as g to delete this comment.
-func (c *Comment) Delete(ctx context.Context, user uid.ID, g UserGroup) error {
+// permissions in his capacity as g to delete this comment. The removal, if not
+// nil, is the reason given by an admin or a mod, which is sent to the author of
+// the comment.
+func (c *Comment) Delete(ctx context.Context, user uid.ID, g UserGroup, removal *Removal) error {
 	if c.Deleted {
 		return errCommentDeleted
 	}
@@ -498,6 +556,10 @@ func (c *Comment) Delete(ctx context.Context, user uid.ID, g UserGroup) error {
 		return errInvalidUserGroup
 	}
 
+	if g == UserGroupNormal {
+		removal = nil
+	}
+
 	now := time.Now()
 	err := msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
 		var newBody string
@@ -521,20 +583,77 @@ func (c *Comment) Delete(ctx context.Context, user uid.ID, g UserGroup) error {
 		if _, err := tx.ExecContext(ctx, "UPDATE users SET no_comments = no_comments - 1 WHERE id = ?", c.AuthorID); err != nil {
 			return err
 		}
+		if g != UserGroupNormal {
+			entry := &ModLogEntry{
+				CommunityID:  uid.NullID{ID: c.CommunityID, Valid: true},
+				ActorID:      user,
+				ActorGroup:   g,
+				Action:       ModActionRemoveComment,
+				TargetType:   "comment",
+				TargetID:     c.ID.String(),
+				TargetUserID: uid.NullID{ID: c.AuthorID, Valid: true},
+				PostID:       uid.NullID{ID: c.PostID, Valid: true},
+			}
+			removal.setOnModLogEntry(entry)
+			return insertModLogEntry(ctx, tx, entry)
+		}
 		return nil
 	})
 	if err != nil {
 		return err
 	}
 
+	author := c.AuthorID
 	c.DeletedAt = msql.NewNullTime(now)
 	c.DeletedBy = uid.NullID{Valid: true, ID: user}
 	c.DeletedAs = g
 	c.StripContent()
 	RemoveAllReportsOfComment(ctx, c.db, c.ID)
+
+	if removal != nil && removal.Comment {
+		post, err := GetPost(ctx, c.db, &c.PostID, "", nil, true)
+		if err == nil {
+			_, err = post.addComment(ctx, user, g, &c.ID, removal.Reason, true)
+		}
+		if err != nil {
+			log.Printf("Failed to add removal reason comment on comment %v: %v\n", c.ID, err)
+		}
+	}
+
+	if removal != nil {
+		go func() {
+			if err := CreatePostDeletedNotification(context.Background(), c.db, author, g, false, c.ID, removal.Reason); err != nil {
+				log.Printf("Failed to create deleted_post notification on comment %v\n", c.ID)
+			}
+		}()
+	}
 	return err
 }
 
+// approve approves c, which is in the mod queue, and adds entry to the mod
+// log. If restore is true, the removal of c (by automod) is undone as well.
+func (c *Comment) approve(ctx context.Context, restore bool, entry *ModLogEntry) error {
+	var author uid.ID
+	if err := c.db.QueryRowContext(ctx, "SELECT user_id FROM comments WHERE id = ?", c.ID).Scan(&author); err != nil {
+		return err
+	}
+	entry.TargetUserID = uid.NullID{ID: author, Valid: true}
+	return msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		if restore {
+			if _, err := tx.ExecContext(ctx, "UPDATE comments SET deleted_at = NULL, deleted_by = NULL, deleted_as = ? WHERE id = ?", UserGroupNaN, c.ID); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, "UPDATE posts_comments SET deleted = false WHERE target_id = ? AND user_id = ?", c.ID, author); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, "UPDATE users SET no_comments = no_comments + 1 WHERE id = ?", author); err != nil {
+				return err
+			}
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
+}
+
 func (c *Comment) setStrippedContent(v bool) {
 	if c.ContentStripped == nil {
 		c.ContentStripped = new(bool)
@@ -574,6 +693,8 @@ func (c *Comment) StripContent() {
 }
 
 // Vote votes on comment (if the comment is not deleted or the post locked).
+// The votes of shadowbanned users are recorded but are not counted in the
+// points of the comment.
 func (c *Comment) Vote(ctx context.Context, user uid.ID, up bool) error {
 	if c.Deleted {
 		return errCommentDeleted
@@ -584,10 +705,18 @@ func (c *Comment) Vote(ctx context.Context, user uid.ID, up bool) error {
 	} else if is {
 		return errPostLocked
 	}
+	if err := checkCommunityWritable(ctx, c.db, c.CommunityID); err != nil {
+		return err
+	}
+
+	shadowbanned, err := UserShadowbanned(ctx, c.db, user)
+	if err != nil {
+		return err
+	}
 
 	point := 1
-	err := msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
-		if _, err := tx.ExecContext(ctx, "INSERT INTO comment_votes (comment_id, user_id, up) VALUES (?, ?, ?)", c.ID, user, up); err != nil {
+	err = msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO comment_votes (comment_id, user_id, up, shadowbanned) VALUES (?, ?, ?, ?)", c.ID, user, up, shadowbanned); err != nil {
 			if msql.IsErrDuplicateErr(err) {
 				return httperr.NewBadRequest("already-voted", "You've already voted on the comment.")
 			}
@@ -601,6 +730,9 @@ func (c *Comment) Vote(ctx context.Context, user uid.ID, up bool) error {
 			query += ", downvotes = downvotes + 1"
 		}
 		query += " WHERE id = ?"
+		if shadowbanned {
+			return nil
+		}
 		if _, err := tx.ExecContext(ctx, query, point, c.ID); err != nil {
 			return err
 		}
@@ -620,6 +752,10 @@ func (c *Comment) Vote(ctx context.Context, user uid.ID, up bool) error {
 	c.ViewerVotedUp.Valid = true
 	c.ViewerVotedUp.Bool = up
 
+	if shadowbanned {
+		return nil
+	}
+
 	// Attempt to update user's points.
 	if up && !c.AuthorID.EqualsTo(user) {
 		incrementUserPoints(ctx, c.db, c.AuthorID, 1)
@@ -634,6 +770,7 @@ func (c *Comment) Vote(ctx context.Context, user uid.ID, up bool) error {
 		}()
 	}
 
+	c.publishVotesEvent()
 	return nil
 }
 
@@ -649,10 +786,13 @@ func (c *Comment) DeleteVote(ctx context.Context, user uid.ID) error {
 	} else if is {
 		return errPostLocked
 	}
+	if err := checkCommunityWritable(ctx, c.db, c.CommunityID); err != nil {
+		return err
+	}
 
-	id, up := 0, false
-	row := c.db.QueryRowContext(ctx, "SELECT id, up FROM comment_votes WHERE comment_id = ? AND user_id = ?", c.ID, user)
-	if err := row.Scan(&id, &up); err != nil {
+	id, up, shadowbanned := 0, false, false
+	row := c.db.QueryRowContext(ctx, "SELECT id, up, shadowbanned FROM comment_votes WHERE comment_id = ? AND user_id = ?", c.ID, user)
+	if err := row.Scan(&id, &up, &shadowbanned); err != nil {
 		return err
 	}
 
@@ -669,6 +809,9 @@ func (c *Comment) DeleteVote(ctx context.Context, user uid.ID) error {
 			query += ", downvotes = downvotes - 1"
 		}
 		query += " WHERE id = ?"
+		if shadowbanned { // The vote was not counted.
+			return nil
+		}
 		if _, err := tx.ExecContext(ctx, query, point, c.ID); err != nil {
 			return err
 		}
@@ -687,11 +830,16 @@ func (c *Comment) DeleteVote(ctx context.Context, user uid.ID) error {
 	c.ViewerVoted.Valid = false
 	c.ViewerVotedUp.Valid = false
 
+	if shadowbanned {
+		return nil
+	}
+
 	// Attempt to update user's points.
 	if up && !c.AuthorID.EqualsTo(user) {
 		incrementUserPoints(ctx, c.db, c.AuthorID, -1)
 	}
 
+	c.publishVotesEvent()
 	return nil
 }
 
@@ -707,10 +855,13 @@ func (c *Comment) ChangeVote(ctx context.Context, user uid.ID, up bool) error {
 	} else if is {
 		return errPostLocked
 	}
+	if err := checkCommunityWritable(ctx, c.db, c.CommunityID); err != nil {
+		return err
+	}
 
-	id, dbUp := 0, false
-	row := c.db.QueryRowContext(ctx, "SELECT id, up FROM comment_votes WHERE comment_id = ? AND user_id = ?", c.ID, user)
-	if err := row.Scan(&id, &dbUp); err != nil {
+	id, dbUp, shadowbanned := 0, false, false
+	row := c.db.QueryRowContext(ctx, "SELECT id, up, shadowbanned FROM comment_votes WHERE comment_id = ? AND user_id = ?", c.ID, user)
+	if err := row.Scan(&id, &dbUp, &shadowbanned); err != nil {
 		return err
 	}
 
@@ -731,6 +882,9 @@ func (c *Comment) ChangeVote(ctx context.Context, user uid.ID, up bool) error {
 			query += ", upvotes = upvotes + 1, downvotes = downvotes - 1"
 		}
 		query += " WHERE id = ?"
+		if shadowbanned { // The vote was not counted.
+			return nil
+		}
 		if _, err := tx.ExecContext(ctx, query, points, c.ID); err != nil {
 			return err
 		}
@@ -750,6 +904,10 @@ func (c *Comment) ChangeVote(ctx context.Context, user uid.ID, up bool) error {
 	c.Points += points
 	c.ViewerVotedUp = msql.NewNullBool(up)
 
+	if shadowbanned {
+		return nil
+	}
+
 	// Attemp to update user's points.
 	if !c.AuthorID.EqualsTo(user) {
 		points := 1
@@ -759,6 +917,7 @@ func (c *Comment) ChangeVote(ctx context.Context, user uid.ID, up bool) error {
 		incrementUserPoints(ctx, c.db, c.AuthorID, points)
 	}
 
+	c.publishVotesEvent()
 	return nil
 }
 
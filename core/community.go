@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -36,14 +37,31 @@ type Community struct {
 	CreatedAt     time.Time       `json:"createdAt"`
 	DeletedAt     msql.NullTime   `json:"deletedAt"`
 	DeletedBy     uid.NullID      `json:"-"`
+	ArchivedAt    msql.NullTime   `json:"archivedAt"` // Archived communities are read-only.
+
+	Visibility          CommunityVisibility `json:"visibility"`
+	PostingRequirements PostingRequirements `json:"postingRequirements"`
+	ModLogPublic        bool                `json:"modLogPublic"` // Whether anyone can view the mod log.
 
 	// IsDefault is nil until Default is called.
 	IsDefault *bool `json:"isDefault,omitempty"`
 
+	// IsAbandoned is nil until Abandoned is called.
+	IsAbandoned *bool `json:"isAbandoned,omitempty"`
+
 	ViewerJoined  msql.NullBool `json:"userJoined"`
 	ViewerMod     msql.NullBool `json:"userMod"`
 	MutedByViewer bool          `json:"isMuted"`
 
+	// Whether the viewer has a pending membership request to, or an invitation
+	// from, the (restricted or private) community.
+	ViewerRequested bool `json:"userRequested"`
+	ViewerInvited   bool `json:"userInvited"`
+
+	// The number of items in the mod queue. It's only set on the communities
+	// of User.ModdingList, by User.LoadModQueueCounts.
+	ModQueueCount *int `json:"modQueueCount,omitempty"`
+
 	Mods           []*User                  `json:"mods"`
 	Rules          []*CommunityRule         `json:"rules"`
 	ReportsDetails *CommunityReportsDetails `json:"ReportsDetails"`
@@ -56,10 +74,18 @@ func buildSelectCommunityQuery(where string) string {
 		"communities.name",
 		"communities.name_lc",
 		"communities.nsfw",
+		"communities.visibility",
+		"communities.allowed_post_types",
+		"communities.min_account_age",
+		"communities.min_points",
+		"communities.title_regex",
+		"communities.min_body_length",
+		"communities.mod_log_public",
 		"communities.about",
 		"communities.no_members",
 		"communities.created_at",
 		"communities.deleted_at",
+		"communities.archived_at",
 	}
 	cols = append(cols, images.ImageColumns("pro_pic")...)
 	cols = append(cols, images.ImageColumns("banner")...)
@@ -103,6 +129,8 @@ func getCommunities(ctx context.Context, db *sql.DB, viewer *uid.ID, where strin
 }
 
 // GetCommunityByName returns a not-found httperr.Error if no community is found.
+// If name is a former name of a community (see Community.Rename), that
+// community is returned.
 func GetCommunityByName(ctx context.Context, db *sql.DB, name string, viewer *uid.ID) (*Community, error) {
 	name = strings.ToLower(name)
 	comms, err := getCommunities(ctx, db, viewer, "WHERE name_lc = ?", name)
@@ -111,7 +139,13 @@ func GetCommunityByName(ctx context.Context, db *sql.DB, name string, viewer *ui
 	}
 
 	if len(comms) == 0 {
-		return nil, errCommunityNotFound
+		comms, err = getCommunities(ctx, db, viewer, "WHERE communities.id = (SELECT community_id FROM community_name_history WHERE community_name_history.name_lc = ?)", name)
+		if err != nil {
+			return nil, err
+		}
+		if len(comms) == 0 {
+			return nil, errCommunityNotFound
+		}
 	}
 
 	if viewer != nil {
@@ -175,16 +209,28 @@ func scanCommunities(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *ui
 	var comms []*Community
 	for rows.Next() {
 		c := &Community{db: db}
+		var (
+			postTypes  uint
+			titleRegex msql.NullString
+		)
 		dests := []any{
 			&c.ID,
 			&c.AuthorID,
 			&c.Name,
 			&c.NameLowerCase,
 			&c.NSFW,
+			&c.Visibility,
+			&postTypes,
+			&c.PostingRequirements.MinAccountAge,
+			&c.PostingRequirements.MinPoints,
+			&titleRegex,
+			&c.PostingRequirements.MinBodyLength,
+			&c.ModLogPublic,
 			&c.About,
 			&c.NumMembers,
 			&c.CreatedAt,
 			&c.DeletedAt,
+			&c.ArchivedAt,
 		}
 
 		proPic, bannerImage := &images.Image{}, &images.Image{}
@@ -194,6 +240,8 @@ func scanCommunities(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *ui
 		if err := rows.Scan(dests...); err != nil {
 			return nil, err
 		}
+		c.PostingRequirements.setPostTypes(postTypes)
+		c.PostingRequirements.TitleRegex = titleRegex.String
 
 		if proPic.ID != nil {
 			proPic.PostScan()
@@ -295,7 +343,7 @@ func CreateCommunity(ctx context.Context, db *sql.DB, creator uid.ID, reqPoints,
 	// Attempt to make user a mod of community.
 	if err := comm.Join(ctx, creator); err == nil {
 		comm.ViewerJoined = msql.NewNullBool(true)
-		if err = makeUserMod(ctx, db, comm, creator, true); err == nil {
+		if err = makeUserMod(ctx, db, comm, creator, true, nil); err == nil {
 			comm.ViewerMod = msql.NewNullBool(true)
 		}
 	}
@@ -429,17 +477,52 @@ func GetCommunitiesPrefix(ctx context.Context, db *sql.DB, s string) ([]*Communi
 	return deduped, nil
 }
 
-// Update updates c.About and c.NSFW.
+// Update updates c.About, c.NSFW, c.Visibility, c.ModLogPublic, and
+// c.PostingRequirements.
 func (c *Community) Update(ctx context.Context, mod uid.ID) error {
+	if c.DeletedAt.Valid {
+		return errCommunityNotFound
+	}
 	if is, err := c.UserModOrAdmin(ctx, mod); err != nil {
 		return err
 	} else if !is {
 		return errNotMod
 	}
+	if !c.Visibility.Valid() {
+		return errInvalidCommunityVisibility
+	}
+	reqs := &c.PostingRequirements
+	if err := reqs.validate(); err != nil {
+		return err
+	}
+
+	entry, err := communityModLogEntry(ctx, c.db, c.ID, mod, ModActionUpdateCommunity)
+	if err != nil {
+		return err
+	}
+	entry.TargetType, entry.TargetID = "community", c.ID.String()
 
 	c.About.String = utils.TruncateUnicodeString(c.About.String, maxCommunityAboutLength)
-	_, err := c.db.ExecContext(ctx, "UPDATE communities SET nsfw = ?, about = ? WHERE id = ?", c.NSFW, c.About, c.ID)
-	return err
+	return msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE communities SET
+				nsfw = ?, visibility = ?, about = ?, mod_log_public = ?,
+				allowed_post_types = ?, min_account_age = ?, min_points = ?, title_regex = ?, min_body_length = ?
+			WHERE id = ?`,
+			c.NSFW, c.Visibility, c.About, c.ModLogPublic,
+			reqs.postTypesMask(), reqs.MinAccountAge, reqs.MinPoints, msql.NilIfEmptyString(reqs.TitleRegex), reqs.MinBodyLength,
+			c.ID)
+		if err != nil {
+			return err
+		}
+		if c.Visibility == CommunityPublic {
+			// Pending requests and invitations are meaningless now.
+			if _, err := tx.ExecContext(ctx, "DELETE FROM community_member_requests WHERE community_id = ?", c.ID); err != nil {
+				return err
+			}
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
 }
 
 // Default reports whether c is a default community, and, if there's no error,
@@ -463,6 +546,9 @@ func (c *Community) Default(ctx context.Context) (bool, error) {
 // removed from the default communities.
 func (c *Community) SetDefault(ctx context.Context, set bool) error {
 	if set {
+		if c.DeletedAt.Valid {
+			return errCommunityNotFound
+		}
 		_, err := c.db.ExecContext(ctx, "INSERT INTO default_communities (name_lc, community_id) VALUES (?, ?)", c.NameLowerCase, c.ID)
 		if err != nil && msql.IsErrDuplicateErr(err) {
 			return nil
@@ -647,7 +733,8 @@ func (c *Community) DeleteBannerImageTx(ctx context.Context, tx *sql.Tx) error {
 	return nil
 }
 
-// PopulateViewerFields populates c.ViewerJoined and c.ViewerMod fields.
+// PopulateViewerFields populates c.ViewerJoined and c.ViewerMod fields (and,
+// if the viewer is not a member, c.ViewerRequested and c.ViewerInvited).
 func (c *Community) PopulateViewerFields(ctx context.Context, user uid.ID) error {
 	row := c.db.QueryRowContext(ctx, "SELECT is_mod FROM community_members WHERE community_id = ? AND user_id = ?", c.ID, user)
 	isMod := false
@@ -655,7 +742,7 @@ func (c *Community) PopulateViewerFields(ctx context.Context, user uid.ID) error
 		if err == sql.ErrNoRows {
 			c.ViewerJoined = msql.NewNullBool(false)
 			c.ViewerMod = msql.NewNullBool(false)
-			return nil
+			return c.populateViewerRequest(ctx, user)
 		}
 		return err
 	}
@@ -664,7 +751,7 @@ func (c *Community) PopulateViewerFields(ctx context.Context, user uid.ID) error
 	return nil
 }
 
-// BanUser bans user by mod. If expires is non-nil, the ban is permanent.
+// BanUser bans user by mod. If expires is nil, the ban is permanent.
 func (c *Community) BanUser(ctx context.Context, mod, user uid.ID, expires *time.Time) error {
 	if is, err := c.UserModOrAdmin(ctx, mod); err != nil {
 		return err
@@ -679,8 +766,29 @@ func (c *Community) BanUser(ctx context.Context, mod, user uid.ID, expires *time
 		t.Valid = true
 		t.Time = *expires
 	}
-	_, err := c.db.ExecContext(ctx, "INSERT INTO community_banned (user_id, community_id, expires, banned_by) VALUES (?, ?, ?, ?)", user, c.ID, t, mod)
-	return err
+	entry, err := c.userModLogEntry(ctx, mod, user, ModActionBanUser)
+	if err != nil {
+		return err
+	}
+	if t.Valid {
+		entry.Metadata = map[string]any{"expires": t.Time}
+	}
+	err = msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO community_banned (user_id, community_id, expires, banned_by) VALUES (?, ?, ?, ?)", user, c.ID, t, mod); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := CreateCommunityBanNotification(context.Background(), c.db, user, c.Name, expires); err != nil {
+			log.Printf("Create community_ban notification failed: %v\n", err)
+		}
+	}()
+	return nil
 }
 
 func (c *Community) UnbanUser(ctx context.Context, mod, user uid.ID) error {
@@ -689,28 +797,59 @@ func (c *Community) UnbanUser(ctx context.Context, mod, user uid.ID) error {
 	} else if !is {
 		return errNotMod
 	}
-	return unbanUserFromCommunity(ctx, c.db, c.ID, user)
+	entry, err := c.userModLogEntry(ctx, mod, user, ModActionUnbanUser)
+	if err != nil {
+		return err
+	}
+	return msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM community_banned WHERE community_id = ? AND user_id = ?", c.ID, user); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
 }
 
-func unbanUserFromCommunity(ctx context.Context, db *sql.DB, community, user uid.ID) error {
-	_, err := db.ExecContext(ctx, "DELETE FROM community_banned WHERE community_id = ? AND user_id = ?", community, user)
-	return err
+// userModLogEntry returns a mod log entry of action taken against user by mod
+// in c.
+func (c *Community) userModLogEntry(ctx context.Context, mod, user uid.ID, action ModAction) (*ModLogEntry, error) {
+	entry, err := communityModLogEntry(ctx, c.db, c.ID, mod, action)
+	if err != nil {
+		return nil, err
+	}
+	entry.TargetType, entry.TargetID = "user", user.String()
+	entry.TargetUserID = uid.NullID{ID: user, Valid: true}
+	return entry, nil
 }
 
-func (c *Community) GetBannedUsers(ctx context.Context) ([]*User, error) {
-	rows, err := c.db.QueryContext(ctx, "SELECT user_id FROM community_banned WHERE community_id = ?", c.ID)
+// GetBannedUsers returns the users banned from c, along with the remaining
+// durations of their bans.
+func (c *Community) GetBannedUsers(ctx context.Context) ([]*BannedUser, error) {
+	rows, err := c.db.QueryContext(ctx, "SELECT user_id, expires, created_at FROM community_banned WHERE community_id = ? ORDER BY created_at DESC", c.ID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var ids []uid.ID
+	bans := make(map[uid.ID]*BannedUser)
+	now := time.Now()
 	for rows.Next() {
-		var id uid.ID
-		if err = rows.Scan(&id); err != nil {
+		var (
+			id  uid.ID
+			ban BannedUser
+		)
+		if err = rows.Scan(&id, &ban.Expires, &ban.BannedAt); err != nil {
 			return nil, err
 		}
+		if ban.Expires.Valid {
+			if !ban.Expires.Time.After(now) {
+				continue // Yet to be lifted.
+			}
+			secs := int64(ban.Expires.Time.Sub(now).Seconds())
+			ban.ExpiresIn = &secs
+		}
 		ids = append(ids, id)
+		bans[id] = &ban
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -719,26 +858,36 @@ func (c *Community) GetBannedUsers(ctx context.Context) ([]*User, error) {
 	if len(ids) == 0 {
 		return nil, nil
 	}
-	return GetUsersByIDs(ctx, c.db, ids, nil)
+	users, err := GetUsersByIDs(ctx, c.db, ids, nil)
+	if err != nil {
+		return nil, err
+	}
+	banned := make([]*BannedUser, 0, len(users))
+	for _, user := range users {
+		ban := bans[user.ID]
+		ban.User = user
+		banned = append(banned, ban)
+	}
+	return banned, nil
 }
 
 // IsUserBannedFromCommunity checks if user is banned from community. If user is
-// banned and the ban is expired he is unbanned.
+// banned and the ban is expired the ban is lifted.
 func IsUserBannedFromCommunity(ctx context.Context, db *sql.DB, community, user uid.ID) (bool, error) {
-	row := db.QueryRowContext(ctx, "SELECT expires FROM community_banned WHERE community_id = ? AND user_id = ?", community, user)
-	var expires msql.NullTime
-	if err := row.Scan(&expires); err != nil {
+	row := db.QueryRowContext(ctx, "SELECT expires, banned_by FROM community_banned WHERE community_id = ? AND user_id = ?", community, user)
+	var (
+		expires  msql.NullTime
+		bannedBy uid.ID
+	)
+	if err := row.Scan(&expires, &bannedBy); err != nil {
 		if err == sql.ErrNoRows {
 			return false, nil
 		}
 		return false, err
 	}
-	if expires.Valid {
-		now := time.Now()
-		if now.Sub(expires.Time) < 0 {
-			// expired
-			return false, unbanUserFromCommunity(ctx, db, community, user)
-		}
+	if now := time.Now(); expires.Valid && !expires.Time.After(now) {
+		_, err := liftCommunityBan(ctx, db, community, user, bannedBy, now)
+		return false, err
 	}
 	return true, nil
 }
@@ -998,7 +1147,16 @@ func MakeUserMod(ctx context.Context, db *sql.DB, c *Community, viewer uid.ID, u
 		}
 	}
 
-	err = makeUserMod(ctx, db, c, user, isMod)
+	action := ModActionAddMod
+	if !isMod {
+		action = ModActionRemoveMod
+	}
+	entry, err := c.userModLogEntry(ctx, viewer, user, action)
+	if err != nil {
+		return err
+	}
+
+	err = makeUserMod(ctx, db, c, user, isMod, entry)
 	if err == nil {
 		if err := c.FixModPositions(ctx); err != nil {
 			log.Println("Fixing mod positions failed: ", err)
@@ -1021,15 +1179,16 @@ func MakeUserMod(ctx context.Context, db *sql.DB, c *Community, viewer uid.ID, u
 // MakeUserModCLI adds or removes user as a mod of c. Do not use this function
 // in an API.
 func MakeUserModCLI(db *sql.DB, c *Community, user uid.ID, isMod bool) error {
-	return makeUserMod(context.Background(), db, c, user, isMod)
+	return makeUserMod(context.Background(), db, c, user, isMod, nil)
 }
 
 // makeUserMod makes user a moderator of c, or, if isMod is false, user is
-// removed as a moderator of c.
+// removed as a moderator of c. If logEntry is non-nil, it's added to the mod
+// log.
 //
 // It's okay to call this function if user is already a mod of c. It doesn't
 // change anything.
-func makeUserMod(ctx context.Context, db *sql.DB, c *Community, user uid.ID, isMod bool) error {
+func makeUserMod(ctx context.Context, db *sql.DB, c *Community, user uid.ID, isMod bool, logEntry *ModLogEntry) error {
 	// When changing the SQL queries of this function, make duplicate the
 	// changes in User.Delete function as well.
 
@@ -1045,35 +1204,45 @@ func makeUserMod(ctx context.Context, db *sql.DB, c *Community, user uid.ID, isM
 	}
 
 	return msql.Transact(ctx, db, func(tx *sql.Tx) error {
-		lowestPos := -1
-		row := tx.QueryRowContext(ctx, "SELECT position FROM community_mods WHERE community_id = ? ORDER BY position DESC LIMIT 1", c.ID)
-		if err := row.Scan(&lowestPos); err != nil {
-			if err != sql.ErrNoRows {
-				return err
-			}
+		if err := makeUserModTx(ctx, tx, c, user, isMod); err != nil {
+			return err
 		}
-
-		query := ""
-		var args []any
-		if isMod {
-			query = "INSERT INTO community_mods (community_id, user_id, position) VALUES (?, ?, ?)"
-			args = append(args, c.ID, user, lowestPos+1)
-		} else {
-			query = "DELETE FROM community_mods WHERE community_id = ? AND user_id = ?"
-			args = append(args, c.ID, user)
+		if logEntry != nil {
+			return insertModLogEntry(ctx, tx, logEntry)
 		}
+		return nil
+	})
+}
 
-		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
-			if !(isMod && msql.IsErrDuplicateErr(err)) {
-				return err
-			}
+// makeUserModTx adds user to (or, if isMod is false, removes user from) the
+// mods of c within tx. The user must already be a member of c.
+func makeUserModTx(ctx context.Context, tx *sql.Tx, c *Community, user uid.ID, isMod bool) error {
+	lowestPos := -1
+	row := tx.QueryRowContext(ctx, "SELECT position FROM community_mods WHERE community_id = ? ORDER BY position DESC LIMIT 1", c.ID)
+	if err := row.Scan(&lowestPos); err != nil {
+		if err != sql.ErrNoRows {
+			return err
 		}
+	}
 
-		if _, err := tx.ExecContext(ctx, "UPDATE community_members SET is_mod = ? WHERE community_id = ? AND user_id = ?", isMod, c.ID, user); err != nil {
+	query := ""
+	var args []any
+	if isMod {
+		query = "INSERT INTO community_mods (community_id, user_id, position) VALUES (?, ?, ?)"
+		args = append(args, c.ID, user, lowestPos+1)
+	} else {
+		query = "DELETE FROM community_mods WHERE community_id = ? AND user_id = ?"
+		args = append(args, c.ID, user)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		if !(isMod && msql.IsErrDuplicateErr(err)) {
 			return err
 		}
-		return nil
-	})
+	}
+
+	_, err := tx.ExecContext(ctx, "UPDATE community_members SET is_mod = ? WHERE community_id = ? AND user_id = ?", isMod, c.ID, user)
+	return err
 }
 
 func (c *Community) AddRule(ctx context.Context, rule, description string, mod uid.ID) error {
@@ -1093,8 +1262,23 @@ func (c *Community) AddRule(ctx context.Context, rule, description string, mod u
 	if description != "" {
 		d = description
 	}
-	_, err := c.db.ExecContext(ctx, "INSERT INTO community_rules (rule, description, community_id, created_by, z_index) VALUES (?, ?, ?, ?, ?)", rule, d, c.ID, mod, zIndex+1)
-	return err
+	entry, err := communityModLogEntry(ctx, c.db, c.ID, mod, ModActionAddRule)
+	if err != nil {
+		return err
+	}
+	entry.Metadata = map[string]any{"rule": rule, "description": description}
+	return msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, "INSERT INTO community_rules (rule, description, community_id, created_by, z_index) VALUES (?, ?, ?, ?, ?)", rule, d, c.ID, mod, zIndex+1)
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		entry.TargetType, entry.TargetID = "rule", strconv.FormatInt(id, 10)
+		return insertModLogEntry(ctx, tx, entry)
+	})
 }
 
 func (c *Community) RemoveRule(ctx context.Context, ruleID string, mod uid.ID) error {
@@ -1103,8 +1287,17 @@ func (c *Community) RemoveRule(ctx context.Context, ruleID string, mod uid.ID) e
 	} else if !is {
 		return errNotMod
 	}
-	_, err := c.db.ExecContext(ctx, "DELETE FROM community_rules WHERE id = ?", ruleID)
-	return err
+	entry, err := communityModLogEntry(ctx, c.db, c.ID, mod, ModActionDeleteRule)
+	if err != nil {
+		return err
+	}
+	entry.TargetType, entry.TargetID = "rule", ruleID
+	return msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM community_rules WHERE id = ? AND community_id = ?", ruleID, c.ID); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
 }
 
 // FetchRules populates c.Rules.
@@ -1206,8 +1399,16 @@ func (r *CommunityRule) Update(ctx context.Context, mod uid.ID) error {
 	} else if !is {
 		return errNotMod
 	}
-	_, err := r.db.ExecContext(ctx, "UPDATE community_rules SET rule = ?, description = ?, z_index = ? WHERE id = ?", r.Rule, r.Description, r.ZIndex, r.ID)
-	return err
+	entry, err := r.modLogEntry(ctx, mod, ModActionEditRule)
+	if err != nil {
+		return err
+	}
+	return msql.Transact(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE community_rules SET rule = ?, description = ?, z_index = ? WHERE id = ?", r.Rule, r.Description, r.ZIndex, r.ID); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
 }
 
 func (r *CommunityRule) Delete(ctx context.Context, mod uid.ID) error {
@@ -1216,8 +1417,27 @@ func (r *CommunityRule) Delete(ctx context.Context, mod uid.ID) error {
 	} else if !is {
 		return errNotMod
 	}
-	_, err := r.db.ExecContext(ctx, "DELETE FROM community_rules WHERE id = ?", r.ID)
-	return err
+	entry, err := r.modLogEntry(ctx, mod, ModActionDeleteRule)
+	if err != nil {
+		return err
+	}
+	return msql.Transact(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM community_rules WHERE id = ?", r.ID); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
+}
+
+// modLogEntry returns a mod log entry of action taken on r by mod.
+func (r *CommunityRule) modLogEntry(ctx context.Context, mod uid.ID, action ModAction) (*ModLogEntry, error) {
+	entry, err := communityModLogEntry(ctx, r.db, r.CommunityID, mod, action)
+	if err != nil {
+		return nil, err
+	}
+	entry.TargetType, entry.TargetID = "rule", strconv.FormatUint(uint64(r.ID), 10)
+	entry.Metadata = map[string]any{"rule": r.Rule, "description": r.Description.String}
+	return entry, nil
 }
 
 // CommunityReportsDetails holds summary information about user-reports
@@ -1229,15 +1449,15 @@ type CommunityReportsDetails struct {
 }
 
 func FetchReportsDetails(ctx context.Context, db *sql.DB, community uid.ID) (d CommunityReportsDetails, err error) {
-	row := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reports WHERE community_id = ?", community)
+	row := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reports WHERE community_id = ? AND "+modReportsCond, community)
 	if err = row.Scan(&d.NumReports); err != nil {
 		return
 	}
-	row = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reports WHERE community_id = ? AND report_type = ?", community, ReportTypePost)
+	row = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reports WHERE community_id = ? AND report_type = ? AND "+modReportsCond, community, ReportTypePost)
 	if err = row.Scan(&d.NumPostReports); err != nil {
 		return
 	}
-	row = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reports WHERE community_id = ? AND report_type = ?", community, ReportTypeComment)
+	row = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reports WHERE community_id = ? AND report_type = ? AND "+modReportsCond, community, ReportTypeComment)
 	if err = row.Scan(&d.NumCommentReports); err != nil {
 		return
 	}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// A deleted community can be restored within this long of its deletion.
+const communityRestoreWindow = time.Hour * 24 * 30
+
+// checkAdminAction returns an error if user is not an admin.
+func checkAdminAction(db *sql.DB, user uid.ID) error {
+	if is, err := IsAdmin(db, &user); err != nil {
+		return err
+	} else if !is {
+		return errNotAdmin
+	}
+	return nil
+}
+
+// adminModLogEntry returns a mod log entry of action taken on c by admin.
+func (c *Community) adminModLogEntry(admin uid.ID, action ModAction) *ModLogEntry {
+	return &ModLogEntry{
+		CommunityID: uid.NullID{ID: c.ID, Valid: true},
+		ActorID:     admin,
+		ActorGroup:  UserGroupAdmins,
+		Action:      action,
+		TargetType:  "community",
+		TargetID:    c.ID.String(),
+	}
+}
+
+// Delete soft deletes c on behalf of admin. Deleted communities, along with
+// their posts, are only visible to admins, and they're removed from the
+// default communities. A deleted community can be restored within 30 days.
+func (c *Community) Delete(ctx context.Context, admin uid.ID) error {
+	if err := checkAdminAction(c.db, admin); err != nil {
+		return err
+	}
+	if c.DeletedAt.Valid {
+		return &httperr.Error{HTTPStatus: http.StatusConflict, Code: "community-deleted", Message: "Community is already deleted."}
+	}
+	return c.setDeleted(ctx, true, uid.NullID{ID: admin, Valid: true}, c.adminModLogEntry(admin, ModActionDeleteCommunity))
+}
+
+// Restore undoes the deletion of c on behalf of admin. It returns an error if
+// c was deleted more than 30 days ago. Restoring c doesn't add it back to the
+// default communities.
+func (c *Community) Restore(ctx context.Context, admin uid.ID) error {
+	if err := checkAdminAction(c.db, admin); err != nil {
+		return err
+	}
+	if !c.DeletedAt.Valid {
+		return httperr.NewBadRequest("community-not-deleted", "Community is not deleted.")
+	}
+	if time.Since(c.DeletedAt.Time) > communityRestoreWindow {
+		return httperr.NewForbidden("community-restore-window-passed", "Community was deleted too long ago to be restored.")
+	}
+	return c.setDeleted(ctx, false, uid.NullID{}, c.adminModLogEntry(admin, ModActionRestoreCommunity))
+}
+
+// SetDeletedCLI deletes c, or, if deleted is false, restores c (regardless of
+// when it was deleted). Do not use this function in an API.
+func (c *Community) SetDeletedCLI(ctx context.Context, deleted bool) error {
+	return c.setDeleted(ctx, deleted, uid.NullID{}, nil)
+}
+
+// setDeleted deletes, or restores, c. If logEntry is non-nil, it's added to
+// the mod log.
+func (c *Community) setDeleted(ctx context.Context, deleted bool, by uid.NullID, logEntry *ModLogEntry) error {
+	var deletedAt msql.NullTime
+	if deleted {
+		deletedAt = msql.NewNullTime(time.Now())
+	}
+	err := msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE communities SET deleted_at = ?, deleted_by = ? WHERE id = ?", deletedAt, by, c.ID); err != nil {
+			return err
+		}
+		if deleted {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM default_communities WHERE community_id = ?", c.ID); err != nil {
+				return err
+			}
+		}
+		if logEntry != nil {
+			return insertModLogEntry(ctx, tx, logEntry)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.DeletedAt, c.DeletedBy = deletedAt, by
+	if deleted {
+		c.IsDefault = new(bool)
+	}
+	return nil
+}
+
+// SetArchived archives c, or, if archived is false, unarchives c, on behalf of
+// admin. Archived communities are read-only: they can be browsed, but nothing
+// can be posted, commented, or voted on in them.
+func (c *Community) SetArchived(ctx context.Context, admin uid.ID, archived bool) error {
+	if err := checkAdminAction(c.db, admin); err != nil {
+		return err
+	}
+	if c.DeletedAt.Valid {
+		return errCommunityNotFound
+	}
+	if c.ArchivedAt.Valid == archived {
+		return nil
+	}
+	action := ModActionArchiveCommunity
+	if !archived {
+		action = ModActionUnarchiveCommunity
+	}
+	return c.setArchived(ctx, archived, uid.NullID{ID: admin, Valid: true}, c.adminModLogEntry(admin, action))
+}
+
+// SetArchivedCLI archives, or unarchives, c. Do not use this function in an
+// API.
+func (c *Community) SetArchivedCLI(ctx context.Context, archived bool) error {
+	return c.setArchived(ctx, archived, uid.NullID{}, nil)
+}
+
+// setArchived archives, or unarchives, c. If logEntry is non-nil, it's added
+// to the mod log.
+func (c *Community) setArchived(ctx context.Context, archived bool, by uid.NullID, logEntry *ModLogEntry) error {
+	var archivedAt msql.NullTime
+	if archived {
+		archivedAt = msql.NewNullTime(time.Now())
+	} else {
+		by = uid.NullID{}
+	}
+	err := msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE communities SET archived_at = ?, archived_by = ? WHERE id = ?", archivedAt, by, c.ID); err != nil {
+			return err
+		}
+		if logEntry != nil {
+			return insertModLogEntry(ctx, tx, logEntry)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.ArchivedAt = archivedAt
+	return nil
+}
@@ -0,0 +1,447 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// CommunityVisibility determines who can read, join, and post in a community.
+type CommunityVisibility int
+
+const (
+	CommunityPublic     = CommunityVisibility(iota) // Anyone can read, join, and post.
+	CommunityRestricted                             // Anyone can read; only approved members can post and comment.
+	CommunityPrivate                                // Only approved members can read, post, and comment.
+)
+
+func (v CommunityVisibility) Valid() bool {
+	_, err := v.MarshalText()
+	return err == nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (v CommunityVisibility) MarshalText() ([]byte, error) {
+	switch v {
+	case CommunityPublic:
+		return []byte("public"), nil
+	case CommunityRestricted:
+		return []byte("restricted"), nil
+	case CommunityPrivate:
+		return []byte("private"), nil
+	}
+	return nil, fmt.Errorf("cannot marshal unsupported CommunityVisibility (%v)", int(v))
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (v *CommunityVisibility) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "public":
+		*v = CommunityPublic
+	case "restricted":
+		*v = CommunityRestricted
+	case "private":
+		*v = CommunityPrivate
+	default:
+		return errInvalidCommunityVisibility
+	}
+	return nil
+}
+
+// isCommunityMemberOrAdmin reports whether user is a member of community or is
+// an admin.
+func isCommunityMemberOrAdmin(ctx context.Context, db *sql.DB, community, user uid.ID) (bool, error) {
+	var n int
+	err := db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM users
+		WHERE id = ? AND (is_admin = TRUE OR id IN (SELECT user_id FROM community_members WHERE community_id = ?))`,
+		user, community).Scan(&n)
+	return n > 0, err
+}
+
+// ViewableBy reports whether viewer (nil if logged out) can see the posts and
+// comments of c.
+func (c *Community) ViewableBy(ctx context.Context, viewer *uid.ID) (bool, error) {
+	if c.Visibility != CommunityPrivate {
+		return true, nil
+	}
+	if viewer == nil {
+		return false, nil
+	}
+	return isCommunityMemberOrAdmin(ctx, c.db, c.ID, *viewer)
+}
+
+// CommunityViewableBy is like Community.ViewableBy but it takes a community
+// ID.
+func CommunityViewableBy(ctx context.Context, db *sql.DB, community uid.ID, viewer *uid.ID) (bool, error) {
+	var (
+		v       CommunityVisibility
+		deleted bool
+	)
+	if err := db.QueryRowContext(ctx, "SELECT visibility, deleted_at IS NOT NULL FROM communities WHERE id = ?", community).Scan(&v, &deleted); err != nil {
+		if err == sql.ErrNoRows {
+			return false, errCommunityNotFound
+		}
+		return false, err
+	}
+	if deleted {
+		// Only admins can see deleted communities.
+		return IsAdmin(db, viewer)
+	}
+	if v != CommunityPrivate {
+		return true, nil
+	}
+	if viewer == nil {
+		return false, nil
+	}
+	return isCommunityMemberOrAdmin(ctx, db, community, *viewer)
+}
+
+// checkCommunityWritable returns an error if community is deleted or archived,
+// that is, if nothing can be posted, commented, or voted on in it.
+func checkCommunityWritable(ctx context.Context, db *sql.DB, community uid.ID) error {
+	var deleted, archived bool
+	if err := db.QueryRowContext(ctx, "SELECT deleted_at IS NOT NULL, archived_at IS NOT NULL FROM communities WHERE id = ?", community).Scan(&deleted, &archived); err != nil {
+		if err == sql.ErrNoRows {
+			return errCommunityNotFound
+		}
+		return err
+	}
+	if deleted {
+		return errCommunityNotFound
+	}
+	if archived {
+		return errCommunityArchived
+	}
+	return nil
+}
+
+// checkCanParticipate returns errNotCommunityMember if user is not allowed to
+// post or comment in community. It also returns an error if community is
+// deleted or archived.
+func checkCanParticipate(ctx context.Context, db *sql.DB, community, user uid.ID) error {
+	if err := checkCommunityWritable(ctx, db, community); err != nil {
+		return err
+	}
+
+	var v CommunityVisibility
+	if err := db.QueryRowContext(ctx, "SELECT visibility FROM communities WHERE id = ?", community).Scan(&v); err != nil {
+		return err
+	}
+	if v == CommunityPublic {
+		return nil
+	}
+	if ok, err := isCommunityMemberOrAdmin(ctx, db, community, user); err != nil {
+		return err
+	} else if !ok {
+		return errNotCommunityMember
+	}
+	return nil
+}
+
+// HiddenCommunities returns the set of communities, out of communities, whose
+// content viewer (nil if logged out) cannot see: the private communities that
+// viewer is not a member of, and the deleted communities.
+func HiddenCommunities(ctx context.Context, db *sql.DB, viewer *uid.ID, communities []uid.ID) (map[uid.ID]bool, error) {
+	if len(communities) == 0 {
+		return nil, nil
+	}
+	if is, err := IsAdmin(db, viewer); err != nil {
+		return nil, err
+	} else if is {
+		return nil, nil
+	}
+
+	query := "SELECT id FROM communities WHERE id IN " + msql.InClauseQuestionMarks(len(communities))
+	args := make([]any, 0, len(communities)+2)
+	for _, id := range communities {
+		args = append(args, id)
+	}
+	query += " AND (deleted_at IS NOT NULL OR (visibility = ?"
+	args = append(args, CommunityPrivate)
+	if viewer != nil {
+		query += " AND id NOT IN (SELECT community_id FROM community_members WHERE user_id = ?)"
+		args = append(args, *viewer)
+	}
+	query += "))"
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return nil, err
+	}
+	hidden := make(map[uid.ID]bool, len(ids))
+	for _, id := range ids {
+		hidden[id] = true
+	}
+	return hidden, nil
+}
+
+// whereViewable returns where (along with args) with a condition appended to
+// it that excludes the posts of the deleted communities and of the private
+// communities that viewer (nil if logged out) is not a member of.
+func whereViewable(where string, args []any, viewer *uid.ID) (string, []any) {
+	if !(where == "" || strings.TrimSpace(strings.ToUpper(where)) == "WHERE") {
+		where += "AND "
+	}
+	where += "community_id NOT IN (SELECT id FROM communities WHERE deleted_at IS NOT NULL OR (visibility = ? "
+	args = append(args, CommunityPrivate)
+	if viewer != nil {
+		where += "AND id NOT IN (SELECT community_id FROM community_members WHERE user_id = ?)"
+		args = append(args, *viewer)
+	}
+	where += ")) "
+	return where, args
+}
+
+// CommunityMemberRequest is a pending request by a user to join a restricted
+// or private community, or a pending invitation of a user by a mod.
+type CommunityMemberRequest struct {
+	User      *User     `json:"user"`
+	Invite    bool      `json:"invite"` // If true, the user was invited by a mod.
+	InvitedBy *User     `json:"invitedBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+var errMemberRequestNotFound = httperr.NewNotFound("member-request-not-found", "Membership request not found.")
+
+// getMemberRequest returns the invited_by column of the membership request of
+// user to c. It returns sql.ErrNoRows if there's none.
+func (c *Community) getMemberRequest(ctx context.Context, user uid.ID) (invitedBy uid.NullID, err error) {
+	err = c.db.QueryRowContext(ctx, "SELECT invited_by FROM community_member_requests WHERE community_id = ? AND user_id = ?", c.ID, user).Scan(&invitedBy)
+	return
+}
+
+// populateViewerRequest sets c.ViewerRequested and c.ViewerInvited.
+func (c *Community) populateViewerRequest(ctx context.Context, user uid.ID) error {
+	c.ViewerRequested, c.ViewerInvited = false, false
+	if c.Visibility == CommunityPublic {
+		return nil
+	}
+	invitedBy, err := c.getMemberRequest(ctx, user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+	c.ViewerInvited = invitedBy.Valid
+	c.ViewerRequested = !invitedBy.Valid
+	return nil
+}
+
+// RequestMembership is how users join restricted and private communities. If
+// user was invited by a mod of c, user is made a member of c right away (in
+// which case joined is true). Otherwise, a membership request is created that
+// the mods of c can approve or reject.
+func (c *Community) RequestMembership(ctx context.Context, user uid.ID) (joined bool, err error) {
+	if banned, err := c.UserBanned(ctx, user); err != nil {
+		return false, err
+	} else if banned {
+		return false, errUserBannedFromCommunity
+	}
+
+	invitedBy, err := c.getMemberRequest(ctx, user)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+	if err == nil {
+		if !invitedBy.Valid {
+			return false, nil // already requested
+		}
+		if err := c.Join(ctx, user); err != nil {
+			return false, err
+		}
+		if err := c.deleteMemberRequest(ctx, user); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	_, err = c.db.ExecContext(ctx, "INSERT INTO community_member_requests (community_id, user_id) VALUES (?, ?)", c.ID, user)
+	if err != nil && !msql.IsErrDuplicateErr(err) {
+		return false, err
+	}
+	return false, nil
+}
+
+func (c *Community) deleteMemberRequest(ctx context.Context, user uid.ID) error {
+	_, err := c.db.ExecContext(ctx, "DELETE FROM community_member_requests WHERE community_id = ? AND user_id = ?", c.ID, user)
+	return err
+}
+
+// CancelMembershipRequest withdraws user's membership request to c, or
+// declines an invitation from c.
+func (c *Community) CancelMembershipRequest(ctx context.Context, user uid.ID) error {
+	return c.deleteMemberRequest(ctx, user)
+}
+
+// GetMemberRequests returns the pending membership requests and invitations of
+// c, latest first.
+func (c *Community) GetMemberRequests(ctx context.Context, mod uid.ID) ([]*CommunityMemberRequest, error) {
+	if is, err := c.UserModOrAdmin(ctx, mod); err != nil {
+		return nil, err
+	} else if !is {
+		return nil, errNotMod
+	}
+
+	rows, err := c.db.QueryContext(ctx, "SELECT user_id, invited_by, created_at FROM community_member_requests WHERE community_id = ? ORDER BY created_at DESC", c.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		reqs      []*CommunityMemberRequest
+		userIDs   []uid.ID
+		invitedBy []uid.NullID
+	)
+	for rows.Next() {
+		var (
+			req     = &CommunityMemberRequest{}
+			user    uid.ID
+			invited uid.NullID
+		)
+		if err := rows.Scan(&user, &invited, &req.CreatedAt); err != nil {
+			return nil, err
+		}
+		req.Invite = invited.Valid
+		reqs = append(reqs, req)
+		userIDs = append(userIDs, user)
+		invitedBy = append(invitedBy, invited)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(reqs) == 0 {
+		return []*CommunityMemberRequest{}, nil
+	}
+
+	ids := append([]uid.ID{}, userIDs...)
+	for _, id := range invitedBy {
+		if id.Valid {
+			ids = append(ids, id.ID)
+		}
+	}
+	users, err := GetUsersByIDs(ctx, c.db, ids, nil)
+	if err != nil {
+		return nil, err
+	}
+	usersMap := make(map[uid.ID]*User, len(users))
+	for _, user := range users {
+		usersMap[user.ID] = user
+	}
+
+	out := reqs[:0]
+	for i, req := range reqs {
+		if req.User = usersMap[userIDs[i]]; req.User == nil {
+			continue
+		}
+		if invitedBy[i].Valid {
+			req.InvitedBy = usersMap[invitedBy[i].ID]
+		}
+		out = append(out, req)
+	}
+	return out, nil
+}
+
+// ApproveMemberRequest makes user, who requested to join c, a member of c.
+func (c *Community) ApproveMemberRequest(ctx context.Context, mod, user uid.ID) error {
+	if is, err := c.UserModOrAdmin(ctx, mod); err != nil {
+		return err
+	} else if !is {
+		return errNotMod
+	}
+
+	res, err := c.db.ExecContext(ctx, "DELETE FROM community_member_requests WHERE community_id = ? AND user_id = ? AND invited_by IS NULL", c.ID, user)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errMemberRequestNotFound
+	}
+	return c.Join(ctx, user)
+}
+
+// RejectMemberRequest rejects the membership request of user to c, or
+// revokes the invitation of user to c.
+func (c *Community) RejectMemberRequest(ctx context.Context, mod, user uid.ID) error {
+	if is, err := c.UserModOrAdmin(ctx, mod); err != nil {
+		return err
+	} else if !is {
+		return errNotMod
+	}
+
+	res, err := c.db.ExecContext(ctx, "DELETE FROM community_member_requests WHERE community_id = ? AND user_id = ?", c.ID, user)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errMemberRequestNotFound
+	}
+	return nil
+}
+
+// InviteMember invites user to join c. If user has already requested to join
+// c, the request is approved instead.
+func (c *Community) InviteMember(ctx context.Context, mod, user uid.ID) error {
+	if is, err := c.UserModOrAdmin(ctx, mod); err != nil {
+		return err
+	} else if !is {
+		return errNotMod
+	}
+	if c.Visibility == CommunityPublic {
+		return httperr.NewBadRequest("community-public", "Anyone can join a public community.")
+	}
+
+	var n int
+	if err := c.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM community_members WHERE community_id = ? AND user_id = ?", c.ID, user).Scan(&n); err != nil {
+		return err
+	} else if n > 0 {
+		return httperr.NewBadRequest("already-member", "User is already a member.")
+	}
+	if banned, err := c.UserBanned(ctx, user); err != nil {
+		return err
+	} else if banned {
+		return errUserBannedFromCommunity
+	}
+
+	invitedBy, err := c.getMemberRequest(ctx, user)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil {
+		if !invitedBy.Valid {
+			return c.ApproveMemberRequest(ctx, mod, user)
+		}
+		return nil // already invited
+	}
+
+	if _, err = c.db.ExecContext(ctx, "INSERT INTO community_member_requests (community_id, user_id, invited_by) VALUES (?, ?, ?)", c.ID, user, mod); err != nil {
+		return err
+	}
+
+	invitedByUser, err := GetUser(ctx, c.db, mod, nil)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := CreateCommunityInviteNotification(context.Background(), c.db, user, c.Name, invitedByUser.Username); err != nil {
+			log.Println("Failed to create community_invite notification: ", err)
+		}
+	}()
+	return nil
+}
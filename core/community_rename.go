@@ -0,0 +1,436 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+const (
+	maxRenameRequestReasonLength   = 2000
+	maxRenameRequestResponseLength = 2000
+)
+
+// RenamedCommunityName returns the current name of the community that was
+// once called name. If name is not a former name of any (undeleted) community,
+// it returns an empty string.
+func RenamedCommunityName(ctx context.Context, db *sql.DB, name string) (string, error) {
+	var current string
+	query := "SELECT communities.name FROM community_name_history INNER JOIN communities ON communities.id = community_name_history.community_id WHERE community_name_history.name_lc = ? AND communities.deleted_at IS NULL"
+	if err := db.QueryRowContext(ctx, query, strings.ToLower(name)).Scan(&current); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return current, nil
+}
+
+// checkNewName returns an error if c cannot be renamed to name. Former names
+// of other communities are not available.
+func (c *Community) checkNewName(ctx context.Context, name string) error {
+	if err := IsUsernameValid(name); err != nil {
+		return httperr.NewBadRequest("invalid-community-name", fmt.Sprintf("Community name invalid. It %s.", err.Error()))
+	}
+	if name == c.Name {
+		return httperr.NewBadRequest("community-name-unchanged", "The community already has that name.")
+	}
+	if exists, comm, err := CommunityExists(ctx, c.db, name); err != nil {
+		return err
+	} else if exists && !comm.ID.EqualsTo(c.ID) {
+		return &httperr.Error{HTTPStatus: http.StatusConflict, Code: "community-exists", Message: fmt.Sprintf("A community with name %s already exists.", name)}
+	}
+	return nil
+}
+
+// Rename changes the name of c to name on behalf of admin. The old name keeps
+// pointing to c (see GetCommunityByName), and the members of c are notified of
+// the change.
+func (c *Community) Rename(ctx context.Context, admin uid.ID, name string) error {
+	if err := checkAdminAction(c.db, admin); err != nil {
+		return err
+	}
+	if c.DeletedAt.Valid {
+		return errCommunityNotFound
+	}
+	oldName := c.Name
+	entry := c.adminModLogEntry(admin, ModActionRenameCommunity)
+	entry.Reason = fmt.Sprintf("Renamed from %s to %s", oldName, name)
+	if err := c.rename(ctx, name, uid.NullID{ID: admin, Valid: true}, entry, nil); err != nil {
+		return err
+	}
+	go c.sendRenameNotifications(context.Background(), oldName)
+	return nil
+}
+
+// RenameCLI changes the name of c to name, and notifies the members of c. Do
+// not use this function in an API.
+func (c *Community) RenameCLI(ctx context.Context, name string) error {
+	oldName := c.Name
+	if err := c.rename(ctx, name, uid.NullID{}, nil, nil); err != nil {
+		return err
+	}
+	c.sendRenameNotifications(ctx, oldName)
+	return nil
+}
+
+// rename changes the name of c to name, recording the old name in the name
+// history of c. It doesn't notify the members of c. If logEntry is non-nil,
+// it's added to the mod log. The update function, if not nil, is run in the
+// same transaction.
+func (c *Community) rename(ctx context.Context, name string, by uid.NullID, logEntry *ModLogEntry, update func(*sql.Tx) error) error {
+	if err := c.checkNewName(ctx, name); err != nil {
+		return err
+	}
+
+	nameLC := strings.ToLower(name)
+	err := msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		// Renaming a community back to one of its former names.
+		if _, err := tx.ExecContext(ctx, "DELETE FROM community_name_history WHERE community_id = ? AND name_lc = ?", c.ID, nameLC); err != nil {
+			return err
+		}
+		if nameLC != c.NameLowerCase {
+			if _, err := tx.ExecContext(ctx, "INSERT INTO community_name_history (community_id, name, name_lc, renamed_by) VALUES (?, ?, ?, ?)",
+				c.ID, c.Name, c.NameLowerCase, by); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE communities SET name = ?, name_lc = ? WHERE id = ?", name, nameLC, c.ID); err != nil {
+			if msql.IsErrDuplicateErr(err) {
+				return &httperr.Error{HTTPStatus: http.StatusConflict, Code: "community-exists", Message: fmt.Sprintf("A community with name %s already exists.", name)}
+			}
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE comments SET community_name = ? WHERE community_id = ?", name, c.ID); err != nil {
+			return err
+		}
+		if update != nil {
+			if err := update(tx); err != nil {
+				return err
+			}
+		}
+		if logEntry != nil {
+			return insertModLogEntry(ctx, tx, logEntry)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c.Name, c.NameLowerCase = name, nameLC
+	return nil
+}
+
+// sendRenameNotifications notifies the members of c that c was renamed from
+// oldName.
+func (c *Community) sendRenameNotifications(ctx context.Context, oldName string) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT community_members.user_id FROM community_members
+		INNER JOIN users ON users.id = community_members.user_id
+		WHERE community_members.community_id = ? AND users.deleted_at IS NULL`, c.ID)
+	if err != nil {
+		log.Printf("Error getting members of community %v: %v\n", c.ID, err)
+		return
+	}
+	members, err := scanIDs(rows)
+	if err != nil {
+		log.Printf("Error getting members of community %v: %v\n", c.ID, err)
+		return
+	}
+
+	for _, member := range members {
+		if err := CreateCommunityRenameNotification(ctx, c.db, member, oldName, c.Name); err != nil {
+			log.Printf("Create community_rename notification failed: %v\n", err)
+		}
+	}
+}
+
+// RenameRequestStatus is the status of a community rename request.
+type RenameRequestStatus string
+
+const (
+	RenameRequestStatusOpen     = RenameRequestStatus("open")
+	RenameRequestStatusApproved = RenameRequestStatus("approved") // The community was renamed.
+	RenameRequestStatusRejected = RenameRequestStatus("rejected")
+)
+
+// Valid reports whether s is a valid RenameRequestStatus.
+func (s RenameRequestStatus) Valid() bool {
+	switch s {
+	case RenameRequestStatusOpen, RenameRequestStatusApproved, RenameRequestStatusRejected:
+		return true
+	}
+	return false
+}
+
+// RenameRequest is a request, by a mod of a community, to change the name of
+// the community. Rename requests are reviewed by the admins.
+type RenameRequest struct {
+	db *sql.DB
+
+	ID            uint                `json:"id"`
+	CommunityID   uid.ID              `json:"communityId"`
+	CommunityName string              `json:"communityName"` // The current name of the community.
+	UserID        uid.ID              `json:"userId"`
+	Username      string              `json:"username"`
+	NewName       string              `json:"newName"`
+	Reason        string              `json:"reason"`
+	Status        RenameRequestStatus `json:"status"`
+	Response      msql.NullString     `json:"response"` // The message of the reviewer.
+	ReviewedBy    uid.NullID          `json:"reviewedBy"`
+	ReviewedAt    msql.NullTime       `json:"reviewedAt"`
+	CreatedAt     time.Time           `json:"createdAt"`
+}
+
+func getRenameRequests(ctx context.Context, db *sql.DB, where string, args ...any) ([]*RenameRequest, error) {
+	cols := []string{
+		"community_rename_requests.id",
+		"community_rename_requests.community_id",
+		"communities.name",
+		"community_rename_requests.user_id",
+		"users.username",
+		"community_rename_requests.new_name",
+		"community_rename_requests.reason",
+		"community_rename_requests.status",
+		"community_rename_requests.response",
+		"community_rename_requests.reviewed_by",
+		"community_rename_requests.reviewed_at",
+		"community_rename_requests.created_at",
+	}
+	joins := []string{
+		"INNER JOIN communities ON communities.id = community_rename_requests.community_id",
+		"INNER JOIN users ON users.id = community_rename_requests.user_id",
+	}
+	rows, err := db.QueryContext(ctx, msql.BuildSelectQuery("community_rename_requests", cols, joins, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []*RenameRequest{}
+	for rows.Next() {
+		t := &RenameRequest{db: db}
+		err := rows.Scan(
+			&t.ID,
+			&t.CommunityID,
+			&t.CommunityName,
+			&t.UserID,
+			&t.Username,
+			&t.NewName,
+			&t.Reason,
+			&t.Status,
+			&t.Response,
+			&t.ReviewedBy,
+			&t.ReviewedAt,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// GetRenameRequest returns the rename request with the given id.
+func GetRenameRequest(ctx context.Context, db *sql.DB, id uint) (*RenameRequest, error) {
+	requests, err := getRenameRequests(ctx, db, "WHERE community_rename_requests.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, httperr.NewNotFound("rename-request-not-found", "Rename request not found.")
+	}
+	return requests[0], nil
+}
+
+// GetRenameRequests returns the rename requests of all communities, the oldest
+// ones first. If status is not empty, only the requests of that status are
+// returned. The results are paginated.
+func GetRenameRequests(ctx context.Context, db *sql.DB, status RenameRequestStatus, limit, page int) ([]*RenameRequest, error) {
+	where, args := "", []any{}
+	if status != "" {
+		if !status.Valid() {
+			return nil, httperr.NewBadRequest("invalid-rename-request-status", "Invalid rename request status.")
+		}
+		where, args = "WHERE community_rename_requests.status = ?", append(args, status)
+	}
+	where += " ORDER BY community_rename_requests.created_at LIMIT ? OFFSET ?"
+	args = append(args, limit, limit*(page-1))
+	return getRenameRequests(ctx, db, where, args...)
+}
+
+// GetRenameRequests returns all the rename requests of c, the latest ones
+// first.
+func (c *Community) GetRenameRequests(ctx context.Context) ([]*RenameRequest, error) {
+	return getRenameRequests(ctx, c.db, "WHERE community_rename_requests.community_id = ? ORDER BY community_rename_requests.created_at DESC", c.ID)
+}
+
+// NewRenameRequest creates a request by mod to change the name of c to name.
+// A community can only have one open rename request at a time.
+func (c *Community) NewRenameRequest(ctx context.Context, mod uid.ID, name, reason string) (*RenameRequest, error) {
+	if is, err := c.UserModOrAdmin(ctx, mod); err != nil {
+		return nil, err
+	} else if !is {
+		return nil, errNotMod
+	}
+	if err := checkCommunityWritable(ctx, c.db, c.ID); err != nil {
+		return nil, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, httperr.NewBadRequest("rename-request-empty", "Give a reason for the new name.")
+	}
+	if utf8.RuneCountInString(reason) > maxRenameRequestReasonLength {
+		return nil, httperr.NewBadRequest("rename-request-too-long", fmt.Sprintf("Reason cannot exceed %d characters.", maxRenameRequestReasonLength))
+	}
+	if err := c.checkNewName(ctx, name); err != nil {
+		return nil, err
+	}
+
+	var open bool
+	query := "SELECT COUNT(*) > 0 FROM community_rename_requests WHERE community_id = ? AND status = ?"
+	if err := c.db.QueryRowContext(ctx, query, c.ID, RenameRequestStatusOpen).Scan(&open); err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, &httperr.Error{HTTPStatus: http.StatusConflict, Code: "rename-request-exists", Message: "The community already has an open rename request."}
+	}
+
+	result, err := c.db.ExecContext(ctx, "INSERT INTO community_rename_requests (community_id, user_id, new_name, reason) VALUES (?, ?, ?, ?)", c.ID, mod, name, reason)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return GetRenameRequest(ctx, c.db, uint(id))
+}
+
+// checkReviewer returns an error if reviewer cannot review t. Only admins can
+// review rename requests.
+func (t *RenameRequest) checkReviewer(reviewer uid.ID) error {
+	if t.Status != RenameRequestStatusOpen {
+		return &httperr.Error{HTTPStatus: http.StatusConflict, Code: "rename-request-closed", Message: "Rename request is already reviewed."}
+	}
+	return checkAdminAction(t.db, reviewer)
+}
+
+// modLogEntry returns a mod log entry of action taken on t by reviewer.
+func (t *RenameRequest) modLogEntry(reviewer uid.ID, action ModAction, reason string) *ModLogEntry {
+	return &ModLogEntry{
+		CommunityID:  uid.NullID{ID: t.CommunityID, Valid: true},
+		ActorID:      reviewer,
+		ActorGroup:   UserGroupAdmins,
+		Action:       action,
+		TargetType:   "rename_request",
+		TargetID:     strconv.FormatUint(uint64(t.ID), 10),
+		TargetUserID: uid.NullID{ID: t.UserID, Valid: true},
+		Reason:       reason,
+	}
+}
+
+// closeTx marks t as reviewed with status by reviewer in tx.
+func (t *RenameRequest) closeTx(ctx context.Context, tx *sql.Tx, reviewer uid.ID, status RenameRequestStatus, message string, now time.Time) error {
+	_, err := tx.ExecContext(ctx, "UPDATE community_rename_requests SET status = ?, response = ?, reviewed_by = ?, reviewed_at = ? WHERE id = ?",
+		status, msql.NilIfEmptyString(message), reviewer, now, t.ID)
+	return err
+}
+
+// closed updates the fields of t after it's reviewed.
+func (t *RenameRequest) closed(reviewer uid.ID, status RenameRequestStatus, message string, now time.Time) {
+	t.Status = status
+	t.Response = msql.NewNullString(msql.NilIfEmptyString(message))
+	t.ReviewedBy = uid.NullID{ID: reviewer, Valid: true}
+	t.ReviewedAt = msql.NewNullTime(now)
+}
+
+// validateRenameRequestResponse trims and validates message, the response of
+// a reviewer.
+func validateRenameRequestResponse(message string) (string, error) {
+	message = strings.TrimSpace(message)
+	if utf8.RuneCountInString(message) > maxRenameRequestResponseLength {
+		return "", httperr.NewBadRequest("rename-request-response-too-long", fmt.Sprintf("Response cannot exceed %d characters.", maxRenameRequestResponseLength))
+	}
+	return message, nil
+}
+
+// Approve approves the request on behalf of reviewer, renaming the community.
+// It returns the renamed community. The members of the community, including
+// the mod who made the request, are notified of the new name.
+func (t *RenameRequest) Approve(ctx context.Context, reviewer uid.ID, message string) (*Community, error) {
+	if err := t.checkReviewer(reviewer); err != nil {
+		return nil, err
+	}
+	message, err := validateRenameRequestResponse(message)
+	if err != nil {
+		return nil, err
+	}
+
+	comm, err := GetCommunityByID(ctx, t.db, t.CommunityID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if comm.DeletedAt.Valid {
+		return nil, errCommunityNotFound
+	}
+
+	now, oldName := time.Now(), comm.Name
+	entry := t.modLogEntry(reviewer, ModActionRenameCommunity, fmt.Sprintf("Renamed from %s to %s", oldName, t.NewName))
+	update := func(tx *sql.Tx) error {
+		return t.closeTx(ctx, tx, reviewer, RenameRequestStatusApproved, message, now)
+	}
+	if err := comm.rename(ctx, t.NewName, uid.NullID{ID: reviewer, Valid: true}, entry, update); err != nil {
+		return nil, err
+	}
+	go comm.sendRenameNotifications(context.Background(), oldName)
+	t.closed(reviewer, RenameRequestStatusApproved, message, now)
+	t.CommunityName = comm.Name
+	return comm, nil
+}
+
+// Reject rejects the request on behalf of reviewer. The message, which is sent
+// to the mod who made the request, is optional.
+func (t *RenameRequest) Reject(ctx context.Context, reviewer uid.ID, message string) error {
+	if err := t.checkReviewer(reviewer); err != nil {
+		return err
+	}
+	message, err := validateRenameRequestResponse(message)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = msql.Transact(ctx, t.db, func(tx *sql.Tx) error {
+		if err := t.closeTx(ctx, tx, reviewer, RenameRequestStatusRejected, message, now); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, t.modLogEntry(reviewer, ModActionRejectRename, message))
+	})
+	if err != nil {
+		return err
+	}
+	t.closed(reviewer, RenameRequestStatusRejected, message, now)
+	go func() {
+		if err := CreateRenameRejectedNotification(context.Background(), t.db, t); err != nil {
+			log.Printf("Error creating rename request notification (request id: %v): %v\n", t.ID, err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,175 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+const (
+	maxRequirementAccountAge = 3650 // in days.
+	maxRequirementTitleRegex = 512  // in bytes.
+)
+
+// PostingRequirements are the requirements, set by the mods of a community,
+// that users have to meet to post (and, some of them, to comment) in the
+// community. Mods of the community and admins are exempt from them.
+type PostingRequirements struct {
+	// The post types allowed in the community. If empty, all post types are
+	// allowed.
+	PostTypes []PostType `json:"postTypes"`
+
+	MinAccountAge int `json:"minAccountAge"` // In days. Applies to comments too.
+	MinPoints     int `json:"minPoints"`     // Applies to comments too.
+
+	// If non-empty, the titles of posts must match this regular expression.
+	TitleRegex string `json:"titleRegex"`
+
+	MinBodyLength int `json:"minBodyLength"` // Of text posts, in runes.
+}
+
+// postTypesMask returns r.PostTypes as a bitmask (as it's stored in the
+// database).
+func (r *PostingRequirements) postTypesMask() (mask uint) {
+	for _, t := range r.PostTypes {
+		mask |= 1 << uint(t)
+	}
+	return mask
+}
+
+// setPostTypes sets r.PostTypes from a bitmask.
+func (r *PostingRequirements) setPostTypes(mask uint) {
+	r.PostTypes = []PostType{}
+	for t := PostType(0); mask>>uint(t) != 0; t++ {
+		if mask&(1<<uint(t)) != 0 && t.Valid() {
+			r.PostTypes = append(r.PostTypes, t)
+		}
+	}
+}
+
+// empty reports whether r has no requirements set.
+func (r *PostingRequirements) empty() bool {
+	return len(r.PostTypes) == 0 && r.MinAccountAge == 0 && r.MinPoints == 0 && r.TitleRegex == "" && r.MinBodyLength == 0
+}
+
+// validate checks that r is valid, and removes duplicate post types.
+func (r *PostingRequirements) validate() error {
+	types := []PostType{}
+	for _, t := range r.PostTypes {
+		if !t.Valid() {
+			return errPostTypeUnsupported
+		}
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	r.PostTypes = types
+
+	if r.MinAccountAge < 0 || r.MinAccountAge > maxRequirementAccountAge {
+		return httperr.NewBadRequest("invalid-min-account-age", fmt.Sprintf("Minimum account age must be between 0 and %d days.", maxRequirementAccountAge))
+	}
+	if r.MinPoints < 0 {
+		return httperr.NewBadRequest("invalid-min-points", "Minimum points cannot be negative.")
+	}
+	if r.MinBodyLength < 0 || r.MinBodyLength > maxPostBodyLength {
+		return httperr.NewBadRequest("invalid-min-body-length", fmt.Sprintf("Minimum body length must be between 0 and %d.", maxPostBodyLength))
+	}
+	if len(r.TitleRegex) > maxRequirementTitleRegex {
+		return httperr.NewBadRequest("title-regex-too-long", "Title regular expression is too long.")
+	}
+	if _, err := regexp.Compile(r.TitleRegex); err != nil {
+		return httperr.NewBadRequest("invalid-title-regex", "Invalid title regular expression: "+err.Error())
+	}
+	return nil
+}
+
+// checkUser returns an error if user doesn't meet the account age and points
+// requirements of r.
+func (r *PostingRequirements) checkUser(user *User, now time.Time) error {
+	if r.MinAccountAge > 0 && now.Sub(user.CreatedAt) < time.Duration(r.MinAccountAge)*24*time.Hour {
+		return httperr.NewForbidden("account-too-new", fmt.Sprintf("Your account has to be at least %d days old to post or comment in this community.", r.MinAccountAge))
+	}
+	if user.Points < r.MinPoints {
+		return httperr.NewForbidden("not-enough-points", fmt.Sprintf("You need at least %d points to post or comment in this community.", r.MinPoints))
+	}
+	return nil
+}
+
+// checkPost returns an error if a post of type postType, with title and body,
+// doesn't meet the requirements of r.
+func (r *PostingRequirements) checkPost(postType PostType, title, body string) error {
+	if len(r.PostTypes) > 0 && !slices.Contains(r.PostTypes, postType) {
+		return httperr.NewForbidden("post-type-not-allowed", "Posts of this type are not allowed in this community.")
+	}
+	if r.TitleRegex != "" {
+		re, err := regexp.Compile(r.TitleRegex)
+		if err != nil {
+			return err
+		}
+		if !re.MatchString(title) {
+			return httperr.NewBadRequest("title-format-mismatch", "The title does not follow the format required by this community.")
+		}
+	}
+	if postType == PostTypeText && utf8.RuneCountInString(body) < r.MinBodyLength {
+		return httperr.NewBadRequest("body-too-short", fmt.Sprintf("The body has to be at least %d characters long in this community.", r.MinBodyLength))
+	}
+	return nil
+}
+
+// getPostingRequirements returns the posting requirements of community.
+func getPostingRequirements(ctx context.Context, db *sql.DB, community uid.ID) (*PostingRequirements, error) {
+	var (
+		r          PostingRequirements
+		mask       uint
+		titleRegex msql.NullString
+	)
+	row := db.QueryRowContext(ctx, "SELECT allowed_post_types, min_account_age, min_points, title_regex, min_body_length FROM communities WHERE id = ?", community)
+	if err := row.Scan(&mask, &r.MinAccountAge, &r.MinPoints, &titleRegex, &r.MinBodyLength); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errCommunityNotFound
+		}
+		return nil, err
+	}
+	r.setPostTypes(mask)
+	r.TitleRegex = titleRegex.String
+	return &r, nil
+}
+
+// checkPostingRequirements returns an error if user doesn't meet the posting
+// requirements of community. If post is false, only the requirements that
+// apply to comments are checked; otherwise, postType, title, and body are
+// checked too.
+func checkPostingRequirements(ctx context.Context, db *sql.DB, community, user uid.ID, post bool, postType PostType, title, body string) error {
+	r, err := getPostingRequirements(ctx, db, community)
+	if err != nil {
+		return err
+	}
+	if r.empty() {
+		return nil
+	}
+	if exempt, err := UserModOrAdmin(ctx, db, community, user); err != nil {
+		return err
+	} else if exempt {
+		return nil
+	}
+
+	u, err := GetUser(ctx, db, user, nil)
+	if err != nil {
+		return err
+	}
+	if err := r.checkUser(u, time.Now()); err != nil {
+		return err
+	}
+	if post {
+		return r.checkPost(postType, title, body)
+	}
+	return nil
+}
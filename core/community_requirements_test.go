@@ -0,0 +1,105 @@
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+)
+
+func TestPostingRequirementsPostTypes(t *testing.T) {
+	cases := [][]PostType{
+		{},
+		{PostTypeText},
+		{PostTypeImage, PostTypeLink},
+		{PostTypeText, PostTypeImage, PostTypeLink},
+	}
+	for _, types := range cases {
+		r := &PostingRequirements{PostTypes: types}
+		var got PostingRequirements
+		got.setPostTypes(r.postTypesMask())
+		if !slices.Equal(got.PostTypes, types) {
+			t.Errorf("post types %v round-tripped to %v", types, got.PostTypes)
+		}
+	}
+}
+
+func TestPostingRequirementsCheck(t *testing.T) {
+	r := &PostingRequirements{
+		PostTypes:     []PostType{PostTypeText, PostTypeLink, PostTypeText},
+		MinAccountAge: 7,
+		MinPoints:     10,
+		TitleRegex:    `^\[(Question|Discussion)\] `,
+		MinBodyLength: 5,
+	}
+	if err := r.validate(); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+	if len(r.PostTypes) != 2 {
+		t.Errorf("validate() did not remove duplicate post types: %v", r.PostTypes)
+	}
+
+	now := time.Now()
+	userCases := []struct {
+		age    time.Duration
+		points int
+		code   string
+	}{
+		{8 * 24 * time.Hour, 10, ""},
+		{6 * 24 * time.Hour, 10, "account-too-new"},
+		{8 * 24 * time.Hour, 9, "not-enough-points"},
+	}
+	for _, c := range userCases {
+		user := &User{CreatedAt: now.Add(-c.age), Points: c.points}
+		if got := errorCode(r.checkUser(user, now)); got != c.code {
+			t.Errorf("checkUser(age: %v, points: %d) = %q, want %q", c.age, c.points, got, c.code)
+		}
+	}
+
+	postCases := []struct {
+		postType    PostType
+		title, body string
+		code        string
+	}{
+		{PostTypeText, "[Question] Why?", "Because.", ""},
+		{PostTypeImage, "[Question] Why?", "", "post-type-not-allowed"},
+		{PostTypeText, "Why?", "Because.", "title-format-mismatch"},
+		{PostTypeText, "[Discussion] Why?", "Eh.", "body-too-short"},
+		{PostTypeLink, "[Discussion] A link", "", ""},
+	}
+	for _, c := range postCases {
+		if got := errorCode(r.checkPost(c.postType, c.title, c.body)); got != c.code {
+			t.Errorf("checkPost(%v, %q, %q) = %q, want %q", c.postType, c.title, c.body, got, c.code)
+		}
+	}
+}
+
+func TestPostingRequirementsValidate(t *testing.T) {
+	cases := []struct {
+		r       PostingRequirements
+		wantErr bool
+	}{
+		{PostingRequirements{}, false},
+		{PostingRequirements{PostTypes: []PostType{PostType(99)}}, true},
+		{PostingRequirements{MinAccountAge: -1}, true},
+		{PostingRequirements{MinPoints: -1}, true},
+		{PostingRequirements{MinBodyLength: maxPostBodyLength + 1}, true},
+		{PostingRequirements{TitleRegex: "(unclosed"}, true},
+	}
+	for _, c := range cases {
+		if err := c.r.validate(); (err != nil) != c.wantErr {
+			t.Errorf("validate(%+v) error = %v, wantErr %v", c.r, err, c.wantErr)
+		}
+	}
+}
+
+func errorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	if e, ok := err.(*httperr.Error); ok {
+		return e.Code
+	}
+	return err.Error()
+}
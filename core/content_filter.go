@@ -0,0 +1,336 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// maxContentFilters is the maximum number of content filters a user can have.
+const maxContentFilters = 200
+
+// ContentFilterType is the type of a ContentFilter, which determines what its
+// pattern is matched against.
+type ContentFilterType string
+
+const (
+	// The pattern is a word, or a phrase, matched (case-insensitively) against
+	// the title and the body of posts and the body of comments.
+	ContentFilterKeyword = ContentFilterType("keyword")
+
+	// The pattern is a regular expression (Go syntax) matched against the title
+	// and the body of posts and the body of comments.
+	ContentFilterRegex = ContentFilterType("regex")
+
+	// The pattern is a domain name matched against the link of posts and the
+	// links in the body of comments. Subdomains of the domain are matched too.
+	ContentFilterDomain = ContentFilterType("domain")
+
+	// The pattern is a post type (text, image, or link).
+	ContentFilterPostType = ContentFilterType("post_type")
+)
+
+// Valid reports whether t is a valid ContentFilterType.
+func (t ContentFilterType) Valid() bool {
+	switch t {
+	case ContentFilterKeyword, ContentFilterRegex, ContentFilterDomain, ContentFilterPostType:
+		return true
+	}
+	return false
+}
+
+// ContentFilterAction is what is done with content matched by a filter.
+type ContentFilterAction string
+
+const (
+	// Matched posts are removed from feeds and search results. Matched
+	// comments, since they cannot be removed from comment trees without their
+	// replies, are shown as hidden.
+	ContentFilterHide = ContentFilterAction("hide")
+
+	// Matched content is shown collapsed.
+	ContentFilterCollapse = ContentFilterAction("collapse")
+)
+
+// Valid reports whether a is a valid ContentFilterAction.
+func (a ContentFilterAction) Valid() bool {
+	return a == ContentFilterHide || a == ContentFilterCollapse
+}
+
+// ContentFilter is a user-defined filter of posts and comments. It's a mute of
+// type MuteTypeFilter.
+type ContentFilter struct {
+	Type    ContentFilterType   `json:"type"`
+	Pattern string              `json:"pattern"`
+	Action  ContentFilterAction `json:"action"`
+
+	re *regexp.Regexp // Compiled pattern of keyword and regex filters.
+}
+
+// validate normalizes f's pattern and checks that f is a valid filter.
+func (f *ContentFilter) validate() error {
+	if !f.Type.Valid() {
+		return httperr.NewBadRequest("invalid_filter_type", "Invalid filter type.")
+	}
+	if f.Action == "" {
+		f.Action = ContentFilterHide
+	}
+	if !f.Action.Valid() {
+		return httperr.NewBadRequest("invalid_filter_action", "Invalid filter action.")
+	}
+
+	f.Pattern = strings.TrimSpace(f.Pattern)
+	switch f.Type {
+	case ContentFilterDomain:
+		f.Pattern = normalizeHostname(f.Pattern)
+	case ContentFilterPostType:
+		f.Pattern = strings.ToLower(f.Pattern)
+		var t PostType
+		if err := t.UnmarshalText([]byte(f.Pattern)); err != nil {
+			return err
+		}
+	}
+	if f.Pattern == "" {
+		return httperr.NewBadRequest("empty_filter", "Filter pattern is empty.")
+	}
+	if utf8.RuneCountInString(f.Pattern) > 256 {
+		return httperr.NewBadRequest("filter_too_long", "Filter pattern is too long.")
+	}
+	if err := f.compile(); err != nil {
+		return httperr.NewBadRequest("invalid_filter_regex", "Invalid regular expression: "+err.Error())
+	}
+	return nil
+}
+
+// compile compiles the pattern of keyword and regex filters.
+func (f *ContentFilter) compile() (err error) {
+	switch f.Type {
+	case ContentFilterKeyword:
+		expr := regexp.QuoteMeta(f.Pattern)
+		if r, _ := utf8.DecodeRuneInString(f.Pattern); isWordRune(r) {
+			expr = `\b` + expr
+		}
+		if r, _ := utf8.DecodeLastRuneInString(f.Pattern); isWordRune(r) {
+			expr = expr + `\b`
+		}
+		f.re, err = regexp.Compile("(?i)" + expr)
+	case ContentFilterRegex:
+		f.re, err = regexp.Compile(f.Pattern)
+	}
+	return err
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// normalizeHostname lowercases hostname and strips any scheme, path, and
+// "www." prefix from it.
+func normalizeHostname(hostname string) string {
+	hostname = strings.ToLower(strings.TrimSpace(hostname))
+	if i := strings.Index(hostname, "://"); i != -1 {
+		hostname = hostname[i+3:]
+	}
+	if i := strings.IndexAny(hostname, "/?#"); i != -1 {
+		hostname = hostname[:i]
+	}
+	return strings.TrimPrefix(hostname, "www.")
+}
+
+// matchesHostname reports whether hostname is the domain of f or one of its
+// subdomains.
+func (f *ContentFilter) matchesHostname(hostname string) bool {
+	hostname = normalizeHostname(hostname)
+	return hostname == f.Pattern || strings.HasSuffix(hostname, "."+f.Pattern)
+}
+
+// commentLinkHostRegexp matches the hostnames of links in Markdown text.
+var commentLinkHostRegexp = regexp.MustCompile(`https?://([^\s/?#)\]>"']+)`)
+
+// matchText reports whether any of texts (the title and the body of a post,
+// say) is matched by f. Domain filters match against the links in texts.
+func (f *ContentFilter) matchText(texts ...string) bool {
+	for _, text := range texts {
+		switch f.Type {
+		case ContentFilterKeyword, ContentFilterRegex:
+			if f.re != nil && f.re.MatchString(text) {
+				return true
+			}
+		case ContentFilterDomain:
+			for _, m := range commentLinkHostRegexp.FindAllStringSubmatch(text, -1) {
+				if f.matchesHostname(m[1]) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// contentFilters is the set of content filters of a user.
+type contentFilters []*ContentFilter
+
+// match returns the action to take on content matched by the filters in fs,
+// which is empty if none of the filters match. Hide takes precedence over
+// collapse. The arguments are the post type, the link hostname (both of which
+// are ignored if empty), and the text of the content.
+func (fs contentFilters) match(postType, hostname string, texts ...string) ContentFilterAction {
+	var action ContentFilterAction
+	for _, f := range fs {
+		var matched bool
+		switch f.Type {
+		case ContentFilterPostType:
+			matched = postType != "" && postType == f.Pattern
+		case ContentFilterDomain:
+			matched = (hostname != "" && f.matchesHostname(hostname)) || f.matchText(texts...)
+		default:
+			matched = f.matchText(texts...)
+		}
+		if matched {
+			if f.Action == ContentFilterHide {
+				return ContentFilterHide
+			}
+			action = f.Action
+		}
+	}
+	return action
+}
+
+// matchPost returns the action to take on post, if any.
+func (fs contentFilters) matchPost(post *Post) ContentFilterAction {
+	if len(fs) == 0 {
+		return ""
+	}
+	postType, _ := post.Type.MarshalText()
+	hostname := ""
+	if post.Link != nil {
+		hostname = post.Link.Hostname
+	}
+	return fs.match(string(postType), hostname, post.Title, post.Body.String)
+}
+
+// matchComment returns the action to take on comment, if any.
+func (fs contentFilters) matchComment(comment *Comment) ContentFilterAction {
+	if len(fs) == 0 {
+		return ""
+	}
+	return fs.match("", "", comment.Body)
+}
+
+// getContentFilters returns the compiled content filters of user.
+func getContentFilters(ctx context.Context, db *sql.DB, user uid.ID) (contentFilters, error) {
+	mutes, err := GetMutedFilters(ctx, db, user)
+	if err != nil {
+		return nil, err
+	}
+	fs := make(contentFilters, 0, len(mutes))
+	for _, mute := range mutes {
+		fs = append(fs, mute.Filter)
+	}
+	return fs, nil
+}
+
+// GetSearchFilter returns a function that reports whether a hit of the posts
+// search index, with the given type, title, and body, is to be hidden from
+// user.
+func GetSearchFilter(ctx context.Context, db *sql.DB, user uid.ID) (func(postType, title, body string) bool, error) {
+	fs, err := getContentFilters(ctx, db, user)
+	if err != nil {
+		return nil, err
+	}
+	return func(postType, title, body string) bool {
+		return fs.match(postType, "", title, body) == ContentFilterHide
+	}, nil
+}
+
+// GetMutedFilters returns the content filters of user as mutes.
+func GetMutedFilters(ctx context.Context, db *sql.DB, user uid.ID) ([]*Mute, error) {
+	rows, err := db.QueryContext(ctx, "SELECT id, type, pattern, action, created_at FROM content_filters WHERE user_id = ? ORDER BY id", user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mutes []*Mute
+	for rows.Next() {
+		mute := &Mute{db: db, User: user, Type: MuteTypeFilter, Filter: &ContentFilter{}}
+		if err := rows.Scan(&mute.ID, &mute.Filter.Type, &mute.Filter.Pattern, &mute.Filter.Action, &mute.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := mute.Filter.compile(); err != nil {
+			continue // Filters are validated before being saved.
+		}
+		mute.setPrintID()
+		mutes = append(mutes, mute)
+	}
+	return mutes, rows.Err()
+}
+
+// MuteContent adds a content filter for user. If a filter with the same type
+// and pattern exists, its action is updated.
+func MuteContent(ctx context.Context, db *sql.DB, user uid.ID, filter *ContentFilter) error {
+	if err := filter.validate(); err != nil {
+		return err
+	}
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM content_filters WHERE user_id = ?", user).Scan(&count); err != nil {
+		return err
+	}
+	if count >= maxContentFilters {
+		return httperr.NewForbidden("max_filters_reached", "Maximum number of filters reached.")
+	}
+	_, err := db.ExecContext(ctx, "INSERT INTO content_filters (user_id, type, pattern, action) VALUES (?, ?, ?, ?)", user, filter.Type, filter.Pattern, filter.Action)
+	if err != nil && msql.IsErrDuplicateErr(err) {
+		_, err = db.ExecContext(ctx, "UPDATE content_filters SET action = ? WHERE user_id = ? AND type = ? AND pattern = ?", filter.Action, user, filter.Type, filter.Pattern)
+	}
+	return err
+}
+
+// setFilteredPosts sets the ViewerFiltered field of posts using viewer's content
+// filters. Posts of viewer are never filtered.
+func setFilteredPosts(ctx context.Context, db *sql.DB, viewer uid.ID, posts []*Post) error {
+	fs, err := getContentFilters(ctx, db, viewer)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		if post.AuthorID != viewer {
+			post.ViewerFiltered = fs.matchPost(post)
+		}
+	}
+	return nil
+}
+
+// setFilteredComments sets the ViewerFiltered field of comments using viewer's
+// content filters. Comments of viewer are never filtered.
+func setFilteredComments(ctx context.Context, db *sql.DB, viewer uid.ID, comments []*Comment) error {
+	fs, err := getContentFilters(ctx, db, viewer)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if comment.AuthorID != viewer {
+			comment.ViewerFiltered = fs.matchComment(comment)
+		}
+	}
+	return nil
+}
+
+// removeHiddenPosts returns posts without the posts hidden by the viewer's
+// content filters.
+func removeHiddenPosts(posts []*Post) []*Post {
+	out := posts[:0]
+	for _, post := range posts {
+		if post.ViewerFiltered != ContentFilterHide {
+			out = append(out, post)
+		}
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestContentFiltersMatch(t *testing.T) {
+	newFilter := func(t ContentFilterType, pattern string, action ContentFilterAction) *ContentFilter {
+		f := &ContentFilter{Type: t, Pattern: pattern, Action: action}
+		if err := f.validate(); err != nil {
+			panic(err)
+		}
+		return f
+	}
+	fs := contentFilters{
+		newFilter(ContentFilterKeyword, "Spoiler", ContentFilterCollapse),
+		newFilter(ContentFilterKeyword, "c++", ContentFilterHide),
+		newFilter(ContentFilterRegex, `^\[meta\]`, ContentFilterHide),
+		newFilter(ContentFilterDomain, "https://www.Example.com/path", ContentFilterHide),
+		newFilter(ContentFilterPostType, "Image", ContentFilterCollapse),
+	}
+
+	cases := []struct {
+		postType, hostname string
+		texts              []string
+		want               ContentFilterAction
+	}{
+		{"text", "", []string{"Big spoilers ahead"}, ""}, // whole words only
+		{"text", "", []string{"Spoiler: it ends", ""}, ContentFilterCollapse},
+		{"text", "", []string{"title", "body with a SPOILER"}, ContentFilterCollapse},
+		{"text", "", []string{"Learning C++ today"}, ContentFilterHide},
+		{"text", "", []string{"[meta] rules"}, ContentFilterHide},
+		{"text", "", []string{"about [meta]"}, ""},
+		{"link", "example.com", []string{"title"}, ContentFilterHide},
+		{"link", "news.example.com", []string{"title"}, ContentFilterHide},
+		{"link", "notexample.com", []string{"title"}, ""},
+		{"", "", []string{"see [this](https://blog.example.com/x)"}, ContentFilterHide},
+		{"image", "", []string{"cat"}, ContentFilterCollapse},
+		{"image", "", []string{"spoiler", "[meta]"}, ContentFilterHide}, // hide wins
+		{"", "", []string{"image"}, ""},
+	}
+	for _, c := range cases {
+		if got := fs.match(c.postType, c.hostname, c.texts...); got != c.want {
+			t.Errorf("match(%q, %q, %q) = %q, want %q", c.postType, c.hostname, c.texts, got, c.want)
+		}
+	}
+}
+
+func TestContentFilterValidate(t *testing.T) {
+	cases := []struct {
+		filter  ContentFilter
+		wantErr bool
+	}{
+		{ContentFilter{Type: ContentFilterKeyword, Pattern: "word"}, false},
+		{ContentFilter{Type: ContentFilterKeyword, Pattern: "   "}, true},
+		{ContentFilter{Type: ContentFilterRegex, Pattern: "(unclosed"}, true},
+		{ContentFilter{Type: ContentFilterPostType, Pattern: "video"}, true},
+		{ContentFilter{Type: "unknown", Pattern: "x"}, true},
+		{ContentFilter{Type: ContentFilterKeyword, Pattern: "x", Action: "delete"}, true},
+	}
+	for _, c := range cases {
+		if err := c.filter.validate(); (err != nil) != c.wantErr {
+			t.Errorf("validate(%+v) error = %v, wantErr %v", c.filter, err, c.wantErr)
+		}
+	}
+}
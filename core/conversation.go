@@ -0,0 +1,696 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	"github.com/discuitnet/discuit/internal/images"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+	"github.com/discuitnet/discuit/internal/utils"
+)
+
+const (
+	// maxConversationMembers is the maximum number of users in a conversation,
+	// including the user who started it.
+	maxConversationMembers = 10
+
+	maxConversationTitleLength = 128
+	maxMessageBodyLength       = 10000
+)
+
+// A Conversation is a private message thread between two users (a direct
+// message) or between a small group of users.
+type Conversation struct {
+	db     *sql.DB
+	viewer uid.ID // The user for whom the conversation was fetched.
+
+	ID            uid.ID          `json:"id"`
+	CreatedBy     uid.ID          `json:"createdBy"`
+	IsGroup       bool            `json:"isGroup"`
+	Title         msql.NullString `json:"title"`
+	LastMessageAt time.Time       `json:"lastMessageAt"`
+	CreatedAt     time.Time       `json:"createdAt"`
+
+	Members []*ConversationMember `json:"members"`
+
+	// The following fields are specific to the user for whom the conversation
+	// was fetched.
+	ViewerMuted bool     `json:"viewerMuted"`
+	NumUnread   int      `json:"noUnread"`
+	LastMessage *Message `json:"lastMessage"`
+}
+
+// ConversationMember is a participant of a conversation. LastReadMessageID and
+// LastReadAt serve as read receipts.
+type ConversationMember struct {
+	UserID            uid.ID        `json:"userId"`
+	Username          string        `json:"username"`
+	LastReadMessageID uid.NullID    `json:"lastReadMessageId"`
+	LastReadAt        msql.NullTime `json:"lastReadAt"`
+	JoinedAt          time.Time     `json:"joinedAt"`
+
+	muted bool
+}
+
+// Message is a message in a conversation. Body is in markdown.
+type Message struct {
+	ID             uid.ID          `json:"id"`
+	ConversationID uid.ID          `json:"conversationId"`
+	AuthorID       uid.ID          `json:"authorId"`
+	AuthorUsername string          `json:"authorUsername"`
+	Body           msql.NullString `json:"body"`
+	Image          *images.Image   `json:"image"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeletedAt      msql.NullTime   `json:"deletedAt"`
+	Deleted        bool            `json:"deleted"`
+
+	authorShadowbanned bool
+}
+
+// messagingBlocked reports whether either of the two users has muted the other.
+func messagingBlocked(ctx context.Context, db *sql.DB, a, b uid.ID) (bool, error) {
+	if muted, err := UserMuted(ctx, db, a, b); err != nil || muted {
+		return muted, err
+	}
+	return UserMuted(ctx, db, b, a)
+}
+
+// CreateConversation starts a conversation between creator and members. If
+// there's only one member, and a direct conversation between the two users
+// already exists, the existing conversation is returned. The title is ignored
+// for direct conversations.
+func CreateConversation(ctx context.Context, db *sql.DB, creator uid.ID, members []uid.ID, title string) (*Conversation, error) {
+	var others []uid.ID
+	for _, member := range members {
+		if member != creator && !slices.Contains(others, member) {
+			others = append(others, member)
+		}
+	}
+	if len(others) == 0 {
+		return nil, httperr.NewBadRequest("conversation-no-members", "A conversation needs at least one other user.")
+	}
+	if len(others)+1 > maxConversationMembers {
+		return nil, httperr.NewBadRequest("conversation-too-many-members",
+			fmt.Sprintf("A conversation can have at most %d members.", maxConversationMembers))
+	}
+
+	for _, member := range others {
+		user, err := GetUser(ctx, db, member, nil)
+		if err != nil {
+			return nil, err
+		}
+		if user.Deleted || user.Banned {
+			return nil, errUserNotFound
+		}
+		if blocked, err := messagingBlocked(ctx, db, creator, member); err != nil {
+			return nil, err
+		} else if blocked {
+			return nil, errMessagingBlocked
+		}
+	}
+
+	isGroup := len(others) > 1
+	if !isGroup {
+		var existing uid.ID
+		err := db.QueryRowContext(ctx, `
+			SELECT conversations.id FROM conversations
+			INNER JOIN conversation_members AS a ON a.conversation_id = conversations.id AND a.user_id = ?
+			INNER JOIN conversation_members AS b ON b.conversation_id = conversations.id AND b.user_id = ?
+			WHERE conversations.is_group = FALSE`, creator, others[0]).Scan(&existing)
+		if err == nil {
+			return GetConversation(ctx, db, existing, creator)
+		} else if err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
+	nullTitle := msql.NullString{}
+	if title = strings.TrimSpace(title); isGroup && title != "" {
+		nullTitle = msql.NewNullString(utils.TruncateUnicodeString(title, maxConversationTitleLength))
+	}
+
+	id := uid.New()
+	err := msql.Transact(ctx, db, func(tx *sql.Tx) error {
+		query, args := msql.BuildInsertQuery("conversations", []msql.ColumnValue{
+			{Name: "id", Value: id},
+			{Name: "created_by", Value: creator},
+			{Name: "is_group", Value: isGroup},
+			{Name: "title", Value: nullTitle},
+		})
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		var rows [][]msql.ColumnValue
+		for _, member := range append([]uid.ID{creator}, others...) {
+			rows = append(rows, []msql.ColumnValue{
+				{Name: "conversation_id", Value: id},
+				{Name: "user_id", Value: member},
+			})
+		}
+		query, args = msql.BuildInsertQuery("conversation_members", rows...)
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetConversation(ctx, db, id, creator)
+}
+
+// GetConversation returns the conversation if viewer is a member of it. If
+// not, it returns a not found error.
+func GetConversation(ctx context.Context, db *sql.DB, id, viewer uid.ID) (*Conversation, error) {
+	convs, err := getConversations(ctx, db, viewer, "WHERE conversations.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(convs) == 0 {
+		return nil, errConversationNotFound
+	}
+	return convs[0], nil
+}
+
+// ConversationsResultSet is a page of conversations.
+type ConversationsResultSet struct {
+	Conversations []*Conversation `json:"conversations"`
+	Next          *string         `json:"next"`
+}
+
+// GetUserConversations returns the conversations of user, the ones with the
+// most recent messages first. The next string, if not nil, is the pagination
+// cursor returned by the previous call.
+func GetUserConversations(ctx context.Context, db *sql.DB, user uid.ID, limit int, next *string) (*ConversationsResultSet, error) {
+	where, args := "", []any{}
+	if next != nil {
+		t, id, err := conversationsCursor(*next)
+		if err != nil {
+			return nil, errInvalidCursor
+		}
+		where = "WHERE (conversations.last_message_at, conversations.id) <= (?, ?) "
+		args = append(args, t, id)
+	}
+	where += fmt.Sprintf("ORDER BY conversations.last_message_at DESC, conversations.id DESC LIMIT %d", limit+1)
+
+	convs, err := getConversations(ctx, db, user, where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &ConversationsResultSet{Conversations: convs}
+	if len(convs) > limit {
+		set.Next = new(string)
+		*set.Next = strconv.FormatInt(convs[limit].LastMessageAt.UnixNano(), 10) + "." + convs[limit].ID.String()
+		set.Conversations = convs[:limit]
+	}
+	return set, nil
+}
+
+// conversationsCursor parses a cursor of the form "<unix nanoseconds>.<id>",
+// as returned by GetUserConversations.
+func conversationsCursor(text string) (time.Time, uid.ID, error) {
+	var id uid.ID
+	nanos, sid, ok := strings.Cut(text, ".")
+	if !ok {
+		return time.Time{}, id, errors.New("invalid cursor")
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, id, err
+	}
+	if err := id.UnmarshalText([]byte(sid)); err != nil {
+		return time.Time{}, id, err
+	}
+	return time.Unix(0, n).UTC(), id, nil
+}
+
+func getConversations(ctx context.Context, db *sql.DB, viewer uid.ID, where string, args ...any) ([]*Conversation, error) {
+	query := msql.BuildSelectQuery("conversations", []string{
+		"conversations.id",
+		"conversations.created_by",
+		"conversations.is_group",
+		"conversations.title",
+		"conversations.last_message_at",
+		"conversations.created_at",
+		"viewer_member.muted",
+	}, []string{
+		"INNER JOIN conversation_members AS viewer_member ON viewer_member.conversation_id = conversations.id AND viewer_member.user_id = ?",
+	}, where)
+
+	rows, err := db.QueryContext(ctx, query, append([]any{viewer}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	convs := []*Conversation{}
+	for rows.Next() {
+		c := &Conversation{db: db, viewer: viewer}
+		if err = rows.Scan(
+			&c.ID,
+			&c.CreatedBy,
+			&c.IsGroup,
+			&c.Title,
+			&c.LastMessageAt,
+			&c.CreatedAt,
+			&c.ViewerMuted); err != nil {
+			return nil, err
+		}
+		convs = append(convs, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(convs) == 0 {
+		return convs, nil
+	}
+	if err := populateConversations(ctx, db, viewer, convs); err != nil {
+		return nil, err
+	}
+	return convs, nil
+}
+
+// populateConversations fetches the members, the latest message, and the
+// number of unread messages (of viewer) of each conversation.
+func populateConversations(ctx context.Context, db *sql.DB, viewer uid.ID, convs []*Conversation) error {
+	m := make(map[uid.ID]*Conversation, len(convs))
+	ids := make([]any, len(convs))
+	for i, c := range convs {
+		m[c.ID] = c
+		ids[i] = c.ID
+		c.Members = []*ConversationMember{}
+	}
+	in := msql.InClauseQuestionMarks(len(ids))
+
+	// Members:
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+			conversation_members.conversation_id,
+			conversation_members.user_id,
+			users.username,
+			users.deleted_at,
+			conversation_members.muted,
+			conversation_members.last_read_message_id,
+			conversation_members.last_read_at,
+			conversation_members.joined_at
+		FROM conversation_members
+		INNER JOIN users ON users.id = conversation_members.user_id
+		WHERE conversation_members.conversation_id IN `+in+`
+		ORDER BY conversation_members.joined_at`, ids...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			convID    uid.ID
+			deletedAt msql.NullTime
+			member    = &ConversationMember{}
+		)
+		if err = rows.Scan(
+			&convID,
+			&member.UserID,
+			&member.Username,
+			&deletedAt,
+			&member.muted,
+			&member.LastReadMessageID,
+			&member.LastReadAt,
+			&member.JoinedAt); err != nil {
+			return err
+		}
+		if deletedAt.Valid {
+			member.Username = "ghost"
+		}
+		m[convID].Members = append(m[convID].Members, member)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	// Latest messages:
+	// (The messages of shadowbanned users are hidden from everyone but
+	// themselves.)
+	messages, err := getMessages(ctx, db, `WHERE conversation_messages.id IN (
+		SELECT MAX(conversation_messages.id)
+		FROM conversation_messages
+		INNER JOIN users ON users.id = conversation_messages.user_id
+		WHERE conversation_messages.conversation_id IN `+in+` AND (users.shadowbanned_at IS NULL OR users.id = ?)
+		GROUP BY conversation_messages.conversation_id)`, append(ids, viewer)...)
+	if err != nil {
+		return err
+	}
+	for _, message := range messages {
+		m[message.ConversationID].LastMessage = message
+	}
+
+	// Unread counts:
+	args := append([]any{viewer}, ids...)
+	args = append(args, viewer)
+	rows2, err := db.QueryContext(ctx, `
+		SELECT conversation_messages.conversation_id, COUNT(*)
+		FROM conversation_messages
+		INNER JOIN conversation_members ON conversation_members.conversation_id = conversation_messages.conversation_id AND conversation_members.user_id = ?
+		WHERE conversation_messages.conversation_id IN `+in+`
+			AND conversation_messages.user_id <> ?
+			AND conversation_messages.user_id NOT IN (SELECT id FROM users WHERE shadowbanned_at IS NOT NULL)
+			AND (conversation_members.last_read_message_id IS NULL OR conversation_messages.id > conversation_members.last_read_message_id)
+		GROUP BY conversation_messages.conversation_id`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows2.Close()
+	for rows2.Next() {
+		var convID uid.ID
+		var n int
+		if err = rows2.Scan(&convID, &n); err != nil {
+			return err
+		}
+		m[convID].NumUnread = n
+	}
+	return rows2.Err()
+}
+
+func (c *Conversation) member(user uid.ID) *ConversationMember {
+	for _, member := range c.Members {
+		if member.UserID == user {
+			return member
+		}
+	}
+	return nil
+}
+
+// SendMessage adds a message to the conversation on behalf of author. The image
+// is optional. Members of the conversation, other than author, are notified of
+// the message, unless they've muted either the conversation or author. The
+// messages of shadowbanned authors are not delivered to the other members.
+func (c *Conversation) SendMessage(ctx context.Context, author uid.ID, body string, image []byte) (*Message, error) {
+	if c.member(author) == nil {
+		return nil, errConversationNotFound
+	}
+
+	body = utils.TruncateUnicodeString(strings.TrimSpace(body), maxMessageBodyLength)
+	if body == "" && image == nil {
+		return nil, httperr.NewBadRequest("message-empty", "Message is empty.")
+	}
+
+	if !c.IsGroup {
+		for _, member := range c.Members {
+			if member.UserID == author {
+				continue
+			}
+			if blocked, err := messagingBlocked(ctx, c.db, author, member.UserID); err != nil {
+				return nil, err
+			} else if blocked {
+				return nil, errMessagingBlocked
+			}
+		}
+	}
+
+	var imageID uid.NullID
+	if image != nil {
+		record, err := images.SaveImage(ctx, c.db, "disk", image, &images.ImageOptions{
+			Width:  5000,
+			Height: 5000,
+			Format: images.ImageFormatJPEG,
+			Fit:    images.ImageFitContain,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to save message image (author: %v): %w", author, err)
+		}
+		imageID.Valid, imageID.ID = true, record.ID
+	}
+
+	var nullBody msql.NullString
+	nullBody.Valid, nullBody.String = body != "", body
+
+	// The messages of shadowbanned users are not delivered to the other
+	// members of the conversation.
+	shadowbanned, err := UserShadowbanned(ctx, c.db, author)
+	if err != nil {
+		return nil, err
+	}
+
+	id, now := uid.New(), time.Now()
+	err = msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		query, args := msql.BuildInsertQuery("conversation_messages", []msql.ColumnValue{
+			{Name: "id", Value: id},
+			{Name: "conversation_id", Value: c.ID},
+			{Name: "user_id", Value: author},
+			{Name: "body", Value: nullBody},
+			{Name: "image_id", Value: imageID},
+			{Name: "created_at", Value: now},
+		})
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		if !shadowbanned {
+			if _, err := tx.ExecContext(ctx, "UPDATE conversations SET last_message_at = ? WHERE id = ?", now, c.ID); err != nil {
+				return err
+			}
+		}
+		// The author has, of course, read their own message.
+		_, err := tx.ExecContext(ctx, "UPDATE conversation_members SET last_read_message_id = ?, last_read_at = ? WHERE conversation_id = ? AND user_id = ?", id, now, c.ID, author)
+		return err
+	})
+	if err != nil {
+		if imageID.Valid {
+			if err := msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+				return images.DeleteImagesTx(ctx, tx, c.db, imageID.ID)
+			}); err != nil {
+				log.Printf("failed to delete image (core.Conversation.SendMessage): %v\n", err)
+			}
+		}
+		return nil, err
+	}
+	if !shadowbanned {
+		c.LastMessageAt = now
+	}
+
+	message, err := getMessage(ctx, c.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if shadowbanned {
+		return message, nil
+	}
+
+	for _, member := range c.Members {
+		if member.UserID == author || member.muted {
+			continue
+		}
+		go func(receiver uid.ID) {
+			if err := CreateNewMessageNotification(context.Background(), c.db, receiver, message); err != nil {
+				log.Printf("Create new_message notification failed: %v\n", err)
+			}
+		}(member.UserID)
+	}
+
+	return message, nil
+}
+
+// MessagesResultSet is a page of messages.
+type MessagesResultSet struct {
+	Messages []*Message `json:"messages"`
+	Next     *string    `json:"next"` // A message id.
+}
+
+// GetMessages returns the messages of the conversation, the latest ones first,
+// excluding those of shadowbanned users other than the viewer. The next string,
+// if not nil, is the pagination cursor returned by the previous call.
+func (c *Conversation) GetMessages(ctx context.Context, limit int, next *string) (*MessagesResultSet, error) {
+	where, args := "WHERE conversation_messages.conversation_id = ?", []any{c.ID}
+	if next != nil {
+		nextID, err := uid.FromString(*next)
+		if err != nil {
+			return nil, errInvalidCursor
+		}
+		where += " AND conversation_messages.id <= ?"
+		args = append(args, nextID)
+	}
+	where += fmt.Sprintf(" ORDER BY conversation_messages.id DESC LIMIT %d", limit+1)
+
+	messages, err := getMessages(ctx, c.db, where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &MessagesResultSet{Messages: messages}
+	if len(messages) > limit {
+		set.Next = new(string)
+		*set.Next = messages[limit].ID.String()
+		set.Messages = messages[:limit]
+	}
+	set.Messages = removeShadowbannedMessages(set.Messages, c.viewer)
+	return set, nil
+}
+
+// MarkRead marks all the messages in the conversation as read by user.
+func (c *Conversation) MarkRead(ctx context.Context, user uid.ID) error {
+	member := c.member(user)
+	if member == nil {
+		return errConversationNotFound
+	}
+	if c.LastMessage == nil {
+		return nil
+	}
+
+	now := time.Now()
+	if _, err := c.db.ExecContext(ctx, "UPDATE conversation_members SET last_read_message_id = ?, last_read_at = ? WHERE conversation_id = ? AND user_id = ?",
+		c.LastMessage.ID, now, c.ID, user); err != nil {
+		return err
+	}
+	member.LastReadMessageID = uid.NullID{Valid: true, ID: c.LastMessage.ID}
+	member.LastReadAt = msql.NewNullTime(now)
+	c.NumUnread = 0
+	return nil
+}
+
+// SetMuted mutes (or unmutes) the conversation for user. No notifications are
+// sent to users who've muted a conversation.
+func (c *Conversation) SetMuted(ctx context.Context, user uid.ID, muted bool) error {
+	member := c.member(user)
+	if member == nil {
+		return errConversationNotFound
+	}
+	if _, err := c.db.ExecContext(ctx, "UPDATE conversation_members SET muted = ? WHERE conversation_id = ? AND user_id = ?", muted, c.ID, user); err != nil {
+		return err
+	}
+	member.muted = muted
+	c.ViewerMuted = muted
+	return nil
+}
+
+// Leave removes user from a group conversation. The conversation is deleted
+// when the last member leaves.
+func (c *Conversation) Leave(ctx context.Context, user uid.ID) error {
+	if c.member(user) == nil {
+		return errConversationNotFound
+	}
+	if !c.IsGroup {
+		return httperr.NewBadRequest("conversation-not-group", "Cannot leave a direct conversation.")
+	}
+	return msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM conversation_members WHERE conversation_id = ? AND user_id = ?", c.ID, user); err != nil {
+			return err
+		}
+		if len(c.Members) == 1 {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM conversations WHERE id = ?", c.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteMessage deletes the content of a message. Only the author of a message
+// can delete it.
+func (c *Conversation) DeleteMessage(ctx context.Context, user, messageID uid.ID) error {
+	message, err := getMessage(ctx, c.db, messageID)
+	if err != nil {
+		return err
+	}
+	if message.ConversationID != c.ID {
+		return errMessageNotFound
+	}
+	if message.AuthorID != user {
+		return errNotAuthor
+	}
+	if message.Deleted {
+		return nil
+	}
+
+	return msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE conversation_messages SET body = NULL, image_id = NULL, deleted_at = ? WHERE id = ?", time.Now(), message.ID); err != nil {
+			return err
+		}
+		if message.Image != nil {
+			return images.DeleteImagesTx(ctx, tx, c.db, *message.Image.ID)
+		}
+		return nil
+	})
+}
+
+func getMessage(ctx context.Context, db *sql.DB, id uid.ID) (*Message, error) {
+	messages, err := getMessages(ctx, db, "WHERE conversation_messages.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, errMessageNotFound
+	}
+	return messages[0], nil
+}
+
+func getMessages(ctx context.Context, db *sql.DB, where string, args ...any) ([]*Message, error) {
+	cols := []string{
+		"conversation_messages.id",
+		"conversation_messages.conversation_id",
+		"conversation_messages.user_id",
+		"users.username",
+		"users.deleted_at",
+		"users.shadowbanned_at IS NOT NULL",
+		"conversation_messages.body",
+		"conversation_messages.created_at",
+		"conversation_messages.deleted_at",
+	}
+	cols = append(cols, images.ImageColumns("message_image")...)
+	query := msql.BuildSelectQuery("conversation_messages", cols, []string{
+		"INNER JOIN users ON users.id = conversation_messages.user_id",
+		"LEFT JOIN images AS message_image ON message_image.id = conversation_messages.image_id",
+	}, where)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*Message{}
+	for rows.Next() {
+		message := &Message{}
+		var authorDeletedAt msql.NullTime
+		dests := []any{
+			&message.ID,
+			&message.ConversationID,
+			&message.AuthorID,
+			&message.AuthorUsername,
+			&authorDeletedAt,
+			&message.authorShadowbanned,
+			&message.Body,
+			&message.CreatedAt,
+			&message.DeletedAt,
+		}
+		image := &images.Image{}
+		dests = append(dests, image.ScanDestinations()...)
+		if err = rows.Scan(dests...); err != nil {
+			return nil, err
+		}
+
+		if authorDeletedAt.Valid {
+			message.AuthorUsername = "ghost"
+		}
+		message.Deleted = message.DeletedAt.Valid
+		if image.ID != nil {
+			image.PostScan()
+			image.AppendCopy("small", 325, 250, images.ImageFitCover, "")
+			image.AppendCopy("large", 1080, 2160, images.ImageFitContain, "")
+			message.Image = image
+		}
+		messages = append(messages, message)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
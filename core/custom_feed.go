@@ -0,0 +1,397 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+	"github.com/discuitnet/discuit/internal/utils"
+)
+
+// maxCustomFeedCommunities is the maximum number of communities a custom feed
+// can have.
+const maxCustomFeedCommunities = 100
+
+var (
+	errCustomFeedNotFound      = httperr.NewNotFound("custom-feed-not-found", "Custom feed not found.")
+	errCustomFeedTooManyComms  = httperr.NewBadRequest("custom-feed-too-many-communities", fmt.Sprintf("A custom feed can have at most %d communities.", maxCustomFeedCommunities))
+	errCustomFeedFollowOwnFeed = httperr.NewBadRequest("custom-feed-own", "Cannot follow your own custom feed.")
+)
+
+// A CustomFeed is a named, user-created feed of the posts of a set of
+// communities. Public custom feeds can be viewed (and followed) by anyone with
+// the link, private ones only by their owner.
+type CustomFeed struct {
+	ID             int             `json:"id"`
+	UserID         uid.ID          `json:"userId"`
+	Username       string          `json:"username"`
+	Name           string          `json:"name"`
+	DisplayName    string          `json:"displayName"`
+	Description    msql.NullString `json:"description"`
+	Public         bool            `json:"public"`
+	NumCommunities int             `json:"numCommunities"`
+	NumFollowers   int             `json:"numFollowers"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	LastUpdatedAt  time.Time       `json:"lastUpdatedAt"`
+
+	// Populated by FetchCommunities.
+	Communities []*Community `json:"communities,omitempty"`
+
+	// Whether the viewer follows the feed.
+	ViewerFollowing bool `json:"following"`
+}
+
+func getCustomFeeds(ctx context.Context, db *sql.DB, viewer *uid.ID, where string, args ...any) ([]*CustomFeed, error) {
+	query := msql.BuildSelectQuery("custom_feeds", []string{
+		"custom_feeds.id",
+		"custom_feeds.user_id",
+		"users.username",
+		"custom_feeds.name",
+		"custom_feeds.display_name",
+		"custom_feeds.description",
+		"custom_feeds.public",
+		"custom_feeds.num_communities",
+		"custom_feeds.num_followers",
+		"custom_feeds.created_at",
+		"custom_feeds.last_updated_at",
+	}, []string{
+		"INNER JOIN users ON custom_feeds.user_id = users.id",
+	}, where)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := []*CustomFeed{}
+	for rows.Next() {
+		f := &CustomFeed{}
+		if err := rows.Scan(
+			&f.ID,
+			&f.UserID,
+			&f.Username,
+			&f.Name,
+			&f.DisplayName,
+			&f.Description,
+			&f.Public,
+			&f.NumCommunities,
+			&f.NumFollowers,
+			&f.CreatedAt,
+			&f.LastUpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if viewer != nil && len(feeds) > 0 {
+		args := []any{*viewer}
+		for _, f := range feeds {
+			args = append(args, f.ID)
+		}
+		rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT feed_id FROM custom_feed_follows WHERE user_id = ? AND feed_id IN %s", msql.InClauseQuestionMarks(len(feeds))), args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				return nil, err
+			}
+			for _, f := range feeds {
+				if f.ID == id {
+					f.ViewerFollowing = true
+				}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return feeds, nil
+}
+
+// GetCustomFeed returns the custom feed with id. If the feed is private and
+// viewer is not its owner, an error is returned as if the feed does not exist.
+func GetCustomFeed(ctx context.Context, db *sql.DB, id int, viewer *uid.ID) (*CustomFeed, error) {
+	feeds, err := getCustomFeeds(ctx, db, viewer, "WHERE custom_feeds.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	return firstVisibleCustomFeed(feeds, viewer)
+}
+
+// GetCustomFeedByName returns the custom feed of user named name. Private
+// feeds are only returned to their owners.
+func GetCustomFeedByName(ctx context.Context, db *sql.DB, user uid.ID, name string, viewer *uid.ID) (*CustomFeed, error) {
+	feeds, err := getCustomFeeds(ctx, db, viewer, "WHERE custom_feeds.user_id = ? AND custom_feeds.name = ?", user, name)
+	if err != nil {
+		return nil, err
+	}
+	return firstVisibleCustomFeed(feeds, viewer)
+}
+
+func firstVisibleCustomFeed(feeds []*CustomFeed, viewer *uid.ID) (*CustomFeed, error) {
+	if len(feeds) == 0 {
+		return nil, errCustomFeedNotFound
+	}
+	f := feeds[0]
+	if !f.Public && !f.IsOwner(viewer) {
+		return nil, errCustomFeedNotFound
+	}
+	return f, nil
+}
+
+// IsOwner reports whether user is the creator of the feed.
+func (f *CustomFeed) IsOwner(user *uid.ID) bool {
+	return user != nil && *user == f.UserID
+}
+
+// GetUsersCustomFeeds returns the custom feeds of user. Private feeds are only
+// returned if viewer is user.
+func GetUsersCustomFeeds(ctx context.Context, db *sql.DB, user uid.ID, viewer *uid.ID) ([]*CustomFeed, error) {
+	where := "WHERE custom_feeds.user_id = ? "
+	if viewer == nil || *viewer != user {
+		where += "AND custom_feeds.public = TRUE "
+	}
+	where += "ORDER BY custom_feeds.name ASC"
+	return getCustomFeeds(ctx, db, viewer, where, user)
+}
+
+// GetFollowedCustomFeeds returns the custom feeds of others that user follows.
+// Feeds that have since been made private are skipped.
+func GetFollowedCustomFeeds(ctx context.Context, db *sql.DB, user uid.ID) ([]*CustomFeed, error) {
+	return getCustomFeeds(ctx, db, &user, `
+		INNER JOIN custom_feed_follows ON custom_feed_follows.feed_id = custom_feeds.id
+		WHERE custom_feed_follows.user_id = ? AND custom_feeds.public = TRUE
+		ORDER BY custom_feed_follows.created_at DESC`, user)
+}
+
+// customFeedNameValid always returns an httperr.Error.
+func customFeedNameValid(name string) error {
+	if err := IsUsernameValid(name); err != nil {
+		return httperr.NewBadRequest("invalid-custom-feed-name", fmt.Sprintf("Custom feed name %v.", err))
+	}
+	return nil
+}
+
+// CreateCustomFeed creates a custom feed of communities for user.
+func CreateCustomFeed(ctx context.Context, db *sql.DB, user uid.ID, name, displayName string, description msql.NullString, public bool, communities []uid.ID) (*CustomFeed, error) {
+	if err := customFeedNameValid(name); err != nil {
+		return nil, err
+	}
+	if displayName == "" {
+		displayName = name
+	}
+	displayName = utils.TruncateUnicodeString(displayName, 50)
+	description.String = utils.TruncateUnicodeString(description.String, maxUserProfileAboutLength)
+	if description.String == "" {
+		description.Valid = false
+	}
+
+	var id int
+	err := msql.Transact(ctx, db, func(tx *sql.Tx) error {
+		query, args := msql.BuildInsertQuery("custom_feeds", []msql.ColumnValue{
+			{Name: "user_id", Value: user},
+			{Name: "name", Value: name},
+			{Name: "display_name", Value: displayName},
+			{Name: "description", Value: description},
+			{Name: "public", Value: public},
+		})
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			if msql.IsErrDuplicateErr(err) {
+				return &httperr.Error{
+					HTTPStatus: http.StatusConflict,
+					Code:       "duplicate-custom-feed",
+					Message:    "A custom feed with that name already exists.",
+				}
+			}
+			return err
+		}
+		lastID, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		id = int(lastID)
+		return setCustomFeedCommunities(ctx, tx, id, communities)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetCustomFeed(ctx, db, id, &user)
+}
+
+// setCustomFeedCommunities replaces the communities of the feed with
+// communities.
+func setCustomFeedCommunities(ctx context.Context, tx *sql.Tx, feed int, communities []uid.ID) error {
+	seen := make(map[uid.ID]bool)
+	var unique []uid.ID
+	for _, c := range communities {
+		if !seen[c] {
+			seen[c] = true
+			unique = append(unique, c)
+		}
+	}
+	if len(unique) > maxCustomFeedCommunities {
+		return errCustomFeedTooManyComms
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM custom_feed_communities WHERE feed_id = ?", feed); err != nil {
+		return err
+	}
+	for _, c := range unique {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO custom_feed_communities (feed_id, community_id) SELECT ?, id FROM communities WHERE id = ?", feed, c); err != nil {
+			return err
+		}
+	}
+	_, err := tx.ExecContext(ctx, `
+		UPDATE custom_feeds SET
+			num_communities = (SELECT COUNT(*) FROM custom_feed_communities WHERE feed_id = ?),
+			last_updated_at = ?
+		WHERE id = ?`, feed, time.Now(), feed)
+	return err
+}
+
+// FetchCommunities populates f.Communities.
+func (f *CustomFeed) FetchCommunities(ctx context.Context, db *sql.DB, viewer *uid.ID) error {
+	rows, err := db.QueryContext(ctx, "SELECT community_id FROM custom_feed_communities WHERE feed_id = ?", f.ID)
+	if err != nil {
+		return err
+	}
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return err
+	}
+	f.Communities = []*Community{}
+	if len(ids) == 0 {
+		return nil
+	}
+	comms, err := GetCommunitiesByIDs(ctx, db, ids, viewer)
+	if err != nil && err != errCommunityNotFound {
+		return err
+	}
+	if comms != nil {
+		f.Communities = comms
+	}
+	return nil
+}
+
+// Update saves the updatable fields of the feed. If communities is not nil,
+// the communities of the feed are replaced with it.
+func (f *CustomFeed) Update(ctx context.Context, db *sql.DB, communities []uid.ID) error {
+	if err := customFeedNameValid(f.Name); err != nil {
+		return err
+	}
+	if f.DisplayName == "" {
+		f.DisplayName = f.Name
+	}
+	f.DisplayName = utils.TruncateUnicodeString(f.DisplayName, 50)
+	f.Description.String = utils.TruncateUnicodeString(f.Description.String, maxUserProfileAboutLength)
+
+	return msql.Transact(ctx, db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE custom_feeds SET
+				name = ?,
+				display_name = ?,
+				description = ?,
+				public = ?,
+				last_updated_at = ?
+			WHERE id = ?`,
+			f.Name,
+			f.DisplayName,
+			f.Description,
+			f.Public,
+			time.Now(),
+			f.ID)
+		if err != nil {
+			if msql.IsErrDuplicateErr(err) {
+				return &httperr.Error{
+					HTTPStatus: http.StatusConflict,
+					Code:       "duplicate-custom-feed",
+					Message:    "A custom feed with that name already exists.",
+				}
+			}
+			return err
+		}
+		if communities != nil {
+			return setCustomFeedCommunities(ctx, tx, f.ID, communities)
+		}
+		return nil
+	})
+}
+
+// UnmarshalUpdatableFieldsJSON extracts the updatable values of the feed from
+// the encoded JSON string.
+func (f *CustomFeed) UnmarshalUpdatableFieldsJSON(data []byte) error {
+	temp := *f // shallow copy
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	f.Name = temp.Name
+	f.DisplayName = temp.DisplayName
+	f.Description = temp.Description
+	if f.Description.String == "" {
+		f.Description.Valid = false
+	}
+	f.Public = temp.Public
+	return nil
+}
+
+// Delete deletes the feed. Its communities and follows are deleted along with
+// it (ON DELETE CASCADE).
+func (f *CustomFeed) Delete(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, "DELETE FROM custom_feeds WHERE id = ?", f.ID)
+	return err
+}
+
+// Follow makes user follow (or unfollow, if follow is false) the feed.
+func (f *CustomFeed) Follow(ctx context.Context, db *sql.DB, user uid.ID, follow bool) error {
+	if f.UserID == user {
+		return errCustomFeedFollowOwnFeed
+	}
+	if follow && !f.Public {
+		return errCustomFeedNotFound
+	}
+	err := msql.Transact(ctx, db, func(tx *sql.Tx) error {
+		var (
+			res sql.Result
+			err error
+		)
+		if follow {
+			res, err = tx.ExecContext(ctx, "INSERT IGNORE INTO custom_feed_follows (user_id, feed_id) VALUES (?, ?)", user, f.ID)
+		} else {
+			res, err = tx.ExecContext(ctx, "DELETE FROM custom_feed_follows WHERE user_id = ? AND feed_id = ?", user, f.ID)
+		}
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+		delta := 1
+		if !follow {
+			delta = -1
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE custom_feeds SET num_followers = num_followers + ? WHERE id = ?", delta, f.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	f.ViewerFollowing = follow
+	return nil
+}
+
+const whereSelectCustomFeedComms = "community_id IN (SELECT custom_feed_communities.community_id FROM custom_feed_communities WHERE custom_feed_communities.feed_id = ?) "
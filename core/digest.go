@@ -0,0 +1,464 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"net/url"
+	"text/template"
+	"time"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	"github.com/discuitnet/discuit/internal/mailer"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+	"github.com/discuitnet/discuit/internal/utils"
+)
+
+// EmailDigest is how often a user receives email digests.
+type EmailDigest int
+
+const (
+	EmailDigestOff = EmailDigest(iota)
+	EmailDigestDaily
+	EmailDigestWeekly
+)
+
+func (d EmailDigest) Valid() bool {
+	_, err := d.MarshalText()
+	return err == nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d EmailDigest) MarshalText() ([]byte, error) {
+	switch d {
+	case EmailDigestOff:
+		return []byte("off"), nil
+	case EmailDigestDaily:
+		return []byte("daily"), nil
+	case EmailDigestWeekly:
+		return []byte("weekly"), nil
+	}
+	return nil, fmt.Errorf("cannot marshal unsupported EmailDigest (%v)", int(d))
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (d *EmailDigest) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "off":
+		*d = EmailDigestOff
+	case "daily":
+		*d = EmailDigestDaily
+	case "weekly":
+		*d = EmailDigestWeekly
+	default:
+		return httperr.NewBadRequest("invalid_email_digest", "Unsupported email digest frequency.")
+	}
+	return nil
+}
+
+// period returns the time between two digests.
+func (d EmailDigest) period() time.Duration {
+	if d == EmailDigestWeekly {
+		return time.Hour * 24 * 7
+	}
+	return time.Hour * 24
+}
+
+const (
+	// The maximum number of notifications and posts listed in a digest.
+	maxDigestNotifications = 10
+	maxDigestPosts         = 5
+
+	// Digests are sent by a job that runs periodically (every hour), so allow
+	// for some slack to keep the time of day of digests from drifting.
+	digestSlack = time.Minute * 30
+)
+
+//go:embed templates/digest.html templates/digest.txt
+var digestTemplatesFS embed.FS
+
+var (
+	digestFuncs = map[string]any{
+		"sub": func(a, b int) int { return a - b },
+	}
+	digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(digestFuncs).ParseFS(digestTemplatesFS, "templates/digest.html"))
+	digestTextTemplate = template.Must(template.New("digest.txt").Funcs(digestFuncs).ParseFS(digestTemplatesFS, "templates/digest.txt"))
+)
+
+// DigestOptions holds the site-wide settings needed to send email digests.
+type DigestOptions struct {
+	SiteName        string
+	PublicURL       string // Without a trailing slash.
+	CommunityPrefix string
+	From            string // The address emails are sent from.
+	HMACSecret      string // For signing unsubscribe links.
+}
+
+type emailDigest struct {
+	SiteName        string
+	CommunityPrefix string
+	Username        string
+	Period          string
+
+	NumUnread     int
+	Notifications []digestNotification
+	Posts         []digestPost
+	ModQueue      []digestModQueue
+
+	NotificationsURL string
+	SettingsURL      string
+	UnsubscribeURL   string
+}
+
+type digestNotification struct {
+	Text string
+}
+
+type digestPost struct {
+	Title       string
+	Community   string
+	Points      int
+	NumComments int
+	URL         string
+}
+
+type digestModQueue struct {
+	Community  string
+	NumReports int
+	URL        string
+}
+
+// empty reports whether there's nothing worth sending in the digest.
+func (d *emailDigest) empty() bool {
+	return d.NumUnread == 0 && len(d.Posts) == 0 && len(d.ModQueue) == 0
+}
+
+// digestUnsubscribeMessage is the message signed by email digest unsubscribe
+// tokens.
+func digestUnsubscribeMessage(user uid.ID) string {
+	return "email_digest_unsubscribe:" + user.String()
+}
+
+// DigestUnsubscribeToken returns the token of the one-click unsubscribe links
+// of user's email digests.
+func DigestUnsubscribeToken(user uid.ID, secret string) string {
+	return utils.NewHMAC(digestUnsubscribeMessage(user), secret)
+}
+
+// UnsubscribeFromEmailDigests turns off email digests for user if token is a
+// valid token returned by DigestUnsubscribeToken.
+func UnsubscribeFromEmailDigests(ctx context.Context, db *sql.DB, user uid.ID, token, secret string) error {
+	if valid, err := utils.ValidMAC(digestUnsubscribeMessage(user), token, secret); err != nil || !valid {
+		return httperr.NewForbidden("invalid_token", "Invalid unsubscribe link.")
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE users SET email_digest = ? WHERE id = ?", EmailDigestOff, user); err != nil {
+		return err
+	}
+	return nil
+}
+
+// digestRecipient is a user who may be due an email digest.
+type digestRecipient struct {
+	ID     uid.ID
+	Email  msql.NullString
+	Digest EmailDigest
+	SentAt msql.NullTime // When the last digest was sent.
+}
+
+// due reports whether a digest is due to be sent to r at time now.
+func (r *digestRecipient) due(now time.Time) bool {
+	if !r.Email.Valid || r.Email.String == "" || r.Digest == EmailDigestOff {
+		return false
+	}
+	if !r.SentAt.Valid {
+		return true
+	}
+	return !r.SentAt.Time.After(now.Add(-r.Digest.period() + digestSlack))
+}
+
+// SendEmailDigests sends email digests to all the users whose digests are due,
+// and it returns the number of emails sent. Digests with nothing in them are
+// skipped.
+func SendEmailDigests(ctx context.Context, db *sql.DB, m mailer.Mailer, opts *DigestOptions) (int, error) {
+	now := time.Now()
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, email, email_digest, email_digest_sent_at FROM users
+		WHERE email_digest <> ? AND email IS NOT NULL AND deleted_at IS NULL AND banned_at IS NULL`,
+		EmailDigestOff)
+	if err != nil {
+		return 0, err
+	}
+
+	var users []uid.ID
+	for rows.Next() {
+		var r digestRecipient
+		if err := rows.Scan(&r.ID, &r.Email, &r.Digest, &r.SentAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if r.due(now) {
+			users = append(users, r.ID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, id := range users {
+		ok, err := sendEmailDigest(ctx, db, m, opts, id, now)
+		if err != nil {
+			// Don't let one user's digest stop everyone else's.
+			log.Printf("Error sending email digest to user %v: %v\n", id, err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// sendEmailDigest sends a digest to user. It reports whether an email was
+// actually sent.
+func sendEmailDigest(ctx context.Context, db *sql.DB, m mailer.Mailer, opts *DigestOptions, userID uid.ID, now time.Time) (bool, error) {
+	user, err := GetUser(ctx, db, userID, nil)
+	if err != nil {
+		return false, err
+	}
+	if !user.Email.Valid || user.Email.String == "" || user.EmailDigest == EmailDigestOff {
+		return false, nil
+	}
+
+	digest, err := buildEmailDigest(ctx, db, user, now.Add(-user.EmailDigest.period()), opts)
+	if err != nil {
+		return false, err
+	}
+
+	sent := false
+	if !digest.empty() {
+		var html, text bytes.Buffer
+		if err := digestHTMLTemplate.Execute(&html, digest); err != nil {
+			return false, err
+		}
+		if err := digestTextTemplate.Execute(&text, digest); err != nil {
+			return false, err
+		}
+		msg := &mailer.Message{
+			From:    opts.From,
+			To:      user.Email.String,
+			Subject: fmt.Sprintf("Your %s summary from %s", digest.Period, opts.SiteName),
+			Text:    text.String(),
+			HTML:    html.String(),
+			Headers: map[string]string{
+				"List-Unsubscribe":      "<" + digest.UnsubscribeURL + ">",
+				"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+			},
+		}
+		if err := m.Send(ctx, msg); err != nil {
+			return false, err
+		}
+		sent = true
+	}
+
+	_, err = db.ExecContext(ctx, "UPDATE users SET email_digest_sent_at = ? WHERE id = ?", now, user.ID)
+	return sent, err
+}
+
+func buildEmailDigest(ctx context.Context, db *sql.DB, user *User, since time.Time, opts *DigestOptions) (*emailDigest, error) {
+	period, _ := user.EmailDigest.MarshalText()
+	d := &emailDigest{
+		SiteName:         opts.SiteName,
+		CommunityPrefix:  opts.CommunityPrefix,
+		Username:         user.Username,
+		Period:           string(period),
+		NotificationsURL: opts.PublicURL + "/notifications",
+		SettingsURL:      opts.PublicURL + "/settings",
+		UnsubscribeURL: opts.PublicURL + "/api/_unsubscribe?" + url.Values{
+			"user":  {user.ID.String()},
+			"token": {DigestUnsubscribeToken(user.ID, opts.HMACSecret)},
+		}.Encode(),
+	}
+
+	// Unread notifications, of the types that the user gets emails for.
+	// Notifications not shown in the notifications list are never seen, so
+	// only the ones since the last digest are included.
+	prefs, err := GetNotificationPreferences(ctx, db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var types []any
+	for _, t := range notificationTypes {
+		if prefs[t].Email {
+			types = append(types, t)
+		}
+	}
+	if len(types) > 0 {
+		where := fmt.Sprintf("WHERE user_id = ? AND seen = false AND (in_app = true OR updated_at > ?) AND type IN %s", msql.InClauseQuestionMarks(len(types)))
+		args := append([]any{user.ID, since}, types...)
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM notifications "+where, args...).Scan(&d.NumUnread); err != nil {
+			return nil, err
+		}
+		if d.NumUnread > 0 {
+			query := msql.BuildSelectQuery("notifications", selectNotificationCols, nil, where+" ORDER BY updated_at DESC LIMIT ?")
+			rows, err := db.QueryContext(ctx, query, append(args, maxDigestNotifications)...)
+			if err != nil {
+				return nil, err
+			}
+			notifs, err := scanNotifications(db, rows)
+			if err != nil && err != sql.ErrNoRows {
+				return nil, err
+			}
+			for _, n := range notifs {
+				d.Notifications = append(d.Notifications, digestNotification{Text: digestNotificationText(n)})
+			}
+		}
+	}
+
+	// Top posts in the communities that the user has joined.
+	rows, err := db.QueryContext(ctx, `
+		SELECT posts.public_id, posts.title, communities.name, posts.points, posts.no_comments
+		FROM posts
+		INNER JOIN communities ON communities.id = posts.community_id
+		WHERE posts.community_id IN (SELECT community_id FROM community_members WHERE user_id = ?)
+			AND posts.deleted = false AND posts.created_at > ?
+		ORDER BY posts.points DESC LIMIT ?`, user.ID, since, maxDigestPosts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			p        digestPost
+			publicID string
+		)
+		if err := rows.Scan(&publicID, &p.Title, &p.Community, &p.Points, &p.NumComments); err != nil {
+			return nil, err
+		}
+		p.URL = fmt.Sprintf("%s/%s%s/post/%s", opts.PublicURL, opts.CommunityPrefix, p.Community, publicID)
+		d.Posts = append(d.Posts, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Open reports in the communities that the user moderates.
+	rows, err = db.QueryContext(ctx, `
+		SELECT communities.name, COUNT(*)
+		FROM reports
+		INNER JOIN communities ON communities.id = reports.community_id
+		WHERE reports.community_id IN (SELECT community_id FROM community_mods WHERE user_id = ?)
+			AND reports.dealt_at IS NULL AND `+modReportsCond+`
+		GROUP BY communities.name
+		ORDER BY communities.name`, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var q digestModQueue
+		if err := rows.Scan(&q.Community, &q.NumReports); err != nil {
+			return nil, err
+		}
+		q.URL = fmt.Sprintf("%s/%s%s/modtools/reports", opts.PublicURL, opts.CommunityPrefix, q.Community)
+		d.ModQueue = append(d.ModQueue, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// digestNotificationText returns a one-line description of a notification.
+func digestNotificationText(n *Notification) string {
+	switch v := n.Notif.(type) {
+	case *NotificationNewComment:
+		if v.NumComments > 1 {
+			return fmt.Sprintf("%d new comments on your post", v.NumComments)
+		}
+		return fmt.Sprintf("@%s commented on your post", v.CommentAuthor)
+	case *NotificationCommentReply:
+		if v.NumComments > 1 {
+			return fmt.Sprintf("%d new replies to your comment", v.NumComments)
+		}
+		return fmt.Sprintf("@%s replied to your comment", v.CommentAuthor)
+	case *NotificationThreadComment:
+		if v.NumComments > 1 {
+			return fmt.Sprintf("%d new comments in a thread you follow", v.NumComments)
+		}
+		return fmt.Sprintf("@%s commented in a thread you follow", v.CommentAuthor)
+	case *NotificationNewPost:
+		return fmt.Sprintf("@%s submitted a new post", v.Author)
+	case *NotificationNewVotes:
+		return fmt.Sprintf("Your %s received %d new upvotes", v.TargetType, v.NoVotes)
+	case *NotificationPostDeleted:
+		if v.Reason != "" {
+			return fmt.Sprintf("Your %s was removed by the %s: %s", v.TargetType, v.DeletedAs, v.Reason)
+		}
+		return fmt.Sprintf("Your %s was removed by the %s", v.TargetType, v.DeletedAs)
+	case *NotificationModAdd:
+		return fmt.Sprintf("You were made a moderator of %s by @%s", v.CommunityName, v.AddedBy)
+	case *NotificationCommunityInvite:
+		return fmt.Sprintf("You were invited to join %s by @%s", v.CommunityName, v.InvitedBy)
+	case *NotificationAutomodMessage:
+		return fmt.Sprintf("Message from the mods of %s: %s", v.CommunityName, v.Message)
+	case *NotificationBanAppeal:
+		text := "Your appeal of your site ban was " + string(v.Status)
+		if v.Status == BanAppealStatusShortened {
+			text = "Your ban from " + v.CommunityName + " was shortened"
+		} else if v.CommunityName != "" {
+			text = fmt.Sprintf("Your appeal of your ban from %s was %s", v.CommunityName, v.Status)
+		}
+		if v.Message != "" {
+			text += ": " + v.Message
+		}
+		return text
+	case *NotificationBanExpired:
+		if v.CommunityName != "" {
+			return fmt.Sprintf("Your ban from %s has expired", v.CommunityName)
+		}
+		return "Your site ban has expired"
+	case *NotificationCommunityBan:
+		if v.Expires != nil {
+			return fmt.Sprintf("You were banned from %s until %s", v.CommunityName, v.Expires.Format("January 2, 2006"))
+		}
+		return fmt.Sprintf("You were banned from %s", v.CommunityName)
+	case *NotificationModmail:
+		if v.ToMods {
+			return fmt.Sprintf("New modmail in %s: %s", v.CommunityName, v.Subject)
+		}
+		return fmt.Sprintf("New message from the mods of %s: %s", v.CommunityName, v.Subject)
+	case *NotificationModTransfer:
+		return fmt.Sprintf("@%s offered to make you the top mod of %s", v.OfferedBy, v.CommunityName)
+	case *NotificationTakeover:
+		text := fmt.Sprintf("Your request to take over %s was %s", v.CommunityName, v.Status)
+		if v.Message != "" {
+			text += ": " + v.Message
+		}
+		return text
+	case *NotificationCommunityRename:
+		return fmt.Sprintf("%s is now called %s", v.OldName, v.CommunityName)
+	case *NotificationRenameRejected:
+		text := fmt.Sprintf("Your request to rename %s to %s was rejected", v.CommunityName, v.NewName)
+		if v.Message != "" {
+			text += ": " + v.Message
+		}
+		return text
+	case *NotificationNewBadge:
+		return fmt.Sprintf("You received the %s badge", v.BadgeType)
+	case *NotificationMention:
+		return fmt.Sprintf("@%s mentioned you in a %s", v.MentionedBy, v.TargetType)
+	case *NotificationNewMessage:
+		if v.NumMessages > 1 {
+			return fmt.Sprintf("%d new messages from @%s", v.NumMessages, v.Sender)
+		}
+		return fmt.Sprintf("New message from @%s", v.Sender)
+	}
+	return "New notification"
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+func TestDigestTemplates(t *testing.T) {
+	d := &emailDigest{
+		SiteName:        "Discuit",
+		CommunityPrefix: "+",
+		Username:        "alice",
+		Period:          "daily",
+		NumUnread:       12,
+		Notifications:   []digestNotification{{Text: "@bob replied to your comment"}},
+		Posts:           []digestPost{{Title: "<script>", Community: "general", URL: "https://example.com/+general/post/abc"}},
+		ModQueue:        []digestModQueue{{Community: "general", NumReports: 3}},
+		UnsubscribeURL:  "https://example.com/api/_unsubscribe?token=x&user=y",
+	}
+
+	var html, text bytes.Buffer
+	if err := digestHTMLTemplate.Execute(&html, d); err != nil {
+		t.Fatal(err)
+	}
+	if err := digestTextTemplate.Execute(&text, d); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html.String(), "<script>") {
+		t.Error("html digest is not escaped")
+	}
+	for _, s := range []string{"@bob replied to your comment", "and 11 more", "+general: 3 open reports", d.UnsubscribeURL} {
+		if !strings.Contains(text.String(), s) {
+			t.Errorf("text digest does not contain %q", s)
+		}
+	}
+}
+
+func TestDigestUnsubscribeToken(t *testing.T) {
+	user, other := uid.New(), uid.New()
+	token := DigestUnsubscribeToken(user, "secret")
+	if token == DigestUnsubscribeToken(other, "secret") {
+		t.Error("tokens of different users are equal")
+	}
+	if token == DigestUnsubscribeToken(user, "another secret") {
+		t.Error("tokens signed with different secrets are equal")
+	}
+}
+
+func TestDigestNotificationText(t *testing.T) {
+	cases := []struct {
+		n    notification
+		want string
+	}{
+		{&NotificationNewComment{NumComments: 1, CommentAuthor: "bob"}, "@bob commented on your post"},
+		{&NotificationNewComment{NumComments: 4}, "4 new comments on your post"},
+		{&NotificationMention{TargetType: "comment", MentionedBy: "bob"}, "@bob mentioned you in a comment"},
+		{&NotificationNewMessage{NumMessages: 1, Sender: "bob"}, "New message from @bob"},
+	}
+	for _, item := range cases {
+		if got := digestNotificationText(&Notification{Notif: item.n}); got != item.want {
+			t.Errorf("expected %q, got %q", item.want, got)
+		}
+	}
+}
+
+func TestDigestRecipientDue(t *testing.T) {
+	now := time.Now()
+	email := msql.NullString{NullString: sql.NullString{String: "alice@example.com", Valid: true}}
+	sentAt := func(ago time.Duration) msql.NullTime {
+		return msql.NullTime{NullTime: sql.NullTime{Time: now.Add(-ago), Valid: true}}
+	}
+	cases := []struct {
+		name string
+		r    digestRecipient
+		want bool
+	}{
+		{"never sent", digestRecipient{Email: email, Digest: EmailDigestDaily}, true},
+		{"daily, due", digestRecipient{Email: email, Digest: EmailDigestDaily, SentAt: sentAt(time.Hour * 24)}, true},
+		{"daily, within slack", digestRecipient{Email: email, Digest: EmailDigestDaily, SentAt: sentAt(time.Hour*23 + time.Minute*45)}, true},
+		{"daily, not due", digestRecipient{Email: email, Digest: EmailDigestDaily, SentAt: sentAt(time.Hour * 12)}, false},
+		{"weekly, due", digestRecipient{Email: email, Digest: EmailDigestWeekly, SentAt: sentAt(time.Hour * 24 * 7)}, true},
+		{"weekly, not due", digestRecipient{Email: email, Digest: EmailDigestWeekly, SentAt: sentAt(time.Hour * 24 * 2)}, false},
+		{"off", digestRecipient{Email: email, Digest: EmailDigestOff}, false},
+		{"no email", digestRecipient{Digest: EmailDigestDaily}, false},
+	}
+	for _, c := range cases {
+		if got := c.r.due(now); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
@@ -22,6 +22,10 @@ var (
 	errImageNotFound = httperr.NewNotFound("image-not-found", "Image not found.")
 
 	errCommunityNotFound = httperr.NewNotFound("community/not-found", "Community not found.")
+	errCommunityArchived = httperr.NewForbidden("community/archived", "Community is archived.")
+
+	errInvalidCommunityVisibility = httperr.NewBadRequest("invalid-community-visibility", "Invalid community visibility.")
+	errNotCommunityMember         = httperr.NewForbidden("not-community-member", "Only approved members can post and comment in this community.")
 
 	errUserNotFound            = httperr.NewNotFound("user_not_found", "User not found.")
 	errUserBannedFromCommunity = httperr.NewForbidden("banned-from-community", "User is banned from the community.")
@@ -34,4 +38,12 @@ var (
 	errPostTypeUnsupported = httperr.NewBadRequest("post-type/unsupported", "Unsupported post type.")
 
 	errInvalidUserGroup = httperr.NewBadRequest("user/invalid-group", "Invalid user-group.")
+
+	errConversationNotFound = httperr.NewNotFound("conversation-not-found", "Conversation not found.")
+	errMessageNotFound      = httperr.NewNotFound("message-not-found", "Message not found.")
+	errMessagingBlocked     = httperr.NewForbidden("messaging-blocked", "Cannot message this user.")
+
+	errModmailThreadNotFound = httperr.NewNotFound("modmail-thread-not-found", "Modmail thread not found.")
+
+	errInvalidCursor = httperr.NewBadRequest("invalid-cursor", "Invalid pagination cursor.")
 )
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// An EventPublisher delivers real-time events to connected clients (possibly
+// through other server instances).
+type EventPublisher interface {
+	Publish(topic string, data []byte) error
+}
+
+var (
+	eventsMutex    sync.RWMutex // guards the following
+	eventPublisher EventPublisher
+)
+
+// EnableEvents enables publishing real-time events to p.
+func EnableEvents(p EventPublisher) {
+	eventsMutex.Lock()
+	defer eventsMutex.Unlock()
+	eventPublisher = p
+}
+
+type EventType string
+
+const (
+	EventTypeNotification       = EventType("notification")
+	EventTypeNotificationsCount = EventType("notifications_count")
+	EventTypeNewComment         = EventType("new_comment")
+	EventTypePostVotes          = EventType("post_votes")
+	EventTypeCommentVotes       = EventType("comment_votes")
+)
+
+// Event is a real-time event. The JSON encoding of an Event is what's sent to
+// clients.
+type Event struct {
+	Type EventType `json:"type"`
+	Data any       `json:"data"`
+}
+
+// UserEventsTopic returns the topic of the events that are sent only to user
+// (like new notifications).
+func UserEventsTopic(user uid.ID) string {
+	return "user:" + user.String()
+}
+
+// PostEventsTopic returns the topic of the events of a post (like new comments
+// and votes), which are sent to everyone viewing the post.
+func PostEventsTopic(post uid.ID) string {
+	return "post:" + post.String()
+}
+
+// publishEvent publishes an event if events are enabled. Errors are logged,
+// not returned.
+func publishEvent(topic string, t EventType, data any) {
+	eventsMutex.RLock()
+	p := eventPublisher
+	eventsMutex.RUnlock()
+
+	if p == nil {
+		return
+	}
+
+	b, err := json.Marshal(Event{Type: t, Data: data})
+	if err != nil {
+		log.Printf("Error marshaling %s event: %v\n", t, err)
+		return
+	}
+	if err := p.Publish(topic, b); err != nil {
+		log.Printf("Error publishing %s event: %v\n", t, err)
+	}
+}
+
+// publishNotificationsCount publishes the number of new notifications of user.
+func publishNotificationsCount(ctx context.Context, db *sql.DB, user uid.ID) {
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT notifications_new_count FROM users WHERE id = ?", user).Scan(&count); err != nil {
+		log.Printf("Error getting new notifications count: %v\n", err)
+		return
+	}
+	publishEvent(UserEventsTopic(user), EventTypeNotificationsCount, struct {
+		Count int `json:"count"`
+	}{count})
+}
+
+type votesEventData struct {
+	PostID    uid.ID  `json:"postId"`
+	CommentID *uid.ID `json:"commentId,omitempty"`
+	Upvotes   int     `json:"upvotes"`
+	Downvotes int     `json:"downvotes"`
+}
+
+func (p *Post) publishVotesEvent() {
+	publishEvent(PostEventsTopic(p.ID), EventTypePostVotes, votesEventData{
+		PostID:    p.ID,
+		Upvotes:   p.Upvotes,
+		Downvotes: p.Downvotes,
+	})
+}
+
+func (c *Comment) publishVotesEvent() {
+	publishEvent(PostEventsTopic(c.PostID), EventTypeCommentVotes, votesEventData{
+		PostID:    c.PostID,
+		CommentID: &c.ID,
+		Upvotes:   c.Upvotes,
+		Downvotes: c.Downvotes,
+	})
+}
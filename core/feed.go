@@ -87,12 +87,13 @@ func (s *FeedSort) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// FeedType distinguishes between the two main content feeds.
+// FeedType distinguishes between the main content feeds.
 type FeedType int
 
 const (
 	FeedTypeAll = FeedType(iota)
 	FeedTypeSubscriptions
+	FeedTypeFollowing
 )
 
 func (ft FeedType) Valid() bool {
@@ -107,6 +108,8 @@ func (ft FeedType) MarshalText() ([]byte, error) {
 		return []byte("all"), nil
 	case FeedTypeSubscriptions:
 		return []byte("subscriptions"), nil
+	case FeedTypeFollowing:
+		return []byte("following"), nil
 	}
 	return nil, fmt.Errorf("cannot marshal unsupported FeedType (%v)", int(ft))
 }
@@ -122,6 +125,8 @@ func (ft *FeedType) UnmarshalText(text []byte) error {
 		*ft = FeedTypeAll
 	case "subscriptions":
 		*ft = FeedTypeSubscriptions
+	case "following":
+		*ft = FeedTypeFollowing
 	default:
 		return fmt.Errorf("cannot unmarshal text unsupported text: %v", string(text))
 	}
@@ -177,14 +182,17 @@ func NextPointsIDCursor(text string) (p int, id *uid.ID, err error) {
 	return
 }
 
-const whereSelectUserComms = "community_id IN (SELECT community_members.community_id FROM community_members WHERE community_members.user_id = ?) "
+const whereSelectUserComms = "community_id IN (SELECT community_members.community_id FROM community_members INNER JOIN communities ON communities.id = community_members.community_id WHERE community_members.user_id = ? AND communities.deleted_at IS NULL) "
 
 type FeedOptions struct {
 	Sort        FeedSort
 	DefaultSort bool
 	Viewer      *uid.ID
-	Community   *uid.ID // Community should be nil if Homefeed is true.
+	Community   *uid.ID // Community should be nil if Homefeed, Following, or CustomFeed is set.
 	Homefeed    bool
+	Following   bool // Posts of the users that Viewer follows.
+	CustomFeed  int  // If non-zero, the ID of the custom feed whose communities' posts to get.
+	HideRead    bool // Exclude the posts that Viewer has read.
 	Limit       int
 	Next        string // The pagination cursor, taken from previous API response.
 }
@@ -229,26 +237,59 @@ func GetFeed(ctx context.Context, db *sql.DB, opts *FeedOptions) (_ *FeedResultS
 	if !opts.Sort.Valid() {
 		return nil, ErrInvalidFeedSort
 	}
-	var set *FeedResultSet
-	if opts.Sort == FeedSortLatest {
-		set, err = getPostsLatest(ctx, db, opts)
-	} else if opts.Sort == FeedSortHot {
-		set, err = getPostsHot(ctx, db, opts)
-	} else if opts.Sort == FeedSortActivity {
-		set, err = getPostsActivity(ctx, db, opts)
-	} else {
-		set, err = getPostsTop(ctx, db, opts)
+	if opts.Community != nil {
+		if ok, err := CommunityViewableBy(ctx, db, *opts.Community, opts.Viewer); err != nil {
+			return nil, err
+		} else if !ok {
+			return &FeedResultSet{Posts: []*Post{}}, nil
+		}
 	}
+	set, err := getFeedPage(ctx, db, opts)
 	if err != nil {
 		return nil, err
 	}
-	if opts.DefaultSort {
+	set.Posts = removeHiddenPosts(set.Posts)
+
+	// The posts hidden by the viewer's content filters are removed only after
+	// they're fetched, so keep fetching until the page is full (or the feed
+	// ends).
+	for i := 0; i < maxFeedRefills && len(set.Posts) < opts.Limit && set.Next != nil; i++ {
+		more := *opts
+		more.Limit = opts.Limit - len(set.Posts)
+		more.Next = fmt.Sprint(set.Next)
+		mset, err := getFeedPage(ctx, db, &more)
+		if err != nil {
+			return nil, err
+		}
+		set.Posts = append(set.Posts, removeHiddenPosts(mset.Posts)...)
+		set.Next = mset.Next
+	}
+
+	if opts.DefaultSort && !opts.Following && opts.CustomFeed == 0 {
 		// Merge pinned posts.
 		return mergePinnedPosts(ctx, db, opts.Viewer, opts.Community, opts.Next, set)
 	}
 	return set, err
 }
 
+// maxFeedRefills is the maximum number of times GetFeed fetches more posts to
+// fill a page from which posts were removed by content filters.
+const maxFeedRefills = 5
+
+// getFeedPage returns a page of the feed of opts, without removing the posts
+// hidden by content filters.
+func getFeedPage(ctx context.Context, db *sql.DB, opts *FeedOptions) (*FeedResultSet, error) {
+	switch opts.Sort {
+	case FeedSortLatest:
+		return getPostsLatest(ctx, db, opts)
+	case FeedSortHot:
+		return getPostsHot(ctx, db, opts)
+	case FeedSortActivity:
+		return getPostsActivity(ctx, db, opts)
+	}
+	return getPostsTop(ctx, db, opts)
+}
+
 // getPostsLatest returns site wide latest posts, if opts.Community is nil, or
 // latest posts in opts.Community, if not.
 func getPostsLatest(ctx context.Context, db *sql.DB, opts *FeedOptions) (*FeedResultSet, error) {
@@ -262,15 +303,28 @@ func getPostsLatest(ctx context.Context, db *sql.DB, opts *FeedOptions) (*FeedRe
 	if opts.Homefeed {
 		where += "AND " + whereSelectUserComms
 		args = append(args, *opts.Viewer)
+	} else if opts.Following {
+		where += "AND " + whereSelectFollowedUsers("posts")
+		args = append(args, *opts.Viewer)
+	} else if opts.CustomFeed != 0 {
+		where += "AND " + whereSelectCustomFeedComms
+		args = append(args, opts.CustomFeed)
 	} else {
 		if opts.Community != nil {
 			where += "AND community_id = ? "
 			args = append(args, *opts.Community)
 		}
 	}
+	if !opts.Homefeed {
+		where, args = whereViewable(where, args, opts.Viewer)
+	}
 	if loggedIn {
 		where, args = whereMuted(where, "posts", args, *opts.Viewer, opts.Community == nil && !opts.Homefeed)
 	}
+	where, args = whereNotShadowbanned(where, "posts", args, opts.Viewer)
+	if opts.HideRead {
+		where, args = whereNotRead(where, "posts.id", args, *opts.Viewer)
+	}
 	if opts.Next != "" {
 		next, err := opts.nextID()
 		if err != nil {
@@ -373,6 +427,22 @@ func whereMuted(where, postsTable string, args []any, viewer uid.ID, muteCommuni
 	return where, args
 }
 
+// whereNotShadowbanned returns where (along with args) with a condition
+// appended to it that excludes the posts of shadowbanned users, except those of
+// viewer (nil if logged out).
+func whereNotShadowbanned(where, postsTable string, args []any, viewer *uid.ID) (string, []any) {
+	if !(where == "" || strings.TrimSpace(strings.ToUpper(where)) == "WHERE") {
+		where += "AND "
+	}
+	where += postsTable + ".user_id NOT IN (SELECT id FROM users WHERE shadowbanned_at IS NOT NULL"
+	if viewer != nil {
+		where += " AND id <> ?"
+		args = append(args, *viewer)
+	}
+	where += ") "
+	return where, args
+}
+
 // getPostsHot returns site wide hot posts, if opts.Community is nil, or hot
 // posts in opts.Community, if not.
 func getPostsHot(ctx context.Context, db *sql.DB, opts *FeedOptions) (*FeedResultSet, error) {
@@ -386,15 +456,28 @@ func getPostsHot(ctx context.Context, db *sql.DB, opts *FeedOptions) (*FeedResul
 	if opts.Homefeed {
 		where += "AND " + whereSelectUserComms
 		args = append(args, *opts.Viewer)
+	} else if opts.Following {
+		where += "AND " + whereSelectFollowedUsers("posts")
+		args = append(args, *opts.Viewer)
+	} else if opts.CustomFeed != 0 {
+		where += "AND " + whereSelectCustomFeedComms
+		args = append(args, opts.CustomFeed)
 	} else {
 		if opts.Community != nil {
 			where += "AND community_id = ? "
 			args = append(args, *opts.Community)
 		}
 	}
+	if !opts.Homefeed {
+		where, args = whereViewable(where, args, opts.Viewer)
+	}
 	if loggedIn {
 		where, args = whereMuted(where, "posts", args, *opts.Viewer, opts.Community == nil && !opts.Homefeed)
 	}
+	where, args = whereNotShadowbanned(where, "posts", args, opts.Viewer)
+	if opts.HideRead {
+		where, args = whereNotRead(where, "posts.id", args, *opts.Viewer)
+	}
 	if opts.Next != "" {
 		nextHotness, nextID, err := opts.nextPointsID()
 		if err != nil {
@@ -438,15 +521,28 @@ func getPostsTopAll(ctx context.Context, db *sql.DB, opts *FeedOptions) (*FeedRe
 	if opts.Homefeed {
 		where += "AND " + whereSelectUserComms
 		args = append(args, *opts.Viewer)
+	} else if opts.Following {
+		where += "AND " + whereSelectFollowedUsers("posts")
+		args = append(args, *opts.Viewer)
+	} else if opts.CustomFeed != 0 {
+		where += "AND " + whereSelectCustomFeedComms
+		args = append(args, opts.CustomFeed)
 	} else {
 		if opts.Community != nil {
 			where += "AND community_id = ? "
 			args = append(args, *opts.Community)
 		}
 	}
+	if !opts.Homefeed {
+		where, args = whereViewable(where, args, opts.Viewer)
+	}
 	if loggedIn {
 		where, args = whereMuted(where, "posts", args, *opts.Viewer, opts.Community == nil && !opts.Homefeed)
 	}
+	where, args = whereNotShadowbanned(where, "posts", args, opts.Viewer)
+	if opts.HideRead {
+		where, args = whereNotRead(where, "posts.id", args, *opts.Viewer)
+	}
 	if opts.Next != "" {
 		nextPoints, nextID, err := opts.nextPointsID()
 		if err != nil {
@@ -490,15 +586,28 @@ func getPostsTop(ctx context.Context, db *sql.DB, opts *FeedOptions) (*FeedResul
 	if opts.Homefeed {
 		where += whereSelectUserComms
 		args = append(args, *opts.Viewer)
+	} else if opts.Following {
+		where += whereSelectFollowedUsers(table)
+		args = append(args, *opts.Viewer)
+	} else if opts.CustomFeed != 0 {
+		where += whereSelectCustomFeedComms
+		args = append(args, opts.CustomFeed)
 	} else {
 		if opts.Community != nil {
 			where += "community_id = ? "
 			args = append(args, *opts.Community)
 		}
 	}
+	if !opts.Homefeed {
+		where, args = whereViewable(where, args, opts.Viewer)
+	}
 	if opts.Viewer != nil {
 		where, args = whereMuted(where, table, args, *opts.Viewer, opts.Community == nil && !opts.Homefeed)
 	}
+	where, args = whereNotShadowbanned(where, table, args, opts.Viewer)
+	if opts.HideRead {
+		where, args = whereNotRead(where, table+".post_id", args, *opts.Viewer)
+	}
 	if opts.Next != "" {
 		nextPoints, nextID, err := opts.nextPointsID()
 		if err != nil {
@@ -551,15 +660,28 @@ func getPostsActivity(ctx context.Context, db *sql.DB, opts *FeedOptions) (*Feed
 	if opts.Homefeed {
 		where += "AND " + whereSelectUserComms
 		args = append(args, *opts.Viewer)
+	} else if opts.Following {
+		where += "AND " + whereSelectFollowedUsers("posts")
+		args = append(args, *opts.Viewer)
+	} else if opts.CustomFeed != 0 {
+		where += "AND " + whereSelectCustomFeedComms
+		args = append(args, opts.CustomFeed)
 	} else {
 		if opts.Community != nil {
 			where += "AND community_id = ? "
 			args = append(args, *opts.Community)
 		}
 	}
+	if !opts.Homefeed {
+		where, args = whereViewable(where, args, opts.Viewer)
+	}
 	if loggedIn {
 		where, args = whereMuted(where, "posts", args, *opts.Viewer, opts.Community == nil && !opts.Homefeed)
 	}
+	where, args = whereNotShadowbanned(where, "posts", args, opts.Viewer)
+	if opts.HideRead {
+		where, args = whereNotRead(where, "posts.id", args, *opts.Viewer)
+	}
 	if opts.Next != "" {
 		next, err := opts.nextInt64()
 		if err != nil {
@@ -687,12 +809,25 @@ func GetUserFeed(ctx context.Context, db *sql.DB, viewer *uid.ID, userID uid.ID,
 		args = append(args, t)
 	}
 
+	viewerAdmin, err := IsAdmin(db, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	// The posts and comments of shadowbanned users are visible only to
+	// themselves and to the admins.
+	if !viewerAdmin && (viewer == nil || *viewer != userID) {
+		if shadowbanned, err := UserShadowbanned(ctx, db, userID); err != nil {
+			return nil, err
+		} else if shadowbanned {
+			return &UserFeedResultSet{Items: []UserFeedItem{}}, nil
+		}
+	}
+
 	// Show posts and comments deleted by someone other than their author to
 	// admins. If the viewer is not an admin, hide them entirely (even if the
 	// comment content is purged).
-	if is, err := IsAdmin(db, viewer); err != nil {
-		return nil, err
-	} else if !is {
+	if !viewerAdmin {
 		query += "AND deleted = false "
 	}
 
@@ -772,6 +907,39 @@ func GetUserFeed(ctx context.Context, db *sql.DB, viewer *uid.ID, userID uid.ID,
 		}
 	}
 
+	var communities []uid.ID
+	for _, item := range set.Items {
+		switch v := item.Item.(type) {
+		case *Post:
+			communities = append(communities, v.CommunityID)
+		case *Comment:
+			communities = append(communities, v.CommunityID)
+		}
+	}
+	hiddenCommunities, err := HiddenCommunities(ctx, db, viewer, communities)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the items hidden by the viewer's content filters, and those of
+	// the private communities that the viewer is not a member of. Since there
+	// are no comment trees here, comments are removed too.
+	items := set.Items[:0]
+	for _, item := range set.Items {
+		switch v := item.Item.(type) {
+		case *Post:
+			if v.ViewerFiltered == ContentFilterHide || hiddenCommunities[v.CommunityID] {
+				continue
+			}
+		case *Comment:
+			if v.ViewerFiltered == ContentFilterHide || hiddenCommunities[v.CommunityID] {
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+	set.Items = items
+
 	if len(ids) == limit+1 {
 		set.Next = &ids[limit]
 	}
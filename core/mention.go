@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// maxMentionsPerItem is the maximum number of users that are notified of a
+// mention in a single post or comment. Mentions beyond this limit are ignored.
+const maxMentionsPerItem = 10
+
+// mentionRegexp matches @username. The @ must either be at the start of the
+// text or follow a character that cannot be part of a username, an email
+// address, or a URL path (so that neither user@example.com nor
+// https://example.com/@user are considered mentions).
+var mentionRegexp = regexp.MustCompile(`(?:^|[^0-9A-Za-z_@/.])@([0-9A-Za-z_]+)`)
+
+// extractMentions returns the lowercased usernames that are mentioned in text,
+// in the order in which they first appear, without duplicates, and at most
+// maxMentionsPerItem of them.
+func extractMentions(text string) []string {
+	var mentions []string
+	for _, match := range mentionRegexp.FindAllStringSubmatch(text, -1) {
+		username := strings.ToLower(match[1])
+		if IsUsernameValid(username) != nil {
+			continue
+		}
+		if slices.Contains(mentions, username) {
+			continue
+		}
+		mentions = append(mentions, username)
+		if len(mentions) == maxMentionsPerItem {
+			break
+		}
+	}
+	return mentions
+}
+
+// newMentions returns the mentions in newText that are not in oldText.
+func newMentions(oldText, newText string) []string {
+	old := extractMentions(oldText)
+	var mentions []string
+	for _, username := range extractMentions(newText) {
+		if !slices.Contains(old, username) {
+			mentions = append(mentions, username)
+		}
+	}
+	return mentions
+}
+
+// sendMentionNotifications notifies each user in usernames that they were
+// mentioned by author in post (or in comment, if comment is not nil). The
+// author and the users in skip are never notified, and no one is notified if
+// author is shadowbanned.
+func sendMentionNotifications(ctx context.Context, db *sql.DB, usernames []string, post *Post, comment *uid.ID, author uid.ID, skip ...uid.ID) {
+	if len(usernames) == 0 {
+		return
+	}
+
+	authorUser, err := GetUser(ctx, db, author, nil)
+	if err != nil {
+		log.Printf("Error getting mention author: %v\n", err)
+		return
+	}
+	if authorUser.Shadowbanned {
+		return
+	}
+
+	for _, username := range usernames {
+		exists, user, err := usernameExists(ctx, db, username)
+		if err != nil {
+			log.Printf("Error looking up mentioned user %s: %v\n", username, err)
+			continue
+		}
+		if !exists || user == author || slices.Contains(skip, user) {
+			continue
+		}
+		if err := CreateMentionNotification(ctx, db, user, post, comment, authorUser); err != nil {
+			log.Printf("Create mention notification failed: %v\n", err)
+		}
+	}
+}
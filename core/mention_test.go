@@ -0,0 +1,43 @@
+package core
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExtractMentions(t *testing.T) {
+	cases := []struct {
+		s    string
+		want []string
+	}{
+		{"", nil},
+		{"@alice", []string{"alice"}},
+		{"hey @Alice and @bob, also @alice", []string{"alice", "bob"}},
+		{"mail me at me@example.com", nil},
+		{"https://example.com/@alice", nil},
+		{"@ab is too short", nil},
+		{"@" + strings.Repeat("a", 22) + " is too long", nil},
+		{"(@alice)", []string{"alice"}},
+	}
+	for _, item := range cases {
+		if got := extractMentions(item.s); !slices.Equal(got, item.want) {
+			t.Errorf("%q: expected %v, got %v", item.s, item.want, got)
+		}
+	}
+
+	var many []string
+	for i := 0; i < maxMentionsPerItem+5; i++ {
+		many = append(many, "@user"+strings.Repeat("x", i))
+	}
+	if got := extractMentions(strings.Join(many, " ")); len(got) != maxMentionsPerItem {
+		t.Errorf("expected %d mentions, got %d", maxMentionsPerItem, len(got))
+	}
+}
+
+func TestNewMentions(t *testing.T) {
+	got := newMentions("hi @alice", "hi @alice and @bob")
+	if want := []string{"bob"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
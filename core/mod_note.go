@@ -0,0 +1,288 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+const maxModNoteLength = 2000
+
+// ModNoteCategory is the category of a mod note.
+type ModNoteCategory string
+
+const (
+	ModNoteCategoryNote    = ModNoteCategory("note")
+	ModNoteCategoryWarning = ModNoteCategory("warning")
+	ModNoteCategorySpam    = ModNoteCategory("spam")
+	ModNoteCategoryBan     = ModNoteCategory("ban")
+)
+
+// Valid reports whether c is a valid ModNoteCategory.
+func (c ModNoteCategory) Valid() bool {
+	switch c {
+	case ModNoteCategoryNote, ModNoteCategoryWarning, ModNoteCategorySpam, ModNoteCategoryBan:
+		return true
+	}
+	return false
+}
+
+// ModNote is a note on a user, visible only to the mods of a community (and to
+// the admins).
+type ModNote struct {
+	db *sql.DB
+
+	ID                uint            `json:"id"`
+	CommunityID       uid.ID          `json:"communityId"`
+	UserID            uid.ID          `json:"userId"` // The user the note is about.
+	Category          ModNoteCategory `json:"category"`
+	Body              string          `json:"body"`
+	CreatedBy         uid.ID          `json:"createdBy"`
+	CreatedByUsername string          `json:"createdByUsername"`
+	CreatedAt         time.Time       `json:"createdAt"`
+}
+
+func getModNotes(ctx context.Context, db *sql.DB, where string, args ...any) ([]*ModNote, error) {
+	cols := []string{
+		"community_mod_notes.id",
+		"community_mod_notes.community_id",
+		"community_mod_notes.user_id",
+		"community_mod_notes.category",
+		"community_mod_notes.body",
+		"community_mod_notes.created_by",
+		"users.username",
+		"community_mod_notes.created_at",
+	}
+	joins := []string{"INNER JOIN users ON users.id = community_mod_notes.created_by"}
+	rows, err := db.QueryContext(ctx, msql.BuildSelectQuery("community_mod_notes", cols, joins, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []*ModNote{}
+	for rows.Next() {
+		n := &ModNote{db: db}
+		err := rows.Scan(
+			&n.ID,
+			&n.CommunityID,
+			&n.UserID,
+			&n.Category,
+			&n.Body,
+			&n.CreatedBy,
+			&n.CreatedByUsername,
+			&n.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// GetModNote returns the mod note with the given id.
+func GetModNote(ctx context.Context, db *sql.DB, id uint) (*ModNote, error) {
+	notes, err := getModNotes(ctx, db, "WHERE community_mod_notes.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) == 0 {
+		return nil, httperr.NewNotFound("mod-note-not-found", "Mod note not found.")
+	}
+	return notes[0], nil
+}
+
+// GetModNotes returns the notes of the mods of community on user, the latest
+// ones first.
+func GetModNotes(ctx context.Context, db *sql.DB, community, user uid.ID) ([]*ModNote, error) {
+	return getModNotes(ctx, db, "WHERE community_mod_notes.community_id = ? AND community_mod_notes.user_id = ? ORDER BY community_mod_notes.id DESC", community, user)
+}
+
+// AddModNote adds a note on user, on behalf of mod, to c.
+func (c *Community) AddModNote(ctx context.Context, mod, user uid.ID, category ModNoteCategory, body string) (*ModNote, error) {
+	if is, err := c.UserModOrAdmin(ctx, mod); err != nil {
+		return nil, err
+	} else if !is {
+		return nil, errNotMod
+	}
+
+	if category == "" {
+		category = ModNoteCategoryNote
+	}
+	if !category.Valid() {
+		return nil, httperr.NewBadRequest("mod-note-invalid-category", "Invalid mod note category.")
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, httperr.NewBadRequest("mod-note-empty", "Mod note cannot be empty.")
+	}
+	if utf8.RuneCountInString(body) > maxModNoteLength {
+		return nil, httperr.NewBadRequest("mod-note-too-long", fmt.Sprintf("Mod note cannot exceed %d characters.", maxModNoteLength))
+	}
+
+	res, err := c.db.ExecContext(ctx, "INSERT INTO community_mod_notes (community_id, user_id, category, body, created_by) VALUES (?, ?, ?, ?, ?)",
+		c.ID, user, category, body, mod)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return GetModNote(ctx, c.db, uint(id))
+}
+
+// Delete deletes n on behalf of mod.
+func (n *ModNote) Delete(ctx context.Context, mod uid.ID) error {
+	if is, err := UserModOrAdmin(ctx, n.db, n.CommunityID, mod); err != nil {
+		return err
+	} else if !is {
+		return errNotMod
+	}
+	_, err := n.db.ExecContext(ctx, "DELETE FROM community_mod_notes WHERE id = ?", n.ID)
+	return err
+}
+
+// ModUserInfo is what the mods of a community see of a user: their notes on
+// the user, and the user's history in the community.
+type ModUserInfo struct {
+	User  *User      `json:"user"`
+	Notes []*ModNote `json:"notes"`
+
+	NumPosts           int `json:"noPosts"`
+	NumComments        int `json:"noComments"`
+	NumPostsRemoved    int `json:"noPostsRemoved"`
+	NumCommentsRemoved int `json:"noCommentsRemoved"`
+
+	// The number of reports against the user's posts and comments.
+	NumReports int `json:"noReports"`
+
+	// The sum of the points of the user's posts and comments.
+	Points int `json:"points"`
+
+	// The current ban of the user from the community, if any.
+	Ban *BannedUser `json:"ban"`
+
+	// The latest posts and comments of the user.
+	Posts    []*Post    `json:"posts"`
+	Comments []*Comment `json:"comments"`
+
+	// The latest mod actions taken against the user (removals, bans, and so
+	// on).
+	ModActions []*ModLogEntry `json:"modActions"`
+}
+
+// modUserInfoItemsLimit is the number of posts, comments, and mod actions in
+// a ModUserInfo.
+const modUserInfoItemsLimit = 10
+
+// GetModUserInfo returns the ModUserInfo of user in c for mod.
+func (c *Community) GetModUserInfo(ctx context.Context, mod uid.ID, user *User) (*ModUserInfo, error) {
+	if is, err := c.UserModOrAdmin(ctx, mod); err != nil {
+		return nil, err
+	} else if !is {
+		return nil, errNotMod
+	}
+
+	info := &ModUserInfo{User: user}
+
+	var err error
+	if info.Notes, err = GetModNotes(ctx, c.db, c.ID, user.ID); err != nil {
+		return nil, err
+	}
+
+	removed := fmt.Sprintf("deleted_as IN (%d, %d)", UserGroupMods, UserGroupAdmins)
+	row := c.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM posts WHERE community_id = ? AND user_id = ?),
+			(SELECT COUNT(*) FROM comments WHERE community_id = ? AND user_id = ?),
+			(SELECT COUNT(*) FROM posts WHERE community_id = ? AND user_id = ? AND deleted = TRUE AND `+removed+`),
+			(SELECT COUNT(*) FROM comments WHERE community_id = ? AND user_id = ? AND deleted_at IS NOT NULL AND `+removed+`),
+			(SELECT COALESCE(SUM(points), 0) FROM posts WHERE community_id = ? AND user_id = ? AND deleted = FALSE)
+				+ (SELECT COALESCE(SUM(points), 0) FROM comments WHERE community_id = ? AND user_id = ? AND deleted_at IS NULL)`,
+		c.ID, user.ID, c.ID, user.ID, c.ID, user.ID, c.ID, user.ID, c.ID, user.ID, c.ID, user.ID)
+	if err := row.Scan(&info.NumPosts, &info.NumComments, &info.NumPostsRemoved, &info.NumCommentsRemoved, &info.Points); err != nil {
+		return nil, err
+	}
+
+	row = c.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM reports
+		LEFT JOIN posts ON reports.report_type = ? AND posts.id = reports.target_id
+		LEFT JOIN comments ON reports.report_type = ? AND comments.id = reports.target_id
+		WHERE reports.community_id = ? AND COALESCE(posts.user_id, comments.user_id) = ?`,
+		ReportTypePost, ReportTypeComment, c.ID, user.ID)
+	if err := row.Scan(&info.NumReports); err != nil {
+		return nil, err
+	}
+
+	ban := &BannedUser{User: user}
+	row = c.db.QueryRowContext(ctx, "SELECT expires, created_at FROM community_banned WHERE community_id = ? AND user_id = ?", c.ID, user.ID)
+	if err := row.Scan(&ban.Expires, &ban.BannedAt); err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == nil {
+		now := time.Now()
+		if !ban.Expires.Valid || ban.Expires.Time.After(now) {
+			if ban.Expires.Valid {
+				secs := int64(ban.Expires.Time.Sub(now).Seconds())
+				ban.ExpiresIn = &secs
+			}
+			info.Ban = ban
+		}
+	}
+
+	latestIDs := func(table string) ([]uid.ID, error) {
+		rows, err := c.db.QueryContext(ctx, fmt.Sprintf("SELECT id FROM %s WHERE community_id = ? AND user_id = ? ORDER BY id DESC LIMIT %d", table, modUserInfoItemsLimit), c.ID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return scanIDs(rows)
+	}
+
+	info.Posts = []*Post{}
+	if ids, err := latestIDs("posts"); err != nil {
+		return nil, err
+	} else if len(ids) > 0 {
+		posts, err := GetPostsByIDs(ctx, c.db, &mod, true, ids...)
+		if err != nil && err != errPostNotFound {
+			return nil, err
+		}
+		if err == nil {
+			info.Posts = posts
+		}
+	}
+	info.Comments = []*Comment{}
+	if ids, err := latestIDs("comments"); err != nil {
+		return nil, err
+	} else if len(ids) > 0 {
+		comments, err := GetCommentsByIDs(ctx, c.db, &mod, ids...)
+		if err != nil {
+			return nil, err
+		}
+		if comments != nil {
+			info.Comments = comments
+		}
+	}
+
+	q := &ModLogQuery{
+		Community:  uid.NullID{ID: c.ID, Valid: true},
+		TargetUser: uid.NullID{ID: user.ID, Valid: true},
+	}
+	set, err := GetModLog(ctx, c.db, q, modUserInfoItemsLimit, nil)
+	if err != nil {
+		return nil, err
+	}
+	info.ModActions = set.Entries
+
+	return info, nil
+}
@@ -0,0 +1,252 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// ReorderMods changes the mod hierarchy of c to order, which must list all the
+// mods of c, the top mod first. Admins can reorder the mods freely, but a mod
+// can only reorder the mods below them in the hierarchy.
+func (c *Community) ReorderMods(ctx context.Context, viewer uid.ID, order []uid.ID) error {
+	if is, err := c.UserModOrAdmin(ctx, viewer); err != nil {
+		return err
+	} else if !is {
+		return errNotMod
+	}
+
+	mods, err := GetCommunityMods(ctx, c.db, c.ID)
+	if err != nil {
+		return err
+	}
+	if len(order) != len(mods) {
+		return httperr.NewBadRequest("invalid-mods-order", "The new order must contain all the mods.")
+	}
+	seen := make(map[uid.ID]bool)
+	for _, id := range order {
+		seen[id] = true
+	}
+	for _, mod := range mods {
+		if !seen[mod.ID] {
+			return httperr.NewBadRequest("invalid-mods-order", "The new order must contain all the mods.")
+		}
+	}
+
+	isAdmin, err := IsAdmin(c.db, &viewer)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		// The viewer, and everyone above the viewer, must keep their
+		// positions.
+		for i, mod := range mods {
+			if order[i] != mod.ID {
+				return httperr.NewForbidden("lower-mod", "You can only reorder the mods below you.")
+			}
+			if mod.ID == viewer {
+				break
+			}
+		}
+	}
+
+	entry, err := communityModLogEntry(ctx, c.db, c.ID, viewer, ModActionReorderMods)
+	if err != nil {
+		return err
+	}
+	return msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		for i, id := range order {
+			if _, err := tx.ExecContext(ctx, "UPDATE community_mods SET position = ? WHERE community_id = ? AND user_id = ?", i, c.ID, id); err != nil {
+				return err
+			}
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
+}
+
+// moveModToTop makes user, who must be a mod of community, the top mod of
+// community, moving every other mod one position down. The positions may need
+// fixing afterwards (with Community.FixModPositions).
+func moveModToTop(ctx context.Context, tx *sql.Tx, community, user uid.ID) error {
+	if _, err := tx.ExecContext(ctx, "UPDATE community_mods SET position = position + 1 WHERE community_id = ?", community); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, "UPDATE community_mods SET position = 0 WHERE community_id = ? AND user_id = ?", community, user)
+	return err
+}
+
+// ModTransfer is an offer, by the top mod of a community (or an admin), to
+// make another mod of the community the top mod. A community can have only
+// one pending transfer at a time.
+type ModTransfer struct {
+	db *sql.DB
+
+	CommunityID   uid.ID    `json:"communityId"`
+	CommunityName string    `json:"communityName"`
+	FromUserID    uid.ID    `json:"fromUserId"`
+	FromUsername  string    `json:"fromUsername"`
+	ToUserID      uid.ID    `json:"toUserId"`
+	ToUsername    string    `json:"toUsername"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// GetModTransfer returns the pending mod transfer of c.
+func (c *Community) GetModTransfer(ctx context.Context) (*ModTransfer, error) {
+	query := msql.BuildSelectQuery("community_mod_transfers", []string{
+		"community_mod_transfers.community_id",
+		"communities.name",
+		"community_mod_transfers.from_user",
+		"from_users.username",
+		"community_mod_transfers.to_user",
+		"to_users.username",
+		"community_mod_transfers.created_at",
+	}, []string{
+		"INNER JOIN communities ON communities.id = community_mod_transfers.community_id",
+		"INNER JOIN users AS from_users ON from_users.id = community_mod_transfers.from_user",
+		"INNER JOIN users AS to_users ON to_users.id = community_mod_transfers.to_user",
+	}, "WHERE community_mod_transfers.community_id = ?")
+
+	t := &ModTransfer{db: c.db}
+	err := c.db.QueryRowContext(ctx, query, c.ID).Scan(
+		&t.CommunityID,
+		&t.CommunityName,
+		&t.FromUserID,
+		&t.FromUsername,
+		&t.ToUserID,
+		&t.ToUsername,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, httperr.NewNotFound("mod-transfer-not-found", "No pending mod transfer.")
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// canTransferOwnership reports whether user is the top mod of c or an admin.
+func (c *Community) canTransferOwnership(ctx context.Context, user uid.ID) (bool, error) {
+	if is, err := IsAdmin(c.db, &user); err != nil || is {
+		return is, err
+	}
+	mods, err := GetCommunityMods(ctx, c.db, c.ID)
+	if err != nil {
+		return false, err
+	}
+	return len(mods) > 0 && mods[0].ID == user, nil
+}
+
+// OfferModTransfer offers, on behalf of viewer, to make the mod "to" the top
+// mod of c. Only the top mod of c and admins can offer a transfer, which has to
+// be accepted by the mod. Any previous pending transfer of c is replaced.
+func (c *Community) OfferModTransfer(ctx context.Context, viewer, to uid.ID) (*ModTransfer, error) {
+	if can, err := c.canTransferOwnership(ctx, viewer); err != nil {
+		return nil, err
+	} else if !can {
+		return nil, httperr.NewForbidden("not-top-mod", "Only the top mod can transfer the community.")
+	}
+
+	mods, err := GetCommunityMods(ctx, c.db, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	isMod := false
+	for _, mod := range mods {
+		if mod.ID == to {
+			isMod = true
+		}
+	}
+	if !isMod {
+		return nil, httperr.NewBadRequest("not-mod", "User is not a moderator of the community.")
+	}
+	if mods[0].ID == to {
+		return nil, httperr.NewBadRequest("already-top-mod", "User is already the top mod.")
+	}
+
+	query := "INSERT INTO community_mod_transfers (community_id, from_user, to_user) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE from_user = ?, to_user = ?, created_at = ?"
+	if _, err := c.db.ExecContext(ctx, query, c.ID, viewer, to, viewer, to, time.Now()); err != nil {
+		return nil, err
+	}
+
+	t, err := c.GetModTransfer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := CreateModTransferNotification(context.Background(), c.db, t); err != nil {
+			log.Printf("Error creating mod transfer notification (community: %v): %v\n", c.Name, err)
+		}
+	}()
+	return t, nil
+}
+
+// Accept makes the receiver of t the top mod of the community. Only the
+// receiver can accept a transfer.
+func (t *ModTransfer) Accept(ctx context.Context, viewer uid.ID) error {
+	if viewer != t.ToUserID {
+		return httperr.NewForbidden("not-transfer-receiver", "The transfer was not offered to you.")
+	}
+
+	comm, err := GetCommunityByID(ctx, t.db, t.CommunityID, nil)
+	if err != nil {
+		return err
+	}
+	if is, err := comm.UserMod(ctx, t.ToUserID); err != nil {
+		return err
+	} else if !is {
+		return httperr.NewBadRequest("not-mod", "User is not a moderator of the community.")
+	}
+	// The top mod might've changed since the transfer was offered.
+	if can, err := comm.canTransferOwnership(ctx, t.FromUserID); err != nil {
+		return err
+	} else if !can {
+		if err := t.delete(ctx); err != nil {
+			return err
+		}
+		return &httperr.Error{HTTPStatus: http.StatusConflict, Code: "mod-transfer-stale", Message: "The transfer is no longer valid."}
+	}
+
+	entry, err := communityModLogEntry(ctx, t.db, t.CommunityID, t.FromUserID, ModActionTransferOwnership)
+	if err != nil {
+		return err
+	}
+	entry.TargetUserID = uid.NullID{ID: t.ToUserID, Valid: true}
+	err = msql.Transact(ctx, t.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM community_mod_transfers WHERE community_id = ?", t.CommunityID); err != nil {
+			return err
+		}
+		if err := moveModToTop(ctx, tx, t.CommunityID, t.ToUserID); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
+	if err != nil {
+		return err
+	}
+	return comm.FixModPositions(ctx)
+}
+
+// Cancel deletes t. The mod who offered the transfer can cancel it, the
+// receiver can decline it, and admins can do either.
+func (t *ModTransfer) Cancel(ctx context.Context, viewer uid.ID) error {
+	if viewer != t.FromUserID && viewer != t.ToUserID {
+		if is, err := IsAdmin(t.db, &viewer); err != nil {
+			return err
+		} else if !is {
+			return errNotAdmin
+		}
+	}
+	return t.delete(ctx)
+}
+
+func (t *ModTransfer) delete(ctx context.Context) error {
+	_, err := t.db.ExecContext(ctx, "DELETE FROM community_mod_transfers WHERE community_id = ?", t.CommunityID)
+	return err
+}
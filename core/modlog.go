@@ -0,0 +1,340 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// ModAction is an action, taken by a mod or an admin, that is recorded in the
+// mod log.
+type ModAction string
+
+const (
+	ModActionRemovePost        = ModAction("remove_post")
+	ModActionRemovePostContent = ModAction("remove_post_content")
+	ModActionRemoveComment     = ModAction("remove_comment")
+	ModActionLockPost          = ModAction("lock_post")
+	ModActionUnlockPost        = ModAction("unlock_post")
+	ModActionPinPost           = ModAction("pin_post")
+	ModActionUnpinPost         = ModAction("unpin_post")
+	ModActionBanUser           = ModAction("ban_user")
+	ModActionUnbanUser         = ModAction("unban_user")
+	ModActionAddMod            = ModAction("add_mod")
+	ModActionRemoveMod         = ModAction("remove_mod")
+	ModActionAddRule           = ModAction("add_rule")
+	ModActionEditRule          = ModAction("edit_rule")
+	ModActionDeleteRule        = ModAction("delete_rule")
+	ModActionDismissReport     = ModAction("dismiss_report")
+	ModActionUpdateCommunity   = ModAction("update_community")
+	ModActionUpdateAutomod     = ModAction("update_automod")
+	ModActionSiteBanUser       = ModAction("site_ban_user")
+	ModActionSiteUnbanUser     = ModAction("site_unban_user")
+	ModActionApprovePost       = ModAction("approve_post")
+	ModActionApproveComment    = ModAction("approve_comment")
+	ModActionEscalateReport    = ModAction("escalate_report")
+	ModActionAcceptBanAppeal   = ModAction("accept_ban_appeal")
+	ModActionRejectBanAppeal   = ModAction("reject_ban_appeal")
+	ModActionShortenBan        = ModAction("shorten_ban")
+	ModActionBanExpired        = ModAction("ban_expired")
+	ModActionSiteBanExpired    = ModAction("site_ban_expired")
+	ModActionShadowbanUser     = ModAction("shadowban_user")
+	ModActionUnshadowbanUser   = ModAction("unshadowban_user")
+	ModActionReorderMods       = ModAction("reorder_mods")
+	ModActionTransferOwnership = ModAction("transfer_ownership")
+	ModActionApproveTakeover   = ModAction("approve_takeover")
+	ModActionRejectTakeover    = ModAction("reject_takeover")
+
+	// Site-wide actions on communities, taken by admins.
+	ModActionDeleteCommunity    = ModAction("delete_community")
+	ModActionRestoreCommunity   = ModAction("restore_community")
+	ModActionArchiveCommunity   = ModAction("archive_community")
+	ModActionUnarchiveCommunity = ModAction("unarchive_community")
+	ModActionRenameCommunity    = ModAction("rename_community")
+	ModActionRejectRename       = ModAction("reject_rename")
+)
+
+// modActions are all the mod actions.
+var modActions = []ModAction{
+	ModActionRemovePost,
+	ModActionRemovePostContent,
+	ModActionRemoveComment,
+	ModActionLockPost,
+	ModActionUnlockPost,
+	ModActionPinPost,
+	ModActionUnpinPost,
+	ModActionBanUser,
+	ModActionUnbanUser,
+	ModActionAddMod,
+	ModActionRemoveMod,
+	ModActionAddRule,
+	ModActionEditRule,
+	ModActionDeleteRule,
+	ModActionDismissReport,
+	ModActionUpdateCommunity,
+	ModActionUpdateAutomod,
+	ModActionSiteBanUser,
+	ModActionSiteUnbanUser,
+	ModActionApprovePost,
+	ModActionApproveComment,
+	ModActionEscalateReport,
+	ModActionAcceptBanAppeal,
+	ModActionRejectBanAppeal,
+	ModActionShortenBan,
+	ModActionBanExpired,
+	ModActionSiteBanExpired,
+	ModActionShadowbanUser,
+	ModActionUnshadowbanUser,
+	ModActionReorderMods,
+	ModActionTransferOwnership,
+	ModActionApproveTakeover,
+	ModActionRejectTakeover,
+	ModActionDeleteCommunity,
+	ModActionRestoreCommunity,
+	ModActionArchiveCommunity,
+	ModActionUnarchiveCommunity,
+	ModActionRenameCommunity,
+	ModActionRejectRename,
+}
+
+// Valid reports whether a is a valid ModAction.
+func (a ModAction) Valid() bool {
+	return slices.Contains(modActions, a)
+}
+
+// ModLogEntry is an entry in the mod log.
+type ModLogEntry struct {
+	ID            int             `json:"id"`
+	CommunityID   uid.NullID      `json:"communityId"` // Not valid for site-wide actions.
+	CommunityName msql.NullString `json:"communityName"`
+	ActorID       uid.ID          `json:"actorId"`
+	ActorUsername string          `json:"actorUsername"`
+	ActorGroup    UserGroup       `json:"actorGroup"`
+	Action        ModAction       `json:"action"`
+
+	// TargetType is one of post, comment, user, rule, report, ban_appeal,
+	// or community.
+	TargetType string `json:"targetType"`
+	TargetID   string `json:"targetId"`
+
+	// The user the action was taken against (the author of a removed post,
+	// say), if any.
+	TargetUserID   uid.NullID      `json:"targetUserId"`
+	TargetUsername msql.NullString `json:"targetUsername"`
+
+	// The post of post and comment targets.
+	PostID       uid.NullID      `json:"postId"`
+	PostPublicID msql.NullString `json:"postPublicId"`
+	PostTitle    msql.NullString `json:"postTitle"`
+
+	Reason    string         `json:"reason"`
+	Metadata  map[string]any `json:"metadata"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// execer is either a *sql.DB or a *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertModLogEntry adds e to the mod log. To have the entry written only if
+// the action is, ex should be the transaction of the action.
+func insertModLogEntry(ctx context.Context, ex execer, e *ModLogEntry) error {
+	var metadata any
+	if len(e.Metadata) > 0 {
+		b, err := json.Marshal(e.Metadata)
+		if err != nil {
+			return err
+		}
+		metadata = string(b)
+	}
+	query := `
+		INSERT INTO mod_log (
+			community_id, actor_id, actor_group, action, target_type, target_id, target_user_id, post_id, reason, metadata
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := ex.ExecContext(ctx, query, e.CommunityID, e.ActorID, e.ActorGroup, e.Action, e.TargetType, e.TargetID,
+		e.TargetUserID, e.PostID, msql.NilIfEmptyString(e.Reason), metadata)
+	return err
+}
+
+// postModLogEntry returns a mod log entry of action taken on post by user in
+// his capacity as g.
+func postModLogEntry(post *Post, user uid.ID, g UserGroup, action ModAction) *ModLogEntry {
+	return &ModLogEntry{
+		CommunityID:  uid.NullID{ID: post.CommunityID, Valid: true},
+		ActorID:      user,
+		ActorGroup:   g,
+		Action:       action,
+		TargetType:   "post",
+		TargetID:     post.ID.String(),
+		TargetUserID: uid.NullID{ID: post.AuthorID, Valid: true},
+		PostID:       uid.NullID{ID: post.ID, Valid: true},
+	}
+}
+
+// communityModLogEntry returns a mod log entry of action taken in community by
+// user. The user group of the entry is mods, if user is a mod of community,
+// and admins otherwise (the permissions of user should already be checked).
+func communityModLogEntry(ctx context.Context, db *sql.DB, community, user uid.ID, action ModAction) (*ModLogEntry, error) {
+	g, err := modOrAdminGroup(ctx, db, community, user)
+	if err != nil {
+		return nil, err
+	}
+	return &ModLogEntry{
+		CommunityID: uid.NullID{ID: community, Valid: true},
+		ActorID:     user,
+		ActorGroup:  g,
+		Action:      action,
+	}, nil
+}
+
+// modOrAdminGroup returns UserGroupMods if user is a mod of community, and
+// UserGroupAdmins otherwise.
+func modOrAdminGroup(ctx context.Context, db *sql.DB, community, user uid.ID) (UserGroup, error) {
+	is, err := UserMod(ctx, db, community, user)
+	if err != nil {
+		return UserGroupNaN, err
+	}
+	if is {
+		return UserGroupMods, nil
+	}
+	return UserGroupAdmins, nil
+}
+
+// ModLogQuery holds the filters of a mod log query. Zero valued fields are
+// ignored.
+type ModLogQuery struct {
+	Community  uid.NullID
+	Actor      uid.NullID
+	TargetUser uid.NullID
+	Action     ModAction
+}
+
+// ModLogResultSet is a page of mod log entries.
+type ModLogResultSet struct {
+	Entries []*ModLogEntry `json:"entries"`
+	Next    *string        `json:"next"` // An entry id.
+}
+
+// GetModLog returns the mod log entries matching q, the latest ones first.
+// The next string, if not nil, is the pagination cursor returned by the
+// previous call.
+func GetModLog(ctx context.Context, db *sql.DB, q *ModLogQuery, limit int, next *string) (*ModLogResultSet, error) {
+	where, args := "WHERE TRUE", []any{}
+	if q.Community.Valid {
+		where += " AND mod_log.community_id = ?"
+		args = append(args, q.Community.ID)
+	}
+	if q.Actor.Valid {
+		where += " AND mod_log.actor_id = ?"
+		args = append(args, q.Actor.ID)
+	}
+	if q.TargetUser.Valid {
+		where += " AND mod_log.target_user_id = ?"
+		args = append(args, q.TargetUser.ID)
+	}
+	if q.Action != "" {
+		if !q.Action.Valid() {
+			return nil, httperr.NewBadRequest("invalid-mod-action", "Invalid mod action.")
+		}
+		where += " AND mod_log.action = ?"
+		args = append(args, q.Action)
+	}
+	if next != nil {
+		nextID, err := strconv.Atoi(*next)
+		if err != nil {
+			return nil, errInvalidCursor
+		}
+		where += " AND mod_log.id <= ?"
+		args = append(args, nextID)
+	}
+	where += fmt.Sprintf(" ORDER BY mod_log.id DESC LIMIT %d", limit+1)
+
+	cols := []string{
+		"mod_log.id",
+		"mod_log.community_id",
+		"communities.name",
+		"mod_log.actor_id",
+		"actors.username",
+		"mod_log.actor_group",
+		"mod_log.action",
+		"mod_log.target_type",
+		"mod_log.target_id",
+		"mod_log.target_user_id",
+		"targets.username",
+		"mod_log.post_id",
+		"posts.public_id",
+		"posts.title",
+		"mod_log.reason",
+		"mod_log.metadata",
+		"mod_log.created_at",
+	}
+	joins := []string{
+		"LEFT JOIN communities ON communities.id = mod_log.community_id",
+		"INNER JOIN users AS actors ON actors.id = mod_log.actor_id",
+		"LEFT JOIN users AS targets ON targets.id = mod_log.target_user_id",
+		"LEFT JOIN posts ON posts.id = mod_log.post_id",
+	}
+	rows, err := db.QueryContext(ctx, msql.BuildSelectQuery("mod_log", cols, joins, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*ModLogEntry{}
+	for rows.Next() {
+		var (
+			e        ModLogEntry
+			reason   msql.NullString
+			metadata []byte
+		)
+		err := rows.Scan(
+			&e.ID,
+			&e.CommunityID,
+			&e.CommunityName,
+			&e.ActorID,
+			&e.ActorUsername,
+			&e.ActorGroup,
+			&e.Action,
+			&e.TargetType,
+			&e.TargetID,
+			&e.TargetUserID,
+			&e.TargetUsername,
+			&e.PostID,
+			&e.PostPublicID,
+			&e.PostTitle,
+			&reason,
+			&metadata,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		e.Reason = reason.String
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &e.Metadata); err != nil {
+				return nil, err
+			}
+		}
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	set := &ModLogResultSet{Entries: entries}
+	if len(entries) > limit {
+		set.Next = new(string)
+		*set.Next = strconv.Itoa(entries[limit].ID)
+		set.Entries = entries[:limit]
+	}
+	return set, nil
+}
@@ -0,0 +1,533 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+	"github.com/discuitnet/discuit/internal/utils"
+)
+
+const maxModmailSubjectLength = 255
+
+// A ModmailThread is a private message thread between a user and the mod team
+// of a community. The mods share a single inbox of the threads of their
+// community (see GetModmail), can assign threads to one another, archive them,
+// and add internal notes to them, which the user cannot see. Mods can reply
+// either as themselves or as the mod team, in which case the user does not see
+// who the author of the message is.
+type ModmailThread struct {
+	db *sql.DB
+
+	ID            uid.ID        `json:"id"`
+	CommunityID   uid.ID        `json:"communityId"`
+	CommunityName string        `json:"communityName"`
+	UserID        uid.ID        `json:"userId"` // The user who opened the thread.
+	Username      string        `json:"username"`
+	Subject       string        `json:"subject"`
+	ArchivedAt    msql.NullTime `json:"archivedAt"`
+	Archived      bool          `json:"archived"`
+	LastMessageAt time.Time     `json:"lastMessageAt"`
+	CreatedAt     time.Time     `json:"createdAt"`
+
+	// The mod the thread is assigned to. Visible only to the mods.
+	AssignedTo         uid.NullID      `json:"assignedTo"`
+	AssignedToUsername msql.NullString `json:"assignedToUsername"`
+
+	// The following fields are specific to the user for whom the thread was
+	// fetched.
+	ViewerIsMod bool `json:"viewerIsMod"` // If true, the thread was fetched for the mod team.
+	Unread      bool `json:"unread"`
+
+	userUnread, modsUnread bool
+}
+
+// ModmailMessage is a message in a modmail thread. Body is in markdown.
+type ModmailMessage struct {
+	ID       uid.ID `json:"id"`
+	ThreadID uid.ID `json:"threadId"`
+
+	// Null, for the user of the thread, if the message was sent as the mod
+	// team.
+	AuthorID       uid.NullID      `json:"authorId"`
+	AuthorUsername msql.NullString `json:"authorUsername"`
+
+	AsMods    bool      `json:"asMods"`
+	Internal  bool      `json:"internal"` // Internal notes are visible only to the mods.
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreateModmailThread opens a modmail thread between user and the mods of c.
+// Banned users can message the mods too.
+func (c *Community) CreateModmailThread(ctx context.Context, user uid.ID, subject, body string) (*ModmailThread, error) {
+	subject = utils.TruncateUnicodeString(strings.TrimSpace(subject), maxModmailSubjectLength)
+	if subject == "" {
+		return nil, httperr.NewBadRequest("modmail-no-subject", "Subject cannot be empty.")
+	}
+	body = utils.TruncateUnicodeString(strings.TrimSpace(body), maxMessageBodyLength)
+	if body == "" {
+		return nil, httperr.NewBadRequest("message-empty", "Message is empty.")
+	}
+
+	id, now := uid.New(), time.Now()
+	err := msql.Transact(ctx, c.db, func(tx *sql.Tx) error {
+		query, args := msql.BuildInsertQuery("modmail_threads", []msql.ColumnValue{
+			{Name: "id", Value: id},
+			{Name: "community_id", Value: c.ID},
+			{Name: "user_id", Value: user},
+			{Name: "subject", Value: subject},
+			{Name: "last_message_at", Value: now},
+			{Name: "created_at", Value: now},
+		})
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		query, args = msql.BuildInsertQuery("modmail_messages", []msql.ColumnValue{
+			{Name: "id", Value: uid.New()},
+			{Name: "thread_id", Value: id},
+			{Name: "user_id", Value: user},
+			{Name: "body", Value: body},
+			{Name: "created_at", Value: now},
+		})
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := GetModmailThread(ctx, c.db, id, user)
+	if err != nil {
+		return nil, err
+	}
+	go t.notify(user, false)
+	return t, nil
+}
+
+// GetModmailThread returns the modmail thread if viewer is either the user of
+// the thread or one of the mods of its community (or an admin). If not, it
+// returns a not found error.
+func GetModmailThread(ctx context.Context, db *sql.DB, id, viewer uid.ID) (*ModmailThread, error) {
+	threads, err := getModmailThreads(ctx, db, "WHERE modmail_threads.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(threads) == 0 {
+		return nil, errModmailThreadNotFound
+	}
+	t := threads[0]
+	if t.UserID != viewer {
+		if is, err := UserModOrAdmin(ctx, db, t.CommunityID, viewer); err != nil {
+			return nil, err
+		} else if !is {
+			return nil, errModmailThreadNotFound
+		}
+		t.ViewerIsMod = true
+	}
+	t.setViewerFields()
+	return t, nil
+}
+
+// setViewerFields sets the fields of t that depend on whether it's fetched for
+// the user of the thread or for the mods.
+func (t *ModmailThread) setViewerFields() {
+	if t.ViewerIsMod {
+		t.Unread = t.modsUnread
+	} else {
+		t.Unread = t.userUnread
+		t.AssignedTo = uid.NullID{}
+		t.AssignedToUsername = msql.NullString{}
+	}
+}
+
+// ModmailThreadsResultSet is a page of modmail threads.
+type ModmailThreadsResultSet struct {
+	Threads []*ModmailThread `json:"threads"`
+	Next    *string          `json:"next"` // A timestamp.
+}
+
+// GetUserModmailThreads returns the modmail threads of user, the ones with the
+// most recent messages first. The next string, if not nil, is the pagination
+// cursor returned by the previous call.
+func GetUserModmailThreads(ctx context.Context, db *sql.DB, user uid.ID, limit int, next *string) (*ModmailThreadsResultSet, error) {
+	set, err := getModmailThreadsPage(ctx, db, "WHERE modmail_threads.user_id = ?", []any{user}, limit, next)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range set.Threads {
+		t.setViewerFields()
+	}
+	return set, nil
+}
+
+// GetModmail returns the modmail threads of c, the ones with the most recent
+// messages first. If archived is true, only the archived threads are returned;
+// otherwise only the ones that are not archived. If assignedTo is valid, only
+// the threads assigned to that mod are returned.
+func (c *Community) GetModmail(ctx context.Context, mod uid.ID, archived bool, assignedTo uid.NullID, limit int, next *string) (*ModmailThreadsResultSet, error) {
+	if is, err := c.UserModOrAdmin(ctx, mod); err != nil {
+		return nil, err
+	} else if !is {
+		return nil, errNotMod
+	}
+
+	where, args := "WHERE modmail_threads.community_id = ?", []any{c.ID}
+	if archived {
+		where += " AND modmail_threads.archived_at IS NOT NULL"
+	} else {
+		where += " AND modmail_threads.archived_at IS NULL"
+	}
+	if assignedTo.Valid {
+		where += " AND modmail_threads.assigned_to = ?"
+		args = append(args, assignedTo)
+	}
+	set, err := getModmailThreadsPage(ctx, c.db, where, args, limit, next)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range set.Threads {
+		t.ViewerIsMod = true
+		t.setViewerFields()
+	}
+	return set, nil
+}
+
+func getModmailThreadsPage(ctx context.Context, db *sql.DB, where string, args []any, limit int, next *string) (*ModmailThreadsResultSet, error) {
+	if next != nil {
+		t, err := time.Parse(time.RFC3339Nano, *next)
+		if err != nil {
+			return nil, errInvalidCursor
+		}
+		where += " AND modmail_threads.last_message_at <= ?"
+		args = append(args, t)
+	}
+	where += fmt.Sprintf(" ORDER BY modmail_threads.last_message_at DESC LIMIT %d", limit+1)
+
+	threads, err := getModmailThreads(ctx, db, where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &ModmailThreadsResultSet{Threads: threads}
+	if len(threads) > limit {
+		set.Next = new(string)
+		*set.Next = threads[limit].LastMessageAt.Format(time.RFC3339Nano)
+		set.Threads = threads[:limit]
+	}
+	return set, nil
+}
+
+func getModmailThreads(ctx context.Context, db *sql.DB, where string, args ...any) ([]*ModmailThread, error) {
+	query := msql.BuildSelectQuery("modmail_threads", []string{
+		"modmail_threads.id",
+		"modmail_threads.community_id",
+		"communities.name",
+		"modmail_threads.user_id",
+		"users.username",
+		"users.deleted_at",
+		"modmail_threads.subject",
+		"modmail_threads.assigned_to",
+		"assignees.username",
+		"modmail_threads.archived_at",
+		"modmail_threads.user_unread",
+		"modmail_threads.mods_unread",
+		"modmail_threads.last_message_at",
+		"modmail_threads.created_at",
+	}, []string{
+		"INNER JOIN communities ON communities.id = modmail_threads.community_id",
+		"INNER JOIN users ON users.id = modmail_threads.user_id",
+		"LEFT JOIN users AS assignees ON assignees.id = modmail_threads.assigned_to",
+	}, where)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	threads := []*ModmailThread{}
+	for rows.Next() {
+		t := &ModmailThread{db: db}
+		var userDeletedAt msql.NullTime
+		if err = rows.Scan(
+			&t.ID,
+			&t.CommunityID,
+			&t.CommunityName,
+			&t.UserID,
+			&t.Username,
+			&userDeletedAt,
+			&t.Subject,
+			&t.AssignedTo,
+			&t.AssignedToUsername,
+			&t.ArchivedAt,
+			&t.userUnread,
+			&t.modsUnread,
+			&t.LastMessageAt,
+			&t.CreatedAt); err != nil {
+			return nil, err
+		}
+		if userDeletedAt.Valid {
+			t.Username = "ghost"
+		}
+		t.Archived = t.ArchivedAt.Valid
+		threads = append(threads, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return threads, nil
+}
+
+// GetMessages returns the messages of the thread, the oldest ones first.
+// Internal notes are excluded, and the authors of the messages sent as the mod
+// team are hidden, if the thread was not fetched for the mods.
+func (t *ModmailThread) GetMessages(ctx context.Context) ([]*ModmailMessage, error) {
+	where := "WHERE modmail_messages.thread_id = ?"
+	if !t.ViewerIsMod {
+		where += " AND modmail_messages.internal = FALSE"
+	}
+	where += " ORDER BY modmail_messages.id"
+
+	messages, err := getModmailMessages(ctx, t.db, where, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !t.ViewerIsMod {
+		for _, message := range messages {
+			message.hideAuthor()
+		}
+	}
+	return messages, nil
+}
+
+// hideAuthor removes the author of m if it was sent as the mod team.
+func (m *ModmailMessage) hideAuthor() {
+	if m.AsMods {
+		m.AuthorID = uid.NullID{}
+		m.AuthorUsername = msql.NullString{}
+	}
+}
+
+func getModmailMessages(ctx context.Context, db *sql.DB, where string, args ...any) ([]*ModmailMessage, error) {
+	query := msql.BuildSelectQuery("modmail_messages", []string{
+		"modmail_messages.id",
+		"modmail_messages.thread_id",
+		"modmail_messages.user_id",
+		"users.username",
+		"users.deleted_at",
+		"modmail_messages.as_mods",
+		"modmail_messages.internal",
+		"modmail_messages.body",
+		"modmail_messages.created_at",
+	}, []string{
+		"INNER JOIN users ON users.id = modmail_messages.user_id",
+	}, where)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*ModmailMessage{}
+	for rows.Next() {
+		m := &ModmailMessage{}
+		var authorDeletedAt msql.NullTime
+		if err = rows.Scan(
+			&m.ID,
+			&m.ThreadID,
+			&m.AuthorID,
+			&m.AuthorUsername,
+			&authorDeletedAt,
+			&m.AsMods,
+			&m.Internal,
+			&m.Body,
+			&m.CreatedAt); err != nil {
+			return nil, err
+		}
+		if authorDeletedAt.Valid {
+			m.AuthorUsername.String = "ghost"
+		}
+		messages = append(messages, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// Reply adds a message to the thread on behalf of author. Only the mods can
+// send messages as the mod team (asMods) and add internal notes. A reply of
+// the user unarchives the thread.
+func (t *ModmailThread) Reply(ctx context.Context, author uid.ID, body string, asMods, internal bool) (*ModmailMessage, error) {
+	fromMods := author != t.UserID
+	if fromMods {
+		if is, err := UserModOrAdmin(ctx, t.db, t.CommunityID, author); err != nil {
+			return nil, err
+		} else if !is {
+			return nil, errModmailThreadNotFound
+		}
+	} else if asMods || internal {
+		return nil, errNotMod
+	}
+
+	body = utils.TruncateUnicodeString(strings.TrimSpace(body), maxMessageBodyLength)
+	if body == "" {
+		return nil, httperr.NewBadRequest("message-empty", "Message is empty.")
+	}
+
+	id, now := uid.New(), time.Now()
+	err := msql.Transact(ctx, t.db, func(tx *sql.Tx) error {
+		query, args := msql.BuildInsertQuery("modmail_messages", []msql.ColumnValue{
+			{Name: "id", Value: id},
+			{Name: "thread_id", Value: t.ID},
+			{Name: "user_id", Value: author},
+			{Name: "as_mods", Value: asMods},
+			{Name: "internal", Value: internal},
+			{Name: "body", Value: body},
+			{Name: "created_at", Value: now},
+		})
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		if internal {
+			return nil
+		}
+		var err error
+		if fromMods {
+			_, err = tx.ExecContext(ctx, "UPDATE modmail_threads SET user_unread = TRUE, mods_unread = FALSE, last_message_at = ? WHERE id = ?", now, t.ID)
+		} else {
+			_, err = tx.ExecContext(ctx, "UPDATE modmail_threads SET mods_unread = TRUE, archived_at = NULL, last_message_at = ? WHERE id = ?", now, t.ID)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !internal {
+		t.LastMessageAt = now
+		if fromMods {
+			t.userUnread, t.modsUnread = true, false
+		} else {
+			t.modsUnread = true
+			t.ArchivedAt, t.Archived = msql.NullTime{}, false
+		}
+		t.setViewerFields()
+		go t.notify(author, fromMods)
+	}
+
+	messages, err := getModmailMessages(ctx, t.db, "WHERE modmail_messages.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, errMessageNotFound
+	}
+	return messages[0], nil
+}
+
+// notify sends a modmail notification of a new message by author in the
+// thread: to the user if the message is from the mods, and otherwise to the
+// mod the thread is assigned to or, if it's not assigned, to all the mods.
+func (t *ModmailThread) notify(author uid.ID, fromMods bool) {
+	ctx := context.Background()
+	var receivers []uid.ID
+	if fromMods {
+		receivers = []uid.ID{t.UserID}
+	} else if t.AssignedTo.Valid {
+		receivers = []uid.ID{t.AssignedTo.ID}
+	} else {
+		rows, err := t.db.QueryContext(ctx, "SELECT user_id FROM community_mods WHERE community_id = ?", t.CommunityID)
+		if err != nil {
+			log.Printf("Failed to get the mods of community %v: %v\n", t.CommunityID, err)
+			return
+		}
+		if receivers, err = scanIDs(rows); err != nil {
+			log.Printf("Failed to scan the mods of community %v: %v\n", t.CommunityID, err)
+			return
+		}
+	}
+	for _, receiver := range receivers {
+		if receiver == author {
+			continue
+		}
+		if err := CreateModmailNotification(ctx, t.db, receiver, t, !fromMods); err != nil {
+			log.Printf("Create modmail notification failed: %v\n", err)
+		}
+	}
+}
+
+// MarkRead marks the thread as read by viewer's side of it (either the user or
+// the mods).
+func (t *ModmailThread) MarkRead(ctx context.Context) error {
+	col := "user_unread"
+	if t.ViewerIsMod {
+		col = "mods_unread"
+	}
+	if _, err := t.db.ExecContext(ctx, "UPDATE modmail_threads SET "+col+" = FALSE WHERE id = ?", t.ID); err != nil {
+		return err
+	}
+	if t.ViewerIsMod {
+		t.modsUnread = false
+	} else {
+		t.userUnread = false
+	}
+	t.Unread = false
+	return nil
+}
+
+// Assign assigns the thread to assignee, who must be a mod of the community
+// (or an admin), on behalf of mod. If assignee is not valid, the thread is
+// unassigned.
+func (t *ModmailThread) Assign(ctx context.Context, mod uid.ID, assignee uid.NullID) error {
+	if is, err := UserModOrAdmin(ctx, t.db, t.CommunityID, mod); err != nil {
+		return err
+	} else if !is {
+		return errNotMod
+	}
+
+	var username msql.NullString
+	if assignee.Valid {
+		if is, err := UserModOrAdmin(ctx, t.db, t.CommunityID, assignee.ID); err != nil {
+			return err
+		} else if !is {
+			return httperr.NewBadRequest("modmail-assignee-not-mod", "Threads can only be assigned to mods.")
+		}
+		if err := t.db.QueryRowContext(ctx, "SELECT username FROM users WHERE id = ?", assignee.ID).Scan(&username); err != nil {
+			return err
+		}
+	}
+
+	if _, err := t.db.ExecContext(ctx, "UPDATE modmail_threads SET assigned_to = ? WHERE id = ?", assignee, t.ID); err != nil {
+		return err
+	}
+	t.AssignedTo, t.AssignedToUsername = assignee, username
+	return nil
+}
+
+// SetArchived archives (or unarchives) the thread on behalf of mod.
+func (t *ModmailThread) SetArchived(ctx context.Context, mod uid.ID, archived bool) error {
+	if is, err := UserModOrAdmin(ctx, t.db, t.CommunityID, mod); err != nil {
+		return err
+	} else if !is {
+		return errNotMod
+	}
+
+	var archivedAt msql.NullTime
+	if archived {
+		archivedAt = msql.NewNullTime(time.Now())
+	}
+	if _, err := t.db.ExecContext(ctx, "UPDATE modmail_threads SET archived_at = ? WHERE id = ?", archivedAt, t.ID); err != nil {
+		return err
+	}
+	t.ArchivedAt, t.Archived = archivedAt, archived
+	return nil
+}
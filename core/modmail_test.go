@@ -0,0 +1,28 @@
+package core
+
+import (
+	"testing"
+
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+func TestModmailMessageHideAuthor(t *testing.T) {
+	author := uid.NullID{ID: uid.New(), Valid: true}
+	username := msql.NewNullString("mod")
+
+	cases := []struct {
+		asMods     bool
+		wantHidden bool
+	}{
+		{false, false},
+		{true, true},
+	}
+	for _, item := range cases {
+		m := &ModmailMessage{AuthorID: author, AuthorUsername: username, AsMods: item.asMods}
+		m.hideAuthor()
+		if hidden := !m.AuthorID.Valid && !m.AuthorUsername.Valid; hidden != item.wantHidden {
+			t.Errorf("asMods %v: author hidden is %v, want %v", item.asMods, hidden, item.wantHidden)
+		}
+	}
+}
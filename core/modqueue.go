@@ -0,0 +1,299 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// ModQueueReason is the reason a post or a comment is in the mod queue.
+type ModQueueReason string
+
+const (
+	ModQueueReasonReported = ModQueueReason("reported") // Has reports.
+	ModQueueReasonFiltered = ModQueueReason("filtered") // Removed by automod, awaiting approval.
+	ModQueueReasonEdited   = ModQueueReason("edited")   // Edited after being reported.
+)
+
+// Valid reports whether r is a valid ModQueueReason.
+func (r ModQueueReason) Valid() bool {
+	switch r {
+	case ModQueueReasonReported, ModQueueReasonFiltered, ModQueueReasonEdited:
+		return true
+	}
+	return false
+}
+
+// ModQueueAction is an action taken on an item of the mod queue. All actions
+// remove the item from the queue, along with its reports.
+type ModQueueAction string
+
+const (
+	ModQueueActionApprove = ModQueueAction("approve") // Restores filtered items.
+	ModQueueActionRemove  = ModQueueAction("remove")
+	ModQueueActionIgnore  = ModQueueAction("ignore")
+)
+
+// ModQueueItem is a post or a comment in the mod queue.
+type ModQueueItem struct {
+	CommunityID uid.ID           `json:"communityId"`
+	TargetType  ContentType      `json:"targetType"`
+	TargetID    uid.ID           `json:"targetId"`
+	PostID      uid.ID           `json:"postId"`
+	Reasons     []ModQueueReason `json:"reasons"`
+	Note        string           `json:"note,omitempty"` // The automod rule, of filtered items.
+	NumReports  int              `json:"noReports"`
+	CreatedAt   time.Time        `json:"createdAt"` // When the item was first queued.
+
+	Post    *Post    `json:"post,omitempty"`
+	Comment *Comment `json:"comment,omitempty"`
+}
+
+// queueForModeration adds the post or the comment, target, to the mod queue
+// for reason (other than ModQueueReasonReported).
+func queueForModeration(ctx context.Context, db *sql.DB, community uid.ID, t ContentType, target, post uid.ID, reason ModQueueReason, note string) error {
+	_, err := db.ExecContext(ctx, "INSERT IGNORE INTO mod_queue (community_id, target_type, target_id, post_id, reason, note) VALUES (?, ?, ?, ?, ?, ?)",
+		community, t, target, post, reason, msql.NilIfEmptyString(note))
+	return err
+}
+
+// queueIfReported adds the post or the comment, target, to the mod queue as
+// edited, if it has reports.
+func queueIfReported(ctx context.Context, db *sql.DB, community uid.ID, t ContentType, target, post uid.ID) error {
+	query := `
+		INSERT IGNORE INTO mod_queue (community_id, target_type, target_id, post_id, reason)
+		SELECT ?, ?, ?, ?, ? FROM DUAL
+		WHERE EXISTS (SELECT 1 FROM reports WHERE target_id = ? AND report_type = ? AND ` + modReportsCond + `)`
+	_, err := db.ExecContext(ctx, query, community, t, target, post, ModQueueReasonEdited, target, t)
+	return err
+}
+
+// modQueueUnion returns a query of the reported and the queued items of
+// communities, one row per reason.
+func modQueueUnion(communities []uid.ID) (string, []any) {
+	in := msql.InClauseQuestionMarks(len(communities))
+	query := fmt.Sprintf(`
+		SELECT community_id, report_type AS target_type, target_id, post_id, '%s' AS reason, NULL AS note, COUNT(*) AS reports, MIN(created_at) AS created_at
+		FROM reports WHERE community_id IN %s AND post_id IS NOT NULL AND %s
+		GROUP BY community_id, report_type, target_id, post_id
+		UNION ALL
+		SELECT community_id, target_type, target_id, post_id, reason, note, 0, created_at
+		FROM mod_queue WHERE community_id IN %s`, ModQueueReasonReported, in, modReportsCond, in)
+	args := make([]any, 0, 2*len(communities))
+	for range 2 {
+		for _, id := range communities {
+			args = append(args, id)
+		}
+	}
+	return query, args
+}
+
+// GetModQueue returns the items in the mod queue of communities, the latest
+// ones first. If reason is not empty, only the items queued for reason are
+// returned. The viewer should be a mod of communities (or an admin).
+func GetModQueue(ctx context.Context, db *sql.DB, communities []uid.ID, reason ModQueueReason, viewer uid.ID, limit, page int) ([]*ModQueueItem, error) {
+	items := []*ModQueueItem{}
+	if len(communities) == 0 {
+		return items, nil
+	}
+	if reason != "" && !reason.Valid() {
+		return nil, httperr.NewBadRequest("invalid-mod-queue-reason", "Invalid mod queue reason.")
+	}
+
+	union, args := modQueueUnion(communities)
+	query := fmt.Sprintf(`
+		SELECT q.community_id, q.target_type, q.target_id, q.post_id, GROUP_CONCAT(DISTINCT q.reason), MAX(q.note), SUM(q.reports), MIN(q.created_at) AS queued_at
+		FROM (%s) AS q
+		GROUP BY q.community_id, q.target_type, q.target_id, q.post_id`, union)
+	if reason != "" {
+		query += " HAVING FIND_IN_SET(?, GROUP_CONCAT(DISTINCT q.reason))"
+		args = append(args, reason)
+	}
+	query += " ORDER BY queued_at DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, limit*(page-1))
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			item    ModQueueItem
+			reasons string
+			note    msql.NullString
+		)
+		if err := rows.Scan(&item.CommunityID, &item.TargetType, &item.TargetID, &item.PostID, &reasons, &note, &item.NumReports, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		for _, r := range strings.Split(reasons, ",") {
+			item.Reasons = append(item.Reasons, ModQueueReason(r))
+		}
+		item.Note = note.String
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if item.TargetType == ContentTypePost {
+			if item.Post, err = GetPost(ctx, db, &item.TargetID, "", &viewer, true); err != nil && !httperr.IsNotFound(err) {
+				return nil, err
+			}
+		} else {
+			if item.Comment, err = GetComment(ctx, db, item.TargetID, &viewer); err != nil && !httperr.IsNotFound(err) {
+				return nil, err
+			}
+		}
+	}
+	return items, nil
+}
+
+// CountModQueue returns the number of items in the mod queue of each of
+// communities. Communities with an empty queue are not in the map.
+func CountModQueue(ctx context.Context, db *sql.DB, communities []uid.ID) (map[uid.ID]int, error) {
+	counts := make(map[uid.ID]int)
+	if len(communities) == 0 {
+		return counts, nil
+	}
+	union, args := modQueueUnion(communities)
+	query := fmt.Sprintf("SELECT q.community_id, COUNT(DISTINCT q.target_id) FROM (%s) AS q GROUP BY q.community_id", union)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id uid.ID
+			n  int
+		)
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		counts[id] = n
+	}
+	return counts, rows.Err()
+}
+
+// TakeModQueueAction takes action on the post or the comment, target, of the
+// mod queue on behalf of mod, and removes it from the queue.
+func TakeModQueueAction(ctx context.Context, db *sql.DB, mod uid.ID, t ContentType, target uid.ID, action ModQueueAction) error {
+	var (
+		post    *Post
+		comment *Comment
+		err     error
+	)
+	if t == ContentTypePost {
+		post, err = GetPost(ctx, db, &target, "", nil, true)
+	} else {
+		comment, err = GetComment(ctx, db, target, nil)
+		if err == nil {
+			post, err = GetPost(ctx, db, &comment.PostID, "", nil, true)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if is, err := UserModOrAdmin(ctx, db, post.CommunityID, mod); err != nil {
+		return err
+	} else if !is {
+		return errNotMod
+	}
+	g, err := modOrAdminGroup(ctx, db, post.CommunityID, mod)
+	if err != nil {
+		return err
+	}
+
+	var filtered bool
+	row := db.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM mod_queue WHERE target_id = ? AND reason = ?", target, ModQueueReasonFiltered)
+	if err := row.Scan(&filtered); err != nil {
+		return err
+	}
+
+	switch action {
+	case ModQueueActionApprove:
+		if comment != nil {
+			entry := &ModLogEntry{
+				CommunityID: uid.NullID{ID: comment.CommunityID, Valid: true},
+				ActorID:     mod,
+				ActorGroup:  g,
+				Action:      ModActionApproveComment,
+				TargetType:  "comment",
+				TargetID:    comment.ID.String(),
+				PostID:      uid.NullID{ID: comment.PostID, Valid: true},
+			}
+			err = comment.approve(ctx, filtered && comment.Deleted, entry)
+		} else {
+			err = post.approve(ctx, filtered && post.Deleted, postModLogEntry(post, mod, g, ModActionApprovePost))
+		}
+		if err != nil {
+			return err
+		}
+	case ModQueueActionRemove:
+		if comment != nil {
+			if !comment.Deleted {
+				err = comment.Delete(ctx, mod, g, nil)
+			} else if filtered {
+				err = confirmFilteredRemoval(ctx, db, post, comment, mod, g)
+			}
+		} else if !post.Deleted {
+			err = post.Delete(ctx, mod, g, false, true, nil)
+		} else if filtered {
+			err = confirmFilteredRemoval(ctx, db, post, nil, mod, g)
+		}
+		if err != nil {
+			return err
+		}
+	case ModQueueActionIgnore:
+	default:
+		return httperr.NewBadRequest("invalid-mod-queue-action", "Invalid mod queue action.")
+	}
+
+	if comment != nil {
+		return RemoveAllReportsOfComment(ctx, db, comment.ID)
+	}
+	return RemoveAllReportsOfPost(ctx, db, post.ID)
+}
+
+// confirmFilteredRemoval is called when mod, in the capacity of g, removes a
+// post or a comment (if comment is not nil) that was filtered by automod, and
+// is therefore already deleted. The removal is added to the mod log and the
+// author is notified, neither of which is done by automod.
+func confirmFilteredRemoval(ctx context.Context, db *sql.DB, post *Post, comment *Comment, mod uid.ID, g UserGroup) error {
+	entry := postModLogEntry(post, mod, g, ModActionRemovePost)
+	author, target := post.AuthorID, post.ID
+	if comment != nil {
+		entry = &ModLogEntry{
+			CommunityID:  uid.NullID{ID: comment.CommunityID, Valid: true},
+			ActorID:      mod,
+			ActorGroup:   g,
+			Action:       ModActionRemoveComment,
+			TargetType:   "comment",
+			TargetID:     comment.ID.String(),
+			TargetUserID: uid.NullID{ID: comment.AuthorID, Valid: true},
+			PostID:       uid.NullID{ID: comment.PostID, Valid: true},
+		}
+		author, target = comment.AuthorID, comment.ID
+	}
+	if err := insertModLogEntry(ctx, db, entry); err != nil {
+		return err
+	}
+	go func() {
+		if err := CreatePostDeletedNotification(context.Background(), db, author, g, comment == nil, target, ""); err != nil {
+			log.Printf("Failed to create deleted_post notification on %s %v: %v\n", entry.TargetType, target, err)
+		}
+	}()
+	return nil
+}
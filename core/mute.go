@@ -16,12 +16,13 @@ import (
 type MuteType string
 
 func (t MuteType) Valid() bool {
-	return slices.Contains([]MuteType{"", MuteTypeUser, MuteTypeCommunity}, t)
+	return slices.Contains([]MuteType{"", MuteTypeUser, MuteTypeCommunity, MuteTypeFilter}, t)
 }
 
 const (
 	MuteTypeUser      = MuteType("user")
 	MuteTypeCommunity = MuteType("community")
+	MuteTypeFilter    = MuteType("filter") // See ContentFilter.
 )
 
 type Mute struct {
@@ -37,6 +38,8 @@ type Mute struct {
 
 	MutedUser      *User      `json:"mutedUser,omitempty"`
 	MutedCommunity *Community `json:"mutedCommunity,omitempty"`
+
+	Filter *ContentFilter `json:"filter,omitempty"` // Only set if Type is MuteTypeFilter.
 }
 
 func (m *Mute) setPrintID() {
@@ -46,6 +49,8 @@ func (m *Mute) setPrintID() {
 		s = "u_" + s
 	case MuteTypeCommunity:
 		s = "c_" + s
+	case MuteTypeFilter:
+		s = "f_" + s
 	default:
 		panic("unknown mute type")
 	}
@@ -65,6 +70,8 @@ func extractMuteID(s string) (t MuteType, id int, err error) {
 		t = MuteTypeUser
 	case "c_":
 		t = MuteTypeCommunity
+	case "f_":
+		t = MuteTypeFilter
 	default:
 		err = errMuteID
 		return
@@ -86,8 +93,13 @@ func GetMutes(ctx context.Context, db *sql.DB, user uid.ID) ([]*Mute, error) {
 	if err != nil {
 		return nil, err
 	}
+	filterMutes, err := GetMutedFilters(ctx, db, user)
+	if err != nil {
+		return nil, err
+	}
 
 	all := append(communityMutes, userMutes...)
+	all = append(all, filterMutes...)
 	sort.Slice(all, func(i, j int) bool {
 		return all[i].CreatedAt.Before(all[j].CreatedAt)
 	})
@@ -193,12 +205,14 @@ func Unmute(ctx context.Context, db *sql.DB, user uid.ID, id string) error {
 		_, err = db.ExecContext(ctx, "delete from muted_communities where id = ? and user_id = ?", idInt, user)
 	case MuteTypeUser:
 		_, err = db.ExecContext(ctx, "delete from muted_users where id = ? and user_id = ?", idInt, user)
+	case MuteTypeFilter:
+		_, err = db.ExecContext(ctx, "delete from content_filters where id = ? and user_id = ?", idInt, user)
 	}
 	return err
 }
 
 // ClearMutes clears all mutes of user if t is empty, otherwise it clears either
-// the community or the user mutes.
+// the community, the user, or the filter mutes.
 func ClearMutes(ctx context.Context, db *sql.DB, user uid.ID, t MuteType) (err error) {
 	if t == "" || t == MuteTypeCommunity {
 		_, err = db.ExecContext(ctx, "DELETE FROM muted_communities WHERE user_id = ?", user)
@@ -208,6 +222,12 @@ func ClearMutes(ctx context.Context, db *sql.DB, user uid.ID, t MuteType) (err e
 	}
 	if t == "" || t == MuteTypeUser {
 		_, err = db.ExecContext(ctx, "DELETE FROM muted_users where user_id = ?", user)
+		if err != nil {
+			return
+		}
+	}
+	if t == "" || t == MuteTypeFilter {
+		_, err = db.ExecContext(ctx, "DELETE FROM content_filters WHERE user_id = ?", user)
 	}
 	return
 }
@@ -13,6 +13,7 @@ func TestExtractMuteID(t *testing.T) {
 	}{
 		{"c_1", MuteTypeCommunity, 1, false},
 		{"u_1234", MuteTypeUser, 1234, false},
+		{"f_12", MuteTypeFilter, 12, false},
 		{"", "", 0, true},
 		{"1234", "", 0, true},
 		{"c_", "", 0, true},
@@ -40,23 +40,55 @@ const MaxNotificationsPerUser = 200
 type NotificationType string
 
 const (
-	NotificationTypeNewComment   = NotificationType("new_comment")
-	NotificationTypeCommentReply = NotificationType("comment_reply")
-	NotificationTypeUpvote       = NotificationType("new_votes") // TODO: change string
-	NotificationTypeDeletePost   = NotificationType("deleted_post")
-	NotificationTypeModAdd       = NotificationType("mod_add")
-	NotificationTypeNewBadge     = NotificationType("new_badge")
+	NotificationTypeNewComment    = NotificationType("new_comment")
+	NotificationTypeCommentReply  = NotificationType("comment_reply")
+	NotificationTypeUpvote        = NotificationType("new_votes") // TODO: change string
+	NotificationTypeDeletePost    = NotificationType("deleted_post")
+	NotificationTypeModAdd        = NotificationType("mod_add")
+	NotificationTypeNewBadge      = NotificationType("new_badge")
+	NotificationTypeMention       = NotificationType("mention")
+	NotificationTypeNewMessage    = NotificationType("new_message")
+	NotificationTypeThreadComment = NotificationType("thread_comment")
+	NotificationTypeNewPost       = NotificationType("new_post")
+
+	NotificationTypeCommunityInvite = NotificationType("community_invite")
+	NotificationTypeAutomodMessage  = NotificationType("automod_message")
+	NotificationTypeBanAppeal       = NotificationType("ban_appeal")
+	NotificationTypeBanExpired      = NotificationType("ban_expired")
+	NotificationTypeCommunityBan    = NotificationType("community_ban")
+	NotificationTypeModmail         = NotificationType("modmail")
+	NotificationTypeModTransfer     = NotificationType("mod_transfer")
+	NotificationTypeTakeover        = NotificationType("community_takeover")
+	NotificationTypeCommunityRename = NotificationType("community_rename")
+	NotificationTypeRenameRejected  = NotificationType("rename_rejected")
 )
 
+// notificationTypes are all the notification types.
+var notificationTypes = []NotificationType{
+	NotificationTypeNewComment,
+	NotificationTypeCommentReply,
+	NotificationTypeUpvote,
+	NotificationTypeDeletePost,
+	NotificationTypeModAdd,
+	NotificationTypeNewBadge,
+	NotificationTypeMention,
+	NotificationTypeNewMessage,
+	NotificationTypeThreadComment,
+	NotificationTypeNewPost,
+	NotificationTypeCommunityInvite,
+	NotificationTypeAutomodMessage,
+	NotificationTypeBanAppeal,
+	NotificationTypeBanExpired,
+	NotificationTypeCommunityBan,
+	NotificationTypeModmail,
+	NotificationTypeModTransfer,
+	NotificationTypeTakeover,
+	NotificationTypeCommunityRename,
+	NotificationTypeRenameRejected,
+}
+
 func (t NotificationType) Valid() bool {
-	return slices.Contains([]NotificationType{
-		NotificationTypeNewComment,
-		NotificationTypeCommentReply,
-		NotificationTypeUpvote,
-		NotificationTypeDeletePost,
-		NotificationTypeModAdd,
-		NotificationTypeNewBadge,
-	}, t)
+	return slices.Contains(notificationTypes, t)
 }
 
 type notification interface {
@@ -75,6 +107,10 @@ type Notification struct {
 	Notif        notification `json:"notif"`
 	notifRawJSON []byte
 
+	// If false, the notification is not shown in the user's notifications
+	// list (it exists only to be delivered through other channels).
+	inApp bool
+
 	Seen      bool          `json:"seen"`
 	SeenAt    msql.NullTime `json:"seenAt"`
 	CreatedAt time.Time     `json:"createdAt"`
@@ -106,6 +142,7 @@ var selectNotificationCols = []string{
 	"notifications.user_id",
 	"notifications.type",
 	"notifications.notif",
+	"notifications.in_app",
 	"notifications.seen",
 	"notifications.seen_at",
 	"notifications.created_at",
@@ -122,6 +159,7 @@ func scanNotifications(db *sql.DB, rows *sql.Rows) ([]*Notification, error) {
 			&n.UserID,
 			&n.Type,
 			&n.notifRawJSON,
+			&n.inApp,
 			&n.Seen,
 			&n.SeenAt,
 			&n.CreatedAt,
@@ -171,12 +209,96 @@ func scanNotifications(db *sql.DB, rows *sql.Rows) ([]*Notification, error) {
 				return nil, err
 			}
 			notif.Notif = nc
+		case NotificationTypeCommunityInvite:
+			nc := &NotificationCommunityInvite{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
+		case NotificationTypeAutomodMessage:
+			nc := &NotificationAutomodMessage{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
+		case NotificationTypeBanAppeal:
+			nc := &NotificationBanAppeal{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
+		case NotificationTypeBanExpired:
+			nc := &NotificationBanExpired{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
+		case NotificationTypeCommunityBan:
+			nc := &NotificationCommunityBan{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
+		case NotificationTypeModmail:
+			nc := &NotificationModmail{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
+		case NotificationTypeModTransfer:
+			nc := &NotificationModTransfer{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
+		case NotificationTypeTakeover:
+			nc := &NotificationTakeover{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
+		case NotificationTypeCommunityRename:
+			nc := &NotificationCommunityRename{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
+		case NotificationTypeRenameRejected:
+			nc := &NotificationRenameRejected{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
 		case NotificationTypeNewBadge:
 			nc := &NotificationNewBadge{}
 			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
 				return nil, err
 			}
 			notif.Notif = nc
+		case NotificationTypeMention:
+			nc := &NotificationMention{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
+		case NotificationTypeNewMessage:
+			nc := &NotificationNewMessage{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
+		case NotificationTypeThreadComment:
+			nc := &NotificationThreadComment{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
+		case NotificationTypeNewPost:
+			nc := &NotificationNewPost{}
+			if err := json.Unmarshal(notif.notifRawJSON, nc); err != nil {
+				return nil, err
+			}
+			notif.Notif = nc
 		default:
 			return nil, fmt.Errorf("unknown notification type: %s", string(notif.Type))
 		}
@@ -186,11 +308,32 @@ func scanNotifications(db *sql.DB, rows *sql.Rows) ([]*Notification, error) {
 }
 
 // removeExcessNotifications keeps only the latest MaxNotificationsPerUser
-// notifications of user. The number of notifications removed is returned.
+// in-app notifications of user, and as many of those that are not shown in-app
+// (which are kept only for push notifications), so that the latter don't push
+// out the former. The number of notifications removed is returned.
 func removeExcessNotifications(ctx context.Context, db *sql.DB, user uid.ID) (n int, err error) {
-	rows, err := db.QueryContext(ctx, "SELECT id FROM notifications WHERE user_id = ? ORDER BY id DESC LIMIT ?,10000000", user, MaxNotificationsPerUser)
+	for _, inApp := range []bool{true, false} {
+		var ids []any
+		if ids, err = excessNotifications(ctx, db, user, inApp); err != nil {
+			return
+		}
+		if len(ids) > 0 {
+			if _, err = db.ExecContext(ctx, "DELETE FROM notifications WHERE id IN "+msql.InClauseQuestionMarks(len(ids)), ids...); err != nil {
+				return
+			}
+		}
+		n += len(ids)
+	}
+	return
+}
+
+// excessNotifications returns the IDs of the notifications of user, with the
+// in_app column set to inApp, that are older than the latest
+// MaxNotificationsPerUser ones.
+func excessNotifications(ctx context.Context, db *sql.DB, user uid.ID, inApp bool) ([]any, error) {
+	rows, err := db.QueryContext(ctx, "SELECT id FROM notifications WHERE user_id = ? AND in_app = ? ORDER BY id DESC LIMIT ?,10000000", user, inApp, MaxNotificationsPerUser)
 	if err != nil {
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -198,20 +341,11 @@ func removeExcessNotifications(ctx context.Context, db *sql.DB, user uid.ID) (n
 	for rows.Next() {
 		id := 0
 		if err = rows.Scan(&id); err != nil {
-			return
+			return nil, err
 		}
 		ids = append(ids, id)
 	}
-
-	if err = rows.Err(); err != nil {
-		return
-	}
-
-	if len(ids) > 0 {
-		_, err = db.ExecContext(ctx, "DELETE FROM notifications WHERE id IN "+msql.InClauseQuestionMarks(len(ids)), ids...)
-	}
-	n = len(ids)
-	return
+	return ids, rows.Err()
 }
 
 // CreateNotification adds a new notification to user's notifications stack.
@@ -223,12 +357,21 @@ func CreateNotification(ctx context.Context, db *sql.DB, user uid.ID, Type Notif
 		return nil
 	}
 
+	channels, err := notificationChannels(ctx, db, user, Type, notif)
+	if err != nil {
+		return err
+	}
+	if channels.none() {
+		// Exit silently if the user has turned off the notification.
+		return nil
+	}
+
 	data, err := json.Marshal(notif)
 	if err != nil {
 		return err
 	}
 
-	res, err := db.ExecContext(ctx, "INSERT INTO notifications (user_id, type, notif) VALUES (?, ?, ?)", user, Type, data)
+	res, err := db.ExecContext(ctx, "INSERT INTO notifications (user_id, type, notif, in_app) VALUES (?, ?, ?, ?)", user, Type, data, channels.InApp)
 	if err != nil {
 		return err
 	}
@@ -251,6 +394,9 @@ func CreateNotification(ctx context.Context, db *sql.DB, user uid.ID, Type Notif
 			log.Println("Error getting notification (CreateNotification)", err)
 			return
 		}
+		if notif.inApp {
+			notif.publishEvents(ctx)
+		}
 		notif.SendPushNotification(ctx)
 	}
 
@@ -296,7 +442,7 @@ func (n *notificationsPaginationCursor) decode(s string) error {
 func GetNotifications(ctx context.Context, db *sql.DB, user uid.ID, limit int, cursor string) ([]*Notification, string, error) {
 	var args []interface{}
 	args = append(args, user)
-	where := "WHERE user_id = ?"
+	where := "WHERE user_id = ? AND in_app = TRUE"
 	if cursor != "" {
 		o := notificationsPaginationCursor{}
 		if err := o.decode(cursor); err != nil {
@@ -332,9 +478,25 @@ func GetNotifications(ctx context.Context, db *sql.DB, user uid.ID, limit int, c
 	return notifs, "", err
 }
 
+// lastNotifications returns the last 10 notifications of user, including the
+// ones not shown in the notifications list. It's used for coalescing similar
+// notifications.
+func lastNotifications(ctx context.Context, db *sql.DB, user uid.ID) ([]*Notification, error) {
+	query := msql.BuildSelectQuery("notifications", selectNotificationCols, nil, "WHERE user_id = ? ORDER BY seen ASC, updated_at DESC LIMIT 10")
+	rows, err := db.QueryContext(ctx, query, user)
+	if err != nil {
+		return nil, err
+	}
+	notifs, err := scanNotifications(db, rows)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return notifs, err
+}
+
 // NotificationsCount returns the number of notifications of user.
 func NotificationsCount(ctx context.Context, db *sql.DB, user uid.ID) (n int, err error) {
-	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM notifications WHERE user_id = ?", user).Scan(&n)
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM notifications WHERE user_id = ? AND in_app = TRUE", user).Scan(&n)
 	return
 }
 
@@ -377,14 +539,27 @@ func (n *Notification) Update(ctx context.Context) error {
 		log.Println("Failed incrementing users.notifications_new_count: ", err)
 	}
 
+	if n.inApp {
+		n.publishEvents(ctx)
+	}
 	n.SendPushNotification(ctx)
 	return nil
 }
 
-// SendPushNotification sends the notification to all matching sessions. Call
+// publishEvents publishes the notification, and the updated new notifications
+// count, to the notification's user as real-time events.
+func (n *Notification) publishEvents(ctx context.Context) {
+	publishEvent(UserEventsTopic(n.UserID), EventTypeNotification, n)
+	publishNotificationsCount(ctx, n.db, n.UserID)
+}
+
+// SendPushNotification sends the notification to all matching sessions, if the
+// user has not turned off push notifications of its type (or muted it). Call
 // EnablePushNotifications before any calls to this method.
 func (n *Notification) SendPushNotification(ctx context.Context) error {
-	if n.Type == NotificationTypeUpvote { // no push notifications for upvotes, for the moment
+	if channels, err := notificationChannels(ctx, n.db, n.UserID, n.Type, n.Notif); err != nil {
+		return err
+	} else if !channels.Push {
 		return nil
 	}
 
@@ -464,9 +639,6 @@ func CreateNewCommentNotification(ctx context.Context, db *sql.DB, post *Post, c
 	if err != nil {
 		return err
 	}
-	if user.ReplyNotificationsOff {
-		return nil
-	}
 
 	if muted, err := user.Muted(ctx, db, author.ID); err != nil {
 		return err
@@ -475,7 +647,7 @@ func CreateNewCommentNotification(ctx context.Context, db *sql.DB, post *Post, c
 	}
 
 	// Select last 10 notifications to see if an identical notification exists.
-	notifs, _, err := GetNotifications(ctx, db, post.AuthorID, 10, "")
+	notifs, err := lastNotifications(ctx, db, post.AuthorID)
 	if err != nil {
 		return err
 	}
@@ -544,9 +716,6 @@ func CreateCommentReplyNotification(ctx context.Context, db *sql.DB, receiver ui
 	if err != nil {
 		return err
 	}
-	if user.ReplyNotificationsOff {
-		return nil
-	}
 
 	if muted, err := user.Muted(ctx, db, author.ID); err != nil {
 		return err
@@ -555,7 +724,7 @@ func CreateCommentReplyNotification(ctx context.Context, db *sql.DB, receiver ui
 	}
 
 	// Select last 10 notifications to see if an identical notification exists.
-	notifs, _, err := GetNotifications(ctx, db, receiver, 10, "")
+	notifs, err := lastNotifications(ctx, db, receiver)
 	if err != nil {
 		return err
 	}
@@ -581,7 +750,7 @@ func CreateCommentReplyNotification(ctx context.Context, db *sql.DB, receiver ui
 }
 
 func updateNewNotificationsCount(ctx context.Context, db *sql.DB, user uid.ID) error {
-	_, err := db.ExecContext(ctx, "UPDATE users SET notifications_new_count = (SELECT COUNT(*) FROM notifications WHERE user_id = ? AND seen = FALSE) WHERE id = ?", user, user)
+	_, err := db.ExecContext(ctx, "UPDATE users SET notifications_new_count = (SELECT COUNT(*) FROM notifications WHERE user_id = ? AND in_app = TRUE AND seen = FALSE) WHERE id = ?", user, user)
 	return err
 }
 
@@ -651,19 +820,13 @@ func (n NotificationNewVotes) marshalJSONForAPI(ctx context.Context, db *sql.DB)
 
 // CreateNewVotesNotification creates a notification of type "new_votes".
 func CreateNewVotesNotification(ctx context.Context, db *sql.DB, user uid.ID, community string, isPost bool, targetID uid.ID) error {
-	if user, err := GetUser(ctx, db, user, nil); err != nil {
-		return err
-	} else if user.UpvoteNotificationsOff {
-		return nil
-	}
-
 	targetType := "post"
 	if !isPost {
 		targetType = "comment"
 	}
 
 	// Select last 10 notifications to see if an identical notification exists.
-	notifs, _, err := GetNotifications(ctx, db, user, 10, "")
+	notifs, err := lastNotifications(ctx, db, user)
 	if err != nil {
 		return err
 	}
@@ -690,6 +853,7 @@ type NotificationPostDeleted struct {
 	TargetType string    `json:"targetType"` // post or comment
 	TargetID   uid.ID    `json:"targetId"`
 	DeletedAs  UserGroup `json:"deletedAs"`
+	Reason     string    `json:"reason,omitempty"` // The reason given by the mods or the admins.
 }
 
 func (n NotificationPostDeleted) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
@@ -720,7 +884,7 @@ func (n NotificationPostDeleted) marshalJSONForAPI(ctx context.Context, db *sql.
 
 // CreatePostDeletedNotification creates a notification of type "deleted_post".
 // In actuall fact it may be a post or a comment.
-func CreatePostDeletedNotification(ctx context.Context, db *sql.DB, user uid.ID, deletedAs UserGroup, isPost bool, targetID uid.ID) error {
+func CreatePostDeletedNotification(ctx context.Context, db *sql.DB, user uid.ID, deletedAs UserGroup, isPost bool, targetID uid.ID, reason string) error {
 	targetType := "post"
 	if !isPost {
 		targetType = "comment"
@@ -730,6 +894,7 @@ func CreatePostDeletedNotification(ctx context.Context, db *sql.DB, user uid.ID,
 		TargetType: targetType,
 		TargetID:   targetID,
 		DeletedAs:  deletedAs,
+		Reason:     reason,
 	}
 	return CreateNotification(ctx, db, user, NotificationTypeDeletePost, n)
 }
@@ -765,6 +930,372 @@ func CreateNewModAddNotification(ctx context.Context, db *sql.DB, user uid.ID, c
 	return CreateNotification(ctx, db, user, NotificationTypeModAdd, n)
 }
 
+// NotificationCommunityInvite is sent when a user is invited to join a
+// restricted or private community.
+type NotificationCommunityInvite struct {
+	CommunityName string `json:"communityName"`
+	InvitedBy     string `json:"invitedBy"`
+}
+
+func (n NotificationCommunityInvite) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	type T NotificationCommunityInvite
+	out := struct {
+		T
+		Community *Community `json:"community"`
+	}{
+		T: (T)(n),
+	}
+
+	c, err := GetCommunityByName(ctx, db, n.CommunityName, nil)
+	if err != nil {
+		return nil, err
+	}
+	out.Community = c
+	return json.Marshal(out)
+}
+
+func CreateCommunityInviteNotification(ctx context.Context, db *sql.DB, user uid.ID, community, invitedBy string) error {
+	n := NotificationCommunityInvite{
+		CommunityName: community,
+		InvitedBy:     invitedBy,
+	}
+	return CreateNotification(ctx, db, user, NotificationTypeCommunityInvite, n)
+}
+
+// NotificationAutomodMessage is sent to the author of a post or a comment
+// matched by an automod rule with a notify action.
+type NotificationAutomodMessage struct {
+	CommunityName string `json:"communityName"`
+	Message       string `json:"message"`
+	TargetType    string `json:"targetType"` // post or comment
+	TargetID      uid.ID `json:"targetId"`
+}
+
+func (n NotificationAutomodMessage) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	type T NotificationAutomodMessage
+	out := struct {
+		T
+		Post    *Post    `json:"post,omitempty"`
+		Comment *Comment `json:"comment,omitempty"`
+	}{
+		T: (T)(n),
+	}
+
+	if n.TargetType == "post" {
+		post, err := GetPost(ctx, db, &n.TargetID, "", nil, true)
+		if err != nil {
+			return nil, err
+		}
+		out.Post = post
+	} else {
+		comment, err := GetComment(ctx, db, n.TargetID, nil)
+		if err != nil {
+			return nil, err
+		}
+		out.Comment = comment
+	}
+	return json.Marshal(out)
+}
+
+func CreateAutomodMessageNotification(ctx context.Context, db *sql.DB, user uid.ID, community, message string, isPost bool, targetID uid.ID) error {
+	targetType := "post"
+	if !isPost {
+		targetType = "comment"
+	}
+
+	n := NotificationAutomodMessage{
+		CommunityName: community,
+		Message:       message,
+		TargetType:    targetType,
+		TargetID:      targetID,
+	}
+	return CreateNotification(ctx, db, user, NotificationTypeAutomodMessage, n)
+}
+
+// NotificationBanAppeal is sent to a banned user when their ban appeal is
+// reviewed.
+type NotificationBanAppeal struct {
+	CommunityName string          `json:"communityName,omitempty"` // Empty for site bans.
+	Status        BanAppealStatus `json:"status"`
+	Message       string          `json:"message,omitempty"`
+	Expires       *time.Time      `json:"expires,omitempty"` // The new expiry of a shortened ban.
+}
+
+func (n NotificationBanAppeal) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	type T NotificationBanAppeal
+	out := struct {
+		T
+		Community *Community `json:"community,omitempty"`
+	}{
+		T: (T)(n),
+	}
+
+	if n.CommunityName != "" {
+		c, err := GetCommunityByName(ctx, db, n.CommunityName, nil)
+		if err != nil {
+			return nil, err
+		}
+		out.Community = c
+	}
+	return json.Marshal(out)
+}
+
+// CreateBanAppealNotification notifies the user of appeal of the outcome of
+// its review.
+func CreateBanAppealNotification(ctx context.Context, db *sql.DB, appeal *BanAppeal) error {
+	n := NotificationBanAppeal{
+		CommunityName: appeal.CommunityName.String,
+		Status:        appeal.Status,
+		Message:       appeal.Response.String,
+	}
+	if appeal.Status == BanAppealStatusShortened && appeal.BanExpires.Valid {
+		n.Expires = &appeal.BanExpires.Time
+	}
+	return CreateNotification(ctx, db, appeal.UserID, NotificationTypeBanAppeal, n)
+}
+
+// NotificationBanExpired is sent to a user when their ban from a community, or
+// their site ban, expires.
+type NotificationBanExpired struct {
+	CommunityName string `json:"communityName,omitempty"` // Empty for site bans.
+}
+
+func (n NotificationBanExpired) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	type T NotificationBanExpired
+	out := struct {
+		T
+		Community *Community `json:"community,omitempty"`
+	}{
+		T: (T)(n),
+	}
+
+	if n.CommunityName != "" {
+		c, err := GetCommunityByName(ctx, db, n.CommunityName, nil)
+		if err != nil {
+			return nil, err
+		}
+		out.Community = c
+	}
+	return json.Marshal(out)
+}
+
+// CreateBanExpiredNotification notifies user that their ban from community
+// (or, if community is empty, their site ban) expired.
+func CreateBanExpiredNotification(ctx context.Context, db *sql.DB, user uid.ID, community string) error {
+	n := NotificationBanExpired{CommunityName: community}
+	return CreateNotification(ctx, db, user, NotificationTypeBanExpired, n)
+}
+
+// NotificationCommunityBan is sent to a user when they're banned from a
+// community.
+type NotificationCommunityBan struct {
+	CommunityName string     `json:"communityName"`
+	Expires       *time.Time `json:"expires,omitempty"` // Nil for permanent bans.
+}
+
+func (n NotificationCommunityBan) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	type T NotificationCommunityBan
+	out := struct {
+		T
+		Community *Community `json:"community"`
+	}{
+		T: (T)(n),
+	}
+
+	c, err := GetCommunityByName(ctx, db, n.CommunityName, nil)
+	if err != nil {
+		return nil, err
+	}
+	out.Community = c
+	return json.Marshal(out)
+}
+
+// CreateCommunityBanNotification notifies user that they were banned from
+// community until expires (or, if expires is nil, permanently).
+func CreateCommunityBanNotification(ctx context.Context, db *sql.DB, user uid.ID, community string, expires *time.Time) error {
+	n := NotificationCommunityBan{CommunityName: community, Expires: expires}
+	return CreateNotification(ctx, db, user, NotificationTypeCommunityBan, n)
+}
+
+// NotificationModmail is sent to the user of a modmail thread when the mods
+// reply to it, and to the mods when the user does.
+type NotificationModmail struct {
+	ThreadID      uid.ID `json:"threadId"`
+	CommunityName string `json:"communityName"`
+	Subject       string `json:"subject"`
+	ToMods        bool   `json:"toMods"` // If true, the receiver is a mod.
+	NumMessages   int    `json:"noMessages"`
+}
+
+func (n NotificationModmail) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	return json.Marshal(n)
+}
+
+// CreateModmailNotification creates a notification of type "modmail". If an
+// unseen notification of the same thread exists in the last 10 items, that
+// notification is updated instead.
+func CreateModmailNotification(ctx context.Context, db *sql.DB, receiver uid.ID, thread *ModmailThread, toMods bool) error {
+	notifs, err := lastNotifications(ctx, db, receiver)
+	if err != nil {
+		return err
+	}
+	for _, notif := range notifs {
+		if notif.Type == NotificationTypeModmail {
+			nm := notif.Notif.(*NotificationModmail)
+			if nm.ThreadID.EqualsTo(thread.ID) && !notif.Seen {
+				nm.NumMessages++
+				return notif.Update(ctx)
+			}
+		}
+	}
+
+	n := NotificationModmail{
+		ThreadID:      thread.ID,
+		CommunityName: thread.CommunityName,
+		Subject:       thread.Subject,
+		ToMods:        toMods,
+		NumMessages:   1,
+	}
+	return CreateNotification(ctx, db, receiver, NotificationTypeModmail, n)
+}
+
+// NotificationModTransfer is sent to a mod when they're offered to become the
+// top mod of a community.
+type NotificationModTransfer struct {
+	CommunityName string `json:"communityName"`
+	OfferedBy     string `json:"offeredBy"`
+}
+
+func (n NotificationModTransfer) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	type T NotificationModTransfer
+	out := struct {
+		T
+		Community *Community `json:"community"`
+	}{
+		T: (T)(n),
+	}
+
+	c, err := GetCommunityByName(ctx, db, n.CommunityName, nil)
+	if err != nil {
+		return nil, err
+	}
+	out.Community = c
+	return json.Marshal(out)
+}
+
+// CreateModTransferNotification notifies the receiver of transfer of the
+// offer.
+func CreateModTransferNotification(ctx context.Context, db *sql.DB, transfer *ModTransfer) error {
+	n := NotificationModTransfer{
+		CommunityName: transfer.CommunityName,
+		OfferedBy:     transfer.FromUsername,
+	}
+	return CreateNotification(ctx, db, transfer.ToUserID, NotificationTypeModTransfer, n)
+}
+
+// NotificationTakeover is sent to a user when their request to take over a
+// community is reviewed.
+type NotificationTakeover struct {
+	CommunityName string                `json:"communityName"`
+	Status        TakeoverRequestStatus `json:"status"`
+	Message       string                `json:"message,omitempty"`
+}
+
+func (n NotificationTakeover) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	type T NotificationTakeover
+	out := struct {
+		T
+		Community *Community `json:"community"`
+	}{
+		T: (T)(n),
+	}
+
+	c, err := GetCommunityByName(ctx, db, n.CommunityName, nil)
+	if err != nil {
+		return nil, err
+	}
+	out.Community = c
+	return json.Marshal(out)
+}
+
+// CreateTakeoverRequestNotification notifies the user of request of the
+// outcome of its review.
+func CreateTakeoverRequestNotification(ctx context.Context, db *sql.DB, request *TakeoverRequest) error {
+	n := NotificationTakeover{
+		CommunityName: request.CommunityName,
+		Status:        request.Status,
+		Message:       request.Response.String,
+	}
+	return CreateNotification(ctx, db, request.UserID, NotificationTypeTakeover, n)
+}
+
+// NotificationCommunityRename is sent to the members of a community when the
+// community is renamed.
+type NotificationCommunityRename struct {
+	CommunityName string `json:"communityName"` // The new name.
+	OldName       string `json:"oldName"`
+}
+
+func (n NotificationCommunityRename) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	type T NotificationCommunityRename
+	out := struct {
+		T
+		Community *Community `json:"community"`
+	}{
+		T: (T)(n),
+	}
+
+	c, err := GetCommunityByName(ctx, db, n.CommunityName, nil)
+	if err != nil {
+		return nil, err
+	}
+	out.Community = c
+	return json.Marshal(out)
+}
+
+// CreateCommunityRenameNotification notifies user that community oldName is
+// now called newName.
+func CreateCommunityRenameNotification(ctx context.Context, db *sql.DB, user uid.ID, oldName, newName string) error {
+	n := NotificationCommunityRename{CommunityName: newName, OldName: oldName}
+	return CreateNotification(ctx, db, user, NotificationTypeCommunityRename, n)
+}
+
+// NotificationRenameRejected is sent to a mod when their request to rename a
+// community is rejected.
+type NotificationRenameRejected struct {
+	CommunityName string `json:"communityName"`
+	NewName       string `json:"newName"` // The requested name.
+	Message       string `json:"message,omitempty"`
+}
+
+func (n NotificationRenameRejected) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	type T NotificationRenameRejected
+	out := struct {
+		T
+		Community *Community `json:"community"`
+	}{
+		T: (T)(n),
+	}
+
+	c, err := GetCommunityByName(ctx, db, n.CommunityName, nil)
+	if err != nil {
+		return nil, err
+	}
+	out.Community = c
+	return json.Marshal(out)
+}
+
+// CreateRenameRejectedNotification notifies the user of request that the
+// request was rejected.
+func CreateRenameRejectedNotification(ctx context.Context, db *sql.DB, request *RenameRequest) error {
+	n := NotificationRenameRejected{
+		CommunityName: request.CommunityName,
+		NewName:       request.NewName,
+		Message:       request.Response.String,
+	}
+	return CreateNotification(ctx, db, request.UserID, NotificationTypeRenameRejected, n)
+}
+
 // VAPIDKeys is an application server key-pair used by the Web Push API.
 type VAPIDKeys struct {
 	Public  string `json:"public"`
@@ -944,3 +1475,246 @@ func CreateNewBadgeNotification(ctx context.Context, db *sql.DB, user uid.ID, ba
 	}
 	return CreateNotification(ctx, db, user, NotificationTypeNewBadge, n)
 }
+
+// NotificationMention is sent when a user is mentioned (with @username) in a
+// post or a comment.
+type NotificationMention struct {
+	TargetType  string `json:"targetType"` // post or comment
+	TargetID    uid.ID `json:"targetId"`
+	PostID      uid.ID `json:"postId"`
+	MentionedBy string `json:"mentionedBy"`
+}
+
+func (n NotificationMention) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	type T NotificationMention
+	out := struct {
+		T
+		Post    *Post    `json:"post"`
+		Comment *Comment `json:"comment,omitempty"`
+	}{
+		T: (T)(n),
+	}
+
+	post, err := GetPost(ctx, db, &n.PostID, "", nil, true)
+	if err != nil {
+		return nil, err
+	}
+	out.Post = post
+
+	if n.TargetType == "comment" {
+		comment, err := GetComment(ctx, db, n.TargetID, nil)
+		if err != nil {
+			return nil, err
+		}
+		out.Comment = comment
+	}
+	return json.Marshal(out)
+}
+
+// CreateMentionNotification creates a notification of type "mention" for
+// receiver. If comment is nil, the mention is in the body of post. No
+// notification is created if receiver has turned off mention notifications, if
+// either of the two users has muted the other, or if receiver is banned (from
+// the site or from the post's community), or if receiver cannot view the
+// post's community.
+func CreateMentionNotification(ctx context.Context, db *sql.DB, receiver uid.ID, post *Post, comment *uid.ID, author *User) error {
+	user, err := GetUser(ctx, db, receiver, nil)
+	if err != nil {
+		return err
+	}
+	if user.Deleted || user.Banned {
+		return nil
+	}
+
+	if muted, err := user.Muted(ctx, db, author.ID); err != nil {
+		return err
+	} else if muted {
+		return nil
+	}
+	if muted, err := user.MutedBy(ctx, db, author.ID); err != nil {
+		return err
+	} else if muted {
+		return nil
+	}
+
+	if banned, err := IsUserBannedFromCommunity(ctx, db, post.CommunityID, user.ID); err != nil {
+		return err
+	} else if banned {
+		return nil
+	}
+	if viewable, err := CommunityViewableBy(ctx, db, post.CommunityID, &receiver); err != nil {
+		return err
+	} else if !viewable {
+		return nil
+	}
+
+	n := NotificationMention{
+		TargetType:  "post",
+		TargetID:    post.ID,
+		PostID:      post.ID,
+		MentionedBy: author.Username,
+	}
+	if comment != nil {
+		n.TargetType = "comment"
+		n.TargetID = *comment
+	}
+	return CreateNotification(ctx, db, receiver, NotificationTypeMention, n)
+}
+
+// NotificationNewMessage is sent to the members of a conversation when a new
+// message is added to it.
+type NotificationNewMessage struct {
+	ConversationID uid.ID `json:"conversationId"`
+
+	// If NumMessages > 1, MessageID and Sender are that of the first message.
+	MessageID   uid.ID `json:"messageId"`
+	Sender      string `json:"sender"`
+	NumMessages int    `json:"noMessages"`
+}
+
+func (n NotificationNewMessage) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	return json.Marshal(n)
+}
+
+// CreateNewMessageNotification creates a notification of type "new_message".
+// If an unseen notification of the same conversation exists in the last 10
+// items, that notification is updated instead.
+func CreateNewMessageNotification(ctx context.Context, db *sql.DB, receiver uid.ID, message *Message) error {
+	if muted, err := UserMuted(ctx, db, receiver, message.AuthorID); err != nil {
+		return err
+	} else if muted {
+		return nil
+	}
+
+	// Select last 10 notifications to see if an identical notification exists.
+	notifs, err := lastNotifications(ctx, db, receiver)
+	if err != nil {
+		return err
+	}
+	for _, notif := range notifs {
+		if notif.Type == NotificationTypeNewMessage {
+			nm := notif.Notif.(*NotificationNewMessage)
+			if nm.ConversationID.EqualsTo(message.ConversationID) && !notif.Seen {
+				nm.NumMessages++
+				return notif.Update(ctx)
+			}
+		}
+	}
+
+	n := NotificationNewMessage{
+		ConversationID: message.ConversationID,
+		MessageID:      message.ID,
+		Sender:         message.AuthorUsername,
+		NumMessages:    1,
+	}
+	return CreateNotification(ctx, db, receiver, NotificationTypeNewMessage, n)
+}
+
+// NotificationThreadComment is sent to the followers of a post, or of a
+// comment's replies, when a comment is added to it.
+type NotificationThreadComment struct {
+	PostID uid.ID `json:"postId"`
+
+	// The followed comment. If null, the followed thread is the whole post.
+	ThreadID uid.NullID `json:"threadId"`
+
+	// If NumComments > 1, CommentID and CommentAuthor are that of the first
+	// new comment.
+	CommentID     uid.ID `json:"commentId"`
+	CommentAuthor string `json:"commentAuthor"`
+	NumComments   int    `json:"noComments"`
+
+	FirstCreatedAt time.Time `json:"firstCreatedAt"`
+}
+
+func (n NotificationThreadComment) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	type T NotificationThreadComment
+	out := struct {
+		T
+		Post *Post `json:"post"`
+	}{
+		T: (T)(n),
+	}
+
+	post, err := GetPost(ctx, db, &n.PostID, "", nil, true)
+	if err != nil {
+		return nil, err
+	}
+	out.Post = post
+	return json.Marshal(out)
+}
+
+// CreateThreadCommentNotification creates a notification of type
+// thread_comment. If an unseen notification of the same thread exists in the
+// last 10 notifications, it's updated instead. No notification is created if
+// receiver cannot view the post's community.
+func CreateThreadCommentNotification(ctx context.Context, db *sql.DB, receiver uid.ID, post *Post, thread uid.NullID, comment uid.ID, author *User) error {
+	user, err := GetUser(ctx, db, receiver, nil)
+	if err != nil {
+		return err
+	}
+	if user.Deleted || user.Banned {
+		return nil
+	}
+
+	if muted, err := user.Muted(ctx, db, author.ID); err != nil {
+		return err
+	} else if muted {
+		return nil
+	}
+	if viewable, err := CommunityViewableBy(ctx, db, post.CommunityID, &receiver); err != nil {
+		return err
+	} else if !viewable {
+		return nil
+	}
+
+	// Select last 10 notifications to see if an identical notification exists.
+	notifs, err := lastNotifications(ctx, db, receiver)
+	if err != nil {
+		return err
+	}
+	for _, notif := range notifs {
+		if notif.Type == NotificationTypeThreadComment {
+			tc := notif.Notif.(*NotificationThreadComment)
+			if tc.PostID.EqualsTo(post.ID) && tc.ThreadID == thread && !notif.Seen { // identical found
+				tc.NumComments++
+				return notif.Update(ctx)
+			}
+		}
+	}
+
+	n := NotificationThreadComment{
+		PostID:         post.ID,
+		ThreadID:       thread,
+		CommentID:      comment,
+		CommentAuthor:  author.Username,
+		NumComments:    1,
+		FirstCreatedAt: time.Now(),
+	}
+	return CreateNotification(ctx, db, receiver, NotificationTypeThreadComment, n)
+}
+
+// NotificationNewPost is sent to the followers of a user (who opted in) when
+// the user submits a new post.
+type NotificationNewPost struct {
+	PostID      uid.ID    `json:"postId"`
+	Author      string    `json:"author"`
+	SubmittedAt time.Time `json:"submittedAt"`
+}
+
+func (n NotificationNewPost) marshalJSONForAPI(ctx context.Context, db *sql.DB) ([]byte, error) {
+	type T NotificationNewPost
+	out := struct {
+		T
+		Post *Post `json:"post"`
+	}{
+		T: (T)(n),
+	}
+
+	post, err := GetPost(ctx, db, &n.PostID, "", nil, true)
+	if err != nil {
+		return nil, err
+	}
+	out.Post = post
+	return json.Marshal(out)
+}
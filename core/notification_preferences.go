@@ -0,0 +1,242 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// NotificationChannels holds whether a notification is delivered through each
+// of the channels through which notifications are delivered.
+type NotificationChannels struct {
+	InApp bool `json:"inApp"` // The notifications list (and real-time events).
+	Push  bool `json:"push"`  // Web push notifications.
+	Email bool `json:"email"` // Email digests.
+}
+
+// none reports whether the notification is delivered through no channel.
+func (c NotificationChannels) none() bool {
+	return !c.InApp && !c.Push && !c.Email
+}
+
+// defaultNotificationChannels returns the channels of notifications of type t
+// of users who have not changed the defaults.
+func defaultNotificationChannels(t NotificationType) NotificationChannels {
+	c := NotificationChannels{InApp: true, Push: true, Email: true}
+	if t == NotificationTypeUpvote {
+		c.Push = false // too noisy
+	}
+	return c
+}
+
+// NotificationPreferences is a user's preference matrix of notification types
+// against notification channels.
+type NotificationPreferences map[NotificationType]NotificationChannels
+
+// GetNotificationPreferences returns the notification preferences of user. The
+// returned map contains all notification types.
+func GetNotificationPreferences(ctx context.Context, db *sql.DB, user uid.ID) (NotificationPreferences, error) {
+	prefs := make(NotificationPreferences)
+	for _, t := range notificationTypes {
+		prefs[t] = defaultNotificationChannels(t)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT type, in_app, push, email FROM notification_preferences WHERE user_id = ?", user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			t NotificationType
+			c NotificationChannels
+		)
+		if err := rows.Scan(&t, &c.InApp, &c.Push, &c.Email); err != nil {
+			return nil, err
+		}
+		if t.Valid() {
+			prefs[t] = c
+		}
+	}
+	return prefs, rows.Err()
+}
+
+// SaveNotificationPreferences saves the preferences of the notification types
+// in prefs. Notification types not in prefs are left unchanged.
+func SaveNotificationPreferences(ctx context.Context, db *sql.DB, user uid.ID, prefs NotificationPreferences) error {
+	for t := range prefs {
+		if !t.Valid() {
+			return httperr.NewBadRequest("invalid_notif_type", "Invalid notification type.")
+		}
+	}
+	return msql.Transact(ctx, db, func(tx *sql.Tx) error {
+		for t, c := range prefs {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO notification_preferences (user_id, type, in_app, push, email) VALUES (?, ?, ?, ?, ?)
+				ON DUPLICATE KEY UPDATE in_app = VALUES(in_app), push = VALUES(push), email = VALUES(email)`,
+				user, t, c.InApp, c.Push, c.Email); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// postNotification is implemented by notifications that are about a post.
+// Such notifications can be muted per post and per community.
+type postNotification interface {
+	notificationPost(ctx context.Context, db *sql.DB) (uid.ID, error)
+}
+
+func (n NotificationNewComment) notificationPost(ctx context.Context, db *sql.DB) (uid.ID, error) {
+	return n.PostID, nil
+}
+
+func (n NotificationCommentReply) notificationPost(ctx context.Context, db *sql.DB) (uid.ID, error) {
+	return n.PostID, nil
+}
+
+func (n NotificationThreadComment) notificationPost(ctx context.Context, db *sql.DB) (uid.ID, error) {
+	return n.PostID, nil
+}
+
+func (n NotificationNewPost) notificationPost(ctx context.Context, db *sql.DB) (uid.ID, error) {
+	return n.PostID, nil
+}
+
+func (n NotificationMention) notificationPost(ctx context.Context, db *sql.DB) (uid.ID, error) {
+	return n.PostID, nil
+}
+
+func (n NotificationNewVotes) notificationPost(ctx context.Context, db *sql.DB) (uid.ID, error) {
+	if n.TargetType == "post" {
+		return n.TargetID, nil
+	}
+	var post uid.ID
+	err := db.QueryRowContext(ctx, "SELECT post_id FROM comments WHERE id = ?", n.TargetID).Scan(&post)
+	return post, err
+}
+
+// notificationChannels returns the channels through which notif, of type t,
+// is to be delivered to user. It takes into account both the user's
+// preferences and their per-post and per-community mutes.
+func notificationChannels(ctx context.Context, db *sql.DB, user uid.ID, t NotificationType, notif notification) (NotificationChannels, error) {
+	c := defaultNotificationChannels(t)
+	err := db.QueryRowContext(ctx, "SELECT in_app, push, email FROM notification_preferences WHERE user_id = ? AND type = ?", user, t).Scan(&c.InApp, &c.Push, &c.Email)
+	if err != nil && err != sql.ErrNoRows {
+		return c, err
+	}
+	if c.none() {
+		return c, nil
+	}
+
+	if pn, ok := notif.(postNotification); ok {
+		post, err := pn.notificationPost(ctx, db)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return c, nil
+			}
+			return c, err
+		}
+		var muted bool
+		if err := db.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM notification_mutes
+				WHERE user_id = ? AND (post_id = ? OR community_id = (SELECT community_id FROM posts WHERE id = ?))
+			)`, user, post, post).Scan(&muted); err != nil {
+			return c, err
+		}
+		if muted {
+			return NotificationChannels{}, nil
+		}
+	}
+	return c, nil
+}
+
+// NotificationMutes are the posts and the communities from which a user has
+// muted all notifications.
+type NotificationMutes struct {
+	Posts       []*Post      `json:"posts"`
+	Communities []*Community `json:"communities"`
+}
+
+// GetNotificationMutes returns the posts and the communities that user has
+// muted notifications from.
+func GetNotificationMutes(ctx context.Context, db *sql.DB, user uid.ID) (*NotificationMutes, error) {
+	rows, err := db.QueryContext(ctx, "SELECT post_id, community_id FROM notification_mutes WHERE user_id = ? ORDER BY created_at DESC", user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var postIDs, communityIDs []uid.ID
+	for rows.Next() {
+		var post, community uid.NullID
+		if err := rows.Scan(&post, &community); err != nil {
+			return nil, err
+		}
+		if post.Valid {
+			postIDs = append(postIDs, post.ID)
+		}
+		if community.Valid {
+			communityIDs = append(communityIDs, community.ID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	mutes := &NotificationMutes{
+		Posts:       []*Post{},
+		Communities: []*Community{},
+	}
+	if posts, err := GetPostsByIDs(ctx, db, &user, false, postIDs...); err != nil && err != errPostNotFound {
+		return nil, err
+	} else if posts != nil {
+		mutes.Posts = posts
+	}
+	if comms, err := GetCommunitiesByIDs(ctx, db, communityIDs, &user); err != nil && err != errCommunityNotFound {
+		return nil, err
+	} else if comms != nil {
+		mutes.Communities = comms
+	}
+	return mutes, nil
+}
+
+// MutePostNotifications mutes (or unmutes) all notifications about post for
+// user.
+func MutePostNotifications(ctx context.Context, db *sql.DB, user, post uid.ID, mute bool) error {
+	if !mute {
+		_, err := db.ExecContext(ctx, "DELETE FROM notification_mutes WHERE user_id = ? AND post_id = ?", user, post)
+		return err
+	}
+	if _, err := GetPost(ctx, db, &post, "", nil, false); err != nil {
+		return err
+	}
+	_, err := db.ExecContext(ctx, "INSERT INTO notification_mutes (user_id, post_id) VALUES (?, ?)", user, post)
+	if err != nil && msql.IsErrDuplicateErr(err) {
+		return nil // already muted
+	}
+	return err
+}
+
+// MuteCommunityNotifications mutes (or unmutes) all notifications about posts
+// in community for user.
+func MuteCommunityNotifications(ctx context.Context, db *sql.DB, user, community uid.ID, mute bool) error {
+	if !mute {
+		_, err := db.ExecContext(ctx, "DELETE FROM notification_mutes WHERE user_id = ? AND community_id = ?", user, community)
+		return err
+	}
+	if _, err := GetCommunityByID(ctx, db, community, nil); err != nil {
+		return err
+	}
+	_, err := db.ExecContext(ctx, "INSERT INTO notification_mutes (user_id, community_id) VALUES (?, ?)", user, community)
+	if err != nil && msql.IsErrDuplicateErr(err) {
+		return nil // already muted
+	}
+	return err
+}
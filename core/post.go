@@ -99,6 +99,9 @@ type Post struct {
 	// Indicates Whether the account of the user who posted the post is deleted.
 	AuthorDeleted bool `json:"userDeleted"`
 
+	// Whether the user who posted the post is shadowbanned.
+	authorShadowbanned bool
+
 	// Indicates whether the post is pinned to the community.
 	Pinned bool `json:"isPinned"`
 
@@ -163,6 +166,24 @@ type Post struct {
 	AuthorMutedByViewer    bool `json:"isAuthorMuted"`
 	CommunityMutedByViewer bool `json:"isCommunityMuted"`
 
+	// The action to take on the post if it's matched by the content filters of
+	// the logged in user.
+	ViewerFiltered ContentFilterAction `json:"filtered,omitempty"`
+
+	// Whether the logged in user has read (seen) the post. Only set if the
+	// user has read tracking turned on.
+	ViewerRead bool `json:"read,omitempty"`
+
+	// The time the logged in user last opened the post page, and the number
+	// of comments posted since then. Set by RecordViewerVisit.
+	ViewerLastVisitedAt *time.Time `json:"lastVisitedAt,omitempty"`
+	ViewerNewComments   int        `json:"newComments,omitempty"`
+
+	// Whether the logged in user follows the post, and the comments of the
+	// post whose replies the user follows. Set by FetchViewerFollows.
+	ViewerFollowing        bool     `json:"following"`
+	ViewerFollowedComments []uid.ID `json:"followedComments,omitempty"`
+
 	Community *Community `json:"community,omitempty"`
 	Author    *User      `json:"author,omitempty"`
 }
@@ -203,6 +224,7 @@ var selectPostCols = []string{
 	"posts.deleted_content_at",
 	"posts.deleted_content_by",
 	"posts.deleted_content_as",
+	"users.shadowbanned_at IS NOT NULL",
 }
 
 var selectPostJoins = []string{
@@ -222,7 +244,7 @@ func init() {
 func buildSelectPostQuery(loggedIn bool, where string) string {
 	if loggedIn {
 		joins := append(selectPostJoins, "LEFT OUTER JOIN post_votes ON posts.id = post_votes.post_id AND post_votes.user_id = ?")
-		cols := append(selectPostCols, "post_votes.id IS NOT NULL", "post_votes.up")
+		cols := append(selectPostCols, "post_votes.id IS NOT NULL", "post_votes.up", "IFNULL(post_votes.shadowbanned, FALSE)")
 		return msql.BuildSelectQuery("posts", cols, joins, where)
 	}
 	return msql.BuildSelectQuery("posts", selectPostCols, selectPostJoins, where)
@@ -314,7 +336,14 @@ func GetPostsByIDs(ctx context.Context, db *sql.DB, viewer *uid.ID, includeDelet
 		return nil, fmt.Errorf("db error on query '%s' with args (%v)", query, args)
 	}
 
-	return scanPosts(ctx, db, rows, viewer)
+	posts, err := scanPosts(ctx, db, rows, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if viewer == nil {
+		posts = removeShadowbannedPosts(posts, nil)
+	}
+	return posts, nil
 }
 
 // scanPosts returns errPostNotFound is no posts are found.
@@ -366,6 +395,7 @@ func scanPosts(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *uid.ID)
 			&post.DeletedContentAt,
 			&post.DeletedContentBy,
 			&post.DeletedContentAs,
+			&post.authorShadowbanned,
 		}
 
 		linkImage := &images.Image{}
@@ -374,14 +404,27 @@ func scanPosts(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *uid.ID)
 		dest = append(dest, linkImage.ScanDestinations()...)
 		dest = append(dest, proPic.ScanDestinations()...)
 		dest = append(dest, bannerImage.ScanDestinations()...)
+		var viewerVoteShadowbanned bool
 		if loggedIn {
-			dest = append(dest, &post.ViewerVoted, &post.ViewerVotedUp)
+			dest = append(dest, &post.ViewerVoted, &post.ViewerVotedUp, &viewerVoteShadowbanned)
 		}
 
 		if err := rows.Scan(dest...); err != nil {
 			return nil, fmt.Errorf("scanning post rows.Scan: %w", err)
 		}
 
+		// The viewer's vote is not counted if it was cast while the viewer was
+		// shadowbanned. Count it for the viewer alone.
+		if viewerVoteShadowbanned {
+			if post.ViewerVotedUp.Bool {
+				post.Upvotes++
+				post.Points++
+			} else {
+				post.Downvotes++
+				post.Points--
+			}
+		}
+
 		if proPic.ID != nil {
 			proPic.PostScan()
 			setCommunityProPicCopies(proPic)
@@ -412,6 +455,14 @@ func scanPosts(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *uid.ID)
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("scanning post rows.Err: %w", err)
 	}
+
+	viewerAdmin, err := IsAdmin(db, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if viewer != nil && !viewerAdmin {
+		posts = removeShadowbannedPosts(posts, viewer)
+	}
 	if len(posts) == 0 {
 		return nil, errPostNotFound
 	}
@@ -438,17 +489,18 @@ func scanPosts(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *uid.ID)
 				}
 			}
 		}
+		if err := setFilteredPosts(ctx, db, *viewer, posts); err != nil {
+			return nil, err
+		}
+		if err := setReadPosts(ctx, db, *viewer, posts); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := populatePostsImages(ctx, db, posts); err != nil {
 		return nil, err
 	}
 
-	viewerAdmin, err := IsAdmin(db, viewer)
-	if err != nil {
-		return nil, err
-	}
-
 	if err := populatePostAuthors(ctx, db, posts, viewerAdmin); err != nil {
 		return nil, fmt.Errorf("failed to populate post authors: %w", err)
 	}
@@ -617,6 +669,12 @@ func createPost(ctx context.Context, db *sql.DB, opts *createPostOpts) (*Post, e
 	} else if is {
 		return nil, errUserBannedFromCommunity
 	}
+	if err := checkCanParticipate(ctx, db, opts.community, opts.author); err != nil {
+		return nil, err
+	}
+	if err := checkPostingRequirements(ctx, db, opts.community, opts.author, true, opts.postType, opts.title, opts.body); err != nil {
+		return nil, err
+	}
 
 	// Truncate title and body if max lengths are exceeded.
 	var post Post
@@ -722,11 +780,28 @@ func createPost(ctx context.Context, db *sql.DB, opts *createPostOpts) (*Post, e
 		return nil, err
 	}
 
+	// Authors follow their posts by default.
+	if _, err := tx.ExecContext(ctx, "INSERT INTO thread_follows (user_id, post_id, target_id) VALUES (?, ?, ?)", opts.author, post.ID, post.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
 
-	return GetPost(ctx, db, &post.ID, "", nil, false)
+	created, err := GetPost(ctx, db, &post.ID, "", nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if mentions := extractMentions(post.Body.String); len(mentions) > 0 {
+		go sendMentionNotifications(context.Background(), db, mentions, created, nil, opts.author)
+	}
+	go sendNewPostNotifications(context.Background(), db, created)
+	go runAutomodOnPost(db, AutomodEventCreate, created.ID)
+
+	return created, nil
 }
 
 func CreateTextPost(ctx context.Context, db *sql.DB, author, community uid.ID, title string, body string) (*Post, error) {
@@ -863,6 +938,9 @@ func (p *Post) Save(ctx context.Context, user uid.ID) error {
 	if !p.AuthorID.EqualsTo(user) {
 		return errNotAuthor
 	}
+	if err := checkCommunityWritable(ctx, p.db, p.CommunityID); err != nil {
+		return err
+	}
 
 	if err := validatePost(p.Title, p.Body.String); err != nil {
 		return err
@@ -870,23 +948,41 @@ func (p *Post) Save(ctx context.Context, user uid.ID) error {
 
 	p.truncateTitleAndBody()
 
+	updateBody := p.Type == PostTypeText && !p.DeletedContent
+	var oldBody msql.NullString
+	if updateBody {
+		if err := p.db.QueryRowContext(ctx, "SELECT body FROM posts WHERE id = ?", p.ID).Scan(&oldBody); err != nil {
+			return err
+		}
+	}
+
 	now := time.Now()
 	var args []any
 	query := "UPDATE posts SET title = ?"
 	args = append(args, p.Title)
-	if p.Type == PostTypeText && !p.DeletedContent {
+	if updateBody {
 		query += ", body = ?"
 		args = append(args, p.Body)
 	}
 	query += ", edited_at = ? WHERE id = ?"
 	args = append(args, now, p.ID)
 
-	_, err := p.db.ExecContext(ctx, query, args...)
-	if err == nil {
-		p.EditedAt.Valid = true
-		p.EditedAt.Time = now
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return err
 	}
-	return err
+	p.EditedAt.Valid = true
+	p.EditedAt.Time = now
+
+	if updateBody {
+		if mentions := newMentions(oldBody.String, p.Body.String); len(mentions) > 0 {
+			go sendMentionNotifications(context.Background(), p.db, mentions, p, nil, p.AuthorID)
+		}
+	}
+	if err := queueIfReported(ctx, p.db, p.CommunityID, ContentTypePost, p.ID, p.ID); err != nil {
+		return err
+	}
+	go runAutomodOnPost(p.db, AutomodEventEdit, p.ID)
+	return nil
 }
 
 // StripAuthorInfo should be called if the author account of the post is deleted
@@ -908,8 +1004,9 @@ func (p *Post) setGhostAuthorID() {
 
 // Delete deletes p on behalf of user, who's deleting the post in his capacity
 // as g. In case the post is deleted by an admin or a mod, a notification is
-// sent to the original poster.
-func (p *Post) Delete(ctx context.Context, user uid.ID, g UserGroup, deleteContent bool, sendNotif bool) error {
+// sent to the original poster. The removal, if not nil, is the reason given by
+// the admin or the mod.
+func (p *Post) Delete(ctx context.Context, user uid.ID, g UserGroup, deleteContent bool, sendNotif bool, removal *Removal) error {
 	if p.Deleted && !(deleteContent && !p.DeletedContent) {
 		return &httperr.Error{
 			HTTPStatus: http.StatusConflict,
@@ -1008,6 +1105,16 @@ func (p *Post) Delete(ctx context.Context, user uid.ID, g UserGroup, deleteConte
 				return err
 			}
 		}
+
+		if g != UserGroupNormal {
+			action := ModActionRemovePost
+			if deleteContent {
+				action = ModActionRemovePostContent
+			}
+			entry := postModLogEntry(p, user, g, action)
+			removal.setOnModLogEntry(entry)
+			return insertModLogEntry(ctx, tx, entry)
+		}
 		return nil
 	})
 	if err != nil {
@@ -1023,9 +1130,23 @@ func (p *Post) Delete(ctx context.Context, user uid.ID, g UserGroup, deleteConte
 		RemoveAllReportsOfPost(ctx, p.db, p.ID)
 	}
 
-	if sendNotif && (g == UserGroupAdmins || g == UserGroupMods) {
+	if g == UserGroupNormal {
+		return nil
+	}
+
+	if removal != nil && removal.Comment {
+		if _, err := p.addComment(ctx, user, g, nil, removal.Reason, true); err != nil {
+			log.Printf("Failed to add removal reason comment on post %v: %v\n", p.PublicID, err)
+		}
+	}
+
+	if sendNotif {
+		var reason string
+		if removal != nil {
+			reason = removal.Reason
+		}
 		go func() {
-			if err := CreatePostDeletedNotification(context.Background(), p.db, p.AuthorID, g, true, p.ID); err != nil {
+			if err := CreatePostDeletedNotification(context.Background(), p.db, p.AuthorID, g, true, p.ID, reason); err != nil {
 				log.Printf("Failed to create deleted_post notification on post %v\n", p.PublicID)
 			}
 		}()
@@ -1034,9 +1155,36 @@ func (p *Post) Delete(ctx context.Context, user uid.ID, g UserGroup, deleteConte
 	return err
 }
 
+// approve approves p, which is in the mod queue, and adds entry to the mod log.
+// If restore is true, the removal of p (by automod) is undone as well.
+func (p *Post) approve(ctx context.Context, restore bool, entry *ModLogEntry) error {
+	return msql.Transact(ctx, p.db, func(tx *sql.Tx) error {
+		if restore {
+			q := "UPDATE posts SET deleted = FALSE, deleted_at = NULL, deleted_by = NULL, deleted_as = ? WHERE id = ?"
+			if _, err := tx.ExecContext(ctx, q, UserGroupNaN, p.ID); err != nil {
+				return err
+			}
+			now := time.Now()
+			for i, table := range postsTables {
+				if p.CreatedAt.Before(now.Add(postsTablesValidity[i])) {
+					continue
+				}
+				q := fmt.Sprintf("INSERT INTO %s (community_id, post_id, user_id, points, created_at) SELECT community_id, id, user_id, points, created_at FROM posts WHERE id = ?", table)
+				if _, err := tx.ExecContext(ctx, q, p.ID); err != nil {
+					return err
+				}
+			}
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
+}
+
 // Lock locks the post on behalf of user who's locking the post in his or her
 // capacity as g.
 func (p *Post) Lock(ctx context.Context, user uid.ID, g UserGroup) error {
+	if err := checkCommunityWritable(ctx, p.db, p.CommunityID); err != nil {
+		return err
+	}
 	switch g {
 	case UserGroupMods:
 		is, err := UserMod(ctx, p.db, p.CommunityID, user)
@@ -1059,7 +1207,12 @@ func (p *Post) Lock(ctx context.Context, user uid.ID, g UserGroup) error {
 	}
 
 	now := time.Now()
-	_, err := p.db.ExecContext(ctx, "UPDATE posts SET locked = ?, locked_by = ?, locked_by_group = ?, locked_at = ? WHERE id = ?", true, user, g, now, p.ID)
+	err := msql.Transact(ctx, p.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE posts SET locked = ?, locked_by = ?, locked_by_group = ?, locked_at = ? WHERE id = ?", true, user, g, now, p.ID); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, postModLogEntry(p, user, g, ModActionLockPost))
+	})
 	if err == nil {
 		p.Locked = true
 		p.LockedAt = msql.NewNullTime(now)
@@ -1071,6 +1224,9 @@ func (p *Post) Lock(ctx context.Context, user uid.ID, g UserGroup) error {
 
 // Unlock unlocks the post on behalf of user.
 func (p *Post) Unlock(ctx context.Context, user uid.ID) error {
+	if err := checkCommunityWritable(ctx, p.db, p.CommunityID); err != nil {
+		return err
+	}
 	// TODO: Add a UserGroup argument to this method.
 
 	isMod, err := UserMod(ctx, p.db, p.CommunityID, user)
@@ -1085,8 +1241,17 @@ func (p *Post) Unlock(ctx context.Context, user uid.ID) error {
 	if !(isMod || u.Admin) {
 		return httperr.NewForbidden("not-mod-not-admin", "User is neither a moderator nor an admin.")
 	}
+	g := UserGroupMods
+	if !isMod {
+		g = UserGroupAdmins
+	}
 
-	_, err = p.db.ExecContext(ctx, "UPDATE posts SET locked = ?, locked_by = null, locked_by_group = ?, locked_at = null WHERE id = ?", false, UserGroupNaN, p.ID)
+	err = msql.Transact(ctx, p.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE posts SET locked = ?, locked_by = null, locked_by_group = ?, locked_at = null WHERE id = ?", false, UserGroupNaN, p.ID); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, postModLogEntry(p, user, g, ModActionUnlockPost))
+	})
 	if err == nil {
 		p.Locked = false
 		p.LockedAt.Valid = false
@@ -1105,6 +1270,12 @@ func (p *Post) Pin(ctx context.Context, user uid.ID, siteWide, unpin bool, skipP
 	if p.Deleted && !unpin {
 		return httperr.NewForbidden("cannot-pin-deleted-post", "Cannot pin deleted posts.")
 	}
+	if !unpin {
+		// Posts can still be unpinned from archived communities.
+		if err := checkCommunityWritable(ctx, p.db, p.CommunityID); err != nil {
+			return err
+		}
+	}
 
 	maxPinsReached := func(ctx context.Context, tx *sql.Tx, community *uid.ID) (reached bool, err error) {
 		count := 0
@@ -1117,8 +1288,11 @@ func (p *Post) Pin(ctx context.Context, user uid.ID, siteWide, unpin bool, skipP
 		return
 	}
 
-	// Check permissions.
+	// Check permissions. Pins are logged only if the permissions are checked
+	// (pins removed as part of other actions aren't).
+	var logEntry *ModLogEntry
 	if !skipPermissions {
+		g := UserGroupAdmins
 		if siteWide { // for site-wise pins
 			admin, err := IsAdmin(p.db, &user)
 			if err != nil {
@@ -1141,8 +1315,17 @@ func (p *Post) Pin(ctx context.Context, user uid.ID, siteWide, unpin bool, skipP
 				if !admin {
 					return errNotMod // user is neither an admin nor a mod
 				}
+			} else {
+				g = UserGroupMods
 			}
 		}
+
+		action := ModActionPinPost
+		if unpin {
+			action = ModActionUnpinPost
+		}
+		logEntry = postModLogEntry(p, user, g, action)
+		logEntry.Metadata = map[string]any{"siteWide": siteWide}
 	}
 
 	return msql.Transact(ctx, p.db, func(tx *sql.Tx) (err error) {
@@ -1184,6 +1367,9 @@ func (p *Post) Pin(ctx context.Context, user uid.ID, siteWide, unpin bool, skipP
 		} else {
 			_, err = tx.ExecContext(ctx, "UPDATE posts SET is_pinned = ? WHERE id = ?", !unpin, p.ID)
 		}
+		if err == nil && logEntry != nil {
+			err = insertModLogEntry(ctx, tx, logEntry)
+		}
 		return err
 	})
 }
@@ -1197,17 +1383,27 @@ func (p *Post) updatePostsTablesPoints(ctx context.Context) error {
 	return nil
 }
 
+// Vote votes on the post. The votes of shadowbanned users are recorded but are
+// not counted in the points of the post.
 func (p *Post) Vote(ctx context.Context, user uid.ID, up bool) error {
 	if p.Locked {
 		return errPostLocked
 	}
+	if err := checkCommunityWritable(ctx, p.db, p.CommunityID); err != nil {
+		return err
+	}
+
+	shadowbanned, err := UserShadowbanned(ctx, p.db, user)
+	if err != nil {
+		return err
+	}
 
 	tx, err := p.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	_, err = tx.ExecContext(ctx, "INSERT INTO post_votes (post_id, user_id, up) VALUES (?, ?, ?)", p.ID, user, up)
+	_, err = tx.ExecContext(ctx, "INSERT INTO post_votes (post_id, user_id, up, shadowbanned) VALUES (?, ?, ?, ?)", p.ID, user, up, shadowbanned)
 	if err != nil {
 		tx.Rollback()
 		if msql.IsErrDuplicateErr(err) {
@@ -1236,10 +1432,12 @@ func (p *Post) Vote(ctx context.Context, user uid.ID, up bool) error {
 	}
 	query += " WHERE id = ?"
 
-	_, err = tx.ExecContext(ctx, query, point, PostHotness(newUpvotes, newDownvotes, p.CreatedAt), p.ID)
-	if err != nil {
-		tx.Rollback()
-		return err
+	if !shadowbanned {
+		_, err = tx.ExecContext(ctx, query, point, PostHotness(newUpvotes, newDownvotes, p.CreatedAt), p.ID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -1252,6 +1450,10 @@ func (p *Post) Vote(ctx context.Context, user uid.ID, up bool) error {
 	p.ViewerVoted = msql.NewNullBool(true)
 	p.ViewerVotedUp = msql.NewNullBool(up)
 
+	if shadowbanned {
+		return nil
+	}
+
 	// Attempt to update user's points.
 	if up && !p.AuthorID.EqualsTo(user) {
 		incrementUserPoints(ctx, p.db, p.AuthorID, 1)
@@ -1266,6 +1468,7 @@ func (p *Post) Vote(ctx context.Context, user uid.ID, up bool) error {
 		}()
 	}
 
+	p.publishVotesEvent()
 	return p.updatePostsTablesPoints(ctx)
 }
 
@@ -1274,10 +1477,13 @@ func (p *Post) DeleteVote(ctx context.Context, user uid.ID) error {
 	if p.Locked {
 		return errPostLocked
 	}
+	if err := checkCommunityWritable(ctx, p.db, p.CommunityID); err != nil {
+		return err
+	}
 
-	id, up := 0, false
-	row := p.db.QueryRowContext(ctx, "SELECT id, up FROM post_votes WHERE post_id = ? AND user_id = ?", p.ID, user)
-	if err := row.Scan(&id, &up); err != nil {
+	id, up, shadowbanned := 0, false, false
+	row := p.db.QueryRowContext(ctx, "SELECT id, up, shadowbanned FROM post_votes WHERE post_id = ? AND user_id = ?", p.ID, user)
+	if err := row.Scan(&id, &up, &shadowbanned); err != nil {
 		return err
 	}
 
@@ -1305,10 +1511,12 @@ func (p *Post) DeleteVote(ctx context.Context, user uid.ID) error {
 	}
 	query += " WHERE id = ?"
 
-	_, err = tx.ExecContext(ctx, query, point, PostHotness(newUpvotes, newDownvotes, p.CreatedAt), p.ID)
-	if err != nil {
-		tx.Rollback()
-		return err
+	if !shadowbanned { // The vote was not counted.
+		_, err = tx.ExecContext(ctx, query, point, PostHotness(newUpvotes, newDownvotes, p.CreatedAt), p.ID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -1321,11 +1529,16 @@ func (p *Post) DeleteVote(ctx context.Context, user uid.ID) error {
 	p.ViewerVoted.Valid = false
 	p.ViewerVotedUp.Valid = false
 
+	if shadowbanned {
+		return nil
+	}
+
 	// Attempt to update user's points.
 	if up && !p.AuthorID.EqualsTo(user) {
 		incrementUserPoints(ctx, p.db, p.AuthorID, -1)
 	}
 
+	p.publishVotesEvent()
 	return p.updatePostsTablesPoints(ctx)
 }
 
@@ -1334,10 +1547,13 @@ func (p *Post) ChangeVote(ctx context.Context, user uid.ID, up bool) error {
 	if p.Locked {
 		return errPostLocked
 	}
+	if err := checkCommunityWritable(ctx, p.db, p.CommunityID); err != nil {
+		return err
+	}
 
-	id, dbUp := 0, false
-	row := p.db.QueryRowContext(ctx, "SELECT id, up FROM post_votes WHERE post_id = ? AND user_id = ?", p.ID, user)
-	if err := row.Scan(&id, &dbUp); err != nil {
+	id, dbUp, shadowbanned := 0, false, false
+	row := p.db.QueryRowContext(ctx, "SELECT id, up, shadowbanned FROM post_votes WHERE post_id = ? AND user_id = ?", p.ID, user)
+	if err := row.Scan(&id, &dbUp, &shadowbanned); err != nil {
 		return err
 	}
 
@@ -1371,10 +1587,12 @@ func (p *Post) ChangeVote(ctx context.Context, user uid.ID, up bool) error {
 	}
 	query += " WHERE id = ?"
 
-	_, err = tx.ExecContext(ctx, query, points, PostHotness(newUpvotes, newDownvotes, p.CreatedAt), p.ID)
-	if err != nil {
-		tx.Rollback()
-		return err
+	if !shadowbanned { // The vote was not counted.
+		_, err = tx.ExecContext(ctx, query, points, PostHotness(newUpvotes, newDownvotes, p.CreatedAt), p.ID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -1386,6 +1604,10 @@ func (p *Post) ChangeVote(ctx context.Context, user uid.ID, up bool) error {
 	p.Points += points
 	p.ViewerVotedUp = msql.NewNullBool(up)
 
+	if shadowbanned {
+		return nil
+	}
+
 	// Attempt to update user's points.
 	if !p.AuthorID.EqualsTo(user) {
 		point := 1
@@ -1395,6 +1617,7 @@ func (p *Post) ChangeVote(ctx context.Context, user uid.ID, up bool) error {
 		incrementUserPoints(ctx, p.db, p.AuthorID, point)
 	}
 
+	p.publishVotesEvent()
 	return p.updatePostsTablesPoints(ctx)
 }
 
@@ -1434,6 +1657,9 @@ func getComments(ctx context.Context, db *sql.DB, viewer *uid.ID, where string,
 		}
 		return ret(nil, err)
 	}
+	if viewer == nil {
+		c = removeShadowbannedComments(c, nil)
+	}
 	return ret(c, nil)
 }
 
@@ -1532,7 +1758,14 @@ func (p *Post) GetCommentReplies(ctx context.Context, viewer *uid.ID, comment ui
 
 // AddComment adds a new comment to post.
 func (p *Post) AddComment(ctx context.Context, user uid.ID, g UserGroup, parentComment *uid.ID, body string) (*Comment, error) {
-	if p.Locked {
+	return p.addComment(ctx, user, g, parentComment, body, false)
+}
+
+// addComment is AddComment, except that, if removalReason is true, the comment
+// is the reason given by a mod or an admin for a removal, which may be posted
+// on a locked post and in reply to a deleted comment.
+func (p *Post) addComment(ctx context.Context, user uid.ID, g UserGroup, parentComment *uid.ID, body string, removalReason bool) (*Comment, error) {
+	if p.Locked && !(removalReason && g != UserGroupNormal) {
 		return nil, errPostLocked
 	}
 
@@ -1542,6 +1775,12 @@ func (p *Post) AddComment(ctx context.Context, user uid.ID, g UserGroup, parentC
 	} else if is {
 		return nil, errUserBannedFromCommunity
 	}
+	if err := checkCanParticipate(ctx, p.db, p.CommunityID, user); err != nil {
+		return nil, err
+	}
+	if err := checkPostingRequirements(ctx, p.db, p.CommunityID, user, false, 0, "", ""); err != nil {
+		return nil, err
+	}
 
 	u, err := GetUser(ctx, p.db, user, nil)
 	if err != nil {
@@ -1568,7 +1807,7 @@ func (p *Post) AddComment(ctx context.Context, user uid.ID, g UserGroup, parentC
 	}
 
 	body = strings.TrimSpace(body)
-	comment, err := addComment(ctx, p.db, p, u, parentComment, body)
+	comment, err := addComment(ctx, p.db, p, u, parentComment, body, removalReason)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// ReadPosts is whether a user's read posts are tracked and, if so, how read
+// posts are shown in feeds.
+type ReadPosts int
+
+const (
+	ReadPostsOff  = ReadPosts(iota) // Read posts are not tracked.
+	ReadPostsShow                   // Read posts are tracked but shown as usual.
+	ReadPostsDim                    // Read posts are shown dimmed.
+	ReadPostsHide                   // Read posts are removed from feeds.
+)
+
+func (p ReadPosts) Valid() bool {
+	_, err := p.MarshalText()
+	return err == nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (p ReadPosts) MarshalText() ([]byte, error) {
+	switch p {
+	case ReadPostsOff:
+		return []byte("off"), nil
+	case ReadPostsShow:
+		return []byte("show"), nil
+	case ReadPostsDim:
+		return []byte("dim"), nil
+	case ReadPostsHide:
+		return []byte("hide"), nil
+	}
+	return nil, fmt.Errorf("cannot marshal unsupported ReadPosts (%v)", int(p))
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (p *ReadPosts) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "off":
+		*p = ReadPostsOff
+	case "show":
+		*p = ReadPostsShow
+	case "dim":
+		*p = ReadPostsDim
+	case "hide":
+		*p = ReadPostsHide
+	default:
+		return httperr.NewBadRequest("invalid_read_posts", "Unsupported read posts option.")
+	}
+	return nil
+}
+
+// maxMarkPostsRead is the maximum number of posts that can be marked as read
+// at once.
+const maxMarkPostsRead = 100
+
+// GetUserReadPosts returns the ReadPosts setting of user.
+func GetUserReadPosts(ctx context.Context, db *sql.DB, user uid.ID) (ReadPosts, error) {
+	var p ReadPosts
+	err := db.QueryRowContext(ctx, "SELECT read_posts FROM users WHERE id = ?", user).Scan(&p)
+	return p, err
+}
+
+// MarkPostsRead marks posts as read (seen) by user. It's a no-op if user has
+// read tracking turned off.
+func MarkPostsRead(ctx context.Context, db *sql.DB, user uid.ID, posts []uid.ID) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	if len(posts) > maxMarkPostsRead {
+		return httperr.NewBadRequest("too_many_posts", fmt.Sprintf("Cannot mark more than %d posts at once.", maxMarkPostsRead))
+	}
+	if p, err := GetUserReadPosts(ctx, db, user); err != nil {
+		return err
+	} else if p == ReadPostsOff {
+		return nil
+	}
+
+	query := "INSERT IGNORE INTO post_reads (user_id, post_id) SELECT ?, id FROM posts WHERE id IN " + msql.InClauseQuestionMarks(len(posts))
+	args := make([]any, 0, len(posts)+1)
+	args = append(args, user)
+	for _, post := range posts {
+		args = append(args, post)
+	}
+	_, err := db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// RecordViewerVisit records that viewer opened the page of the post. It sets
+// the ViewerLastVisitedAt field of the post to the time of viewer's previous
+// visit, and ViewerNewComments to the number of comments (by others) posted
+// since then. It's a no-op if viewer has read tracking turned off.
+func (p *Post) RecordViewerVisit(ctx context.Context, viewer uid.ID) error {
+	if rp, err := GetUserReadPosts(ctx, p.db, viewer); err != nil {
+		return err
+	} else if rp == ReadPostsOff {
+		return nil
+	}
+
+	var last msql.NullTime
+	err := p.db.QueryRowContext(ctx, "SELECT visited_at FROM post_reads WHERE user_id = ? AND post_id = ?", viewer, p.ID).Scan(&last)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if last.Valid {
+		t := last.Time
+		p.ViewerLastVisitedAt = &t
+		if err := p.db.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM comments
+			WHERE post_id = ? AND created_at > ? AND user_id <> ? AND deleted_at IS NULL`,
+			p.ID, last.Time, viewer).Scan(&p.ViewerNewComments); err != nil {
+			return err
+		}
+	}
+
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO post_reads (user_id, post_id, visited_at) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE visited_at = VALUES(visited_at)`,
+		viewer, p.ID, time.Now())
+	return err
+}
+
+// setReadPosts sets the ViewerRead field of the posts that viewer has read.
+func setReadPosts(ctx context.Context, db *sql.DB, viewer uid.ID, posts []*Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	args := make([]any, 0, len(posts)+1)
+	args = append(args, viewer)
+	for _, post := range posts {
+		args = append(args, post.ID)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT post_id FROM post_reads WHERE user_id = ? AND post_id IN "+msql.InClauseQuestionMarks(len(posts)), args...)
+	if err != nil {
+		return err
+	}
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		for _, post := range posts {
+			if post.ID == id {
+				post.ViewerRead = true
+			}
+		}
+	}
+	return nil
+}
+
+// whereNotRead returns where (along with args) with a condition appended to it
+// that excludes the posts that viewer has read. postIDColumn is the column with
+// the post ID (posts.id, for instance).
+func whereNotRead(where, postIDColumn string, args []any, viewer uid.ID) (string, []any) {
+	where += " AND " + postIDColumn + " NOT IN (SELECT post_id FROM post_reads WHERE user_id = ?) "
+	args = append(args, viewer)
+	return where, args
+}
+
+// PurgePostReads deletes the read posts records older than retention.
+func PurgePostReads(ctx context.Context, db *sql.DB, retention time.Duration) (int64, error) {
+	before := time.Now().Add(-retention)
+	res, err := db.ExecContext(ctx, "DELETE FROM post_reads WHERE seen_at < ? AND (visited_at IS NULL OR visited_at < ?)", before, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
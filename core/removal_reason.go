@@ -0,0 +1,251 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+	"github.com/discuitnet/discuit/internal/utils"
+)
+
+const (
+	maxRemovalReasonTitleLength   = 128
+	maxRemovalReasonMessageLength = 1000
+
+	// maxRemovalReasonLength is the maximum length of the reason of a
+	// removal (the message of a removal reason along with the note of the
+	// mod).
+	maxRemovalReasonLength = 1024
+)
+
+// RemovalReason is a template of a reason, that the mods of a community pick
+// from when removing a post or a comment.
+type RemovalReason struct {
+	db *sql.DB
+
+	ID          uint   `json:"id"`
+	CommunityID uid.ID `json:"communityId"`
+	Title       string `json:"title"`
+	Message     string `json:"message"`
+
+	// The community rule that this reason is tied to, if any.
+	RuleID msql.NullInt32  `json:"ruleId"`
+	Rule   msql.NullString `json:"rule"`
+
+	ZIndex    int       `json:"zIndex"`
+	CreatedBy uid.ID    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func getRemovalReasons(ctx context.Context, db *sql.DB, where string, args ...any) ([]*RemovalReason, error) {
+	cols := []string{
+		"community_removal_reasons.id",
+		"community_removal_reasons.community_id",
+		"community_removal_reasons.title",
+		"community_removal_reasons.message",
+		"community_removal_reasons.rule_id",
+		"community_rules.rule",
+		"community_removal_reasons.z_index",
+		"community_removal_reasons.created_by",
+		"community_removal_reasons.created_at",
+	}
+	joins := []string{"LEFT JOIN community_rules ON community_rules.id = community_removal_reasons.rule_id"}
+	rows, err := db.QueryContext(ctx, msql.BuildSelectQuery("community_removal_reasons", cols, joins, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reasons := []*RemovalReason{}
+	for rows.Next() {
+		r := &RemovalReason{db: db}
+		err := rows.Scan(
+			&r.ID,
+			&r.CommunityID,
+			&r.Title,
+			&r.Message,
+			&r.RuleID,
+			&r.Rule,
+			&r.ZIndex,
+			&r.CreatedBy,
+			&r.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reasons = append(reasons, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return reasons, nil
+}
+
+// GetRemovalReasons returns the removal reasons of community, in the order
+// they are to be displayed.
+func GetRemovalReasons(ctx context.Context, db *sql.DB, community uid.ID) ([]*RemovalReason, error) {
+	return getRemovalReasons(ctx, db, "WHERE community_removal_reasons.community_id = ? ORDER BY community_removal_reasons.z_index, community_removal_reasons.id", community)
+}
+
+// GetRemovalReason returns the removal reason with the given id.
+func GetRemovalReason(ctx context.Context, db *sql.DB, id uint) (*RemovalReason, error) {
+	reasons, err := getRemovalReasons(ctx, db, "WHERE community_removal_reasons.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(reasons) == 0 {
+		return nil, httperr.NewNotFound("removal-reason-not-found", "Removal reason not found.")
+	}
+	return reasons[0], nil
+}
+
+// validate trims and validates the fields of r. The rule of r, if any, must
+// belong to the community of r.
+func (r *RemovalReason) validate(ctx context.Context) error {
+	r.Title = utils.TruncateUnicodeString(strings.TrimSpace(r.Title), maxRemovalReasonTitleLength)
+	r.Message = strings.TrimSpace(r.Message)
+	if r.Title == "" {
+		return httperr.NewBadRequest("removal-reason-no-title", "Removal reason title cannot be empty.")
+	}
+	if r.Message == "" {
+		return httperr.NewBadRequest("removal-reason-no-message", "Removal reason message cannot be empty.")
+	}
+	if utf8.RuneCountInString(r.Message) > maxRemovalReasonMessageLength {
+		return httperr.NewBadRequest("removal-reason-too-long", fmt.Sprintf("Removal reason message cannot exceed %d characters.", maxRemovalReasonMessageLength))
+	}
+	r.Rule = msql.NullString{}
+	if r.RuleID.Valid {
+		rule, err := GetCommunityRule(ctx, r.db, uint(r.RuleID.Int32))
+		if err != nil {
+			return err
+		}
+		if rule.CommunityID != r.CommunityID {
+			return httperr.NewBadRequest("removal-reason-invalid-rule", "Rule is not a rule of the community.")
+		}
+		r.Rule = msql.NewNullString(rule.Rule)
+	}
+	return nil
+}
+
+// AddRemovalReason adds a removal reason to c on behalf of mod.
+func (c *Community) AddRemovalReason(ctx context.Context, mod uid.ID, title, message string, ruleID msql.NullInt32) (*RemovalReason, error) {
+	if is, err := c.UserModOrAdmin(ctx, mod); err != nil {
+		return nil, err
+	} else if !is {
+		return nil, errNotMod
+	}
+
+	r := &RemovalReason{
+		db:          c.db,
+		CommunityID: c.ID,
+		Title:       title,
+		Message:     message,
+		RuleID:      ruleID,
+		CreatedBy:   mod,
+	}
+	if err := r.validate(ctx); err != nil {
+		return nil, err
+	}
+
+	var zIndex int
+	row := c.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(z_index), 0) FROM community_removal_reasons WHERE community_id = ?", c.ID)
+	if err := row.Scan(&zIndex); err != nil {
+		return nil, err
+	}
+
+	res, err := c.db.ExecContext(ctx, "INSERT INTO community_removal_reasons (community_id, title, message, rule_id, z_index, created_by) VALUES (?, ?, ?, ?, ?, ?)",
+		r.CommunityID, r.Title, r.Message, r.RuleID, zIndex+1, r.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return GetRemovalReason(ctx, c.db, uint(id))
+}
+
+// Update updates the title, message, rule, and ZIndex of r.
+func (r *RemovalReason) Update(ctx context.Context, mod uid.ID) error {
+	if is, err := UserModOrAdmin(ctx, r.db, r.CommunityID, mod); err != nil {
+		return err
+	} else if !is {
+		return errNotMod
+	}
+	if err := r.validate(ctx); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, "UPDATE community_removal_reasons SET title = ?, message = ?, rule_id = ?, z_index = ? WHERE id = ?",
+		r.Title, r.Message, r.RuleID, r.ZIndex, r.ID)
+	return err
+}
+
+// Delete deletes r on behalf of mod.
+func (r *RemovalReason) Delete(ctx context.Context, mod uid.ID) error {
+	if is, err := UserModOrAdmin(ctx, r.db, r.CommunityID, mod); err != nil {
+		return err
+	} else if !is {
+		return errNotMod
+	}
+	_, err := r.db.ExecContext(ctx, "DELETE FROM community_removal_reasons WHERE id = ?", r.ID)
+	return err
+}
+
+// Removal is the reason given by a mod or an admin for removing a post or a
+// comment. The reason is sent to the author of the post or the comment, and
+// it's recorded in the mod log.
+type Removal struct {
+	ReasonID uint // The removal reason used, if not 0.
+	Reason   string
+
+	// If true, Reason is also posted as a public mod comment.
+	Comment bool
+}
+
+// NewRemoval returns a Removal with the message of the removal reason, of
+// community, with the given id (if it's not 0) followed by note. It returns
+// nil if both are empty.
+func NewRemoval(ctx context.Context, db *sql.DB, community uid.ID, reasonID uint, note string, comment bool) (*Removal, error) {
+	var parts []string
+	if reasonID != 0 {
+		reason, err := GetRemovalReason(ctx, db, reasonID)
+		if err != nil {
+			return nil, err
+		}
+		if reason.CommunityID != community {
+			return nil, httperr.NewBadRequest("removal-reason-invalid", "Removal reason is not of the community.")
+		}
+		if reason.Rule.Valid {
+			parts = append(parts, "Rule: "+reason.Rule.String)
+		}
+		parts = append(parts, reason.Message)
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		parts = append(parts, note)
+	}
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	return &Removal{
+		ReasonID: reasonID,
+		Reason:   utils.TruncateUnicodeString(strings.Join(parts, "\n\n"), maxRemovalReasonLength),
+		Comment:  comment,
+	}, nil
+}
+
+// setOnModLogEntry adds the reason of rm, if rm is not nil, to e.
+func (rm *Removal) setOnModLogEntry(e *ModLogEntry) {
+	if rm == nil {
+		return
+	}
+	e.Reason = rm.Reason
+	if rm.ReasonID != 0 {
+		e.Metadata = map[string]any{"removalReasonId": rm.ReasonID}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/discuitnet/discuit/internal/httperr"
@@ -66,6 +67,13 @@ type Report struct {
 	DealtBy     uid.NullID      `json:"dealtBy"`
 	CreatedAt   time.Time       `json:"createdAt"`
 
+	// ForAdmins is true if the report was made for a reason that's routed to
+	// the admins. Such reports, and the escalated ones, are only visible to
+	// the admins.
+	ForAdmins   bool          `json:"forAdmins"`
+	EscalatedAt msql.NullTime `json:"escalatedAt"`
+	EscalatedBy uid.NullID    `json:"escalatedBy"`
+
 	Target interface{} `json:"target"`
 }
 
@@ -83,12 +91,20 @@ var selectReportCols = []string{
 	"reports.created_at",
 	"report_reasons.title",
 	"report_reasons.description",
+	"report_reasons.for_admins",
+	"reports.escalated_at",
+	"reports.escalated_by",
 }
 
 var selectReportJoins = []string{
 	"INNER JOIN report_reasons ON reports.reason_id = report_reasons.id",
 }
 
+// modReportsCond is the SQL condition that matches the reports handled by the
+// mods of a community: those made for reasons that are not routed to the
+// admins, and not escalated to the admins.
+const modReportsCond = "(reports.escalated_at IS NULL AND reports.reason_id NOT IN (SELECT id FROM report_reasons WHERE for_admins))"
+
 // NewReport creates a new report on target.
 func NewReport(ctx context.Context, db *sql.DB, community uid.ID, post uid.NullID, t ReportType, reason int, target, createdBy uid.ID) (*Report, error) {
 	if is, err := IsUserBannedFromCommunity(ctx, db, community, createdBy); err != nil {
@@ -97,6 +113,14 @@ func NewReport(ctx context.Context, db *sql.DB, community uid.ID, post uid.NullI
 		return nil, errUserBannedFromCommunity
 	}
 
+	var forAdmins bool
+	if err := db.QueryRowContext(ctx, "SELECT for_admins FROM report_reasons WHERE id = ?", reason).Scan(&forAdmins); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, httperr.NewBadRequest("invalid-report-reason", "Invalid report reason.")
+		}
+		return nil, err
+	}
+
 	has, err := hasUserMadeReport(ctx, db, createdBy, target, t, reason)
 	if err != nil {
 		return nil, err
@@ -132,6 +156,16 @@ func NewReport(ctx context.Context, db *sql.DB, community uid.ID, post uid.NullI
 	if err != nil {
 		return nil, err
 	}
+
+	// Reports routed to the admins are not visible to the mods, nor to their
+	// automod rules.
+	if !forAdmins {
+		if t == ReportTypePost {
+			go runAutomodOnPost(db, AutomodEventReport, target)
+		} else {
+			go runAutomodOnComment(db, AutomodEventReport, target)
+		}
+	}
 	return GetReport(ctx, db, int(id))
 }
 
@@ -187,7 +221,10 @@ func scanReports(db *sql.DB, rows *sql.Rows) ([]*Report, error) {
 			&r.DealtBy,
 			&r.CreatedAt,
 			&r.Reason,
-			&r.Description)
+			&r.Description,
+			&r.ForAdmins,
+			&r.EscalatedAt,
+			&r.EscalatedBy)
 		if err != nil {
 			return nil, err
 		}
@@ -255,17 +292,58 @@ func (r *Report) FetchTarget(ctx context.Context) error {
 
 // Delete deletes the report permanently.
 func (r *Report) Delete(ctx context.Context, mod uid.ID) error {
-	_, err := r.db.ExecContext(ctx, "DELETE FROM reports WHERE id = ?", r.ID)
-	return err
+	entry, err := communityModLogEntry(ctx, r.db, r.CommunityID, mod, ModActionDismissReport)
+	if err != nil {
+		return err
+	}
+	entry.TargetType, entry.TargetID = "report", strconv.Itoa(r.ID)
+	entry.PostID = r.PostID
+	entry.Metadata = map[string]any{"reportType": r.Type, "reportTargetId": r.TargetID, "reason": r.Reason}
+	return msql.Transact(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM reports WHERE id = ?", r.ID); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
+}
+
+// Escalate hands the report over to the admins on behalf of mod. Escalated
+// reports are removed from the reports of the community.
+func (r *Report) Escalate(ctx context.Context, mod uid.ID) error {
+	if r.ForAdmins || r.EscalatedAt.Valid {
+		return &httperr.Error{HTTPStatus: http.StatusConflict, Code: "already-escalated", Message: "Report is already with the admins."}
+	}
+	entry, err := communityModLogEntry(ctx, r.db, r.CommunityID, mod, ModActionEscalateReport)
+	if err != nil {
+		return err
+	}
+	entry.TargetType, entry.TargetID = "report", strconv.Itoa(r.ID)
+	entry.PostID = r.PostID
+	entry.Metadata = map[string]any{"reportType": r.Type, "reportTargetId": r.TargetID, "reason": r.Reason}
+
+	now := time.Now()
+	err = msql.Transact(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE reports SET escalated_at = ?, escalated_by = ? WHERE id = ?", now, mod, r.ID); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
+	if err != nil {
+		return err
+	}
+	r.EscalatedAt = msql.NewNullTime(now)
+	r.EscalatedBy = uid.NullID{ID: mod, Valid: true}
+	return nil
 }
 
-// GetReports retrives user submitted reports in community. The results are paginated.
+// GetReports retrives user submitted reports in community, other than the
+// ones that are with the admins. The results are paginated.
 func GetReports(ctx context.Context, db *sql.DB, community uid.ID, t ReportType, limit, page int) ([]*Report, error) {
-	query := msql.BuildSelectQuery("reports", selectReportCols, selectReportJoins, "WHERE reports.community_id = ?")
+	query := msql.BuildSelectQuery("reports", selectReportCols, selectReportJoins, "WHERE reports.community_id = ? AND "+modReportsCond)
 	if t != ReportTypeAll {
 		query += " AND report_type = ?"
 	}
-	query += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
+	query += " ORDER BY reports.created_at DESC LIMIT ? OFFSET ?"
 
 	var rows *sql.Rows
 	var err error
@@ -291,10 +369,68 @@ func GetReports(ctx context.Context, db *sql.DB, community uid.ID, t ReportType,
 	return reports, nil
 }
 
+// AdminReportsQuery holds the filters of the site-wide reports queue. Zero
+// valued fields are ignored.
+type AdminReportsQuery struct {
+	Reason    int // A report reason id.
+	Escalated bool
+	Community uid.NullID
+	MinAge    time.Duration
+	MaxAge    time.Duration
+}
+
+// GetAdminReports returns the reports that are with the admins (those made for
+// a reason routed to the admins, and those escalated by mods) matching q, the
+// latest ones first. The results are paginated.
+func GetAdminReports(ctx context.Context, db *sql.DB, q *AdminReportsQuery, limit, page int) ([]*Report, error) {
+	where, args := "WHERE NOT "+modReportsCond, []any{}
+	if q.Reason != 0 {
+		where += " AND reports.reason_id = ?"
+		args = append(args, q.Reason)
+	}
+	if q.Escalated {
+		where += " AND reports.escalated_at IS NOT NULL"
+	}
+	if q.Community.Valid {
+		where += " AND reports.community_id = ?"
+		args = append(args, q.Community.ID)
+	}
+	now := time.Now()
+	if q.MinAge > 0 {
+		where += " AND reports.created_at <= ?"
+		args = append(args, now.Add(-q.MinAge))
+	}
+	if q.MaxAge > 0 {
+		where += " AND reports.created_at >= ?"
+		args = append(args, now.Add(-q.MaxAge))
+	}
+	where += " ORDER BY reports.created_at DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, limit*(page-1))
+
+	rows, err := db.QueryContext(ctx, msql.BuildSelectQuery("reports", selectReportCols, selectReportJoins, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := scanReports(db, rows)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []*Report{}, nil
+		}
+		return nil, err
+	}
+	for _, r := range reports {
+		if err = r.FetchTarget(ctx); err != nil && !httperr.IsNotFound(err) {
+			return nil, errors.New("couldn't fetch target: " + err.Error())
+		}
+	}
+	return reports, nil
+}
+
 type ReportReason struct {
 	ID          int             `json:"id"`
 	Title       string          `json:"title"`
 	Description msql.NullString `json:"description"`
+	ForAdmins   bool            `json:"forAdmins"` // Reports made for this reason go to the admins.
 	CreatedAt   time.Time       `json:"-"`
 }
 
@@ -302,7 +438,7 @@ type ReportReason struct {
 // dozen at most.
 func GetReportReasons(ctx context.Context, db *sql.DB) ([]ReportReason, error) {
 	var all []ReportReason
-	rows, err := db.QueryContext(ctx, "SELECT id, title, description, created_at FROM report_reasons")
+	rows, err := db.QueryContext(ctx, "SELECT id, title, description, for_admins, created_at FROM report_reasons")
 	if err != nil {
 		return nil, err
 	}
@@ -310,7 +446,7 @@ func GetReportReasons(ctx context.Context, db *sql.DB) ([]ReportReason, error) {
 
 	for rows.Next() {
 		r := ReportReason{}
-		if err = rows.Scan(&r.ID, &r.Title, &r.Description, &r.CreatedAt); err != nil {
+		if err = rows.Scan(&r.ID, &r.Title, &r.Description, &r.ForAdmins, &r.CreatedAt); err != nil {
 			return nil, err
 		}
 		all = append(all, r)
@@ -326,12 +462,23 @@ func RemoveAllReportsOfCommunity(ctx context.Context, db *sql.DB, community uid.
 	return err
 }
 
+// RemoveAllReportsOfPost removes all reports of post, and of its comments,
+// and removes them from the mod queue. The reports that are with the admins
+// are kept.
 func RemoveAllReportsOfPost(ctx context.Context, db *sql.DB, post uid.ID) error {
-	_, err := db.ExecContext(ctx, "DELETE FROM reports WHERE post_id = ?", post)
+	if _, err := db.ExecContext(ctx, "DELETE FROM reports WHERE post_id = ? AND "+modReportsCond, post); err != nil {
+		return err
+	}
+	_, err := db.ExecContext(ctx, "DELETE FROM mod_queue WHERE target_type = ? AND target_id = ?", ContentTypePost, post)
 	return err
 }
 
+// RemoveAllReportsOfComment removes all reports of comment, and removes it
+// from the mod queue. The reports that are with the admins are kept.
 func RemoveAllReportsOfComment(ctx context.Context, db *sql.DB, comment uid.ID) error {
-	_, err := db.ExecContext(ctx, "DELETE FROM reports WHERE target_id = ? AND report_type = ?", comment, ReportTypeComment)
+	if _, err := db.ExecContext(ctx, "DELETE FROM reports WHERE target_id = ? AND report_type = ? AND "+modReportsCond, comment, ReportTypeComment); err != nil {
+		return err
+	}
+	_, err := db.ExecContext(ctx, "DELETE FROM mod_queue WHERE target_id = ?", comment)
 	return err
 }
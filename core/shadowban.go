@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// The posts, comments, and votes of shadowbanned users are visible only to the
+// users themselves and to the admins. For everyone else, the posts are
+// excluded from the feeds (see whereNotShadowbanned) and are removed by
+// scanPosts (for logged in viewers) and by GetPostsByIDs (for logged out
+// viewers). Comments are handled similarly by scanComments and getComments.
+// Since GetPost and GetComment are also used internally with a nil viewer, the
+// handlers that serve logged out users must call CheckViewable.
+
+// UserShadowbanned reports whether user is shadowbanned.
+func UserShadowbanned(ctx context.Context, db *sql.DB, user uid.ID) (bool, error) {
+	var is bool
+	err := db.QueryRowContext(ctx, "SELECT shadowbanned_at IS NOT NULL FROM users WHERE id = ?", user).Scan(&is)
+	return is, err
+}
+
+// AuthorShadowbanned reports whether the author of the post is shadowbanned.
+func (p *Post) AuthorShadowbanned() bool {
+	return p.authorShadowbanned
+}
+
+// CheckViewable returns a not found error if viewer is logged out (nil) and
+// the author of the post is shadowbanned.
+func (p *Post) CheckViewable(viewer *uid.ID) error {
+	if viewer == nil && p.authorShadowbanned {
+		return errPostNotFound
+	}
+	return nil
+}
+
+// AuthorShadowbanned reports whether the author of the comment is
+// shadowbanned.
+func (c *Comment) AuthorShadowbanned() bool {
+	return c.authorShadowbanned
+}
+
+// CheckViewable returns a not found error if viewer is logged out (nil) and
+// the author of the comment is shadowbanned.
+func (c *Comment) CheckViewable(viewer *uid.ID) error {
+	if viewer == nil && c.authorShadowbanned {
+		return errCommentNotFound
+	}
+	return nil
+}
+
+// removeShadowbannedPosts removes the posts of shadowbanned users, except
+// those of viewer, from posts.
+func removeShadowbannedPosts(posts []*Post, viewer *uid.ID) []*Post {
+	return slices.DeleteFunc(posts, func(p *Post) bool {
+		return p.authorShadowbanned && (viewer == nil || p.AuthorID != *viewer)
+	})
+}
+
+// removeShadowbannedComments removes the comments of shadowbanned users,
+// except those of viewer, from comments.
+func removeShadowbannedComments(comments []*Comment, viewer *uid.ID) []*Comment {
+	return slices.DeleteFunc(comments, func(c *Comment) bool {
+		return c.authorShadowbanned && (viewer == nil || c.AuthorID != *viewer)
+	})
+}
+
+// removeShadowbannedMessages removes the messages of shadowbanned users, except
+// those of viewer, from messages.
+func removeShadowbannedMessages(messages []*Message, viewer uid.ID) []*Message {
+	return slices.DeleteFunc(messages, func(m *Message) bool {
+		return m.authorShadowbanned && m.AuthorID != viewer
+	})
+}
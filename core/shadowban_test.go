@@ -0,0 +1,39 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+func TestRemoveShadowbannedPosts(t *testing.T) {
+	author, other := uid.New(), uid.New()
+	newPosts := func() []*Post {
+		return []*Post{
+			{Title: "a", AuthorID: other},
+			{Title: "b", AuthorID: author, authorShadowbanned: true},
+			{Title: "c", AuthorID: other},
+		}
+	}
+	titles := func(posts []*Post) string {
+		s := ""
+		for _, p := range posts {
+			s += p.Title
+		}
+		return s
+	}
+
+	cases := []struct {
+		viewer *uid.ID
+		want   string
+	}{
+		{nil, "ac"},
+		{&other, "ac"},
+		{&author, "abc"},
+	}
+	for _, item := range cases {
+		if got := titles(removeShadowbannedPosts(newPosts(), item.viewer)); got != item.want {
+			t.Errorf("viewer %v: got posts %s, want %s", item.viewer, got, item.want)
+		}
+	}
+}
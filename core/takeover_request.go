@@ -0,0 +1,359 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+const (
+	maxTakeoverRequestLength         = 5000
+	maxTakeoverRequestResponseLength = 2000
+
+	// The mods of a community that haven't been seen for this long are
+	// considered inactive.
+	modInactivityPeriod = time.Hour * 24 * 90
+)
+
+// Abandoned reports whether c has no active mods, that is, whether it has no
+// mods at all or none of its mods have been seen in the last 90 days. It also
+// sets c.IsAbandoned.
+func (c *Community) Abandoned(ctx context.Context) (bool, error) {
+	var active bool
+	query := "SELECT COUNT(*) > 0 FROM community_mods INNER JOIN users ON users.id = community_mods.user_id WHERE community_mods.community_id = ? AND users.deleted_at IS NULL AND users.last_seen > ?"
+	if err := c.db.QueryRowContext(ctx, query, c.ID, time.Now().Add(-modInactivityPeriod)).Scan(&active); err != nil {
+		return false, err
+	}
+	abandoned := !active
+	c.IsAbandoned = &abandoned
+	return abandoned, nil
+}
+
+// TakeoverRequestStatus is the status of a community takeover request.
+type TakeoverRequestStatus string
+
+const (
+	TakeoverRequestStatusOpen     = TakeoverRequestStatus("open")
+	TakeoverRequestStatusApproved = TakeoverRequestStatus("approved") // The user was made the top mod.
+	TakeoverRequestStatusRejected = TakeoverRequestStatus("rejected")
+)
+
+// Valid reports whether s is a valid TakeoverRequestStatus.
+func (s TakeoverRequestStatus) Valid() bool {
+	switch s {
+	case TakeoverRequestStatusOpen, TakeoverRequestStatusApproved, TakeoverRequestStatusRejected:
+		return true
+	}
+	return false
+}
+
+// TakeoverRequest is a request, by a user, to become the top mod of an
+// abandoned community. Takeover requests are reviewed by the admins.
+type TakeoverRequest struct {
+	db *sql.DB
+
+	ID            uint                  `json:"id"`
+	CommunityID   uid.ID                `json:"communityId"`
+	CommunityName string                `json:"communityName"`
+	UserID        uid.ID                `json:"userId"`
+	Username      string                `json:"username"`
+	Body          string                `json:"body"`
+	Status        TakeoverRequestStatus `json:"status"`
+	Response      msql.NullString       `json:"response"` // The message of the reviewer.
+	ReviewedBy    uid.NullID            `json:"reviewedBy"`
+	ReviewedAt    msql.NullTime         `json:"reviewedAt"`
+	CreatedAt     time.Time             `json:"createdAt"`
+}
+
+func getTakeoverRequests(ctx context.Context, db *sql.DB, where string, args ...any) ([]*TakeoverRequest, error) {
+	cols := []string{
+		"community_takeover_requests.id",
+		"community_takeover_requests.community_id",
+		"communities.name",
+		"community_takeover_requests.user_id",
+		"users.username",
+		"community_takeover_requests.body",
+		"community_takeover_requests.status",
+		"community_takeover_requests.response",
+		"community_takeover_requests.reviewed_by",
+		"community_takeover_requests.reviewed_at",
+		"community_takeover_requests.created_at",
+	}
+	joins := []string{
+		"INNER JOIN communities ON communities.id = community_takeover_requests.community_id",
+		"INNER JOIN users ON users.id = community_takeover_requests.user_id",
+	}
+	rows, err := db.QueryContext(ctx, msql.BuildSelectQuery("community_takeover_requests", cols, joins, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []*TakeoverRequest{}
+	for rows.Next() {
+		t := &TakeoverRequest{db: db}
+		err := rows.Scan(
+			&t.ID,
+			&t.CommunityID,
+			&t.CommunityName,
+			&t.UserID,
+			&t.Username,
+			&t.Body,
+			&t.Status,
+			&t.Response,
+			&t.ReviewedBy,
+			&t.ReviewedAt,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// GetTakeoverRequest returns the takeover request with the given id.
+func GetTakeoverRequest(ctx context.Context, db *sql.DB, id uint) (*TakeoverRequest, error) {
+	requests, err := getTakeoverRequests(ctx, db, "WHERE community_takeover_requests.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, httperr.NewNotFound("takeover-request-not-found", "Takeover request not found.")
+	}
+	return requests[0], nil
+}
+
+// GetTakeoverRequests returns the takeover requests of all communities, the
+// oldest ones first. If status is not empty, only the requests of that status
+// are returned. The results are paginated.
+func GetTakeoverRequests(ctx context.Context, db *sql.DB, status TakeoverRequestStatus, limit, page int) ([]*TakeoverRequest, error) {
+	where, args := "", []any{}
+	if status != "" {
+		if !status.Valid() {
+			return nil, httperr.NewBadRequest("invalid-takeover-request-status", "Invalid takeover request status.")
+		}
+		where, args = "WHERE community_takeover_requests.status = ?", append(args, status)
+	}
+	where += " ORDER BY community_takeover_requests.created_at LIMIT ? OFFSET ?"
+	args = append(args, limit, limit*(page-1))
+	return getTakeoverRequests(ctx, db, where, args...)
+}
+
+// GetUserTakeoverRequests returns all the takeover requests made by user, the
+// latest ones first.
+func GetUserTakeoverRequests(ctx context.Context, db *sql.DB, user uid.ID) ([]*TakeoverRequest, error) {
+	return getTakeoverRequests(ctx, db, "WHERE community_takeover_requests.user_id = ? ORDER BY community_takeover_requests.created_at DESC", user)
+}
+
+// NewTakeoverRequest creates a request by user to become the top mod of c,
+// which must be abandoned (see Community.Abandoned). A user can only have one
+// open request per community.
+func (c *Community) NewTakeoverRequest(ctx context.Context, user uid.ID, body string) (*TakeoverRequest, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, httperr.NewBadRequest("takeover-request-empty", "Takeover request cannot be empty.")
+	}
+	if utf8.RuneCountInString(body) > maxTakeoverRequestLength {
+		return nil, httperr.NewBadRequest("takeover-request-too-long", fmt.Sprintf("Takeover request cannot exceed %d characters.", maxTakeoverRequestLength))
+	}
+
+	if abandoned, err := c.Abandoned(ctx); err != nil {
+		return nil, err
+	} else if !abandoned {
+		return nil, httperr.NewForbidden("community-not-abandoned", "The community has active moderators.")
+	}
+	if banned, err := c.UserBanned(ctx, user); err != nil {
+		return nil, err
+	} else if banned {
+		return nil, errUserBannedFromCommunity
+	}
+
+	var open bool
+	query := "SELECT COUNT(*) > 0 FROM community_takeover_requests WHERE community_id = ? AND user_id = ? AND status = ?"
+	if err := c.db.QueryRowContext(ctx, query, c.ID, user, TakeoverRequestStatusOpen).Scan(&open); err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, &httperr.Error{HTTPStatus: http.StatusConflict, Code: "takeover-request-exists", Message: "You already have an open takeover request for the community."}
+	}
+
+	result, err := c.db.ExecContext(ctx, "INSERT INTO community_takeover_requests (community_id, user_id, body) VALUES (?, ?, ?)", c.ID, user, body)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return GetTakeoverRequest(ctx, c.db, uint(id))
+}
+
+var errTakeoverRequestClosed = &httperr.Error{HTTPStatus: http.StatusConflict, Code: "takeover-request-closed", Message: "Takeover request is already reviewed."}
+
+// checkReviewer returns an error if reviewer cannot review t. Only admins can
+// review takeover requests.
+func (t *TakeoverRequest) checkReviewer(reviewer uid.ID) error {
+	if t.Status != TakeoverRequestStatusOpen {
+		return errTakeoverRequestClosed
+	}
+	if is, err := IsAdmin(t.db, &reviewer); err != nil {
+		return err
+	} else if !is {
+		return errNotAdmin
+	}
+	return nil
+}
+
+// modLogEntry returns a mod log entry of action taken on t by reviewer.
+func (t *TakeoverRequest) modLogEntry(reviewer uid.ID, action ModAction, message string) *ModLogEntry {
+	return &ModLogEntry{
+		CommunityID:  uid.NullID{ID: t.CommunityID, Valid: true},
+		ActorID:      reviewer,
+		ActorGroup:   UserGroupAdmins,
+		Action:       action,
+		TargetType:   "takeover_request",
+		TargetID:     strconv.FormatUint(uint64(t.ID), 10),
+		TargetUserID: uid.NullID{ID: t.UserID, Valid: true},
+		Reason:       message,
+	}
+}
+
+// close marks t as reviewed with status by reviewer, and records entry in the
+// mod log. The update function, if not nil, is run in the same transaction,
+// while the takeover requests of the community are locked, and only if t is
+// still open.
+func (t *TakeoverRequest) close(ctx context.Context, reviewer uid.ID, status TakeoverRequestStatus, message string, entry *ModLogEntry, update func(*sql.Tx) error) error {
+	now := time.Now()
+	err := msql.Transact(ctx, t.db, func(tx *sql.Tx) error {
+		// Lock the requests of the community so that concurrent reviews of
+		// them are serialized.
+		rows, err := tx.QueryContext(ctx, "SELECT id FROM community_takeover_requests WHERE community_id = ? FOR UPDATE", t.CommunityID)
+		if err != nil {
+			return err
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, "UPDATE community_takeover_requests SET status = ?, response = ?, reviewed_by = ?, reviewed_at = ? WHERE id = ? AND status = ?",
+			status, msql.NilIfEmptyString(message), reviewer, now, t.ID, TakeoverRequestStatusOpen)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n != 1 {
+			return errTakeoverRequestClosed
+		}
+
+		if update != nil {
+			if err := update(tx); err != nil {
+				return err
+			}
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	t.Status = status
+	t.Response = msql.NewNullString(msql.NilIfEmptyString(message))
+	t.ReviewedBy = uid.NullID{ID: reviewer, Valid: true}
+	t.ReviewedAt = msql.NewNullTime(now)
+	go func() {
+		if err := CreateTakeoverRequestNotification(context.Background(), t.db, t); err != nil {
+			log.Printf("Error creating takeover request notification (request id: %v): %v\n", t.ID, err)
+		}
+	}()
+	return nil
+}
+
+// validateTakeoverRequestResponse trims and validates message, the response of
+// a reviewer.
+func validateTakeoverRequestResponse(message string) (string, error) {
+	message = strings.TrimSpace(message)
+	if utf8.RuneCountInString(message) > maxTakeoverRequestResponseLength {
+		return "", httperr.NewBadRequest("takeover-request-response-too-long", fmt.Sprintf("Response cannot exceed %d characters.", maxTakeoverRequestResponseLength))
+	}
+	return message, nil
+}
+
+// Approve approves the request on behalf of reviewer, making the user the top
+// mod of the community. The existing mods, if any, remain mods below the user.
+// The message, which is sent to the user, is optional. The request can't be
+// approved if the community is no longer abandoned.
+func (t *TakeoverRequest) Approve(ctx context.Context, reviewer uid.ID, message string) error {
+	if err := t.checkReviewer(reviewer); err != nil {
+		return err
+	}
+	message, err := validateTakeoverRequestResponse(message)
+	if err != nil {
+		return err
+	}
+
+	comm, err := GetCommunityByID(ctx, t.db, t.CommunityID, nil)
+	if err != nil {
+		return err
+	}
+	// The original mods may have come back since the request was made.
+	if abandoned, err := comm.Abandoned(ctx); err != nil {
+		return err
+	} else if !abandoned {
+		return httperr.NewForbidden("community-not-abandoned", "The community has active moderators.")
+	}
+
+	if err := comm.Join(ctx, t.UserID); err != nil {
+		if e, ok := err.(*httperr.Error); !ok || e.HTTPStatus != http.StatusConflict {
+			return err
+		}
+	}
+	update := func(tx *sql.Tx) error {
+		// Another request for the community may have been approved since
+		// the check above.
+		var approved bool
+		query := "SELECT COUNT(*) > 0 FROM community_takeover_requests WHERE community_id = ? AND id <> ? AND status = ? AND reviewed_at >= ?"
+		if err := tx.QueryRowContext(ctx, query, t.CommunityID, t.ID, TakeoverRequestStatusApproved, t.CreatedAt).Scan(&approved); err != nil {
+			return err
+		}
+		if approved {
+			return httperr.NewForbidden("community-not-abandoned", "The community has active moderators.")
+		}
+		if err := makeUserModTx(ctx, tx, comm, t.UserID, true); err != nil {
+			return err
+		}
+		return moveModToTop(ctx, tx, t.CommunityID, t.UserID)
+	}
+	if err := t.close(ctx, reviewer, TakeoverRequestStatusApproved, message, t.modLogEntry(reviewer, ModActionApproveTakeover, message), update); err != nil {
+		return err
+	}
+	return comm.FixModPositions(ctx)
+}
+
+// Reject rejects the request on behalf of reviewer. The message, which is sent
+// to the user, is optional.
+func (t *TakeoverRequest) Reject(ctx context.Context, reviewer uid.ID, message string) error {
+	if err := t.checkReviewer(reviewer); err != nil {
+		return err
+	}
+	message, err := validateTakeoverRequestResponse(message)
+	if err != nil {
+		return err
+	}
+	return t.close(ctx, reviewer, TakeoverRequestStatusRejected, message, t.modLogEntry(reviewer, ModActionRejectTakeover, message), nil)
+}
@@ -0,0 +1,254 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strconv"
+	"time"
+
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// ThreadFollow is a user's subscription to the new comments of a post, or to
+// the new replies anywhere below a comment (its subtree). The authors of posts
+// follow their posts by default.
+type ThreadFollow struct {
+	ID        int        `json:"id"`
+	UserID    uid.ID     `json:"-"`
+	PostID    uid.ID     `json:"postId"`
+	CommentID uid.NullID `json:"commentId"` // If null, the whole post is followed.
+	CreatedAt time.Time  `json:"createdAt"`
+
+	Post    *Post    `json:"post"`
+	Comment *Comment `json:"comment,omitempty"`
+}
+
+// FollowThread makes user follow post, or, if comment is not nil, the replies
+// of comment (which should be a comment of post).
+func FollowThread(ctx context.Context, db *sql.DB, user, post uid.ID, comment *uid.ID) error {
+	if _, err := GetPost(ctx, db, &post, "", nil, false); err != nil {
+		return err
+	}
+	target, commentID := post, uid.NullID{}
+	if comment != nil {
+		c, err := GetComment(ctx, db, *comment, nil)
+		if err != nil {
+			return err
+		}
+		if !c.PostID.EqualsTo(post) {
+			return errCommentNotFound
+		}
+		if c.Deleted {
+			return errCommentDeleted
+		}
+		target = c.ID
+		commentID.Valid, commentID.ID = true, c.ID
+	}
+	_, err := db.ExecContext(ctx, "INSERT INTO thread_follows (user_id, post_id, comment_id, target_id) VALUES (?, ?, ?, ?)", user, post, commentID, target)
+	if err != nil && msql.IsErrDuplicateErr(err) {
+		return nil // already following
+	}
+	return err
+}
+
+// UnfollowThread undoes FollowThread.
+func UnfollowThread(ctx context.Context, db *sql.DB, user, post uid.ID, comment *uid.ID) error {
+	target := post
+	if comment != nil {
+		target = *comment
+	}
+	_, err := db.ExecContext(ctx, "DELETE FROM thread_follows WHERE user_id = ? AND post_id = ? AND target_id = ?", user, post, target)
+	return err
+}
+
+type ThreadFollowsResultSet struct {
+	Threads []*ThreadFollow `json:"threads"`
+	Next    *string         `json:"next"`
+}
+
+// GetFollowedThreads returns the threads that user follows, the most recently
+// followed ones first. Threads of deleted posts and comments are skipped.
+func GetFollowedThreads(ctx context.Context, db *sql.DB, user uid.ID, limit int, next *string) (*ThreadFollowsResultSet, error) {
+	where := "WHERE user_id = ?"
+	args := []any{user}
+	if next != nil {
+		id, err := strconv.Atoi(*next)
+		if err != nil {
+			return nil, errInvalidCursor
+		}
+		where += " AND id <= ?"
+		args = append(args, id)
+	}
+	args = append(args, limit+1)
+
+	rows, err := db.QueryContext(ctx, "SELECT id, user_id, post_id, comment_id, created_at FROM thread_follows "+where+" ORDER BY id DESC LIMIT ?", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var threads []*ThreadFollow
+	for rows.Next() {
+		t := &ThreadFollow{}
+		if err := rows.Scan(&t.ID, &t.UserID, &t.PostID, &t.CommentID, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		threads = append(threads, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	set := &ThreadFollowsResultSet{Threads: []*ThreadFollow{}}
+	if len(threads) > limit {
+		next := strconv.Itoa(threads[limit].ID)
+		set.Next = &next
+		threads = threads[:limit]
+	}
+
+	var postIDs, commentIDs []uid.ID
+	for _, t := range threads {
+		postIDs = append(postIDs, t.PostID)
+		if t.CommentID.Valid {
+			commentIDs = append(commentIDs, t.CommentID.ID)
+		}
+	}
+	posts, err := GetPostsByIDs(ctx, db, &user, false, postIDs...)
+	if err != nil && err != errPostNotFound {
+		return nil, err
+	}
+	comments, err := GetCommentsByIDs(ctx, db, &user, commentIDs...)
+	if err != nil && err != errCommentNotFound {
+		return nil, err
+	}
+
+	for _, t := range threads {
+		for _, post := range posts {
+			if post.ID == t.PostID {
+				t.Post = post
+				break
+			}
+		}
+		if t.CommentID.Valid {
+			for _, comment := range comments {
+				if comment.ID == t.CommentID.ID {
+					t.Comment = comment
+					break
+				}
+			}
+		}
+		if t.Post == nil || (t.CommentID.Valid && (t.Comment == nil || t.Comment.Deleted)) {
+			continue
+		}
+		set.Threads = append(set.Threads, t)
+	}
+	return set, nil
+}
+
+// FetchViewerFollows sets p.ViewerFollowing and p.ViewerFollowedComments.
+func (p *Post) FetchViewerFollows(ctx context.Context, viewer uid.ID) error {
+	rows, err := p.db.QueryContext(ctx, "SELECT comment_id FROM thread_follows WHERE user_id = ? AND post_id = ?", viewer, p.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	p.ViewerFollowing = false
+	p.ViewerFollowedComments = []uid.ID{}
+	for rows.Next() {
+		var comment uid.NullID
+		if err := rows.Scan(&comment); err != nil {
+			return err
+		}
+		if comment.Valid {
+			p.ViewerFollowedComments = append(p.ViewerFollowedComments, comment.ID)
+		} else {
+			p.ViewerFollowing = true
+		}
+	}
+	return rows.Err()
+}
+
+// threadFollowers returns the followers of a new comment of post, whose
+// ancestors are ancestors, mapped to the thread they follow (the comment
+// closest to the new comment, or null if only the whole post).
+func threadFollowers(ctx context.Context, db *sql.DB, post uid.ID, ancestors []uid.ID) (map[uid.ID]uid.NullID, error) {
+	rows, err := db.QueryContext(ctx, "SELECT user_id, comment_id FROM thread_follows WHERE post_id = ?", post)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	depth := func(thread uid.NullID) int {
+		if !thread.Valid {
+			return -1
+		}
+		for i, id := range ancestors {
+			if id == thread.ID {
+				return i
+			}
+		}
+		return -2 // not an ancestor
+	}
+
+	followers := make(map[uid.ID]uid.NullID)
+	for rows.Next() {
+		var (
+			user   uid.ID
+			thread uid.NullID
+		)
+		if err := rows.Scan(&user, &thread); err != nil {
+			return nil, err
+		}
+		d := depth(thread)
+		if d == -2 {
+			continue
+		}
+		if current, ok := followers[user]; !ok || d > depth(current) {
+			followers[user] = thread
+		}
+	}
+	return followers, rows.Err()
+}
+
+// sendNewCommentNotifications sends the notifications of a new comment (whose
+// ancestors are ancestors) to the author of the parent comment and to the
+// followers of the threads the comment is in. No user gets more than one
+// notification, and none are sent if author is shadowbanned.
+func sendNewCommentNotifications(ctx context.Context, db *sql.DB, post *Post, parent *Comment, comment uid.ID, ancestors []uid.ID, author *User) {
+	if author.Shadowbanned {
+		return
+	}
+
+	notified := map[uid.ID]bool{author.ID: true}
+
+	if parent != nil && !notified[parent.AuthorID] {
+		if err := CreateCommentReplyNotification(ctx, db, parent.AuthorID, parent.ID, comment, author, post); err != nil {
+			log.Printf("Create reply notification failed: %v\n", err)
+		}
+		notified[parent.AuthorID] = true
+	}
+
+	followers, err := threadFollowers(ctx, db, post.ID, ancestors)
+	if err != nil {
+		log.Printf("Error getting thread followers: %v\n", err)
+		return
+	}
+	for user, thread := range followers {
+		if notified[user] {
+			continue
+		}
+		notified[user] = true
+		if user == post.AuthorID && !thread.Valid {
+			if err := CreateNewCommentNotification(ctx, db, post, comment, author); err != nil {
+				log.Printf("Create new_comment notification failed: %v\n", err)
+			}
+			continue
+		}
+		if err := CreateThreadCommentNotification(ctx, db, user, post, thread, comment, author); err != nil {
+			log.Printf("Create thread_comment notification failed: %v\n", err)
+		}
+	}
+}
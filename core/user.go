@@ -112,26 +112,46 @@ type User struct {
 	Badges           Badges          `json:"badges"`
 	NumPosts         int             `json:"noPosts"`
 	NumComments      int             `json:"noComments"`
+	NumFollowers     int             `json:"noFollowers"`
+	NumFollowing     int             `json:"noFollowing"`
 	LastSeen         time.Time       `json:"-"` // accurate to within 5 minutes
 	CreatedAt        time.Time       `json:"createdAt"`
 	Deleted          bool            `json:"deleted"`
 	DeletedAt        msql.NullTime   `json:"deletedAt,omitempty"`
 
 	// User preferences.
-	UpvoteNotificationsOff  bool     `json:"upvoteNotificationsOff"`
-	ReplyNotificationsOff   bool     `json:"replyNotificationsOff"`
-	HomeFeed                FeedType `json:"homeFeed"`
-	RememberFeedSort        bool     `json:"rememberFeedSort"`
-	EmbedsOff               bool     `json:"embedsOff"`
-	HideUserProfilePictures bool     `json:"hideUserProfilePictures"`
+	HomeFeed                FeedType    `json:"homeFeed"`
+	RememberFeedSort        bool        `json:"rememberFeedSort"`
+	EmbedsOff               bool        `json:"embedsOff"`
+	HideUserProfilePictures bool        `json:"hideUserProfilePictures"`
+	EmailDigest             EmailDigest `json:"emailDigest"`
+	ReadPosts               ReadPosts   `json:"readPosts"`
 
 	// No banned users are supposed to be logged in. Make sure to log them out
 	// before banning.
 	BannedAt msql.NullTime `json:"bannedAt"`
 	Banned   bool          `json:"isBanned"`
 
+	// The expiry of the site ban, if the ban is not permanent. Expired bans
+	// are lifted by LiftExpiredBans.
+	BanExpires msql.NullTime   `json:"banExpires"`
+	BanReason  msql.NullString `json:"-"`
+	bannedBy   uid.NullID
+
+	// The posts, comments, and votes of shadowbanned users are visible only
+	// to themselves (and to the admins). ShadowbannedPublic is set only if the
+	// viewer is an admin.
+	ShadowbannedAt     msql.NullTime `json:"-"`
+	Shadowbanned       bool          `json:"-"`
+	ShadowbannedPublic *bool         `json:"isShadowbanned,omitempty"`
+
 	MutedByViewer bool `json:"-"`
 
+	// Whether the viewer follows the user, and whether the viewer gets
+	// notified of the user's new posts.
+	FollowedByViewer   bool `json:"isFollowing"`
+	ViewerFollowNotify bool `json:"followNotify"`
+
 	NumNewNotifications int `json:"notificationsNewCount"`
 
 	// The list of communities the user moderates.
@@ -224,17 +244,23 @@ func buildSelectUserQuery(where string) string {
 		"users.is_admin",
 		"users.no_posts",
 		"users.no_comments",
+		"users.no_followers",
+		"users.no_following",
 		"users.notifications_new_count",
 		"users.last_seen",
 		"users.created_at",
 		"users.deleted_at",
 		"users.banned_at",
-		"users.upvote_notifications_off",
-		"users.reply_notifications_off",
+		"users.ban_expires",
+		"users.ban_reason",
+		"users.banned_by",
+		"users.shadowbanned_at",
 		"users.home_feed",
 		"users.remember_feed_sort",
 		"users.embeds_off",
 		"users.hide_user_profile_pictures",
+		"users.email_digest",
+		"users.read_posts",
 	}
 	cols = append(cols, images.ImageColumns("pro_pic")...)
 	joins := []string{
@@ -339,17 +365,23 @@ func scanUsers(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *uid.ID)
 			&u.Admin,
 			&u.NumPosts,
 			&u.NumComments,
+			&u.NumFollowers,
+			&u.NumFollowing,
 			&u.NumNewNotifications,
 			&u.LastSeen,
 			&u.CreatedAt,
 			&u.DeletedAt,
 			&u.BannedAt,
-			&u.UpvoteNotificationsOff,
-			&u.ReplyNotificationsOff,
+			&u.BanExpires,
+			&u.BanReason,
+			&u.bannedBy,
+			&u.ShadowbannedAt,
 			&u.HomeFeed,
 			&u.RememberFeedSort,
 			&u.EmbedsOff,
 			&u.HideUserProfilePictures,
+			&u.EmailDigest,
+			&u.ReadPosts,
 		}
 
 		proPic := &images.Image{}
@@ -367,8 +399,9 @@ func scanUsers(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *uid.ID)
 		u.preGhostBadges = u.Badges
 
 		if u.BannedAt.Valid {
-			u.Banned = true
+			u.Banned = !u.BanExpires.Valid || u.BanExpires.Time.After(time.Now())
 		}
+		u.Shadowbanned = u.ShadowbannedAt.Valid
 
 		if proPic.ID != nil {
 			proPic.PostScan()
@@ -399,6 +432,9 @@ func scanUsers(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *uid.ID)
 				}
 			}
 		}
+		if err := fetchViewerFollows(ctx, db, *viewer, users...); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := fetchBadges(db, users...); err != nil {
@@ -419,6 +455,10 @@ func scanUsers(ctx context.Context, db *sql.DB, rows *sql.Rows, viewer *uid.ID)
 				*user.EmailPublic = user.Email.String
 			}
 		}
+		if viewerAdmin {
+			user.ShadowbannedPublic = new(bool)
+			*user.ShadowbannedPublic = user.Shadowbanned
+		}
 		// Set the user info of deleted users to the ghost user for everyone
 		// except the admins.
 		if user.Deleted && !viewerAdmin {
@@ -586,22 +626,29 @@ func (u *User) Update(ctx context.Context) error {
 	UPDATE users SET
 		email = ?, 
 		about_me = ?,
-		upvote_notifications_off = ?,
-		reply_notifications_off = ?,
 		home_feed = ?,
 		remember_feed_sort = ?,
 		embeds_off = ?,
-		hide_user_profile_pictures = ?
+		hide_user_profile_pictures = ?,
+		email_digest = ?,
+		read_posts = ?
 	WHERE id = ?`,
 		u.EmailPublic,
 		u.About,
-		u.UpvoteNotificationsOff,
-		u.ReplyNotificationsOff,
 		u.HomeFeed,
 		u.RememberFeedSort,
 		u.EmbedsOff,
 		u.HideUserProfilePictures,
+		u.EmailDigest,
+		u.ReadPosts,
 		u.ID)
+	if err != nil {
+		return err
+	}
+	if u.ReadPosts == ReadPostsOff {
+		// Tracking is turned off, so forget the posts read so far.
+		_, err = u.db.ExecContext(ctx, "DELETE FROM post_reads WHERE user_id = ?", u.ID)
+	}
 	return err
 }
 
@@ -655,6 +702,9 @@ func (u *User) Delete(ctx context.Context) error {
 		if _, err := tx.ExecContext(ctx, "DELETE FROM community_members WHERE user_id = ?", u.ID); err != nil {
 			return err
 		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM community_member_requests WHERE user_id = ?", u.ID); err != nil {
+			return err
+		}
 
 		// Remove the user from all mod positions the user holds.
 		if _, err := tx.ExecContext(ctx, "DELETE FROM community_mods WHERE user_id = ?", u.ID); err != nil {
@@ -691,6 +741,43 @@ func (u *User) Delete(ctx context.Context) error {
 			return err
 		}
 
+		// Delete the user's notification preferences.
+		if _, err := tx.ExecContext(ctx, "DELETE FROM notification_preferences WHERE user_id = ?", u.ID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM notification_mutes WHERE user_id = ?", u.ID); err != nil {
+			return err
+		}
+
+		// Unfollow all users and remove all followers.
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET no_followers = no_followers - 1 WHERE id IN (SELECT followee_id FROM user_follows WHERE follower_id = ?)", u.ID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET no_following = no_following - 1 WHERE id IN (SELECT follower_id FROM user_follows WHERE followee_id = ?)", u.ID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM user_follows WHERE follower_id = ? OR followee_id = ?", u.ID, u.ID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET no_followers = 0, no_following = 0 WHERE id = ?", u.ID); err != nil {
+			return err
+		}
+
+		// Unfollow all threads.
+		if _, err := tx.ExecContext(ctx, "DELETE FROM thread_follows WHERE user_id = ?", u.ID); err != nil {
+			return err
+		}
+
+		// Forget the read posts.
+		if _, err := tx.ExecContext(ctx, "DELETE FROM post_reads WHERE user_id = ?", u.ID); err != nil {
+			return err
+		}
+
+		// Remove the user from all conversations.
+		if _, err := tx.ExecContext(ctx, "DELETE FROM conversation_members WHERE user_id = ?", u.ID); err != nil {
+			return err
+		}
+
 		// Delete the user's lists.
 		if _, err := tx.ExecContext(ctx, "DELETE FROM lists WHERE user_id = ?", u.ID); err != nil {
 			return err
@@ -759,7 +846,7 @@ func (u *User) DeleteContent(ctx context.Context, n int, admin uid.ID) error {
 
 	for _, post := range posts {
 		if !(post.Deleted && post.DeletedContent) {
-			if err := post.Delete(ctx, admin, UserGroupAdmins, true, false); err != nil {
+			if err := post.Delete(ctx, admin, UserGroupAdmins, true, false, nil); err != nil {
 				return err
 			}
 		}
@@ -784,7 +871,7 @@ func (u *User) DeleteContent(ctx context.Context, n int, admin uid.ID) error {
 
 	for _, comment := range comments {
 		if !comment.Deleted {
-			if err := comment.Delete(ctx, admin, UserGroupAdmins); err != nil {
+			if err := comment.Delete(ctx, admin, UserGroupAdmins, nil); err != nil {
 				return err
 			}
 		}
@@ -793,33 +880,124 @@ func (u *User) DeleteContent(ctx context.Context, n int, admin uid.ID) error {
 	return nil
 }
 
-// Ban bans the user from site. Important: Make sure to log out all sessions of
-// this user before calling this function, and never allow this user to login.
+// Ban bans the user from site on behalf of admin. If expires is nil, the ban is
+// permanent. Important: Make sure to log out all sessions of this user before
+// calling this function, and never allow this user to login.
 //
 // Note: An admin can be banned.
-func (u *User) Ban(ctx context.Context) error {
+func (u *User) Ban(ctx context.Context, admin uid.ID, expires *time.Time, reason string) error {
 	if u.Deleted {
 		return ErrUserDeleted
 	}
 
 	t := time.Now()
-	_, err := u.db.ExecContext(ctx, "UPDATE users SET banned_at = ? WHERE id = ?", t, u.ID)
+	var e msql.NullTime
+	if expires != nil {
+		if !expires.After(t) {
+			return httperr.NewBadRequest("ban-expiry-past", "Ban expiry must be in the future.")
+		}
+		e = msql.NewNullTime(*expires)
+	}
+	reason = utils.TruncateUnicodeString(strings.TrimSpace(reason), maxBanReasonLength)
+
+	entry := u.siteModLogEntry(admin, ModActionSiteBanUser)
+	entry.Reason = reason
+	if e.Valid {
+		entry.Metadata = map[string]any{"expires": e.Time}
+	}
+	err := msql.Transact(ctx, u.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET banned_at = ?, ban_expires = ?, ban_reason = ?, banned_by = ? WHERE id = ?",
+			t, e, msql.NilIfEmptyString(reason), admin, u.ID); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, entry)
+	})
 	if err == nil {
 		u.BannedAt = msql.NewNullTime(t)
 		u.Banned = true
+		u.BanExpires = e
+		u.BanReason = msql.NewNullString(msql.NilIfEmptyString(reason))
+		u.bannedBy = uid.NullID{ID: admin, Valid: true}
+	}
+	return err
+}
+
+// Unban unbans the user from site on behalf of admin.
+func (u *User) Unban(ctx context.Context, admin uid.ID) error {
+	if u.Deleted {
+		return ErrUserDeleted
+	}
+
+	return msql.Transact(ctx, u.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET banned_at = NULL, ban_expires = NULL, ban_reason = NULL, banned_by = NULL WHERE id = ?", u.ID); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, u.siteModLogEntry(admin, ModActionSiteUnbanUser))
+	})
+}
+
+// Shadowban shadowbans the user on behalf of admin. The posts, comments, and
+// votes of the user from here on are hidden from everyone but the user and the
+// admins, and the user's content no longer triggers notifications. Unlike a
+// ban, the user is not logged out, and nothing is shown to the user.
+func (u *User) Shadowban(ctx context.Context, admin uid.ID) error {
+	if u.Deleted {
+		return ErrUserDeleted
+	}
+	if u.Shadowbanned {
+		return nil
+	}
+
+	t := time.Now()
+	err := msql.Transact(ctx, u.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET shadowbanned_at = ?, shadowbanned_by = ? WHERE id = ?", t, admin, u.ID); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, u.siteModLogEntry(admin, ModActionShadowbanUser))
+	})
+	if err == nil {
+		u.ShadowbannedAt = msql.NewNullTime(t)
+		u.Shadowbanned = true
 	}
 	return err
 }
 
-func (u *User) Unban(ctx context.Context) error {
+// Unshadowban lifts the shadowban of the user on behalf of admin. The votes
+// the user cast while shadowbanned remain uncounted.
+func (u *User) Unshadowban(ctx context.Context, admin uid.ID) error {
 	if u.Deleted {
 		return ErrUserDeleted
 	}
+	if !u.Shadowbanned {
+		return nil
+	}
 
-	_, err := u.db.ExecContext(ctx, "UPDATE users SET banned_at = NULL WHERE id = ?", u.ID)
+	err := msql.Transact(ctx, u.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET shadowbanned_at = NULL, shadowbanned_by = NULL WHERE id = ?", u.ID); err != nil {
+			return err
+		}
+		return insertModLogEntry(ctx, tx, u.siteModLogEntry(admin, ModActionUnshadowbanUser))
+	})
+	if err == nil {
+		u.ShadowbannedAt = msql.NullTime{}
+		u.Shadowbanned = false
+	}
 	return err
 }
 
+// siteModLogEntry returns a mod log entry of a site-wide action taken against
+// u by admin.
+func (u *User) siteModLogEntry(admin uid.ID, action ModAction) *ModLogEntry {
+	return &ModLogEntry{
+		ActorID:      admin,
+		ActorGroup:   UserGroupAdmins,
+		Action:       action,
+		TargetType:   "user",
+		TargetID:     u.ID.String(),
+		TargetUserID: uid.NullID{ID: u.ID, Valid: true},
+	}
+}
+
 // MakeAdmin makes the user an admin of the site. If isAdmin is false
 // admin is removed as an admin.
 func (u *User) MakeAdmin(ctx context.Context, isAdmin bool) error {
@@ -852,6 +1030,7 @@ func (u *User) ResetNewNotificationsCount(ctx context.Context) error {
 	err := resetNewNotificationsCount(ctx, u.db, u.ID)
 	if err == nil {
 		u.NumNewNotifications = 0
+		publishNotificationsCount(ctx, u.db, u.ID)
 	}
 	return err
 }
@@ -926,13 +1105,31 @@ func CountAllUsers(ctx context.Context, db *sql.DB) (n int, err error) {
 }
 
 func (u *User) LoadModdingList(ctx context.Context) error {
-	comms, err := getCommunities(ctx, u.db, nil, "WHERE communities.id IN (SELECT community_mods.community_id FROM community_mods WHERE user_id = ?)", u.ID)
+	comms, err := getCommunities(ctx, u.db, nil, "WHERE communities.deleted_at IS NULL AND communities.id IN (SELECT community_mods.community_id FROM community_mods WHERE user_id = ?)", u.ID)
 	if err == nil {
 		u.ModdingList = comms
 	}
 	return err
 }
 
+// LoadModQueueCounts sets the number of items in the mod queue of each of the
+// communities of u.ModdingList. Call LoadModdingList first.
+func (u *User) LoadModQueueCounts(ctx context.Context) error {
+	ids := make([]uid.ID, len(u.ModdingList))
+	for i, comm := range u.ModdingList {
+		ids[i] = comm.ID
+	}
+	counts, err := CountModQueue(ctx, u.db, ids)
+	if err != nil {
+		return err
+	}
+	for _, comm := range u.ModdingList {
+		n := counts[comm.ID]
+		comm.ModQueueCount = &n
+	}
+	return nil
+}
+
 func (u *User) DeleteProPicTx(ctx context.Context, tx *sql.Tx) error {
 	if u.ProPic == nil {
 		return nil
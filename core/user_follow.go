@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// FollowUser makes follower follow followee, so that followee's posts show up
+// in follower's following feed. If notify is true, follower is notified of
+// followee's new posts. If follower already follows followee, only the notify
+// setting is updated.
+func FollowUser(ctx context.Context, db *sql.DB, follower, followee uid.ID, notify bool) error {
+	if follower == followee {
+		return httperr.NewBadRequest("follow_self", "Cannot follow yourself.")
+	}
+	user, err := GetUser(ctx, db, followee, nil)
+	if err != nil {
+		return err
+	}
+	if user.Deleted {
+		return ErrUserDeleted
+	}
+	if user.Banned {
+		return httperr.NewForbidden("user_banned", "User is banned.")
+	}
+
+	return msql.Transact(ctx, db, func(tx *sql.Tx) error {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM user_follows WHERE follower_id = ? AND followee_id = ?)", follower, followee).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			_, err := tx.ExecContext(ctx, "UPDATE user_follows SET notify = ? WHERE follower_id = ? AND followee_id = ?", notify, follower, followee)
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "INSERT INTO user_follows (follower_id, followee_id, notify) VALUES (?, ?, ?)", follower, followee, notify); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET no_followers = no_followers + 1 WHERE id = ?", followee); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "UPDATE users SET no_following = no_following + 1 WHERE id = ?", follower)
+		return err
+	})
+}
+
+// UnfollowUser undoes FollowUser.
+func UnfollowUser(ctx context.Context, db *sql.DB, follower, followee uid.ID) error {
+	return msql.Transact(ctx, db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, "DELETE FROM user_follows WHERE follower_id = ? AND followee_id = ?", follower, followee)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return nil // not following
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET no_followers = no_followers - 1 WHERE id = ?", followee); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE users SET no_following = no_following - 1 WHERE id = ?", follower)
+		return err
+	})
+}
+
+// GetFollowedUsers returns the users that user follows, the most recently
+// followed first. Deleted and banned users are skipped.
+func GetFollowedUsers(ctx context.Context, db *sql.DB, user uid.ID) ([]*User, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT followee_id FROM user_follows
+		INNER JOIN users ON users.id = user_follows.followee_id
+		WHERE follower_id = ? AND users.deleted_at IS NULL AND users.banned_at IS NULL
+		ORDER BY user_follows.id DESC`, user)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*User{}, nil
+	}
+
+	users, err := GetUsersByIDs(ctx, db, ids, &user)
+	if err != nil {
+		return nil, err
+	}
+	// Restore the order.
+	ordered := make([]*User, 0, len(users))
+	for _, id := range ids {
+		for _, u := range users {
+			if u.ID == id {
+				ordered = append(ordered, u)
+				break
+			}
+		}
+	}
+	return ordered, nil
+}
+
+// fetchViewerFollows sets the FollowedByViewer and ViewerFollowNotify fields
+// of users.
+func fetchViewerFollows(ctx context.Context, db *sql.DB, viewer uid.ID, users ...*User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	args := []any{viewer}
+	for _, u := range users {
+		args = append(args, u.ID)
+	}
+	query := fmt.Sprintf("SELECT followee_id, notify FROM user_follows WHERE follower_id = ? AND followee_id IN %s", msql.InClauseQuestionMarks(len(users)))
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			followee uid.ID
+			notify   bool
+		)
+		if err := rows.Scan(&followee, &notify); err != nil {
+			return err
+		}
+		for _, u := range users {
+			if u.ID == followee {
+				u.FollowedByViewer = true
+				u.ViewerFollowNotify = notify
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// whereSelectFollowedUsers returns a where clause condition that selects the
+// posts, in table, of the users that a user (the query argument) follows.
+// Posts of banned users are excluded.
+func whereSelectFollowedUsers(table string) string {
+	return table + `.user_id IN (
+		SELECT followee_id FROM user_follows
+		INNER JOIN users ON users.id = user_follows.followee_id
+		WHERE follower_id = ? AND users.banned_at IS NULL) `
+}
+
+// sendNewPostNotifications notifies the followers of the author of post who
+// opted in to new post notifications (unless the author is shadowbanned), and
+// who can view the community of post.
+func sendNewPostNotifications(ctx context.Context, db *sql.DB, post *Post) {
+	if post.authorShadowbanned {
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT user_follows.follower_id FROM user_follows
+		INNER JOIN users ON users.id = user_follows.follower_id
+		WHERE user_follows.followee_id = ? AND user_follows.notify = TRUE
+			AND users.deleted_at IS NULL AND users.banned_at IS NULL
+			AND user_follows.follower_id NOT IN (SELECT user_id FROM muted_users WHERE muted_user_id = ?)
+			AND user_follows.follower_id NOT IN (SELECT user_id FROM muted_communities WHERE community_id = ?)`,
+		post.AuthorID, post.AuthorID, post.CommunityID)
+	if err != nil {
+		log.Printf("Error getting followers of user %v: %v\n", post.AuthorID, err)
+		return
+	}
+	followers, err := scanIDs(rows)
+	if err != nil {
+		log.Printf("Error getting followers of user %v: %v\n", post.AuthorID, err)
+		return
+	}
+
+	n := NotificationNewPost{
+		PostID:      post.ID,
+		Author:      post.AuthorUsername,
+		SubmittedAt: post.CreatedAt,
+	}
+	for _, follower := range followers {
+		if viewable, err := CommunityViewableBy(ctx, db, post.CommunityID, &follower); err != nil {
+			log.Printf("Error checking community visibility for user %v: %v\n", follower, err)
+			continue
+		} else if !viewable {
+			continue
+		}
+		if err := CreateNotification(ctx, db, follower, NotificationTypeNewPost, n); err != nil {
+			log.Printf("Create new_post notification failed: %v\n", err)
+		}
+	}
+}
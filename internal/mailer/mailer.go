@@ -0,0 +1,188 @@
+// Package mailer sends emails.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A Message is an email with a plain-text body and, optionally, an HTML body.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+
+	Text string
+	HTML string // Optional.
+
+	// Additional headers (like List-Unsubscribe).
+	Headers map[string]string
+}
+
+// Bytes returns the message encoded in the RFC 5322 format. If the message has
+// an HTML body, the message is a multipart/alternative message.
+func (m *Message) Bytes() ([]byte, error) {
+	if m.From == "" || m.To == "" {
+		return nil, errors.New("mailer: message has no sender or recipient")
+	}
+
+	var buf bytes.Buffer
+	headers := map[string]string{
+		"From":         m.From,
+		"To":           m.To,
+		"Subject":      mime.QEncoding.Encode("utf-8", m.Subject),
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"MIME-Version": "1.0",
+	}
+	for key, value := range m.Headers {
+		headers[key] = value
+	}
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.ContainsAny(headers[key], "\r\n") {
+			return nil, fmt.Errorf("mailer: invalid value for header %s", key)
+		}
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, headers[key])
+	}
+
+	if m.HTML == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, m.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	w := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", w.Boundary())
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(pw, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, s string) error {
+	qw := quotedprintable.NewWriter(w)
+	if _, err := qw.Write([]byte(s)); err != nil {
+		return err
+	}
+	return qw.Close()
+}
+
+// A Mailer sends emails.
+type Mailer interface {
+	Send(ctx context.Context, m *Message) error
+}
+
+// FileMailer is a Mailer that, instead of sending emails, writes them to files
+// in a folder. It's meant for development and testing.
+type FileMailer struct {
+	Dir string
+}
+
+// NewFileMailer returns a FileMailer that writes emails to dir (which is
+// created if it does not exist).
+func NewFileMailer(dir string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileMailer{Dir: dir}, nil
+}
+
+// Send implements Mailer.
+func (f *FileMailer) Send(ctx context.Context, m *Message) error {
+	b, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+	random := make([]byte, 4)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), hex.EncodeToString(random))
+	return os.WriteFile(filepath.Join(f.Dir, name), b, 0644)
+}
+
+// SMTPMailer is a Mailer that sends emails through an SMTP server.
+type SMTPMailer struct {
+	Addr     string // Of the form "host:port".
+	Username string // If empty, no authentication is done.
+	Password string
+}
+
+// NewSMTPMailer returns an SMTPMailer.
+func NewSMTPMailer(addr, username, password string) *SMTPMailer {
+	return &SMTPMailer{
+		Addr:     addr,
+		Username: username,
+		Password: password,
+	}
+}
+
+// Send implements Mailer.
+func (s *SMTPMailer) Send(ctx context.Context, m *Message) error {
+	b, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+	from, err := parseAddress(m.From)
+	if err != nil {
+		return err
+	}
+	to, err := parseAddress(m.To)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, _ := strings.Cut(s.Addr, ":")
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	return smtp.SendMail(s.Addr, auth, from, []string{to}, b)
+}
+
+// parseAddress returns the bare email address of an address of the form
+// "Name <user@example.com>" or "user@example.com".
+func parseAddress(addr string) (string, error) {
+	a, err := mail.ParseAddress(addr)
+	if err != nil {
+		return "", fmt.Errorf("mailer: invalid address %q: %w", addr, err)
+	}
+	return a.Address, nil
+}
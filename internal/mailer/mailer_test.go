@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"io"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestMessageBytes(t *testing.T) {
+	m := &Message{
+		From:    "Discuit <no-reply@example.com>",
+		To:      "alice@example.com",
+		Subject: "Hello",
+		Text:    "plain body",
+		HTML:    "<p>html body</p>",
+		Headers: map[string]string{"List-Unsubscribe": "<https://example.com/unsubscribe>"},
+	}
+	b, err := m.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.Header.Get("List-Unsubscribe"); got != m.Headers["List-Unsubscribe"] {
+		t.Errorf("expected List-Unsubscribe header %q, got %q", m.Headers["List-Unsubscribe"], got)
+	}
+	if ct := msg.Header.Get("Content-Type"); !strings.HasPrefix(ct, "multipart/alternative") {
+		t.Errorf("expected a multipart/alternative message, got %q", ct)
+	}
+	body, _ := io.ReadAll(msg.Body)
+	for _, s := range []string{"plain body", "<p>html body</p>"} {
+		if !strings.Contains(string(body), s) {
+			t.Errorf("message body does not contain %q", s)
+		}
+	}
+
+	m.Headers = map[string]string{"X-Injected": "a\r\nBcc: eve@example.com"}
+	if _, err := m.Bytes(); err == nil {
+		t.Error("expected an error for a header with a newline")
+	}
+}
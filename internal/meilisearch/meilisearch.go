@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"log"
 
 	"github.com/discuitnet/discuit/config"
@@ -85,6 +86,10 @@ func NewSearchClient(host, key string) *MeiliSearch {
 }
 
 func (c *MeiliSearch) index(indexName string, documents []map[string]interface{}, primaryKey ...string) error {
+	if len(documents) == 0 {
+		return nil
+	}
+
 	index := c.client.Index(indexName)
 	var objects []map[string]interface{}
 	batchSize := 50 * 1024 * 1024 // 50MiB
@@ -233,8 +238,8 @@ func (c *MeiliSearch) IndexAllUsersInMeiliSearch(ctx context.Context, db *sql.DB
 		// Convert the users to the format MeiliSearch expects.
 		var usersToIndex []User
 		for _, user := range users {
-			// Exclude the ghost user.
-			if user.Username == "ghost" {
+			// Exclude the ghost user and the shadowbanned users.
+			if user.Username == "ghost" || user.Shadowbanned {
 				continue
 			}
 
@@ -306,23 +311,11 @@ func (c *MeiliSearch) IndexAllPostsInMeiliSearch(ctx context.Context, db *sql.DB
 		// Convert the posts to the format MeiliSearch expects.
 		var postsToIndex []Post
 		for _, post := range posts {
-			postsToIndex = append(postsToIndex, Post{
-				ID:   post.ID,
-				Type: post.Type,
-
-				PublicID: post.PublicID,
-
-				AuthorID:       post.AuthorID,
-				AuthorUsername: post.AuthorUsername,
-
-				Title: post.Title,
-				Body:  post.Body,
-
-				CommunityID:   post.CommunityID,
-				CommunityName: post.CommunityName,
-
-				CreatedAt: post.CreatedAt.Unix(),
-			})
+			// Exclude the posts of shadowbanned users.
+			if post.AuthorShadowbanned() {
+				continue
+			}
+			postsToIndex = append(postsToIndex, newPostDocument(post))
 		}
 
 		// Convert to interface slice.
@@ -406,6 +399,18 @@ func (c *MeiliSearch) DeleteDocument(ctx context.Context, indexName string, docu
 	return nil
 }
 
+// DeleteDocumentsByFilter deletes all the documents of the index that match
+// filter.
+func (c *MeiliSearch) DeleteDocumentsByFilter(ctx context.Context, indexName string, filter string) error {
+	index := c.client.Index(indexName)
+	_, err := index.DeleteDocumentsByFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (c *MeiliSearch) GarbageCollect(ctx context.Context) error {
 	// TODO: Ensure that we are not holding on to documents that have been deleted in the database.
 	return nil
@@ -432,7 +437,7 @@ func CommunityUpdateOrCreateDocumentIfEnabled(ctx context.Context, config *confi
 }
 
 func UserUpdateOrCreateDocumentIfEnabled(ctx context.Context, config *config.Config, user *core.User) {
-	if !config.MeiliEnabled {
+	if !config.MeiliEnabled || user.Shadowbanned {
 		return
 	}
 
@@ -451,28 +456,12 @@ func UserUpdateOrCreateDocumentIfEnabled(ctx context.Context, config *config.Con
 }
 
 func PostUpdateOrCreateDocumentIfEnabled(ctx context.Context, config *config.Config, post *core.Post) {
-	if !config.MeiliEnabled {
+	if !config.MeiliEnabled || post.AuthorShadowbanned() {
 		return
 	}
 
 	client := NewSearchClient(config.MeiliHost, config.MeiliKey)
-	err := client.UpdateOrCreateDocument(ctx, "posts", Post{
-		ID:   post.ID,
-		Type: post.Type,
-
-		PublicID: post.PublicID,
-
-		AuthorID:       post.AuthorID,
-		AuthorUsername: post.AuthorUsername,
-
-		Title: post.Title,
-		Body:  post.Body,
-
-		CommunityID:   post.CommunityID,
-		CommunityName: post.CommunityName,
-
-		CreatedAt: post.CreatedAt.Unix(),
-	})
+	err := client.UpdateOrCreateDocument(ctx, "posts", newPostDocument(post))
 	if err != nil {
 		log.Printf("Error updating or creating document in MeiliSearch: %v", err)
 	}
@@ -514,6 +503,135 @@ func PostDeleteDocumentIfEnabled(ctx context.Context, config *config.Config, pos
 	}
 }
 
+// UserPostsDeleteDocumentsIfEnabled removes all the posts of the user from the
+// posts index.
+func UserPostsDeleteDocumentsIfEnabled(ctx context.Context, config *config.Config, userID string) {
+	if !config.MeiliEnabled {
+		return
+	}
+
+	client := NewSearchClient(config.MeiliHost, config.MeiliKey)
+	err := client.DeleteDocumentsByFilter(ctx, "posts", fmt.Sprintf("user_id = %q", userID))
+	if err != nil {
+		log.Printf("Error deleting documents in MeiliSearch: %v", err)
+	}
+}
+
+// CommunityPostsUpdateDocumentsIfEnabled updates the community name of all the
+// posts of comm in the posts index (after comm is renamed).
+func CommunityPostsUpdateDocumentsIfEnabled(ctx context.Context, config *config.Config, db *sql.DB, comm *core.Community) {
+	if !config.MeiliEnabled {
+		return
+	}
+
+	// Only the indexed posts, so as to not create partial documents.
+	rows, err := db.QueryContext(ctx, `
+		SELECT posts.id FROM posts
+		INNER JOIN users ON users.id = posts.user_id
+		WHERE posts.community_id = ? AND posts.deleted_at IS NULL AND users.shadowbanned_at IS NULL`, comm.ID)
+	if err != nil {
+		log.Printf("Error getting posts of community %v: %v", comm.ID, err)
+		return
+	}
+	defer rows.Close()
+
+	var documents []map[string]interface{}
+	for rows.Next() {
+		var id uid.ID
+		if err := rows.Scan(&id); err != nil {
+			log.Printf("Error getting posts of community %v: %v", comm.ID, err)
+			return
+		}
+		documents = append(documents, map[string]interface{}{
+			"id":             id,
+			"community_name": comm.Name,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error getting posts of community %v: %v", comm.ID, err)
+		return
+	}
+
+	client := NewSearchClient(config.MeiliHost, config.MeiliKey)
+	if err := client.index("posts", documents, "id"); err != nil {
+		log.Printf("Error updating documents in MeiliSearch: %v", err)
+	}
+}
+
+// UserPostsUpdateOrCreateDocumentsIfEnabled adds all the (non-deleted) posts
+// of the user to the posts index (after the user is unshadowbanned).
+func UserPostsUpdateOrCreateDocumentsIfEnabled(ctx context.Context, config *config.Config, db *sql.DB, userID uid.ID) {
+	if !config.MeiliEnabled {
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT id FROM posts WHERE user_id = ? AND deleted_at IS NULL", userID)
+	if err != nil {
+		log.Printf("Error getting posts of user %v: %v", userID, err)
+		return
+	}
+	defer rows.Close()
+
+	var ids []uid.ID
+	for rows.Next() {
+		var id uid.ID
+		if err := rows.Scan(&id); err != nil {
+			log.Printf("Error getting posts of user %v: %v", userID, err)
+			return
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error getting posts of user %v: %v", userID, err)
+		return
+	}
+
+	posts, err := core.GetPostsByIDs(ctx, db, nil, false, ids...)
+	if err != nil {
+		log.Printf("Error getting posts of user %v: %v", userID, err)
+		return
+	}
+	if len(posts) == 0 {
+		return
+	}
+
+	var postsToIndex = make([]interface{}, len(posts))
+	for i, post := range posts {
+		postsToIndex[i] = newPostDocument(post)
+	}
+	documents, err := utils.ConvertToMapSlice(postsToIndex)
+	if err != nil {
+		log.Printf("Error converting posts of user %v: %v", userID, err)
+		return
+	}
+
+	client := NewSearchClient(config.MeiliHost, config.MeiliKey)
+	if err := client.index("posts", documents, "id"); err != nil {
+		log.Printf("Error updating or creating documents in MeiliSearch: %v", err)
+	}
+}
+
+// newPostDocument returns the search document of post.
+func newPostDocument(post *core.Post) Post {
+	return Post{
+		ID:   post.ID,
+		Type: post.Type,
+
+		PublicID: post.PublicID,
+
+		AuthorID:       post.AuthorID,
+		AuthorUsername: post.AuthorUsername,
+
+		Title: post.Title,
+		Body:  post.Body,
+
+		CommunityID:   post.CommunityID,
+		CommunityName: post.CommunityName,
+
+		CreatedAt: post.CreatedAt.Unix(),
+	}
+}
+
 func sendBatch(indexObj *meilisearch.Index, objects []map[string]interface{}, primaryKey ...string) error {
 	data, err := json.Marshal(objects)
 	if err != nil {
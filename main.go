@@ -7,6 +7,7 @@ import (
 	discuitCLI "github.com/discuitnet/discuit/cli"
 	"github.com/discuitnet/discuit/cli/addalluserstocommunity"
 	"github.com/discuitnet/discuit/cli/admin"
+	"github.com/discuitnet/discuit/cli/community"
 	"github.com/discuitnet/discuit/cli/deleteuser"
 	"github.com/discuitnet/discuit/cli/fixhotness"
 	"github.com/discuitnet/discuit/cli/forcepasschange"
@@ -36,6 +37,7 @@ func main() {
 			serve.Command,
 			admin.Command,
 			mod.Command,
+			community.Command,
 			hardreset.Command,
 			populatepost.Command,
 			forcepasschange.Command,
@@ -0,0 +1 @@
+alter table users drop column mention_notifications_off;
@@ -0,0 +1 @@
+alter table users add column mention_notifications_off bool not null default false;
@@ -0,0 +1,5 @@
+drop table if exists conversation_messages;
+
+drop table if exists conversation_members;
+
+drop table if exists conversations;
@@ -0,0 +1,41 @@
+create table if not exists conversations (
+	id binary (12) not null,
+	created_by binary (12) not null,
+	is_group bool not null default false,
+	title varchar (128),
+	last_message_at datetime not null default current_timestamp(),
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	foreign key (created_by) references users (id)
+);
+
+create table if not exists conversation_members (
+	conversation_id binary (12) not null,
+	user_id binary (12) not null,
+	muted bool not null default false,
+	last_read_message_id binary (12), /* For read receipts. */
+	last_read_at datetime,
+	joined_at datetime not null default current_timestamp(),
+
+	primary key (conversation_id, user_id),
+	index (user_id),
+	foreign key (conversation_id) references conversations (id) on delete cascade,
+	foreign key (user_id) references users (id)
+);
+
+create table if not exists conversation_messages (
+	id binary (12) not null,
+	conversation_id binary (12) not null,
+	user_id binary (12) not null,
+	body text,
+	image_id binary (12),
+	created_at datetime not null default current_timestamp(),
+	deleted_at datetime,
+
+	primary key (id),
+	index (conversation_id, id),
+	foreign key (conversation_id) references conversations (id) on delete cascade,
+	foreign key (user_id) references users (id),
+	foreign key (image_id) references images (id)
+);
@@ -0,0 +1,2 @@
+alter table users drop column email_digest_sent_at;
+alter table users drop column email_digest;
@@ -0,0 +1,12 @@
+alter table notifications drop column in_app;
+
+alter table users add column upvote_notifications_off bool not null default false;
+alter table users add column reply_notifications_off bool not null default false;
+alter table users add column mention_notifications_off bool not null default false;
+
+update users set upvote_notifications_off = true where id in (select user_id from notification_preferences where type = 'new_votes' and in_app = false);
+update users set reply_notifications_off = true where id in (select user_id from notification_preferences where type = 'comment_reply' and in_app = false);
+update users set mention_notifications_off = true where id in (select user_id from notification_preferences where type = 'mention' and in_app = false);
+
+drop table if exists notification_mutes;
+drop table if exists notification_preferences;
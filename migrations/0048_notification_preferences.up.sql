@@ -0,0 +1,40 @@
+create table if not exists notification_preferences (
+	user_id binary (12) not null,
+	type varchar (32) not null,
+	in_app bool not null default true,
+	push bool not null default true,
+	email bool not null default true,
+
+	primary key (user_id, type),
+	foreign key (user_id) references users (id) on delete cascade
+);
+
+create table if not exists notification_mutes (
+	id int unsigned not null auto_increment,
+	user_id binary (12) not null,
+	post_id binary (12),
+	community_id binary (12),
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	unique key (user_id, post_id),
+	unique key (user_id, community_id),
+	foreign key (user_id) references users (id) on delete cascade,
+	foreign key (post_id) references posts (id) on delete cascade,
+	foreign key (community_id) references communities (id) on delete cascade
+);
+
+insert into notification_preferences (user_id, type, in_app, push, email)
+	select id, 'new_votes', false, false, false from users where upvote_notifications_off = true;
+insert into notification_preferences (user_id, type, in_app, push, email)
+	select id, 'new_comment', false, false, false from users where reply_notifications_off = true;
+insert into notification_preferences (user_id, type, in_app, push, email)
+	select id, 'comment_reply', false, false, false from users where reply_notifications_off = true;
+insert into notification_preferences (user_id, type, in_app, push, email)
+	select id, 'mention', false, false, false from users where mention_notifications_off = true;
+
+alter table users drop column upvote_notifications_off;
+alter table users drop column reply_notifications_off;
+alter table users drop column mention_notifications_off;
+
+alter table notifications add column in_app bool not null default true after notif;
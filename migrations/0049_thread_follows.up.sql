@@ -0,0 +1,20 @@
+create table if not exists thread_follows (
+	id int unsigned not null auto_increment,
+	user_id binary (12) not null,
+	post_id binary (12) not null,
+	comment_id binary (12), -- If null, the whole post is followed.
+	target_id binary (12) not null, -- Either comment_id or post_id.
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	unique key (user_id, target_id),
+	index (post_id),
+	index (user_id, created_at),
+	foreign key (user_id) references users (id) on delete cascade,
+	foreign key (post_id) references posts (id) on delete cascade,
+	foreign key (comment_id) references comments (id) on delete cascade
+);
+
+-- Authors follow their posts by default.
+insert into thread_follows (user_id, post_id, target_id, created_at)
+	select user_id, id, id, created_at from posts where deleted = false;
@@ -0,0 +1,4 @@
+alter table users drop column no_following;
+alter table users drop column no_followers;
+
+drop table user_follows;
@@ -0,0 +1,16 @@
+create table if not exists user_follows (
+	id int unsigned not null auto_increment,
+	follower_id binary (12) not null,
+	followee_id binary (12) not null,
+	notify bool not null default false, -- Notify the follower of new posts.
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	unique key (follower_id, followee_id),
+	index (followee_id),
+	foreign key (follower_id) references users (id) on delete cascade,
+	foreign key (followee_id) references users (id) on delete cascade
+);
+
+alter table users add column no_followers int not null default 0 after no_comments;
+alter table users add column no_following int not null default 0 after no_followers;
@@ -0,0 +1,3 @@
+drop table custom_feed_follows;
+drop table custom_feed_communities;
+drop table custom_feeds;
@@ -0,0 +1 @@
+drop table content_filters;
@@ -0,0 +1,12 @@
+create table if not exists content_filters (
+	id int unsigned not null auto_increment,
+	user_id binary (12) not null,
+	type varchar(32) not null, -- keyword, regex, domain, or post_type.
+	pattern varchar(512) not null,
+	action varchar(32) not null default 'hide', -- hide or collapse.
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	unique key (user_id, type, pattern),
+	foreign key (user_id) references users (id) on delete cascade
+);
@@ -0,0 +1,2 @@
+alter table users drop column read_posts;
+drop table post_reads;
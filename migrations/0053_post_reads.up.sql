@@ -0,0 +1,13 @@
+create table if not exists post_reads (
+	user_id binary (12) not null,
+	post_id binary (12) not null,
+	seen_at datetime not null default current_timestamp(), -- When the post was first seen (scrolled past or opened).
+	visited_at datetime, -- When the post page was last opened.
+
+	primary key (user_id, post_id),
+	index (seen_at),
+	foreign key (user_id) references users (id) on delete cascade,
+	foreign key (post_id) references posts (id) on delete cascade
+);
+
+alter table users add column read_posts tinyint not null default 0 after email_digest;
@@ -0,0 +1,2 @@
+drop table community_member_requests;
+alter table communities drop column visibility;
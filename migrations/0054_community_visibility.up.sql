@@ -0,0 +1,13 @@
+alter table communities add column visibility tinyint not null default 0 after nsfw;
+
+create table if not exists community_member_requests (
+	community_id binary (12) not null,
+	user_id binary (12) not null,
+	invited_by binary (12), -- If not null, the row is an invitation by a mod (not a request by the user).
+	created_at datetime not null default current_timestamp(),
+
+	primary key (community_id, user_id),
+	index (user_id),
+	foreign key (community_id) references communities (id) on delete cascade,
+	foreign key (user_id) references users (id) on delete cascade
+);
@@ -0,0 +1,5 @@
+alter table communities drop column allowed_post_types;
+alter table communities drop column min_account_age;
+alter table communities drop column min_points;
+alter table communities drop column title_regex;
+alter table communities drop column min_body_length;
@@ -0,0 +1,5 @@
+alter table communities add column allowed_post_types int unsigned not null default 0 after visibility; -- A bitmask of post types (0 means all types are allowed).
+alter table communities add column min_account_age int not null default 0 after allowed_post_types; -- In days.
+alter table communities add column min_points int not null default 0 after min_account_age;
+alter table communities add column title_regex varchar (512) after min_points;
+alter table communities add column min_body_length int not null default 0 after title_regex;
@@ -0,0 +1,2 @@
+drop table automod_hits;
+drop table automod_configs;
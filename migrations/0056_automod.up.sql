@@ -0,0 +1,27 @@
+create table if not exists automod_configs (
+	community_id binary (12) not null,
+	config text not null, -- YAML (or JSON) source of the rules.
+	updated_by binary (12) not null, -- Actions of the rules are taken on behalf of this user.
+	updated_at datetime not null default current_timestamp(),
+
+	primary key (community_id),
+	foreign key (community_id) references communities (id) on delete cascade
+);
+
+create table if not exists automod_hits (
+	id bigint unsigned not null auto_increment,
+	community_id binary (12) not null,
+	rule_name varchar(128) not null,
+	event varchar(16) not null, -- create, edit, or report.
+	target_type tinyint not null, -- 0 for posts, 1 for comments.
+	target_id binary (12) not null,
+	post_id binary (12) not null,
+	actions varchar(255) not null, -- Comma separated.
+	dry_run boolean not null default false,
+	error varchar(255),
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	index (community_id, rule_name),
+	foreign key (community_id) references communities (id) on delete cascade
+);
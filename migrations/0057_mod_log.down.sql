@@ -0,0 +1,2 @@
+drop table mod_log;
+alter table communities drop column mod_log_public;
@@ -0,0 +1,22 @@
+alter table communities add column mod_log_public boolean not null default false after min_body_length;
+
+create table if not exists mod_log (
+	id bigint unsigned not null auto_increment,
+	community_id binary (12), -- Null for site-wide actions.
+	actor_id binary (12) not null,
+	actor_group tinyint not null, -- Admins or mods.
+	action varchar(32) not null,
+	target_type varchar(16) not null, -- post, comment, user, rule, report, or community.
+	target_id varchar(32) not null,
+	target_user_id binary (12), -- The user the action was taken against, if any.
+	post_id binary (12), -- The post of post and comment targets.
+	reason varchar(1024),
+	metadata json,
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	index (community_id, id),
+	index (actor_id, id),
+	index (target_user_id, id),
+	index (action, id)
+);
@@ -0,0 +1 @@
+drop table community_removal_reasons;
@@ -0,0 +1,15 @@
+create table if not exists community_removal_reasons (
+	id int unsigned not null auto_increment,
+	community_id binary (12) not null,
+	title varchar(128) not null,
+	message text not null, -- Sent to the author of the removed post or comment.
+	rule_id int unsigned, -- The community rule that the reason is tied to, if any.
+	z_index int not null default 0,
+	created_by binary (12) not null,
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	foreign key (community_id) references communities (id) on delete cascade,
+	foreign key (rule_id) references community_rules (id) on delete set null,
+	foreign key (created_by) references users (id)
+);
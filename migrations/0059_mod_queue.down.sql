@@ -0,0 +1 @@
+drop table mod_queue;
@@ -0,0 +1,16 @@
+create table if not exists mod_queue (
+	id bigint unsigned not null auto_increment,
+	community_id binary (12) not null,
+	target_type tinyint not null, -- 0 for posts, 1 for comments.
+	target_id binary (12) not null,
+	post_id binary (12) not null,
+	reason varchar(16) not null, -- filtered or edited (reported items are in the reports table).
+	note varchar(255), -- The automod rule, of filtered items.
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	unique key (target_id, reason),
+	index (community_id, created_at),
+	index (post_id),
+	foreign key (community_id) references communities (id) on delete cascade
+);
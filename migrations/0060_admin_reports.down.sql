@@ -0,0 +1,7 @@
+alter table reports drop index escalated_at;
+alter table reports drop column escalated_by;
+alter table reports drop column escalated_at;
+
+delete from reports where reason_id in (select id from report_reasons where for_admins);
+delete from report_reasons where for_admins;
+alter table report_reasons drop column for_admins;
@@ -0,0 +1,9 @@
+alter table report_reasons add column for_admins boolean not null default false after description; -- Reports made for these reasons go to the admins (not to the mods).
+
+insert into report_reasons (title, description, for_admins) values ("Illegal content", "Content that is illegal to host, such as child sexual abuse material.", true);
+insert into report_reasons (title, description, for_admins) values ("Breaks site rules", "Content that breaks the site-wide rules, regardless of the rules of the community.", true);
+insert into report_reasons (title, description, for_admins) values ("Moderator abuse", "A moderator abusing their powers.", true);
+
+alter table reports add column escalated_at datetime after dealt_by; -- When a mod escalated the report to the admins.
+alter table reports add column escalated_by binary (12) after escalated_at;
+alter table reports add index (escalated_at);
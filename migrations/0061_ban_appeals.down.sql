@@ -0,0 +1 @@
+drop table ban_appeals;
@@ -0,0 +1,17 @@
+create table if not exists ban_appeals (
+	id int unsigned not null auto_increment,
+	user_id binary (12) not null,
+	community_id binary (12), -- Null for appeals of site bans.
+	body text not null,
+	status varchar(16) not null default "open", -- open, accepted, rejected, or shortened.
+	response text, -- The message of the reviewer.
+	reviewed_by binary (12),
+	reviewed_at datetime,
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	index (community_id, status, created_at),
+	index (user_id),
+	foreign key (user_id) references users (id) on delete cascade,
+	foreign key (community_id) references communities (id) on delete cascade
+);
@@ -0,0 +1,6 @@
+alter table community_banned drop index expires;
+
+alter table users drop index ban_expires;
+alter table users drop column banned_by;
+alter table users drop column ban_reason;
+alter table users drop column ban_expires;
@@ -0,0 +1,6 @@
+alter table users add column ban_expires datetime after banned_at; -- Null for permanent site bans.
+alter table users add column ban_reason varchar(1024) after ban_expires;
+alter table users add column banned_by binary (12) after ban_reason;
+alter table users add index (ban_expires);
+
+alter table community_banned add index (expires);
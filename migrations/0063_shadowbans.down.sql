@@ -0,0 +1,5 @@
+alter table comment_votes drop column shadowbanned;
+alter table post_votes drop column shadowbanned;
+
+alter table users drop column shadowbanned_by;
+alter table users drop column shadowbanned_at;
@@ -0,0 +1,7 @@
+alter table users add column shadowbanned_at datetime after banned_by;
+alter table users add column shadowbanned_by binary (12) after shadowbanned_at;
+
+-- Votes cast by shadowbanned users are not counted in the points of posts and
+-- comments.
+alter table post_votes add column shadowbanned bool not null default false after up;
+alter table comment_votes add column shadowbanned bool not null default false after up;
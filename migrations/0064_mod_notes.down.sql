@@ -0,0 +1 @@
+drop table community_mod_notes;
@@ -0,0 +1,15 @@
+create table if not exists community_mod_notes (
+	id int unsigned not null auto_increment,
+	community_id binary (12) not null,
+	user_id binary (12) not null, -- The user the note is about.
+	category varchar(16) not null default "note", -- note, warning, spam, or ban.
+	body text not null,
+	created_by binary (12) not null,
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	index (community_id, user_id),
+	foreign key (community_id) references communities (id) on delete cascade,
+	foreign key (user_id) references users (id),
+	foreign key (created_by) references users (id)
+);
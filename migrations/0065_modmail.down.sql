@@ -0,0 +1,2 @@
+drop table modmail_messages;
+drop table modmail_threads;
@@ -0,0 +1,34 @@
+create table if not exists modmail_threads (
+	id binary (12) not null,
+	community_id binary (12) not null,
+	user_id binary (12) not null, -- The user who opened the thread.
+	subject varchar (255) not null,
+	assigned_to binary (12), -- The mod the thread is assigned to.
+	archived_at datetime,
+	user_unread bool not null default false,
+	mods_unread bool not null default true,
+	last_message_at datetime not null default current_timestamp(),
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	index (community_id, last_message_at),
+	index (user_id, last_message_at),
+	foreign key (community_id) references communities (id) on delete cascade,
+	foreign key (user_id) references users (id),
+	foreign key (assigned_to) references users (id) on delete set null
+);
+
+create table if not exists modmail_messages (
+	id binary (12) not null,
+	thread_id binary (12) not null,
+	user_id binary (12) not null, -- The actual author, even of messages sent as the mod team.
+	as_mods bool not null default false, -- Sent as the mod team, without revealing the author to the user.
+	internal bool not null default false, -- Internal notes are visible only to the mods.
+	body text not null,
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	index (thread_id, id),
+	foreign key (thread_id) references modmail_threads (id) on delete cascade,
+	foreign key (user_id) references users (id)
+);
@@ -0,0 +1,2 @@
+drop table community_takeover_requests;
+drop table community_mod_transfers;
@@ -0,0 +1,30 @@
+create table if not exists community_mod_transfers (
+	community_id binary (12) not null,
+	from_user binary (12) not null, -- The top mod (or the admin) who offered the transfer.
+	to_user binary (12) not null, -- The mod who's offered to be the top mod.
+	created_at datetime not null default current_timestamp(),
+
+	primary key (community_id),
+	foreign key (community_id) references communities (id) on delete cascade,
+	foreign key (from_user) references users (id) on delete cascade,
+	foreign key (to_user) references users (id) on delete cascade
+);
+
+create table if not exists community_takeover_requests (
+	id int unsigned not null auto_increment,
+	community_id binary (12) not null,
+	user_id binary (12) not null,
+	body text not null,
+	status varchar(16) not null default "open", -- open, approved, or rejected.
+	response text, -- The message of the reviewing admin.
+	reviewed_by binary (12),
+	reviewed_at datetime,
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	index (status, created_at),
+	index (community_id, user_id),
+	index (user_id),
+	foreign key (community_id) references communities (id) on delete cascade,
+	foreign key (user_id) references users (id) on delete cascade
+);
@@ -0,0 +1,3 @@
+alter table communities drop index deleted_at;
+alter table communities drop column archived_by;
+alter table communities drop column archived_at;
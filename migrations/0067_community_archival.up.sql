@@ -0,0 +1,5 @@
+-- Archived communities are read-only: they can be browsed, but nothing can be
+-- posted, commented on, or voted on in them.
+alter table communities add column archived_at datetime after deleted_by;
+alter table communities add column archived_by binary (12) after archived_at;
+alter table communities add index (deleted_at);
@@ -0,0 +1,5 @@
+alter table default_communities drop foreign key default_communities_fk_name_lc;
+alter table default_communities add constraint default_communities_ibfk_1 foreign key (name_lc) references communities (name_lc);
+
+drop table community_name_history;
+drop table community_rename_requests;
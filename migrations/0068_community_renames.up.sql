@@ -0,0 +1,35 @@
+create table if not exists community_rename_requests (
+	id int unsigned not null auto_increment,
+	community_id binary (12) not null,
+	user_id binary (12) not null, -- The mod who made the request.
+	new_name varchar (128) not null,
+	reason text not null,
+	status varchar(16) not null default "open", -- open, approved, or rejected.
+	response text, -- The message of the reviewing admin.
+	reviewed_by binary (12),
+	reviewed_at datetime,
+	created_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	index (status, created_at),
+	index (community_id, status),
+	foreign key (community_id) references communities (id) on delete cascade,
+	foreign key (user_id) references users (id) on delete cascade
+);
+
+create table if not exists community_name_history (
+	id int unsigned not null auto_increment,
+	community_id binary (12) not null,
+	name varchar (128) not null, -- A former name of the community.
+	name_lc varchar (128) not null,
+	renamed_by binary (12), -- Null if renamed from the CLI.
+	renamed_at datetime not null default current_timestamp(),
+
+	primary key (id),
+	unique (name_lc),
+	index (community_id),
+	foreign key (community_id) references communities (id) on delete cascade
+);
+
+alter table default_communities drop foreign key default_communities_ibfk_1;
+alter table default_communities add constraint default_communities_fk_name_lc foreign key (name_lc) references communities (name_lc) on update cascade;
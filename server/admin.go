@@ -1,14 +1,17 @@
 package server
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/discuitnet/discuit/core"
 	"github.com/discuitnet/discuit/internal/httperr"
+	"github.com/discuitnet/discuit/internal/meilisearch"
 )
 
 //	@Summary		Admin actions
-//	@Description	Perform admin actions like banning users, setting default forums, etc.
+//	@Description	Perform admin actions like banning (or shadowbanning) users, setting default forums, deleting (or restoring) and archiving communities, etc.
 //	@Router			/api/_admin [POST]
 //	@Success		200
 //	@Tags			Admin
@@ -71,7 +74,15 @@ func (s *Server) adminActions(w *responseWriter, r *request) error {
 				return err
 			}
 		}
-		if err := user.Ban(r.ctx); err != nil {
+		var expires *time.Time
+		if expiresText, ok := reqBody["expires"].(string); ok && expiresText != "" {
+			expires = new(time.Time)
+			if err := expires.UnmarshalText([]byte(expiresText)); err != nil {
+				return httperr.NewBadRequest("invalid_expires", "Invalid expires.")
+			}
+		}
+		reason, _ := reqBody["reason"].(string)
+		if err := user.Ban(r.ctx, *r.viewer, expires, reason); err != nil {
 			return err
 		}
 	case "unban_user":
@@ -83,9 +94,34 @@ func (s *Server) adminActions(w *responseWriter, r *request) error {
 		if err != nil {
 			return err
 		}
-		if err := user.Unban(r.ctx); err != nil {
+		if err := user.Unban(r.ctx, *r.viewer); err != nil {
 			return err
 		}
+	case "shadowban_user", "unshadowban_user":
+		username, ok := reqBody["username"].(string)
+		if !ok {
+			return invalidJSONErr
+		}
+		user, err := core.GetUserByUsername(r.ctx, s.db, username, nil)
+		if err != nil {
+			return err
+		}
+		if action == "shadowban_user" {
+			if user.Admin {
+				return httperr.NewForbidden("no_ban_admin", "Admin can't ban another admin, yo!")
+			}
+			if err := user.Shadowban(r.ctx, *r.viewer); err != nil {
+				return err
+			}
+			meilisearch.UserDeleteDocumentIfEnabled(r.ctx, s.config, user.ID.String())
+			meilisearch.UserPostsDeleteDocumentsIfEnabled(r.ctx, s.config, user.ID.String())
+		} else {
+			if err := user.Unshadowban(r.ctx, *r.viewer); err != nil {
+				return err
+			}
+			meilisearch.UserUpdateOrCreateDocumentIfEnabled(r.ctx, s.config, user)
+			go meilisearch.UserPostsUpdateOrCreateDocumentsIfEnabled(context.Background(), s.config, s.db, user.ID)
+		}
 	case "add_default_forum", "remove_default_forum":
 		name, ok := reqBody["name"].(string)
 		if !ok {
@@ -98,6 +134,55 @@ func (s *Server) adminActions(w *responseWriter, r *request) error {
 		if err = comm.SetDefault(r.ctx, action == "add_default_forum"); err != nil {
 			return err
 		}
+	case "delete_community", "restore_community":
+		name, ok := reqBody["name"].(string)
+		if !ok {
+			return invalidJSONErr
+		}
+		comm, err := core.GetCommunityByName(r.ctx, s.db, name, r.viewer)
+		if err != nil {
+			return err
+		}
+		if action == "delete_community" {
+			if err := comm.Delete(r.ctx, *r.viewer); err != nil {
+				return err
+			}
+			meilisearch.CommunityDeleteDocumentIfEnabled(r.ctx, s.config, comm.ID.String())
+		} else {
+			if err := comm.Restore(r.ctx, *r.viewer); err != nil {
+				return err
+			}
+			meilisearch.CommunityUpdateOrCreateDocumentIfEnabled(r.ctx, s.config, comm)
+		}
+	case "archive_community", "unarchive_community":
+		name, ok := reqBody["name"].(string)
+		if !ok {
+			return invalidJSONErr
+		}
+		comm, err := core.GetCommunityByName(r.ctx, s.db, name, r.viewer)
+		if err != nil {
+			return err
+		}
+		if err := comm.SetArchived(r.ctx, *r.viewer, action == "archive_community"); err != nil {
+			return err
+		}
+	case "rename_community":
+		name, ok := reqBody["name"].(string)
+		if !ok {
+			return invalidJSONErr
+		}
+		newName, ok := reqBody["newName"].(string)
+		if !ok {
+			return invalidJSONErr
+		}
+		comm, err := core.GetCommunityByName(r.ctx, s.db, name, r.viewer)
+		if err != nil {
+			return err
+		}
+		if err := comm.Rename(r.ctx, *r.viewer, newName); err != nil {
+			return err
+		}
+		s.communityRenamed(r.ctx, comm)
 	default:
 		return httperr.NewBadRequest("invalid_action", "Unsupported admin action.")
 	}
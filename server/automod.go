@@ -0,0 +1,80 @@
+package server
+
+import (
+	"strconv"
+
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/httperr"
+)
+
+// @Summary		Get automod config.
+// @Description	Get the automod config (the YAML or JSON source and the parsed rules) of a community.
+// @Router			/api/communities/{communityID}/automod [GET]
+// @Success		200	{object}	core.AutomodConfig
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+func (s *Server) getAutomodConfig(w *responseWriter, r *request, comm *core.Community) error {
+	config, err := core.GetAutomodConfig(r.ctx, s.db, comm.ID)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(config)
+}
+
+// @Summary		Update automod config.
+// @Description	Replace the automod config of a community. The config is YAML or JSON. Actions of the rules are taken on behalf of the user who last saved the config, or, if that user is no longer a mod, on behalf of the top mod. An empty config deletes all the rules.
+// @Router			/api/communities/{communityID}/automod [PUT]
+// @Success		200	{object}	core.AutomodConfig
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			body			body	object{config=string}	true	"Body"
+func (s *Server) updateAutomodConfig(w *responseWriter, r *request, comm *core.Community) error {
+	values, err := r.unmarshalJSONBodyToStringsMap(false)
+	if err != nil {
+		return err
+	}
+	text, ok := values["config"]
+	if !ok {
+		return httperr.NewBadRequest("no_config", "No config.")
+	}
+
+	config, err := comm.SaveAutomodConfig(r.ctx, *r.viewer, text)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(config)
+}
+
+// @Summary		Get automod hits.
+// @Description	Get the log of automod rules matching posts and comments of a community, most recent first.
+// @Router			/api/communities/{communityID}/automod/hits [GET]
+// @Success		200	{array}	core.AutomodHit
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			rule			query	string	false	"Only hits of the rule with this name"
+// @Param			limit			query	int		false	"Number of hits per page"
+// @Param			page			query	int		false	"Page number"
+func (s *Server) getAutomodHits(w *responseWriter, r *request, comm *core.Community) error {
+	query := r.urlQueryParams()
+
+	limit, err := getFeedLimit(query, s.config.PaginationLimit, s.config.PaginationLimitMax)
+	if err != nil {
+		return err
+	}
+
+	page := 1
+	if spage := query.Get("page"); spage != "" {
+		if page, err = strconv.Atoi(spage); err != nil || page < 1 {
+			return httperr.NewBadRequest("invalid_page", "Invalid page.")
+		}
+	}
+
+	hits, err := core.GetAutomodHits(r.ctx, s.db, comm.ID, query.Get("rule"), limit, page)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(hits)
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/httperr"
+	"github.com/discuitnet/discuit/internal/httputil"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// @Summary		Appeal a ban.
+// @Description	Appeal a ban from a community (given by the community field), which is reviewed by the mods of the community, or a site ban, which is reviewed by the admins. Since site banned users cannot log in, appeals of site bans are authenticated with the username and password fields instead. A ban can only have one open appeal at a time.
+// @Router			/api/ban_appeals [POST]
+// @Success		200	{object}	core.BanAppeal
+// @Tags			User
+// @Param			body	body	object{community=string,body=string,username=string,password=string}	true	"Body"
+func (s *Server) addBanAppeal(w *responseWriter, r *request) error {
+	values, err := r.unmarshalJSONBodyToStringsMap(true)
+	if err != nil {
+		return err
+	}
+
+	var community uid.NullID
+	if values["community"] != "" {
+		if community.ID, err = strToID(values["community"]); err != nil {
+			return err
+		}
+		community.Valid = true
+	}
+
+	var user uid.ID
+	if r.loggedIn {
+		user = *r.viewer
+	} else {
+		if community.Valid {
+			return errNotLoggedIn
+		}
+		username := values["username"]
+		ip := httputil.GetIP(r.req)
+		if err := s.rateLimit(r, "login_1_"+ip, time.Second, 10); err != nil {
+			return err
+		}
+		if err := s.rateLimit(r, "login_2_"+ip+username, time.Hour, 20); err != nil {
+			return err
+		}
+		u, err := core.MatchLoginCredentials(r.ctx, s.db, username, values["password"])
+		if err != nil {
+			return err
+		}
+		user = u.ID
+	}
+
+	if err := s.rateLimit(r, "ban_appeal_"+user.String(), time.Hour, 5); err != nil {
+		return err
+	}
+
+	appeal, err := core.NewBanAppeal(r.ctx, s.db, user, community, values["body"])
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(appeal)
+}
+
+// @Summary		Get the user's ban appeals.
+// @Description	Get all the ban appeals made by the logged in user, the latest ones first.
+// @Router			/api/ban_appeals [GET]
+// @Success		200	{array}	core.BanAppeal
+// @Tags			User
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func (s *Server) getUserBanAppeals(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+	appeals, err := core.GetUserBanAppeals(r.ctx, s.db, *r.viewer)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(appeals)
+}
+
+// writeBanAppeals writes a page of the appeals of the bans from community (or,
+// if community is null, of the site bans) given by the status, limit, and page
+// URL query parameters.
+func (s *Server) writeBanAppeals(w *responseWriter, r *request, community uid.NullID) error {
+	query := r.urlQueryParams()
+	limit, err := getFeedLimit(query, s.config.PaginationLimit, s.config.PaginationLimitMax)
+	if err != nil {
+		return err
+	}
+	page := 1
+	if spage := query.Get("page"); spage != "" {
+		if page, err = strconv.Atoi(spage); err != nil || page < 1 {
+			return httperr.NewBadRequest("invalid_page", "Invalid page.")
+		}
+	}
+
+	status := core.BanAppealStatus(query.Get("status"))
+	appeals, err := core.GetBanAppeals(r.ctx, s.db, community, status, limit, page)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(appeals)
+}
+
+// @Summary		Get the ban appeals of a community.
+// @Description	Get the appeals of the bans from a community, the oldest ones first.
+// @Router			/api/communities/{communityID}/ban_appeals [GET]
+// @Success		200	{array}	core.BanAppeal
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			status			query	string	false	"Only appeals of this status"	Enums(open, accepted, rejected, shortened)
+// @Param			limit			query	int		false	"Number of appeals per page"
+// @Param			page			query	int		false	"Page number"
+func (s *Server) getCommunityBanAppeals(w *responseWriter, r *request, comm *core.Community) error {
+	return s.writeBanAppeals(w, r, uid.NullID{ID: comm.ID, Valid: true})
+}
+
+// @Summary		Get the appeals of site bans.
+// @Description	Get the appeals of site bans, the oldest ones first. Only admins can view them.
+// @Router			/api/_admin/ban_appeals [GET]
+// @Success		200	{array}	core.BanAppeal
+// @Tags			Admin
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			status			query	string	false	"Only appeals of this status"	Enums(open, accepted, rejected, shortened)
+// @Param			limit			query	int		false	"Number of appeals per page"
+// @Param			page			query	int		false	"Page number"
+func (s *Server) getSiteBanAppeals(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+	if is, err := core.IsAdmin(s.db, r.viewer); err != nil {
+		return err
+	} else if !is {
+		return httperr.NewForbidden("not_admin", "You are not an admin.")
+	}
+	return s.writeBanAppeals(w, r, uid.NullID{})
+}
+
+// @Summary		Review a ban appeal.
+// @Description	Accept a ban appeal (which unbans the user), reject it, or shorten the ban to expire at expires. Appeals of community bans are reviewed by the mods of the community, and appeals of site bans by the admins. The user is notified of the outcome, along with the optional message.
+// @Router			/api/ban_appeals/{appealID} [POST]
+// @Success		200	{object}	core.BanAppeal
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			appealID		path	string	true	"Ban appeal ID"
+// @Param			body			body	object{action=string,message=string,expires=string}	true	"Body (action is accept, reject, or shorten)"
+func (s *Server) reviewBanAppeal(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	id, err := strconv.ParseUint(r.muxVar("appealID"), 10, 32)
+	if err != nil {
+		return httperr.NewBadRequest("invalid_appeal_id", "Invalid ban appeal ID.")
+	}
+	appeal, err := core.GetBanAppeal(r.ctx, s.db, uint(id))
+	if err != nil {
+		return err
+	}
+
+	values, err := r.unmarshalJSONBodyToStringsMap(true)
+	if err != nil {
+		return err
+	}
+	message := values["message"]
+	switch values["action"] {
+	case "accept":
+		err = appeal.Accept(r.ctx, *r.viewer, message)
+	case "reject":
+		err = appeal.Reject(r.ctx, *r.viewer, message)
+	case "shorten":
+		var expires time.Time
+		if err := expires.UnmarshalText([]byte(values["expires"])); err != nil {
+			return httperr.NewBadRequest("invalid_expires", "Invalid expires.")
+		}
+		err = appeal.Shorten(r.ctx, *r.viewer, expires, message)
+	default:
+		return httperr.NewBadRequest("invalid_action", "Unsupported action.")
+	}
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(appeal)
+}
@@ -20,6 +20,12 @@ func (s *Server) getComments(w *responseWriter, r *request) error {
 	if err != nil {
 		return err
 	}
+	if err = post.CheckViewable(r.viewer); err != nil {
+		return err
+	}
+	if err = s.checkCommunityViewable(r, post.CommunityID); err != nil {
+		return err
+	}
 
 	query := r.urlQueryParams()
 
@@ -85,6 +91,12 @@ func (s *Server) getComment(w *responseWriter, r *request) error {
 	if err != nil {
 		return err
 	}
+	if err = comment.CheckViewable(r.viewer); err != nil {
+		return err
+	}
+	if err = s.checkCommunityViewable(r, comment.CommunityID); err != nil {
+		return err
+	}
 
 	return w.writeJSON(comment)
 }
@@ -209,6 +221,9 @@ func (s *Server) updateComment(w *responseWriter, r *request) error {
 //	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
 //	@Param			postID			path	string	true	"Post ID"
 //	@Param			commentID		path	string	true	"Comment ID"
+//	@Param			reasonId		query	int		false	"ID of a removal reason of the community (for mods and admins)"
+//	@Param			reason			query	string	false	"Removal reason note, appended to the removal reason (for mods and admins)"
+//	@Param			reasonComment	query	bool	false	"Whether to post the removal reason as a public reply to the comment"
 func (s *Server) deleteComment(w *responseWriter, r *request) error {
 	if !r.loggedIn {
 		return errNotLoggedIn
@@ -235,7 +250,11 @@ func (s *Server) deleteComment(w *responseWriter, r *request) error {
 		}
 	}
 
-	if err := comment.Delete(r.ctx, *r.viewer, deleteAs); err != nil {
+	removal, err := s.removalFromQuery(r, comment.CommunityID, deleteAs)
+	if err != nil {
+		return err
+	}
+	if err := comment.Delete(r.ctx, *r.viewer, deleteAs, removal); err != nil {
 		return err
 	}
 
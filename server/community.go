@@ -33,6 +33,17 @@ func userModOrAdmin(ctx context.Context, db *sql.DB, user uid.ID, c *core.Commun
 	return false, nil
 }
 
+// checkCommunityViewable returns errPrivateCommunity if the viewer of r cannot
+// see the posts and comments of community.
+func (s *Server) checkCommunityViewable(r *request, community uid.ID) error {
+	if ok, err := core.CommunityViewableBy(r.ctx, s.db, community, r.viewer); err != nil {
+		return err
+	} else if !ok {
+		return errPrivateCommunity
+	}
+	return nil
+}
+
 // @Summary		Create a community.
 // @Description	Create a community.
 // @Router			/api/community [POST]
@@ -162,6 +173,14 @@ func (s *Server) getCommunity(w *responseWriter, r *request) error {
 	if err != nil {
 		return err
 	}
+	if comm.DeletedAt.Valid {
+		// Only admins can see deleted communities.
+		if is, err := core.IsAdmin(s.db, r.viewer); err != nil {
+			return err
+		} else if !is {
+			return httperr.NewNotFound("community/not-found", "Community not found.")
+		}
+	}
 
 	if err = comm.PopulateMods(r.ctx); err != nil {
 		return err
@@ -172,6 +191,9 @@ func (s *Server) getCommunity(w *responseWriter, r *request) error {
 	if _, err = comm.Default(r.ctx); err != nil {
 		return err
 	}
+	if _, err = comm.Abandoned(r.ctx); err != nil {
+		return err
+	}
 
 	return w.writeJSON(comm)
 }
@@ -217,12 +239,19 @@ func (s *Server) updateCommunity(w *responseWriter, r *request) error {
 		return err
 	}
 
-	rcomm := core.Community{}
+	rcomm := core.Community{
+		Visibility:          comm.Visibility,
+		PostingRequirements: comm.PostingRequirements,
+		ModLogPublic:        comm.ModLogPublic,
+	}
 	if err = r.unmarshalJSONBody(&rcomm); err != nil {
 		return err
 	}
 	comm.NSFW = rcomm.NSFW
 	comm.About = rcomm.About
+	comm.Visibility = rcomm.Visibility
+	comm.PostingRequirements = rcomm.PostingRequirements
+	comm.ModLogPublic = rcomm.ModLogPublic
 
 	if err = comm.Update(r.ctx, *r.viewer); err != nil {
 		return err
@@ -269,10 +298,19 @@ func (s *Server) joinCommunity(w *responseWriter, r *request) error {
 		return err
 	}
 
+	// Restricted and private communities can only be joined with the approval
+	// of a mod (or by accepting an invitation).
+	joined := community.ViewerJoined.Bool
 	if req.Leave {
-		err = community.Leave(r.ctx, user.ID)
-	} else {
+		if joined {
+			err = community.Leave(r.ctx, user.ID)
+		} else {
+			err = community.CancelMembershipRequest(r.ctx, user.ID)
+		}
+	} else if community.Visibility == core.CommunityPublic || joined {
 		err = community.Join(r.ctx, user.ID)
+	} else {
+		_, err = community.RequestMembership(r.ctx, user.ID)
 	}
 	if err != nil {
 		return err
@@ -280,8 +318,9 @@ func (s *Server) joinCommunity(w *responseWriter, r *request) error {
 
 	meilisearch.CommunityUpdateOrCreateDocumentIfEnabled(r.ctx, s.config, community)
 
-	community.ViewerJoined = msql.NewNullBool(!req.Leave)
-	community.ViewerMod = msql.NewNullBool(false)
+	if err = community.PopulateViewerFields(r.ctx, user.ID); err != nil {
+		return err
+	}
 
 	return w.writeJSON(community)
 }
@@ -718,7 +757,7 @@ func (s *Server) deleteReport(w *responseWriter, r *request) error {
 	if err != nil {
 		return httperr.NewBadRequest("invalid_report_id", "Invalid report ID.")
 	}
-	report, err := core.GetReport(r.ctx, s.db, reportID)
+	report, err := s.getCommunityReport(r, comm, reportID)
 	if err != nil {
 		return err
 	}
@@ -732,10 +771,128 @@ func (s *Server) deleteReport(w *responseWriter, r *request) error {
 	return w.writeJSON(report)
 }
 
+// getCommunityReport returns the report of comm with the id reportID. Reports
+// that are with the admins are not found unless the viewer is an admin.
+func (s *Server) getCommunityReport(r *request, comm *core.Community, reportID int) (*core.Report, error) {
+	report, err := core.GetReport(r.ctx, s.db, reportID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, httperr.NewNotFound("report-not-found", "Report not found.")
+		}
+		return nil, err
+	}
+	if report.CommunityID != comm.ID {
+		return nil, httperr.NewNotFound("report-not-found", "Report not found.")
+	}
+	if report.ForAdmins || report.EscalatedAt.Valid {
+		viewer, err := core.GetUser(r.ctx, s.db, *r.viewer, nil)
+		if err != nil {
+			return nil, err
+		}
+		if !viewer.Admin {
+			return nil, httperr.NewNotFound("report-not-found", "Report not found.")
+		}
+	}
+	return report, nil
+}
+
+// @Summary		Escalate a report to the admins.
+// @Description	Hand a report over to the admins. Escalated reports are removed from the reports of the community and are added to the site-wide reports queue of the admins.
+// @Router			/api/communities/{communityID}/reports/{reportID}/escalate [POST]
+// @Success		200	{object}	core.Report
+// @Tags			Report
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			reportID		path	string	true	"Report ID"
+func (s *Server) escalateReport(w *responseWriter, r *request, comm *core.Community) error {
+	reportID, err := strconv.Atoi(r.muxVar("reportID"))
+	if err != nil {
+		return httperr.NewBadRequest("invalid_report_id", "Invalid report ID.")
+	}
+	report, err := s.getCommunityReport(r, comm, reportID)
+	if err != nil {
+		return err
+	}
+	if err = report.Escalate(r.ctx, *r.viewer); err != nil {
+		return err
+	}
+	return w.writeJSON(report)
+}
+
+// @Summary		Get the site-wide reports queue.
+// @Description	Get the reports that are with the admins, the latest ones first: those made for a reason routed to the admins, and those escalated by mods. Only admins can view them.
+// @Router			/api/_admin/reports [GET]
+// @Success		200	{array}	core.Report
+// @Tags			Admin
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			reason			query	string	false	"Only reports of this reason (a report reason id), or escalated for only the escalated reports"
+// @Param			community		query	string	false	"Only reports of this community (name)"
+// @Param			minAge			query	int		false	"Only reports at least this many hours old"
+// @Param			maxAge			query	int		false	"Only reports at most this many hours old"
+// @Param			limit			query	int		false	"Number of reports per page"
+// @Param			page			query	int		false	"Page number"
+func (s *Server) getAdminReports(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+	admin, err := core.GetUser(r.ctx, s.db, *r.viewer, nil)
+	if err != nil {
+		return err
+	}
+	if !admin.Admin {
+		return httperr.NewForbidden("not_admin", "You are not an admin.")
+	}
+
+	query := r.urlQueryParams()
+	limit, err := getFeedLimit(query, s.config.PaginationLimit, s.config.PaginationLimitMax)
+	if err != nil {
+		return err
+	}
+	page := 1
+	if spage := query.Get("page"); spage != "" {
+		if page, err = strconv.Atoi(spage); err != nil || page < 1 {
+			return httperr.NewBadRequest("invalid_page", "Invalid page.")
+		}
+	}
+
+	q := &core.AdminReportsQuery{}
+	switch reason := query.Get("reason"); reason {
+	case "":
+	case "escalated":
+		q.Escalated = true
+	default:
+		if q.Reason, err = strconv.Atoi(reason); err != nil {
+			return httperr.NewBadRequest("invalid_reason", "Invalid report reason.")
+		}
+	}
+	if name := query.Get("community"); name != "" {
+		comm, err := core.GetCommunityByName(r.ctx, s.db, name, nil)
+		if err != nil {
+			return err
+		}
+		q.Community = uid.NullID{ID: comm.ID, Valid: true}
+	}
+	for key, age := range map[string]*time.Duration{"minAge": &q.MinAge, "maxAge": &q.MaxAge} {
+		if hours := query.Get(key); hours != "" {
+			n, err := strconv.Atoi(hours)
+			if err != nil || n < 0 {
+				return httperr.NewBadRequest("invalid_"+key, "Invalid "+key+".")
+			}
+			*age = time.Duration(n) * time.Hour
+		}
+	}
+
+	reports, err := core.GetAdminReports(r.ctx, s.db, q, limit, page)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(reports)
+}
+
 // @Summary		Get community banned users.
-// @Description	Get community banned users.
+// @Description	Get community banned users, along with the remaining durations of their bans.
 // @Router			/api/communities/{communityID}/banned [GET]
-// @Success		200
+// @Success		200	{array}	core.BannedUser
 // @Tags			Community
 // @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
 // @Param			communityID		path	string	true	"Community ID"
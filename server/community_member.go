@@ -0,0 +1,109 @@
+package server
+
+import (
+	"time"
+
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/httperr"
+)
+
+// withCommunityMod calls f with the community in the URL if the viewer is a
+// mod of the community or an admin.
+func (s *Server) withCommunityMod(f func(*responseWriter, *request, *core.Community) error) handler {
+	return handler(func(w *responseWriter, r *request) error {
+		if !r.loggedIn {
+			return errNotLoggedIn
+		}
+
+		cid, err := strToID(r.muxVar("communityID"))
+		if err != nil {
+			return err
+		}
+		comm, err := core.GetCommunityByID(r.ctx, s.db, cid, r.viewer)
+		if err != nil {
+			return err
+		}
+
+		if ok, err := userModOrAdmin(r.ctx, s.db, *r.viewer, comm); err != nil {
+			return err
+		} else if !ok {
+			return errNotAdminNorMod
+		}
+		return f(w, r, comm)
+	})
+}
+
+// @Summary		Get membership requests.
+// @Description	Get the pending membership requests and invitations of a restricted or private community.
+// @Router			/api/communities/{communityID}/member_requests [GET]
+// @Success		200	{array}	core.CommunityMemberRequest
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+func (s *Server) getCommunityMemberRequests(w *responseWriter, r *request, comm *core.Community) error {
+	reqs, err := comm.GetMemberRequests(r.ctx, *r.viewer)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(reqs)
+}
+
+// @Summary		Invite a user.
+// @Description	Invite a user to join a restricted or private community. If the user has already requested to join, the request is approved.
+// @Router			/api/communities/{communityID}/member_requests [POST]
+// @Success		200	{object}	core.User
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+func (s *Server) inviteCommunityMember(w *responseWriter, r *request, comm *core.Community) error {
+	if err := s.rateLimit(r, "comm_invite_1_"+r.viewer.String(), time.Second, 1); err != nil {
+		return err
+	}
+	if err := s.rateLimit(r, "comm_invite_2_"+r.viewer.String(), time.Hour*24, 200); err != nil {
+		return err
+	}
+
+	values, err := r.unmarshalJSONBodyToStringsMap(true)
+	if err != nil {
+		return err
+	}
+	username, ok := values["username"]
+	if !ok {
+		return httperr.NewBadRequest("no_username", "No username.")
+	}
+
+	user, err := core.GetUserByUsername(r.ctx, s.db, username, nil)
+	if err != nil {
+		return err
+	}
+	if err := comm.InviteMember(r.ctx, *r.viewer, user.ID); err != nil {
+		return err
+	}
+	return w.writeJSON(user)
+}
+
+// @Summary		Approve or reject a membership request.
+// @Description	Approve (PUT) a user's request to join a restricted or private community, or reject it (DELETE). Deleting an invitation revokes it.
+// @Router			/api/communities/{communityID}/member_requests/{username} [PUT]
+// @Router			/api/communities/{communityID}/member_requests/{username} [DELETE]
+// @Success		200	{object}	core.User
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			username		path	string	true	"Username"
+func (s *Server) handleCommunityMemberRequest(w *responseWriter, r *request, comm *core.Community) error {
+	user, err := core.GetUserByUsername(r.ctx, s.db, r.muxVar("username"), nil)
+	if err != nil {
+		return err
+	}
+
+	if r.req.Method == "PUT" {
+		err = comm.ApproveMemberRequest(r.ctx, *r.viewer, user.ID)
+	} else {
+		err = comm.RejectMemberRequest(r.ctx, *r.viewer, user.ID)
+	}
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(user)
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/httperr"
+	"github.com/discuitnet/discuit/internal/meilisearch"
+)
+
+// communityRenamed updates the search documents of comm, and of its posts,
+// after comm is renamed.
+func (s *Server) communityRenamed(ctx context.Context, comm *core.Community) {
+	meilisearch.CommunityUpdateOrCreateDocumentIfEnabled(ctx, s.config, comm)
+	go meilisearch.CommunityPostsUpdateDocumentsIfEnabled(context.Background(), s.config, s.db, comm)
+}
+
+// @Summary		Request to rename a community.
+// @Description	Request to change the name of a community. Only the mods of the community can make the request, which is reviewed by the admins. A community can only have one open rename request at a time.
+// @Router			/api/communities/{communityID}/rename_requests [POST]
+// @Success		200	{object}	core.RenameRequest
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			body			body	object{name=string,reason=string}	true	"The new name and the reason for it"
+func (s *Server) addRenameRequest(w *responseWriter, r *request, comm *core.Community) error {
+	values, err := r.unmarshalJSONBodyToStringsMap(true)
+	if err != nil {
+		return err
+	}
+
+	if err := s.rateLimit(r, "rename_request_"+r.viewer.String(), time.Hour, 5); err != nil {
+		return err
+	}
+
+	request, err := comm.NewRenameRequest(r.ctx, *r.viewer, values["name"], values["reason"])
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(request)
+}
+
+// @Summary		Get the rename requests of a community.
+// @Description	Get all the rename requests of a community, the latest ones first. Only the mods of the community can view them.
+// @Router			/api/communities/{communityID}/rename_requests [GET]
+// @Success		200	{array}	core.RenameRequest
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+func (s *Server) getCommunityRenameRequests(w *responseWriter, r *request, comm *core.Community) error {
+	requests, err := comm.GetRenameRequests(r.ctx)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(requests)
+}
+
+// @Summary		Get community rename requests.
+// @Description	Get the rename requests of all communities, the oldest ones first. Only admins can view them.
+// @Router			/api/_admin/rename_requests [GET]
+// @Success		200	{array}	core.RenameRequest
+// @Tags			Admin
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			status			query	string	false	"Only requests of this status"	Enums(open, approved, rejected)
+// @Param			limit			query	int		false	"Number of requests per page"
+// @Param			page			query	int		false	"Page number"
+func (s *Server) getRenameRequests(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+	if is, err := core.IsAdmin(s.db, r.viewer); err != nil {
+		return err
+	} else if !is {
+		return httperr.NewForbidden("not_admin", "You are not an admin.")
+	}
+
+	query := r.urlQueryParams()
+	limit, err := getFeedLimit(query, s.config.PaginationLimit, s.config.PaginationLimitMax)
+	if err != nil {
+		return err
+	}
+	page := 1
+	if spage := query.Get("page"); spage != "" {
+		if page, err = strconv.Atoi(spage); err != nil || page < 1 {
+			return httperr.NewBadRequest("invalid_page", "Invalid page.")
+		}
+	}
+
+	status := core.RenameRequestStatus(query.Get("status"))
+	requests, err := core.GetRenameRequests(r.ctx, s.db, status, limit, page)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(requests)
+}
+
+// @Summary		Review a rename request.
+// @Description	Approve a community rename request (which renames the community) or reject it. Only admins can review rename requests. When a community is renamed, its old name keeps redirecting to it and its members are notified. When a request is rejected, the mod who made it is notified, along with the optional message.
+// @Router			/api/rename_requests/{requestID} [POST]
+// @Success		200	{object}	core.RenameRequest
+// @Tags			Admin
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			requestID		path	string	true	"Rename request ID"
+// @Param			body			body	object{action=string,message=string}	true	"Body (action is approve or reject)"
+func (s *Server) reviewRenameRequest(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	id, err := strconv.ParseUint(r.muxVar("requestID"), 10, 32)
+	if err != nil {
+		return httperr.NewBadRequest("invalid_request_id", "Invalid rename request ID.")
+	}
+	request, err := core.GetRenameRequest(r.ctx, s.db, uint(id))
+	if err != nil {
+		return err
+	}
+
+	values, err := r.unmarshalJSONBodyToStringsMap(true)
+	if err != nil {
+		return err
+	}
+	message := values["message"]
+	switch values["action"] {
+	case "approve":
+		comm, err := request.Approve(r.ctx, *r.viewer, message)
+		if err != nil {
+			return err
+		}
+		s.communityRenamed(r.ctx, comm)
+	case "reject":
+		if err := request.Reject(r.ctx, *r.viewer, message); err != nil {
+			return err
+		}
+	default:
+		return httperr.NewBadRequest("invalid_action", "Unsupported action.")
+	}
+	return w.writeJSON(request)
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/httperr"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// @Summary		Get the logged in user's conversations.
+// @Description	Get the logged in user's conversations, the ones with the latest messages first.
+// @Router			/api/conversations [GET]
+// @Success		200
+// @Tags			Conversations
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			limit			query	int		false	"Limit"
+// @Param			next			query	string	false	"Next"
+func (s *Server) getConversations(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	limit, err := getFeedLimit(r.urlQueryParams(), 20, 50)
+	if err != nil {
+		return err
+	}
+	var next *string
+	if nextString := r.urlQueryParamsValue("next"); nextString != "" {
+		next = &nextString
+	}
+
+	set, err := core.GetUserConversations(r.ctx, s.db, *r.viewer, limit, next)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(set)
+}
+
+// @Summary		Start a conversation.
+// @Description	Start a conversation with one or more users. If a direct conversation with the (only) user already exists, that conversation is returned.
+// @Router			/api/conversations [POST]
+// @Success		200
+// @Tags			Conversations
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func (s *Server) createConversation(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	form := struct {
+		Usernames []string `json:"usernames"`
+		Title     string   `json:"title"` // Only for group conversations.
+	}{}
+	if err := r.unmarshalJSONBody(&form); err != nil {
+		return err
+	}
+
+	if err := s.rateLimit(r, "conversations_c_1_"+r.viewer.String(), time.Second*5, 1); err != nil {
+		return err
+	}
+	if err := s.rateLimit(r, "conversations_c_2_"+r.viewer.String(), time.Hour*24, 50); err != nil {
+		return err
+	}
+
+	var members []uid.ID
+	for _, username := range form.Usernames {
+		user, err := core.GetUserByUsername(r.ctx, s.db, username, nil)
+		if err != nil {
+			return err
+		}
+		members = append(members, user.ID)
+	}
+
+	conv, err := core.CreateConversation(r.ctx, s.db, *r.viewer, members, form.Title)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(conv)
+}
+
+func (s *Server) withConversation(f func(*responseWriter, *request, *core.Conversation) error) handler {
+	return handler(func(w *responseWriter, r *request) error {
+		if !r.loggedIn {
+			return errNotLoggedIn
+		}
+
+		convID, err := strToID(r.muxVar("conversationID"))
+		if err != nil {
+			return err
+		}
+
+		conv, err := core.GetConversation(r.ctx, s.db, convID, *r.viewer)
+		if err != nil {
+			return err
+		}
+
+		return f(w, r, conv)
+	})
+}
+
+// @Summary		Get a conversation.
+// @Description	Get a conversation. The members of the conversation include read receipts.
+// @Router			/api/conversations/{conversationID} [GET]
+// @Success		200
+// @Tags			Conversations
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			conversationID	path	string	true	"Conversation ID"
+func (s *Server) getConversation(w *responseWriter, r *request, conv *core.Conversation) error {
+	return w.writeJSON(conv)
+}
+
+// @Summary		Update a conversation.
+// @Description	Mark a conversation as read, mute or unmute it, or leave it (group conversations only).
+// @Router			/api/conversations/{conversationID} [PUT]
+// @Success		200
+// @Tags			Conversations
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			conversationID	path	string	true	"Conversation ID"
+// @Param			action			query	string	true	"Action"	Enums(markRead,mute,unmute,leave)
+func (s *Server) updateConversation(w *responseWriter, r *request, conv *core.Conversation) error {
+	var err error
+	switch action := r.urlQueryParamsValue("action"); action {
+	case "markRead":
+		err = conv.MarkRead(r.ctx, *r.viewer)
+	case "mute", "unmute":
+		err = conv.SetMuted(r.ctx, *r.viewer, action == "mute")
+	case "leave":
+		if err = conv.Leave(r.ctx, *r.viewer); err != nil {
+			return err
+		}
+		return w.writeString(`{"success":true}`)
+	default:
+		return httperr.NewBadRequest("invalid_action", "Unsupported action.")
+	}
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(conv)
+}
+
+// @Summary		Get the messages of a conversation.
+// @Description	Get the messages of a conversation, the latest ones first.
+// @Router			/api/conversations/{conversationID}/messages [GET]
+// @Success		200
+// @Tags			Conversations
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			conversationID	path	string	true	"Conversation ID"
+// @Param			limit			query	int		false	"Limit"
+// @Param			next			query	string	false	"Next"
+func (s *Server) getConversationMessages(w *responseWriter, r *request, conv *core.Conversation) error {
+	limit, err := getFeedLimit(r.urlQueryParams(), 30, 100)
+	if err != nil {
+		return err
+	}
+	var next *string
+	if nextString := r.urlQueryParamsValue("next"); nextString != "" {
+		next = &nextString
+	}
+
+	set, err := conv.GetMessages(r.ctx, limit, next)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(set)
+}
+
+// @Summary		Send a message.
+// @Description	Send a message to a conversation. The request body is either JSON ({"body": "..."}) or, for attaching an image, multipart/form-data with the fields body and image.
+// @Router			/api/conversations/{conversationID}/messages [POST]
+// @Success		200
+// @Tags			Conversations
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			conversationID	path	string	true	"Conversation ID"
+func (s *Server) sendConversationMessage(w *responseWriter, r *request, conv *core.Conversation) error {
+	if err := s.rateLimit(r, "messages_1_"+r.viewer.String(), time.Second, 2); err != nil {
+		return err
+	}
+	if err := s.rateLimit(r, "messages_2_"+r.viewer.String(), time.Hour*24, 2000); err != nil {
+		return err
+	}
+
+	var (
+		body  string
+		image []byte
+	)
+	if strings.HasPrefix(r.req.Header.Get("Content-Type"), "multipart/form-data") {
+		r.req.Body = http.MaxBytesReader(w, r.req.Body, int64(s.config.MaxImageSize)) // limit max upload size
+		if err := r.req.ParseMultipartForm(int64(s.config.MaxImageSize)); err != nil {
+			return httperr.NewBadRequest("file_size_exceeded", "Max file size exceeded.")
+		}
+		body = r.req.FormValue("body")
+		file, _, err := r.req.FormFile("image")
+		if err != nil && err != http.ErrMissingFile {
+			return err
+		}
+		if file != nil {
+			defer file.Close()
+			if image, err = io.ReadAll(file); err != nil {
+				return err
+			}
+		}
+	} else {
+		form := struct {
+			Body string `json:"body"`
+		}{}
+		if err := r.unmarshalJSONBody(&form); err != nil {
+			return err
+		}
+		body = form.Body
+	}
+
+	message, err := conv.SendMessage(r.ctx, *r.viewer, body, image)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(message)
+}
+
+// @Summary		Delete a message.
+// @Description	Delete a message. Only the author of a message can delete it.
+// @Router			/api/conversations/{conversationID}/messages/{messageID} [DELETE]
+// @Success		200
+// @Tags			Conversations
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			conversationID	path	string	true	"Conversation ID"
+// @Param			messageID		path	string	true	"Message ID"
+func (s *Server) deleteConversationMessage(w *responseWriter, r *request, conv *core.Conversation) error {
+	messageID, err := strToID(r.muxVar("messageID"))
+	if err != nil {
+		return err
+	}
+	if err := conv.DeleteMessage(r.ctx, *r.viewer, messageID); err != nil {
+		return err
+	}
+	return w.writeString(`{"success":true}`)
+}
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/httperr"
+	msql "github.com/discuitnet/discuit/internal/sql"
+	"github.com/discuitnet/discuit/internal/uid"
+	"github.com/gorilla/feeds"
+)
+
+var errNotCustomFeedOwner = httperr.NewForbidden("not-custom-feed-owner", "Not custom feed owner.")
+
+// @Summary		Get user's custom feeds.
+// @Description	Get the custom feeds of a user. Private feeds are only returned to their owner.
+// @Router			/api/users/{username}/feeds [GET]
+// @Success		200	{array}	core.CustomFeed
+// @Tags			Custom feeds
+// @Param			Authorization	header	string	false	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			username		path	string	true	"Username"
+func (s *Server) getUsersCustomFeeds(w *responseWriter, r *request) error {
+	user, err := core.GetUserByUsername(r.ctx, s.db, r.muxVar("username"), r.viewer)
+	if err != nil {
+		return err
+	}
+
+	feeds, err := core.GetUsersCustomFeeds(r.ctx, s.db, user.ID, r.viewer)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(feeds)
+}
+
+// @Summary		Create a custom feed.
+// @Description	Create a custom feed of a set of communities.
+// @Router			/api/users/{username}/feeds [POST]
+// @Success		200	{object}	core.CustomFeed
+// @Tags			Custom feeds
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			username		path	string	true	"Username"
+func (s *Server) createCustomFeed(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	user, err := core.GetUserByUsername(r.ctx, s.db, r.muxVar("username"), r.viewer)
+	if err != nil {
+		return err
+	}
+	if user.ID != *r.viewer {
+		return errNotCustomFeedOwner
+	}
+
+	form := struct {
+		Name        string          `json:"name"`
+		DisplayName string          `json:"displayName"` // Optional field, defaults to Name.
+		Description msql.NullString `json:"description"`
+		Public      bool            `json:"public"`
+		Communities []uid.ID        `json:"communities"`
+	}{}
+	if err := r.unmarshalJSONBody(&form); err != nil {
+		return err
+	}
+
+	if err := s.rateLimit(r, "cfeed_c_1_"+r.viewer.String(), time.Second*2, 1); err != nil {
+		return err
+	}
+	if err := s.rateLimit(r, "cfeed_c_2_"+r.viewer.String(), time.Hour*24, 50); err != nil {
+		return err
+	}
+
+	feed, err := core.CreateCustomFeed(r.ctx, s.db, user.ID, form.Name, form.DisplayName, form.Description, form.Public, form.Communities)
+	if err != nil {
+		return err
+	}
+	if err := feed.FetchCommunities(r.ctx, s.db, r.viewer); err != nil {
+		return err
+	}
+	return w.writeJSON(feed)
+}
+
+func (s *Server) withCustomFeed(f func(*responseWriter, *request, *core.CustomFeed) error) handler {
+	return handler(func(w *responseWriter, r *request) error {
+		user, err := core.GetUserByUsername(r.ctx, s.db, r.muxVar("username"), nil)
+		if err != nil {
+			return err
+		}
+
+		feed, err := core.GetCustomFeedByName(r.ctx, s.db, user.ID, r.muxVar("feedname"), r.viewer)
+		if err != nil {
+			return err
+		}
+
+		return f(w, r, feed)
+	})
+}
+
+// @Summary		Get a custom feed.
+// @Description	Get a custom feed, along with its communities.
+// @Router			/api/users/{username}/feeds/{feedname} [GET]
+// @Success		200	{object}	core.CustomFeed
+// @Tags			Custom feeds
+// @Param			Authorization	header	string	false	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			username		path	string	true	"Username"
+// @Param			feedname		path	string	true	"Feed name"
+func (s *Server) getCustomFeed(w *responseWriter, r *request, feed *core.CustomFeed) error {
+	if err := feed.FetchCommunities(r.ctx, s.db, r.viewer); err != nil {
+		return err
+	}
+	return w.writeJSON(feed)
+}
+
+// @Summary		Update a custom feed.
+// @Description	Update a custom feed. If communities is set in the body, the communities of the feed are replaced with it.
+// @Router			/api/users/{username}/feeds/{feedname} [PUT]
+// @Success		200	{object}	core.CustomFeed
+// @Tags			Custom feeds
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			username		path	string	true	"Username"
+// @Param			feedname		path	string	true	"Feed name"
+func (s *Server) updateCustomFeed(w *responseWriter, r *request, feed *core.CustomFeed) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+	if !feed.IsOwner(r.viewer) {
+		return errNotCustomFeedOwner
+	}
+
+	if err := s.rateLimit(r, "cfeed_e_1_"+r.viewer.String(), time.Second*1, 1); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(r.req.Body)
+	if err != nil {
+		return err
+	}
+	if err := feed.UnmarshalUpdatableFieldsJSON(data); err != nil {
+		return httperr.NewBadRequest("", "Bad JSON body.")
+	}
+	form := struct {
+		Communities []uid.ID `json:"communities"`
+	}{}
+	if err := json.Unmarshal(data, &form); err != nil {
+		return httperr.NewBadRequest("", "Bad JSON body.")
+	}
+
+	if err := feed.Update(r.ctx, s.db, form.Communities); err != nil {
+		return err
+	}
+	if feed, err = core.GetCustomFeed(r.ctx, s.db, feed.ID, r.viewer); err != nil {
+		return err
+	}
+	if err := feed.FetchCommunities(r.ctx, s.db, r.viewer); err != nil {
+		return err
+	}
+	return w.writeJSON(feed)
+}
+
+// @Summary		Delete a custom feed.
+// @Description	Delete a custom feed.
+// @Router			/api/users/{username}/feeds/{feedname} [DELETE]
+// @Success		200	{object}	core.CustomFeed
+// @Tags			Custom feeds
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			username		path	string	true	"Username"
+// @Param			feedname		path	string	true	"Feed name"
+func (s *Server) deleteCustomFeed(w *responseWriter, r *request, feed *core.CustomFeed) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+	if !feed.IsOwner(r.viewer) {
+		return errNotCustomFeedOwner
+	}
+
+	if err := feed.Delete(r.ctx, s.db); err != nil {
+		return err
+	}
+	return w.writeJSON(feed)
+}
+
+// @Summary		Follow or unfollow a custom feed.
+// @Description	Follow (POST) or unfollow (DELETE) the public custom feed of another user.
+// @Router			/api/users/{username}/feeds/{feedname}/follow [POST]
+// @Router			/api/users/{username}/feeds/{feedname}/follow [DELETE]
+// @Success		200	{object}	core.CustomFeed
+// @Tags			Custom feeds
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			username		path	string	true	"Username"
+// @Param			feedname		path	string	true	"Feed name"
+func (s *Server) followCustomFeed(w *responseWriter, r *request, feed *core.CustomFeed) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	if err := feed.Follow(r.ctx, s.db, *r.viewer, r.req.Method == "POST"); err != nil {
+		return err
+	}
+	return w.writeJSON(feed)
+}
+
+// @Summary		Get the posts of a custom feed.
+// @Description	Get the posts of the communities of a custom feed.
+// @Router			/api/users/{username}/feeds/{feedname}/posts [GET]
+// @Success		200	{object}	core.FeedResultSet
+// @Tags			Custom feeds
+// @Param			Authorization	header	string	false	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			username		path	string	true	"Username"
+// @Param			feedname		path	string	true	"Feed name"
+// @Param			sort			query	string	false	"Sort"	Enums(latest,hot,activity,day,week,month,year,all)
+// @Param			limit			query	int		false	"Limit"
+// @Param			next			query	string	false	"Next cursor"
+func (s *Server) getCustomFeedPosts(w *responseWriter, r *request, feed *core.CustomFeed) error {
+	query := r.urlQueryParams()
+	sort := s.config.DefaultFeedSort
+	if query.Get("sort") != "" {
+		if err := sort.UnmarshalText([]byte(query.Get("sort"))); err != nil {
+			return core.ErrInvalidFeedSort
+		}
+	}
+	limit, err := getFeedLimit(query, s.config.PaginationLimit, s.config.PaginationLimitMax)
+	if err != nil {
+		return err
+	}
+	hideRead, err := s.hideReadPosts(r)
+	if err != nil {
+		return err
+	}
+
+	set, err := core.GetFeed(r.ctx, s.db, &core.FeedOptions{
+		Sort:       sort,
+		Viewer:     r.viewer,
+		CustomFeed: feed.ID,
+		HideRead:   hideRead,
+		Limit:      limit,
+		Next:       query.Get("next"),
+	})
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(set)
+}
+
+// @Summary		Get followed custom feeds.
+// @Description	Get the custom feeds of other users that the logged in user follows.
+// @Router			/api/_followed_feeds [GET]
+// @Success		200	{array}	core.CustomFeed
+// @Tags			Custom feeds
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func (s *Server) getFollowedCustomFeeds(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	feeds, err := core.GetFollowedCustomFeeds(r.ctx, s.db, *r.viewer)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(feeds)
+}
+
+// @Summary		Get custom feed syndication feed
+// @Description	Get the latest posts of a public custom feed as an RSS, Atom, or JSON feed.
+// @Router			/api/feed/f/{username}/{feedname} [GET]
+// @Success		200
+// @Tags			Feed
+// @Param			username	path	string	true	"Username"
+// @Param			feedname	path	string	true	"Feed name"
+// @Param			type		query	string	false	"Feed type (atom, rss, json)"	Enums(atom, rss, json)	Default(rss)
+func (s *Server) getCustomFeedSyndicationFeed(w *responseWriter, r *request, feed *core.CustomFeed) error {
+	set, err := core.GetFeed(r.ctx, s.db, &core.FeedOptions{
+		Sort:       core.FeedSortLatest,
+		Viewer:     r.viewer,
+		CustomFeed: feed.ID,
+		Limit:      50,
+	})
+	if err != nil {
+		return err
+	}
+
+	feedPublicURL := fmt.Sprintf("%s/@%s/feeds/%s", s.config.PublicUrl, feed.Username, feed.Name)
+	out := &feeds.Feed{
+		Title:       feed.DisplayName + " Feed - " + s.config.SiteName,
+		Link:        &feeds.Link{Href: feedPublicURL},
+		Description: feed.Description.String,
+		Id:          fmt.Sprintf("custom-feed-%d", feed.ID),
+		Image: &feeds.Image{
+			Url:   fmt.Sprintf("%s/favicon.png", s.config.PublicUrl),
+			Title: feed.DisplayName,
+			Link:  feedPublicURL,
+		},
+		Items: s.syndicationFeedItems(set.Posts),
+	}
+	return writeSyndicationFeed(w, r.urlQueryParamsValue("type"), out)
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/discuitnet/discuit/core"
+	"github.com/gomodule/redigo/redis"
+)
+
+// eventsChannelPrefix is prepended to the topics of core events to get the
+// names of the Redis pub/sub channels.
+const eventsChannelPrefix = "events:"
+
+// redisEventPublisher implements core.EventPublisher. Events are published to
+// Redis so that clients connected to any server instance receive them.
+type redisEventPublisher struct {
+	pool *redis.Pool
+}
+
+func (p *redisEventPublisher) Publish(topic string, data []byte) error {
+	conn := p.pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("PUBLISH", eventsChannelPrefix+topic, data)
+	return err
+}
+
+// eventsHub holds a single Redis subscription per server instance and fans out
+// the received events to the clients connected to this instance.
+type eventsHub struct {
+	pool *redis.Pool
+
+	mu   sync.Mutex // guards the following
+	subs map[string]map[chan []byte]struct{}
+}
+
+func newEventsHub(pool *redis.Pool) *eventsHub {
+	h := &eventsHub{
+		pool: pool,
+		subs: make(map[string]map[chan []byte]struct{}),
+	}
+	go h.run()
+	return h
+}
+
+// run receives events from Redis until the end of time, reconnecting on
+// failure.
+func (h *eventsHub) run() {
+	for {
+		if err := h.receive(); err != nil {
+			log.Printf("Events hub Redis subscription error: %v\n", err)
+		}
+		time.Sleep(time.Second * 5)
+	}
+}
+
+func (h *eventsHub) receive() error {
+	conn, err := h.pool.Dial()
+	if err != nil {
+		return err
+	}
+	psc := redis.PubSubConn{Conn: conn}
+	defer psc.Close()
+
+	if err := psc.PSubscribe(eventsChannelPrefix + "*"); err != nil {
+		return err
+	}
+	for {
+		switch v := psc.Receive().(type) {
+		case redis.Message:
+			h.dispatch(strings.TrimPrefix(v.Channel, eventsChannelPrefix), v.Data)
+		case error:
+			return v
+		}
+	}
+}
+
+func (h *eventsHub) dispatch(topic string, data []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.subs[topic] {
+		select {
+		case c <- data:
+		default:
+			// Drop the event for clients that aren't keeping up.
+		}
+	}
+}
+
+// subscribe returns a channel on which the events of topics are sent. Call
+// unsubscribe with the same topics when done.
+func (h *eventsHub) subscribe(topics ...string) chan []byte {
+	c := make(chan []byte, 16)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, topic := range topics {
+		if h.subs[topic] == nil {
+			h.subs[topic] = make(map[chan []byte]struct{})
+		}
+		h.subs[topic][c] = struct{}{}
+	}
+	return c
+}
+
+func (h *eventsHub) unsubscribe(c chan []byte, topics ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, topic := range topics {
+		delete(h.subs[topic], c)
+		if len(h.subs[topic]) == 0 {
+			delete(h.subs, topic)
+		}
+	}
+}
+
+// @Summary		Stream real-time events.
+// @Description	A Server-Sent Events stream. Logged in users receive new notifications and notification counts. If the post query parameter is set, new comments and vote counts of that post are also sent.
+// @Router			/api/_events [GET]
+// @Success		200
+// @Tags			Events
+// @Param			post	query	string	false	"The public ID of the post the client is viewing"
+func (s *Server) streamEvents(w *responseWriter, r *request) error {
+	var topics []string
+	if r.loggedIn {
+		topics = append(topics, core.UserEventsTopic(*r.viewer))
+	}
+	if postID := r.urlQueryParamsValue("post"); postID != "" {
+		post, err := core.GetPost(r.ctx, s.db, nil, postID, r.viewer, false)
+		if err != nil {
+			return err
+		}
+		if err := s.checkCommunityViewable(r, post.CommunityID); err != nil {
+			return err
+		}
+		if err := post.CheckViewable(r.viewer); err != nil {
+			return err
+		}
+		topics = append(topics, core.PostEventsTopic(post.ID))
+	}
+	if len(topics) == 0 {
+		return errNotLoggedIn
+	}
+
+	c := s.events.subscribe(topics...)
+	defer s.events.unsubscribe(c, topics...)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+	if err := w.writeString(": connected\n\n"); err != nil {
+		return nil
+	}
+	w.flush()
+
+	keepAlive := time.NewTicker(time.Second * 30)
+	defer keepAlive.Stop()
+
+	for {
+		var err error
+		select {
+		case <-r.ctx.Done():
+			return nil
+		case data := <-c:
+			_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+		case <-keepAlive.C:
+			err = w.writeString(": ping\n\n")
+		}
+		if err != nil {
+			return nil // client is gone
+		}
+		w.flush()
+	}
+}
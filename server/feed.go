@@ -96,6 +96,16 @@ func isFilterValid(filter string) bool {
 	return false
 }
 
+// hideReadPosts reports whether the logged in user (if any) has opted to hide
+// read posts from feeds.
+func (s *Server) hideReadPosts(r *request) (bool, error) {
+	if !r.loggedIn {
+		return false, nil
+	}
+	readPosts, err := core.GetUserReadPosts(r.ctx, s.db, *r.viewer)
+	return readPosts == core.ReadPostsHide, err
+}
+
 var errInvalidFeedFilter = httperr.NewBadRequest("invalid_filter", "Invalid feed filter.")
 
 //	@Summary		Get feed.
@@ -109,7 +119,7 @@ var errInvalidFeedFilter = httperr.NewBadRequest("invalid_filter", "Invalid feed
 //	@Param			sort			query	string	false	"Sort feed by"			Enums(latest,top)
 //	@Param			limit			query	int		false	"Limit"
 //	@Param			next			query	string	false	"Next cursor"
-//	@Param			feed			query	string	false	"Feed type"	Enums(home,community)
+//	@Param			feed			query	string	false	"Feed type"	Enums(home,following,community)
 func (s *Server) feed(w *responseWriter, r *request) error {
 	query := r.urlQueryParams()
 	communityIDText := query.Get("communityId")
@@ -134,10 +144,13 @@ func (s *Server) feed(w *responseWriter, r *request) error {
 	}
 	var set *core.FeedResultSet
 
-	feed := query.Get("feed") // All or home or community.
+	feed := query.Get("feed") // All or home or following or community.
 	if filter == "" {
-		// Home, all and community feeds.
-		homeFeed := feed == "home"
+		// Home, all, following and community feeds.
+		homeFeed, following := feed == "home", feed == "following"
+		if following && !r.loggedIn {
+			return errNotLoggedIn
+		}
 		var cid *uid.ID
 		if communityIDText != "" {
 			c, err := strToID(communityIDText)
@@ -147,7 +160,11 @@ func (s *Server) feed(w *responseWriter, r *request) error {
 			cid = &c
 		}
 		if cid != nil {
-			homeFeed = false
+			homeFeed, following = false, false
+		}
+		hideRead, err := s.hideReadPosts(r)
+		if err != nil {
+			return err
 		}
 		set, err = core.GetFeed(r.ctx, s.db, &core.FeedOptions{
 			Sort:        sort,
@@ -155,6 +172,8 @@ func (s *Server) feed(w *responseWriter, r *request) error {
 			Viewer:      r.viewer,
 			Community:   cid,
 			Homefeed:    homeFeed,
+			Following:   following,
+			HideRead:    hideRead,
 			Limit:       limit,
 			Next:        nextText,
 		})
@@ -4,7 +4,6 @@ package server
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/discuitnet/discuit/core"
 	"github.com/gorilla/feeds"
@@ -40,7 +39,6 @@ func (s *Server) getCommunityFeed(w *responseWriter, r *request) error {
 		query         = r.urlQueryParams()
 		comm          *core.Community
 		set           *core.FeedResultSet
-		feedResponse  string
 		err           error
 	)
 
@@ -85,10 +83,15 @@ func (s *Server) getCommunityFeed(w *responseWriter, r *request) error {
 		}
 	}
 
-	feed.Items = []*feeds.Item{}
+	feed.Items = s.syndicationFeedItems(set.Posts)
+	return writeSyndicationFeed(w, query.Get("type"), feed)
+}
 
-	for _, item := range set.Posts {
-		itemPublicUrl := fmt.Sprintf("%s/%s%s/post/%s", s.config.PublicUrl, s.config.CommunityPrefix, comm.Name, item.PublicID)
+// syndicationFeedItems returns the RSS/Atom/JSON feed items of posts.
+func (s *Server) syndicationFeedItems(posts []*core.Post) []*feeds.Item {
+	items := []*feeds.Item{}
+	for _, item := range posts {
+		itemPublicUrl := fmt.Sprintf("%s/%s%s/post/%s", s.config.PublicUrl, s.config.CommunityPrefix, item.CommunityName, item.PublicID)
 		var author string
 		if item.AuthorDeleted || item.AuthorGhostID != "" {
 			fmt.Println(item.AuthorUsername, item.AuthorDeleted, item.AuthorGhostID)
@@ -140,37 +143,32 @@ func (s *Server) getCommunityFeed(w *responseWriter, r *request) error {
 		}
 		fi.Description = ""
 
-		feed.Items = append(feed.Items, fi)
+		items = append(items, fi)
 	}
+	return items
+}
 
-	var feedContentType string
-
-	// Switch based on query params.
-	switch query.Get("type") {
+// writeSyndicationFeed writes feed in format, which is one of atom, rss, and
+// json (rss, if empty).
+func writeSyndicationFeed(w *responseWriter, format string, feed *feeds.Feed) error {
+	var (
+		feedContentType string
+		feedResponse    string
+		err             error
+	)
+	switch format {
 	case "atom":
 		feedContentType = "application/atom+xml"
 		feedResponse, err = feed.ToAtom()
-		if err != nil {
-			log.Fatal(err)
-		}
-	case "rss":
-		feedContentType = "application/rss+xml"
-		feedResponse, err = feed.ToRss()
-		if err != nil {
-			log.Fatal(err)
-		}
 	case "json":
 		feedContentType = "application/json"
 		feedResponse, err = feed.ToJSON()
-		if err != nil {
-			log.Fatal(err)
-		}
 	default:
 		feedContentType = "application/rss+xml"
 		feedResponse, err = feed.ToRss()
-		if err != nil {
-			log.Fatal(err)
-		}
+	}
+	if err != nil {
+		return err
 	}
 
 	w.Header().Del("Content-Type")
@@ -45,6 +45,14 @@ func (rw *responseWriter) writeString(s string) error {
 	return err
 }
 
+// flush sends any buffered data to the client, if the underlying
+// http.ResponseWriter supports it.
+func (rw *responseWriter) flush() {
+	if f, ok := rw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // A request represents an HTTP request specific to Server.
 type request struct {
 	req *http.Request
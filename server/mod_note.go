@@ -0,0 +1,84 @@
+package server
+
+import (
+	"strconv"
+
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/httperr"
+)
+
+// @Summary		Get the mod info of a user.
+// @Description	Get the mod notes on a user and the user's history in a community: counts of their posts, comments, removals, and reports against them, their points, their current ban, their latest posts and comments, and the latest mod actions taken against them.
+// @Router			/api/communities/{communityID}/users/{username}/modinfo [GET]
+// @Success		200	{object}	core.ModUserInfo
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			username		path	string	true	"Username"
+func (s *Server) getUserModInfo(w *responseWriter, r *request, comm *core.Community) error {
+	user, err := core.GetUserByUsername(r.ctx, s.db, r.muxVar("username"), r.viewer)
+	if err != nil {
+		return err
+	}
+	info, err := comm.GetModUserInfo(r.ctx, *r.viewer, user)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(info)
+}
+
+// @Summary		Add a mod note.
+// @Description	Add a note on a user that only the mods of the community (and the admins) can see. The category is one of note, warning, spam, and ban.
+// @Router			/api/communities/{communityID}/users/{username}/notes [POST]
+// @Success		200	{object}	core.ModNote
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			username		path	string	true	"Username"
+// @Param			body			body	object{category=string,body=string}	true	"Body"
+func (s *Server) addModNote(w *responseWriter, r *request, comm *core.Community) error {
+	user, err := core.GetUserByUsername(r.ctx, s.db, r.muxVar("username"), nil)
+	if err != nil {
+		return err
+	}
+
+	values, err := r.unmarshalJSONBodyToStringsMap(true)
+	if err != nil {
+		return err
+	}
+
+	note, err := comm.AddModNote(r.ctx, *r.viewer, user.ID, core.ModNoteCategory(values["category"]), values["body"])
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(note)
+}
+
+// @Summary		Delete a mod note.
+// @Description	Delete a mod note.
+// @Router			/api/communities/{communityID}/users/{username}/notes/{noteID} [DELETE]
+// @Success		200	{object}	core.ModNote
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			username		path	string	true	"Username"
+// @Param			noteID			path	int		true	"Mod note ID"
+func (s *Server) deleteModNote(w *responseWriter, r *request, comm *core.Community) error {
+	errNotFound := httperr.NewNotFound("mod-note-not-found", "Mod note not found.")
+	noteID, err := strconv.ParseUint(r.muxVar("noteID"), 10, 32)
+	if err != nil {
+		return errNotFound
+	}
+	note, err := core.GetModNote(r.ctx, s.db, uint(noteID))
+	if err != nil {
+		return err
+	}
+	if note.CommunityID != comm.ID {
+		return errNotFound
+	}
+
+	if err = note.Delete(r.ctx, *r.viewer); err != nil {
+		return err
+	}
+	return w.writeJSON(note)
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// @Summary		Reorder the mods of a community.
+// @Description	Change the mod hierarchy of a community. The mods field must list the IDs of all the mods of the community, the top mod first. A mod can only reorder the mods below them; admins can reorder all of them.
+// @Router			/api/communities/{communityID}/mods [PUT]
+// @Success		200	{array}	core.User
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			body			body	object{mods=[]string}	true	"Body"
+func (s *Server) reorderCommunityMods(w *responseWriter, r *request, comm *core.Community) error {
+	form := struct {
+		Mods []uid.ID `json:"mods"`
+	}{}
+	if err := r.unmarshalJSONBody(&form); err != nil {
+		return err
+	}
+
+	if err := comm.ReorderMods(r.ctx, *r.viewer, form.Mods); err != nil {
+		return err
+	}
+	if err := comm.PopulateMods(r.ctx); err != nil {
+		return err
+	}
+	return w.writeJSON(comm.Mods)
+}
+
+// @Summary		Get the pending mod transfer of a community.
+// @Description	Get the pending offer, by the top mod of a community, to make another mod the top mod.
+// @Router			/api/communities/{communityID}/mod_transfer [GET]
+// @Success		200	{object}	core.ModTransfer
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+func (s *Server) getModTransfer(w *responseWriter, r *request, comm *core.Community) error {
+	transfer, err := comm.GetModTransfer(r.ctx)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(transfer)
+}
+
+// @Summary		Offer to transfer a community.
+// @Description	Offer to make another mod of a community the top mod. Only the top mod (and admins) can make the offer, which the mod has to accept. Any previous pending offer is replaced.
+// @Router			/api/communities/{communityID}/mod_transfer [POST]
+// @Success		200	{object}	core.ModTransfer
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			body			body	object{username=string}	true	"The mod to transfer the community to"
+func (s *Server) offerModTransfer(w *responseWriter, r *request, comm *core.Community) error {
+	values, err := r.unmarshalJSONBodyToStringsMap(true)
+	if err != nil {
+		return err
+	}
+	user, err := core.GetUserByUsername(r.ctx, s.db, values["username"], nil)
+	if err != nil {
+		return err
+	}
+
+	transfer, err := comm.OfferModTransfer(r.ctx, *r.viewer, user.ID)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(transfer)
+}
+
+// @Summary		Accept a mod transfer.
+// @Description	Accept the pending offer to become the top mod of a community. Only the mod the offer was made to can accept it.
+// @Router			/api/communities/{communityID}/mod_transfer [PUT]
+// @Success		200	{array}	core.User
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+func (s *Server) acceptModTransfer(w *responseWriter, r *request, comm *core.Community) error {
+	transfer, err := comm.GetModTransfer(r.ctx)
+	if err != nil {
+		return err
+	}
+	if err := transfer.Accept(r.ctx, *r.viewer); err != nil {
+		return err
+	}
+	if err := comm.PopulateMods(r.ctx); err != nil {
+		return err
+	}
+	return w.writeJSON(comm.Mods)
+}
+
+// @Summary		Cancel or decline a mod transfer.
+// @Description	Cancel the pending mod transfer of a community (by the mod who offered it) or decline it (by the mod it was offered to).
+// @Router			/api/communities/{communityID}/mod_transfer [DELETE]
+// @Success		200	{object}	core.ModTransfer
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+func (s *Server) deleteModTransfer(w *responseWriter, r *request, comm *core.Community) error {
+	transfer, err := comm.GetModTransfer(r.ctx)
+	if err != nil {
+		return err
+	}
+	if err := transfer.Cancel(r.ctx, *r.viewer); err != nil {
+		return err
+	}
+	return w.writeJSON(transfer)
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/httperr"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// modLogQueryFromURL returns the filters of a mod log query (other than the
+// community one) found in the URL query parameters of r.
+func (s *Server) modLogQueryFromURL(r *request) (*core.ModLogQuery, error) {
+	query := r.urlQueryParams()
+	q := &core.ModLogQuery{Action: core.ModAction(query.Get("action"))}
+	if username := query.Get("actor"); username != "" {
+		user, err := core.GetUserByUsername(r.ctx, s.db, username, nil)
+		if err != nil {
+			return nil, err
+		}
+		q.Actor = uid.NullID{ID: user.ID, Valid: true}
+	}
+	if username := query.Get("user"); username != "" {
+		user, err := core.GetUserByUsername(r.ctx, s.db, username, nil)
+		if err != nil {
+			return nil, err
+		}
+		q.TargetUser = uid.NullID{ID: user.ID, Valid: true}
+	}
+	return q, nil
+}
+
+// writeModLog writes a page of the mod log entries matching q.
+func (s *Server) writeModLog(w *responseWriter, r *request, q *core.ModLogQuery) error {
+	limit, err := getFeedLimit(r.urlQueryParams(), s.config.PaginationLimit, s.config.PaginationLimitMax)
+	if err != nil {
+		return err
+	}
+	var next *string
+	if nextString := r.urlQueryParamsValue("next"); nextString != "" {
+		next = &nextString
+	}
+
+	set, err := core.GetModLog(r.ctx, s.db, q, limit, next)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(set)
+}
+
+// @Summary		Get the mod log of a community.
+// @Description	Get the log of the actions taken by the mods (and admins) of a community, the latest ones first. Unless the community has made its mod log public, only its mods and admins can view it.
+// @Router			/api/communities/{communityID}/modlog [GET]
+// @Success		200	{object}	core.ModLogResultSet
+// @Tags			Community
+// @Param			communityID	path	string	true	"Community ID"
+// @Param			action		query	string	false	"Only entries of this action"
+// @Param			actor		query	string	false	"Only actions taken by this user (username)"
+// @Param			user		query	string	false	"Only actions taken against this user (username)"
+// @Param			limit		query	int		false	"Number of entries per page"
+// @Param			next		query	string	false	"Pagination cursor"
+func (s *Server) getCommunityModLog(w *responseWriter, r *request) error {
+	cid, err := strToID(r.muxVar("communityID"))
+	if err != nil {
+		return err
+	}
+	comm, err := core.GetCommunityByID(r.ctx, s.db, cid, r.viewer)
+	if err != nil {
+		return err
+	}
+	if err := s.checkCommunityViewable(r, comm.ID); err != nil {
+		return err
+	}
+
+	if !comm.ModLogPublic {
+		if !r.loggedIn {
+			return errNotLoggedIn
+		}
+		if ok, err := userModOrAdmin(r.ctx, s.db, *r.viewer, comm); err != nil {
+			return err
+		} else if !ok {
+			return httperr.NewForbidden("mod-log-private", "The mod log of this community is not public.")
+		}
+	}
+
+	q, err := s.modLogQueryFromURL(r)
+	if err != nil {
+		return err
+	}
+	q.Community = uid.NullID{ID: comm.ID, Valid: true}
+	return s.writeModLog(w, r, q)
+}
+
+// @Summary		Get the site-wide mod log.
+// @Description	Get the log of the actions taken by all mods and admins, the latest ones first. Only admins can view it.
+// @Router			/api/_admin/modlog [GET]
+// @Success		200	{object}	core.ModLogResultSet
+// @Tags			Admin
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			community		query	string	false	"Only entries of this community (name)"
+// @Param			action			query	string	false	"Only entries of this action"
+// @Param			actor			query	string	false	"Only actions taken by this user (username)"
+// @Param			user			query	string	false	"Only actions taken against this user (username)"
+// @Param			limit			query	int		false	"Number of entries per page"
+// @Param			next			query	string	false	"Pagination cursor"
+func (s *Server) getAdminModLog(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+	admin, err := core.GetUser(r.ctx, s.db, *r.viewer, nil)
+	if err != nil {
+		return err
+	}
+	if !admin.Admin {
+		return httperr.NewForbidden("not_admin", "You are not an admin.")
+	}
+
+	q, err := s.modLogQueryFromURL(r)
+	if err != nil {
+		return err
+	}
+	if name := r.urlQueryParamsValue("community"); name != "" {
+		comm, err := core.GetCommunityByName(r.ctx, s.db, name, nil)
+		if err != nil {
+			return err
+		}
+		q.Community = uid.NullID{ID: comm.ID, Valid: true}
+	}
+	return s.writeModLog(w, r, q)
+}
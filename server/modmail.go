@@ -0,0 +1,239 @@
+package server
+
+import (
+	"time"
+
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/httperr"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// @Summary		Get the logged in user's modmail threads.
+// @Description	Get the modmail threads the logged in user opened with the mods of communities, the ones with the latest messages first.
+// @Router			/api/modmail [GET]
+// @Success		200	{object}	core.ModmailThreadsResultSet
+// @Tags			Modmail
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			limit			query	int		false	"Limit"
+// @Param			next			query	string	false	"Next"
+func (s *Server) getUserModmail(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	limit, err := getFeedLimit(r.urlQueryParams(), 20, 50)
+	if err != nil {
+		return err
+	}
+	var next *string
+	if nextString := r.urlQueryParamsValue("next"); nextString != "" {
+		next = &nextString
+	}
+
+	set, err := core.GetUserModmailThreads(r.ctx, s.db, *r.viewer, limit, next)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(set)
+}
+
+// @Summary		Message the mods of a community.
+// @Description	Open a modmail thread with the mod team of a community.
+// @Router			/api/communities/{communityID}/modmail [POST]
+// @Success		200	{object}	core.ModmailThread
+// @Tags			Modmail
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			body			body	object{subject=string,body=string}	true	"Body"
+func (s *Server) createModmailThread(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	cid, err := strToID(r.muxVar("communityID"))
+	if err != nil {
+		return err
+	}
+	comm, err := core.GetCommunityByID(r.ctx, s.db, cid, r.viewer)
+	if err != nil {
+		return err
+	}
+
+	values, err := r.unmarshalJSONBodyToStringsMap(true)
+	if err != nil {
+		return err
+	}
+
+	if err := s.rateLimit(r, "modmail_c_1_"+r.viewer.String(), time.Second*10, 1); err != nil {
+		return err
+	}
+	if err := s.rateLimit(r, "modmail_c_2_"+r.viewer.String(), time.Hour*24, 20); err != nil {
+		return err
+	}
+
+	thread, err := comm.CreateModmailThread(r.ctx, *r.viewer, values["subject"], values["body"])
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(thread)
+}
+
+// @Summary		Get the modmail of a community.
+// @Description	Get the shared modmail inbox of the mods of a community, the threads with the latest messages first.
+// @Router			/api/communities/{communityID}/modmail [GET]
+// @Success		200	{object}	core.ModmailThreadsResultSet
+// @Tags			Modmail
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			archived		query	bool	false	"If true, only archived threads are returned"
+// @Param			assignedTo		query	string	false	"Only the threads assigned to this user ID are returned"
+// @Param			limit			query	int		false	"Limit"
+// @Param			next			query	string	false	"Next"
+func (s *Server) getCommunityModmail(w *responseWriter, r *request, comm *core.Community) error {
+	query := r.urlQueryParams()
+	limit, err := getFeedLimit(query, 20, 50)
+	if err != nil {
+		return err
+	}
+	var next *string
+	if nextString := query.Get("next"); nextString != "" {
+		next = &nextString
+	}
+	var assignedTo uid.NullID
+	if idString := query.Get("assignedTo"); idString != "" {
+		if assignedTo.ID, err = strToID(idString); err != nil {
+			return err
+		}
+		assignedTo.Valid = true
+	}
+
+	set, err := comm.GetModmail(r.ctx, *r.viewer, query.Get("archived") == "true", assignedTo, limit, next)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(set)
+}
+
+func (s *Server) withModmailThread(f func(*responseWriter, *request, *core.ModmailThread) error) handler {
+	return handler(func(w *responseWriter, r *request) error {
+		if !r.loggedIn {
+			return errNotLoggedIn
+		}
+
+		threadID, err := strToID(r.muxVar("threadID"))
+		if err != nil {
+			return err
+		}
+
+		thread, err := core.GetModmailThread(r.ctx, s.db, threadID, *r.viewer)
+		if err != nil {
+			return err
+		}
+
+		return f(w, r, thread)
+	})
+}
+
+// @Summary		Get a modmail thread.
+// @Description	Get a modmail thread along with its messages. The mods of the community also see the internal notes of the thread and the authors of all the messages. Getting a thread marks it as read.
+// @Router			/api/modmail/{threadID} [GET]
+// @Success		200
+// @Tags			Modmail
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			threadID		path	string	true	"Thread ID"
+func (s *Server) getModmailThread(w *responseWriter, r *request, thread *core.ModmailThread) error {
+	messages, err := thread.GetMessages(r.ctx)
+	if err != nil {
+		return err
+	}
+	if err := thread.MarkRead(r.ctx); err != nil {
+		return err
+	}
+	return w.writeJSON(struct {
+		*core.ModmailThread
+		Messages []*core.ModmailMessage `json:"messages"`
+	}{thread, messages})
+}
+
+// @Summary		Update a modmail thread.
+// @Description	Assign a modmail thread to a mod (or unassign it), or archive or unarchive it. Only the mods of the community can update a thread.
+// @Router			/api/modmail/{threadID} [PUT]
+// @Success		200	{object}	core.ModmailThread
+// @Tags			Modmail
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			threadID		path	string	true	"Thread ID"
+// @Param			action			query	string	true	"Action"	Enums(assign,archive,unarchive)
+// @Param			body			body	object{username=string}	false	"The mod to assign the thread to (for the assign action); empty to unassign"
+func (s *Server) updateModmailThread(w *responseWriter, r *request, thread *core.ModmailThread) error {
+	if !thread.ViewerIsMod {
+		return errNotAdminNorMod
+	}
+
+	var err error
+	switch action := r.urlQueryParamsValue("action"); action {
+	case "assign":
+		var assignee uid.NullID
+		if assignee, err = s.modmailAssignee(r); err != nil {
+			return err
+		}
+		err = thread.Assign(r.ctx, *r.viewer, assignee)
+	case "archive", "unarchive":
+		err = thread.SetArchived(r.ctx, *r.viewer, action == "archive")
+	default:
+		return httperr.NewBadRequest("invalid_action", "Unsupported action.")
+	}
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(thread)
+}
+
+// modmailAssignee returns the user, given in the username field of the request
+// body, to assign a modmail thread to. It returns a null ID if the username is
+// empty.
+func (s *Server) modmailAssignee(r *request) (uid.NullID, error) {
+	values, err := r.unmarshalJSONBodyToStringsMap(true)
+	if err != nil {
+		return uid.NullID{}, err
+	}
+	if values["username"] == "" {
+		return uid.NullID{}, nil
+	}
+	user, err := core.GetUserByUsername(r.ctx, s.db, values["username"], nil)
+	if err != nil {
+		return uid.NullID{}, err
+	}
+	return uid.NullID{ID: user.ID, Valid: true}, nil
+}
+
+// @Summary		Reply to a modmail thread.
+// @Description	Add a message to a modmail thread. The mods of the community can reply as the mod team (asMods), which hides their identity from the user, and can add internal notes, which only the mods see.
+// @Router			/api/modmail/{threadID}/messages [POST]
+// @Success		200	{object}	core.ModmailMessage
+// @Tags			Modmail
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			threadID		path	string	true	"Thread ID"
+// @Param			body			body	object{body=string,asMods=bool,internal=bool}	true	"Body"
+func (s *Server) replyToModmailThread(w *responseWriter, r *request, thread *core.ModmailThread) error {
+	if err := s.rateLimit(r, "modmail_m_1_"+r.viewer.String(), time.Second, 2); err != nil {
+		return err
+	}
+	if err := s.rateLimit(r, "modmail_m_2_"+r.viewer.String(), time.Hour*24, 1000); err != nil {
+		return err
+	}
+
+	form := struct {
+		Body     string `json:"body"`
+		AsMods   bool   `json:"asMods"`
+		Internal bool   `json:"internal"`
+	}{}
+	if err := r.unmarshalJSONBody(&form); err != nil {
+		return err
+	}
+
+	message, err := thread.Reply(r.ctx, *r.viewer, form.Body, form.AsMods, form.Internal)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(message)
+}
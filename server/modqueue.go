@@ -0,0 +1,112 @@
+package server
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/httperr"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// writeModQueue writes the page of the mod queue of communities given by the
+// reason, limit, and page URL query parameters.
+func (s *Server) writeModQueue(w *responseWriter, r *request, communities []uid.ID, query url.Values) error {
+	limit, err := getFeedLimit(query, s.config.PaginationLimit, s.config.PaginationLimitMax)
+	if err != nil {
+		return err
+	}
+
+	page := 1
+	if spage := query.Get("page"); spage != "" {
+		if page, err = strconv.Atoi(spage); err != nil || page < 1 {
+			return httperr.NewBadRequest("invalid_page", "Invalid page.")
+		}
+	}
+
+	reason := core.ModQueueReason(query.Get("reason"))
+	items, err := core.GetModQueue(r.ctx, s.db, communities, reason, *r.viewer, limit, page)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(items)
+}
+
+// @Summary		Get the mod queue of a community.
+// @Description	Get the reported posts and comments, the posts and comments removed by automod awaiting approval, and the posts and comments edited after being reported, of a community. The latest items are returned first.
+// @Router			/api/communities/{communityID}/modqueue [GET]
+// @Success		200	{array}	core.ModQueueItem
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			reason			query	string	false	"Only the items queued for this reason"	Enums(reported, filtered, edited)
+// @Param			limit			query	int		false	"Number of items per page"
+// @Param			page			query	int		false	"Page number"
+func (s *Server) getCommunityModQueue(w *responseWriter, r *request, comm *core.Community) error {
+	return s.writeModQueue(w, r, []uid.ID{comm.ID}, r.urlQueryParams())
+}
+
+// @Summary		Get the mod queue of all the communities the user mods.
+// @Description	Get the mod queue of all the communities the user mods, or of one of them with the community query parameter.
+// @Router			/api/modqueue [GET]
+// @Success		200	{array}	core.ModQueueItem
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			community		query	string	false	"Only the items of the community with this ID"
+// @Param			reason			query	string	false	"Only the items queued for this reason"	Enums(reported, filtered, edited)
+// @Param			limit			query	int		false	"Number of items per page"
+// @Param			page			query	int		false	"Page number"
+func (s *Server) getModQueue(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	user, err := core.GetUser(r.ctx, s.db, *r.viewer, nil)
+	if err != nil {
+		return err
+	}
+	if err := user.LoadModdingList(r.ctx); err != nil {
+		return err
+	}
+
+	query := r.urlQueryParams()
+	var communities []uid.ID
+	for _, comm := range user.ModdingList {
+		if c := query.Get("community"); c == "" || c == comm.ID.String() {
+			communities = append(communities, comm.ID)
+		}
+	}
+	return s.writeModQueue(w, r, communities, query)
+}
+
+// @Summary		Act on an item of the mod queue.
+// @Description	Approve, remove, or ignore a post or a comment of the mod queue. All actions remove the item from the queue, along with its reports. Approving a post or a comment removed by automod restores it.
+// @Router			/api/modqueue [POST]
+// @Success		200
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			body			body	object{targetType=string,targetId=string,action=string}	true	"Body (targetType is post or comment, and action is approve, remove, or ignore)"
+func (s *Server) modQueueAction(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	values, err := r.unmarshalJSONBodyToStringsMap(true)
+	if err != nil {
+		return err
+	}
+	var targetType core.ContentType
+	if err := targetType.UnmarshalText([]byte(values["targetType"])); err != nil {
+		return httperr.NewBadRequest("invalid_target_type", "Invalid target type.")
+	}
+	targetID, err := strToID(values["targetId"])
+	if err != nil {
+		return err
+	}
+
+	action := core.ModQueueAction(values["action"])
+	if err := core.TakeModQueueAction(r.ctx, s.db, *r.viewer, targetType, targetID, action); err != nil {
+		return err
+	}
+	return w.writeString(`{"success":true}`)
+}
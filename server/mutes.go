@@ -10,8 +10,42 @@ import (
 	"github.com/discuitnet/discuit/internal/uid"
 )
 
-// @Summary		Get a list of muted users and communities
-// @Description	Get a list of muted users and communities
+// writeMutes writes the mutes of the logged in user, grouped by type, to w.
+func (s *Server) writeMutes(r *request, w io.Writer) error {
+	commMutes, err := core.GetMutedCommunities(r.ctx, s.db, *r.viewer, true)
+	if err != nil {
+		return err
+	}
+	userMutes, err := core.GetMutedUsers(r.ctx, s.db, *r.viewer, true)
+	if err != nil {
+		return err
+	}
+	filterMutes, err := core.GetMutedFilters(r.ctx, s.db, *r.viewer)
+	if err != nil {
+		return err
+	}
+
+	if commMutes == nil {
+		commMutes = []*core.Mute{}
+	}
+	if userMutes == nil {
+		userMutes = []*core.Mute{}
+	}
+	if filterMutes == nil {
+		filterMutes = []*core.Mute{}
+	}
+
+	response := struct {
+		CommunityMutes []*core.Mute `json:"communityMutes"`
+		UserMutes      []*core.Mute `json:"userMutes"`
+		FilterMutes    []*core.Mute `json:"filterMutes"`
+	}{commMutes, userMutes, filterMutes}
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+// @Summary		Get a list of muted users, communities, and content filters
+// @Description	Get a list of muted users, communities, and content filters
 // @Router			/api/mutes [GET]
 // @Success		200
 // @Tags			Mutes
@@ -22,28 +56,7 @@ func (s *Server) getListMutedUsersCommunities(w *responseWriter, r *request) err
 	}
 
 	writeMutes := func(w io.Writer) error {
-		commMutes, err := core.GetMutedCommunities(r.ctx, s.db, *r.viewer, true)
-		if err != nil {
-			return err
-		}
-		userMutes, err := core.GetMutedUsers(r.ctx, s.db, *r.viewer, true)
-		if err != nil {
-			return err
-		}
-
-		if commMutes == nil {
-			commMutes = []*core.Mute{}
-		}
-		if userMutes == nil {
-			userMutes = []*core.Mute{}
-		}
-
-		response := struct {
-			CommunityMutes []*core.Mute `json:"communityMutes"`
-			UserMutes      []*core.Mute `json:"userMutes"`
-		}{commMutes, userMutes}
-
-		return json.NewEncoder(w).Encode(response)
+		return s.writeMutes(r, w)
 	}
 
 	switch r.req.Method {
@@ -53,8 +66,9 @@ func (s *Server) getListMutedUsersCommunities(w *responseWriter, r *request) err
 		}
 	case "POST":
 		request := struct {
-			UserID      uid.ID `json:"userId"`
-			CommunityID uid.ID `json:"communityId"`
+			UserID      uid.ID              `json:"userId"`
+			CommunityID uid.ID              `json:"communityId"`
+			Filter      *core.ContentFilter `json:"filter"`
 		}{}
 		if err := r.unmarshalJSONBody(&request); err != nil {
 			return err
@@ -69,6 +83,11 @@ func (s *Server) getListMutedUsersCommunities(w *responseWriter, r *request) err
 				return err
 			}
 		}
+		if request.Filter != nil {
+			if err := core.MuteContent(r.ctx, s.db, *r.viewer, request.Filter); err != nil {
+				return err
+			}
+		}
 		if err := writeMutes(w); err != nil {
 			return err
 		}
@@ -94,8 +113,8 @@ func (s *Server) getListMutedUsersCommunities(w *responseWriter, r *request) err
 	return nil
 }
 
-// @Summary		Mute a user or community
-// @Description	Mute a user or community
+// @Summary		Mute a user or community, or add a content filter
+// @Description	Mute a user or community, or add a content filter. A content filter ({"filter": {"type": "keyword", "pattern": "...", "action": "hide"}}) hides, or collapses, posts and comments matched by a keyword, a regular expression, a link domain, or a post type.
 // @Router			/api/mutes [POST]
 // @Success		200
 // @Tags			Mutes
@@ -106,28 +125,7 @@ func (s *Server) muteUserOrCommunity(w *responseWriter, r *request) error {
 	}
 
 	writeMutes := func(w io.Writer) error {
-		commMutes, err := core.GetMutedCommunities(r.ctx, s.db, *r.viewer, true)
-		if err != nil {
-			return err
-		}
-		userMutes, err := core.GetMutedUsers(r.ctx, s.db, *r.viewer, true)
-		if err != nil {
-			return err
-		}
-
-		if commMutes == nil {
-			commMutes = []*core.Mute{}
-		}
-		if userMutes == nil {
-			userMutes = []*core.Mute{}
-		}
-
-		response := struct {
-			CommunityMutes []*core.Mute `json:"communityMutes"`
-			UserMutes      []*core.Mute `json:"userMutes"`
-		}{commMutes, userMutes}
-
-		return json.NewEncoder(w).Encode(response)
+		return s.writeMutes(r, w)
 	}
 
 	switch r.req.Method {
@@ -137,8 +135,9 @@ func (s *Server) muteUserOrCommunity(w *responseWriter, r *request) error {
 		}
 	case "POST":
 		request := struct {
-			UserID      uid.ID `json:"userId"`
-			CommunityID uid.ID `json:"communityId"`
+			UserID      uid.ID              `json:"userId"`
+			CommunityID uid.ID              `json:"communityId"`
+			Filter      *core.ContentFilter `json:"filter"`
 		}{}
 		if err := r.unmarshalJSONBody(&request); err != nil {
 			return err
@@ -153,6 +152,11 @@ func (s *Server) muteUserOrCommunity(w *responseWriter, r *request) error {
 				return err
 			}
 		}
+		if request.Filter != nil {
+			if err := core.MuteContent(r.ctx, s.db, *r.viewer, request.Filter); err != nil {
+				return err
+			}
+		}
 		if err := writeMutes(w); err != nil {
 			return err
 		}
@@ -184,35 +188,14 @@ func (s *Server) muteUserOrCommunity(w *responseWriter, r *request) error {
 // @Success		200
 // @Tags			Mutes
 // @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
-// @Param			type			query	string	false	"The type of mute to clear"			Enums(user, community)
+// @Param			type			query	string	false	"The type of mute to clear"			Enums(user, community, filter)
 func (s *Server) clearAllMutes(w *responseWriter, r *request) error {
 	if !r.loggedIn {
 		return errNotLoggedIn
 	}
 
 	writeMutes := func(w io.Writer) error {
-		commMutes, err := core.GetMutedCommunities(r.ctx, s.db, *r.viewer, true)
-		if err != nil {
-			return err
-		}
-		userMutes, err := core.GetMutedUsers(r.ctx, s.db, *r.viewer, true)
-		if err != nil {
-			return err
-		}
-
-		if commMutes == nil {
-			commMutes = []*core.Mute{}
-		}
-		if userMutes == nil {
-			userMutes = []*core.Mute{}
-		}
-
-		response := struct {
-			CommunityMutes []*core.Mute `json:"communityMutes"`
-			UserMutes      []*core.Mute `json:"userMutes"`
-		}{commMutes, userMutes}
-
-		return json.NewEncoder(w).Encode(response)
+		return s.writeMutes(r, w)
 	}
 
 	switch r.req.Method {
@@ -222,8 +205,9 @@ func (s *Server) clearAllMutes(w *responseWriter, r *request) error {
 		}
 	case "POST":
 		request := struct {
-			UserID      uid.ID `json:"userId"`
-			CommunityID uid.ID `json:"communityId"`
+			UserID      uid.ID              `json:"userId"`
+			CommunityID uid.ID              `json:"communityId"`
+			Filter      *core.ContentFilter `json:"filter"`
 		}{}
 		if err := r.unmarshalJSONBody(&request); err != nil {
 			return err
@@ -238,6 +222,11 @@ func (s *Server) clearAllMutes(w *responseWriter, r *request) error {
 				return err
 			}
 		}
+		if request.Filter != nil {
+			if err := core.MuteContent(r.ctx, s.db, *r.viewer, request.Filter); err != nil {
+				return err
+			}
+		}
 		if err := writeMutes(w); err != nil {
 			return err
 		}
@@ -263,8 +252,8 @@ func (s *Server) clearAllMutes(w *responseWriter, r *request) error {
 	return nil
 }
 
-// @Summary		Unmute a user or community
-// @Description	Unmute a user or community
+// @Summary		Unmute a user or community, or delete a content filter
+// @Description	Unmute a user or community, or delete a content filter
 // @Router			/api/mutes/{muteID} [DELETE]
 // @Success		200
 // @Tags			Mutes
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/httperr"
+)
+
+// @Summary		Get notification preferences.
+// @Description	Get the logged in user's notification preferences (a matrix of notification types against channels), and the posts and communities from which notifications are muted.
+// @Router			/api/notifications/preferences [GET]
+// @Success		200
+// @Tags			Notifications
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func (s *Server) getNotificationPreferences(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	res := struct {
+		Preferences core.NotificationPreferences `json:"preferences"`
+		Mutes       *core.NotificationMutes      `json:"mutes"`
+	}{}
+	var err error
+	if res.Preferences, err = core.GetNotificationPreferences(r.ctx, s.db, *r.viewer); err != nil {
+		return err
+	}
+	if res.Mutes, err = core.GetNotificationMutes(r.ctx, s.db, *r.viewer); err != nil {
+		return err
+	}
+	return w.writeJSON(res)
+}
+
+// @Summary		Update notification preferences.
+// @Description	Update the logged in user's notification preferences. The request body is an object of notification types to channels (for example, {"new_votes": {"inApp": true, "push": false, "email": false}}). Notification types not in the body are left unchanged.
+// @Router			/api/notifications/preferences [PUT]
+// @Success		200
+// @Tags			Notifications
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func (s *Server) updateNotificationPreferences(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	prefs := make(core.NotificationPreferences)
+	if err := r.unmarshalJSONBody(&prefs); err != nil {
+		return err
+	}
+	if err := core.SaveNotificationPreferences(r.ctx, s.db, *r.viewer, prefs); err != nil {
+		return err
+	}
+
+	prefs, err := core.GetNotificationPreferences(r.ctx, s.db, *r.viewer)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(prefs)
+}
+
+// @Summary		Mute or unmute notifications from a post or a community.
+// @Description	Mute (POST) or unmute (DELETE) all notifications about a post (postId, the public ID of the post) or about the posts of a community (communityId). The parameters are passed as URL query parameters.
+// @Router			/api/notifications/mutes [POST]
+// @Router			/api/notifications/mutes [DELETE]
+// @Success		200
+// @Tags			Notifications
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			postId			query	string	false	"Post ID"
+// @Param			communityId		query	string	false	"Community ID"
+func (s *Server) muteNotifications(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	mute := r.req.Method == "POST"
+	if postID := r.urlQueryParamsValue("postId"); postID != "" {
+		post, err := core.GetPost(r.ctx, s.db, nil, postID, r.viewer, true)
+		if err != nil {
+			return err
+		}
+		if err := core.MutePostNotifications(r.ctx, s.db, *r.viewer, post.ID, mute); err != nil {
+			return err
+		}
+	} else if communityID := r.urlQueryParamsValue("communityId"); communityID != "" {
+		id, err := strToID(communityID)
+		if err != nil {
+			return err
+		}
+		if err := core.MuteCommunityNotifications(r.ctx, s.db, *r.viewer, id, mute); err != nil {
+			return err
+		}
+	} else {
+		return httperr.NewBadRequest("missing_target", "Either postId or communityId is required.")
+	}
+	return w.writeString(`{"success":true}`)
+}
@@ -106,17 +106,35 @@ func (s *Server) addPost(w *responseWriter, r *request) error {
 //	@Tags			Posts
 //	@Param			postID			path	string	true	"The ID of the post to get"
 //	@Param			fetchCommunity	query	string	false	"Fetch the community of the post"	Enums(true, false)
+//	@Param			visit			query	string	false	"Record a visit to the post page (if read tracking is on)"	Enums(true, false)
 func (s *Server) getPost(w *responseWriter, r *request) error {
 	postID := r.muxVar("postID") // public post id
 	post, err := core.GetPost(r.ctx, s.db, nil, postID, r.viewer, true)
 	if err != nil {
 		return err
 	}
+	if err = post.CheckViewable(r.viewer); err != nil {
+		return err
+	}
+	if err = s.checkCommunityViewable(r, post.CommunityID); err != nil {
+		return err
+	}
 
 	if _, err = post.GetComments(r.ctx, r.viewer, nil); err != nil {
 		return err
 	}
 
+	if r.loggedIn {
+		if err = post.FetchViewerFollows(r.ctx, *r.viewer); err != nil {
+			return err
+		}
+		if r.urlQueryParamsValue("visit") == "true" {
+			if err = post.RecordViewerVisit(r.ctx, *r.viewer); err != nil {
+				return err
+			}
+		}
+	}
+
 	if fetchCommunity := r.urlQueryParamsValue("fetchCommunity"); fetchCommunity == "" || fetchCommunity == "true" {
 		comm, err := core.GetCommunityByID(r.ctx, s.db, post.CommunityID, r.viewer)
 		if err != nil {
@@ -230,6 +248,9 @@ func (s *Server) updatePost(w *responseWriter, r *request) error {
 //	@Tags			Posts
 //	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
 //	@Param			postID			path	string	true	"The ID of the post to delete"
+//	@Param			reasonId		query	int		false	"ID of a removal reason of the community (for mods and admins)"
+//	@Param			reason			query	string	false	"Removal reason note, appended to the removal reason (for mods and admins)"
+//	@Param			reasonComment	query	bool	false	"Whether to post the removal reason as a public comment"
 func (s *Server) deletePost(w *responseWriter, r *request) error {
 	postID := r.muxVar("postID") // public post id
 	if !r.loggedIn {
@@ -258,7 +279,11 @@ func (s *Server) deletePost(w *responseWriter, r *request) error {
 			return httperr.NewBadRequest("", "deleteContent must be a bool.")
 		}
 	}
-	if err := post.Delete(r.ctx, *r.viewer, as, deleteContent, true); err != nil {
+	removal, err := s.removalFromQuery(r, post.CommunityID, as)
+	if err != nil {
+		return err
+	}
+	if err := post.Delete(r.ctx, *r.viewer, as, deleteContent, true, removal); err != nil {
 		return err
 	}
 
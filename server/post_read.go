@@ -0,0 +1,36 @@
+package server
+
+import (
+	"time"
+
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// @Summary		Mark posts as read.
+// @Description	Mark posts, that have been scrolled past or opened, as read by the logged in user. It's a no-op if the user has read tracking turned off.
+// @Router			/api/_read [POST]
+// @Success		200
+// @Tags			Posts
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func (s *Server) markPostsRead(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	form := struct {
+		PostIDs []uid.ID `json:"postIds"`
+	}{}
+	if err := r.unmarshalJSONBody(&form); err != nil {
+		return err
+	}
+
+	if err := s.rateLimit(r, "read_1_"+r.viewer.String(), time.Second*2, 5); err != nil {
+		return err
+	}
+
+	if err := core.MarkPostsRead(r.ctx, s.db, *r.viewer, form.PostIDs); err != nil {
+		return err
+	}
+	return w.writeString(`{"success":true}`)
+}
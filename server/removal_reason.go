@@ -0,0 +1,140 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/httperr"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// @Summary		Get removal reasons.
+// @Description	Get the removal reasons of a community, which mods pick from when removing a post or a comment.
+// @Router			/api/communities/{communityID}/removal_reasons [GET]
+// @Success		200	{array}	core.RemovalReason
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+func (s *Server) getRemovalReasons(w *responseWriter, r *request, comm *core.Community) error {
+	reasons, err := core.GetRemovalReasons(r.ctx, s.db, comm.ID)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(reasons)
+}
+
+// @Summary		Add a removal reason.
+// @Description	Add a removal reason to a community. The reason can optionally be tied to a rule of the community.
+// @Router			/api/communities/{communityID}/removal_reasons [POST]
+// @Success		200	{object}	core.RemovalReason
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			body			body	object{title=string,message=string,ruleId=int}	true	"Body"
+func (s *Server) addRemovalReason(w *responseWriter, r *request, comm *core.Community) error {
+	req := core.RemovalReason{}
+	if err := r.unmarshalJSONBody(&req); err != nil {
+		return err
+	}
+
+	reason, err := comm.AddRemovalReason(r.ctx, *r.viewer, req.Title, req.Message, req.RuleID)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(reason)
+}
+
+// getRemovalReason returns the removal reason, of comm, of the reasonID route
+// variable.
+func (s *Server) getRemovalReason(r *request, comm *core.Community) (*core.RemovalReason, error) {
+	errNotFound := httperr.NewNotFound("removal-reason-not-found", "Removal reason not found.")
+	reasonID, err := strconv.ParseUint(r.muxVar("reasonID"), 10, 32)
+	if err != nil {
+		return nil, errNotFound
+	}
+	reason, err := core.GetRemovalReason(r.ctx, s.db, uint(reasonID))
+	if err != nil {
+		return nil, err
+	}
+	if reason.CommunityID != comm.ID {
+		return nil, errNotFound
+	}
+	return reason, nil
+}
+
+// @Summary		Update a removal reason.
+// @Description	Update the title, message, rule, and zIndex of a removal reason.
+// @Router			/api/communities/{communityID}/removal_reasons/{reasonID} [PUT]
+// @Success		200	{object}	core.RemovalReason
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			reasonID		path	int		true	"Removal reason ID"
+// @Param			body			body	object{title=string,message=string,ruleId=int,zIndex=int}	true	"Body"
+func (s *Server) updateRemovalReason(w *responseWriter, r *request, comm *core.Community) error {
+	reason, err := s.getRemovalReason(r, comm)
+	if err != nil {
+		return err
+	}
+
+	req := core.RemovalReason{}
+	if err := r.unmarshalJSONBody(&req); err != nil {
+		return err
+	}
+	reason.Title = req.Title
+	reason.Message = req.Message
+	reason.RuleID = req.RuleID
+	reason.ZIndex = req.ZIndex
+
+	if err = reason.Update(r.ctx, *r.viewer); err != nil {
+		return err
+	}
+	return w.writeJSON(reason)
+}
+
+// @Summary		Delete a removal reason.
+// @Description	Delete a removal reason.
+// @Router			/api/communities/{communityID}/removal_reasons/{reasonID} [DELETE]
+// @Success		200	{object}	core.RemovalReason
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			reasonID		path	int		true	"Removal reason ID"
+func (s *Server) deleteRemovalReason(w *responseWriter, r *request, comm *core.Community) error {
+	reason, err := s.getRemovalReason(r, comm)
+	if err != nil {
+		return err
+	}
+	if err = reason.Delete(r.ctx, *r.viewer); err != nil {
+		return err
+	}
+	return w.writeJSON(reason)
+}
+
+// removalFromQuery returns the removal reason, given in the reasonId, reason,
+// and reasonComment URL query parameters, of a post or a comment of community
+// that's being deleted as g. It returns nil if no reason is given.
+func (s *Server) removalFromQuery(r *request, community uid.ID, g core.UserGroup) (*core.Removal, error) {
+	if g == core.UserGroupNormal {
+		return nil, nil
+	}
+	query := r.urlQueryParams()
+
+	var reasonID uint64
+	if v := query.Get("reasonId"); v != "" {
+		var err error
+		if reasonID, err = strconv.ParseUint(v, 10, 32); err != nil {
+			return nil, httperr.NewBadRequest("invalid_reason_id", "Invalid removal reason id.")
+		}
+	}
+	comment := false
+	if rc := strings.ToLower(query.Get("reasonComment")); rc != "" {
+		if rc == "true" {
+			comment = true
+		} else if rc != "false" {
+			return nil, httperr.NewBadRequest("", "reasonComment must be a bool.")
+		}
+	}
+	return core.NewRemoval(r.ctx, s.db, community, uint(reasonID), query.Get("reason"), comment)
+}
@@ -1,10 +1,13 @@
 package server
 
 import (
+	"fmt"
 	"slices"
 
+	"github.com/discuitnet/discuit/core"
 	"github.com/discuitnet/discuit/internal/httperr"
 	"github.com/discuitnet/discuit/internal/meilisearch"
+	"github.com/discuitnet/discuit/internal/uid"
 )
 
 //	@Summary		Search
@@ -46,5 +49,53 @@ func (s *Server) search(w *responseWriter, r *request) error {
 		return httperr.NewBadRequest("bad_request", err.Error())
 	}
 
+	if index == "posts" {
+		// Remove the posts of the private communities that the viewer is not a
+		// member of.
+		var communities []uid.ID
+		for _, hit := range results.Hits {
+			if m, ok := hit.(map[string]any); ok {
+				if id, err := uid.FromString(fmt.Sprint(m["community_id"])); err == nil {
+					communities = append(communities, id)
+				}
+			}
+		}
+		hidden, err := core.HiddenCommunities(r.ctx, s.db, r.viewer, communities)
+		if err != nil {
+			return err
+		}
+		hits := results.Hits[:0]
+		for _, hit := range results.Hits {
+			if m, ok := hit.(map[string]any); ok {
+				if id, err := uid.FromString(fmt.Sprint(m["community_id"])); err != nil || hidden[id] {
+					continue
+				}
+			}
+			hits = append(hits, hit)
+		}
+		results.Hits = hits
+	}
+
+	if index == "posts" && r.loggedIn {
+		// Remove the posts hidden by the viewer's content filters.
+		hidden, err := core.GetSearchFilter(r.ctx, s.db, *r.viewer)
+		if err != nil {
+			return err
+		}
+		hits := results.Hits[:0]
+		for _, hit := range results.Hits {
+			if m, ok := hit.(map[string]any); ok {
+				postType, _ := m["type"].(string)
+				title, _ := m["title"].(string)
+				body, _ := m["body"].(string)
+				if user, _ := m["user_id"].(string); user != r.viewer.String() && hidden(postType, title, body) {
+					continue
+				}
+			}
+			hits = append(hits, hit)
+		}
+		results.Hits = hits
+	}
+
 	return w.writeJSON(results)
 }
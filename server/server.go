@@ -43,6 +43,8 @@ var (
 	}
 
 	errNotAdminNorMod = httperr.NewForbidden("not_admin_nor_mod", "User neither an admin nor a mod.")
+
+	errPrivateCommunity = httperr.NewForbidden("private-community", "Only members can see the content of this community.")
 )
 
 type Server struct {
@@ -69,6 +71,9 @@ type Server struct {
 	http500LoggerFile *os.File
 
 	webPushVAPIDKeys core.VAPIDKeys
+
+	// For real-time events.
+	events *eventsHub
 }
 
 func New(db *sql.DB, conf *config.Config) (*Server, error) {
@@ -101,6 +106,9 @@ func New(db *sql.DB, conf *config.Config) (*Server, error) {
 		core.EnablePushNotifications(keys, "discuit@previnder.com")
 	}
 
+	s.events = newEventsHub(s.redisPool)
+	core.EnableEvents(&redisEventPublisher{pool: s.redisPool})
+
 	s.openLoggers()
 
 	// OpenAPI
@@ -111,17 +119,29 @@ func New(db *sql.DB, conf *config.Config) (*Server, error) {
 	r.Handle("/api/_login", s.withHandler(s.login)).Methods("POST")
 	r.Handle("/api/_signup", s.withHandler(s.signup)).Methods("POST")
 	r.Handle("/api/_user", s.withHandler(s.getLoggedInUser)).Methods("GET")
+	r.Handle("/api/_events", s.withHandler(s.streamEvents)).Methods("GET")
 
 	r.Handle("/api/search", s.withHandler(s.search)).Methods("GET")
 
 	r.Handle("/api/users/{username}", s.withHandler(s.getUser)).Methods("GET")
 	r.Handle("/api/users/{username}", s.withHandler(s.deleteUser)).Methods("DELETE")
 	r.Handle("/api/users/{username}/feed", s.withHandler(s.getUsersFeed)).Methods("GET")
+	r.Handle("/api/users/{username}/follow", s.withHandler(s.followUser)).Methods("POST", "DELETE")
+	r.Handle("/api/_following", s.withHandler(s.getFollowedUsers)).Methods("GET")
 	r.Handle("/api/users/{username}/pro_pic", s.withHandler(s.UploadUserProPic)).Methods("POST")
 	r.Handle("/api/users/{username}/pro_pic", s.withHandler(s.deleteUserProPic)).Methods("DELETE")
 	r.Handle("/api/users/{username}/badges", s.withHandler(s.addBadge)).Methods("POST")
 	r.Handle("/api/users/{username}/badges/{badgeId}", s.withHandler(s.deleteBadge)).Methods("DELETE")
 
+	r.Handle("/api/users/{username}/feeds", s.withHandler(s.getUsersCustomFeeds)).Methods("GET")
+	r.Handle("/api/users/{username}/feeds", s.withHandler(s.createCustomFeed)).Methods("POST")
+	r.Handle("/api/users/{username}/feeds/{feedname}", s.withHandler(s.withCustomFeed(s.getCustomFeed))).Methods("GET")
+	r.Handle("/api/users/{username}/feeds/{feedname}", s.withHandler(s.withCustomFeed(s.updateCustomFeed))).Methods("PUT")
+	r.Handle("/api/users/{username}/feeds/{feedname}", s.withHandler(s.withCustomFeed(s.deleteCustomFeed))).Methods("DELETE")
+	r.Handle("/api/users/{username}/feeds/{feedname}/posts", s.withHandler(s.withCustomFeed(s.getCustomFeedPosts))).Methods("GET")
+	r.Handle("/api/users/{username}/feeds/{feedname}/follow", s.withHandler(s.withCustomFeed(s.followCustomFeed))).Methods("POST", "DELETE")
+	r.Handle("/api/_followed_feeds", s.withHandler(s.getFollowedCustomFeeds)).Methods("GET")
+
 	r.Handle("/api/users/{username}/lists", s.withHandler(s.getUsersLists)).Methods("GET")
 	r.Handle("/api/users/{username}/lists", s.withHandler(s.createList)).Methods("POST")
 	r.Handle("/api/lists/_saved_to", s.withHandler(s.getSaveToLists)).Methods("GET")
@@ -147,11 +167,29 @@ func New(db *sql.DB, conf *config.Config) (*Server, error) {
 	r.Handle("/api/mutes/communities/{mutedCommunityID}", s.withHandler(s.deleteCommunityMute)).Methods("DELETE")
 	r.Handle("/api/mutes/{muteID}", s.withHandler(s.deleteMute)).Methods("DELETE")
 
+	r.Handle("/api/conversations", s.withHandler(s.getConversations)).Methods("GET")
+	r.Handle("/api/conversations", s.withHandler(s.createConversation)).Methods("POST")
+	r.Handle("/api/conversations/{conversationID}", s.withHandler(s.withConversation(s.getConversation))).Methods("GET")
+	r.Handle("/api/conversations/{conversationID}", s.withHandler(s.withConversation(s.updateConversation))).Methods("PUT")
+	r.Handle("/api/conversations/{conversationID}/messages", s.withHandler(s.withConversation(s.getConversationMessages))).Methods("GET")
+	r.Handle("/api/conversations/{conversationID}/messages", s.withHandler(s.withConversation(s.sendConversationMessage))).Methods("POST")
+	r.Handle("/api/conversations/{conversationID}/messages/{messageID}", s.withHandler(s.withConversation(s.deleteConversationMessage))).Methods("DELETE")
+
+	r.Handle("/api/modmail", s.withHandler(s.getUserModmail)).Methods("GET")
+	r.Handle("/api/modmail/{threadID}", s.withHandler(s.withModmailThread(s.getModmailThread))).Methods("GET")
+	r.Handle("/api/modmail/{threadID}", s.withHandler(s.withModmailThread(s.updateModmailThread))).Methods("PUT")
+	r.Handle("/api/modmail/{threadID}/messages", s.withHandler(s.withModmailThread(s.replyToModmailThread))).Methods("POST")
+	r.Handle("/api/communities/{communityID}/modmail", s.withHandler(s.createModmailThread)).Methods("POST")
+	r.Handle("/api/communities/{communityID}/modmail", s.withHandler(s.withCommunityMod(s.getCommunityModmail))).Methods("GET")
+
 	r.Handle("/api/posts", s.withHandler(s.feed)).Methods("GET")
 	r.Handle("/api/posts", s.withHandler(s.addPost)).Methods("POST")
 	r.Handle("/api/posts/{postID}", s.withHandler(s.getPost)).Methods("GET")
 	r.Handle("/api/posts/{postID}", s.withHandler(s.updatePost)).Methods("PUT")
 	r.Handle("/api/posts/{postID}", s.withHandler(s.deletePost)).Methods("DELETE")
+	r.Handle("/api/posts/{postID}/follow", s.withHandler(s.followThread)).Methods("POST", "DELETE")
+	r.Handle("/api/followed_threads", s.withHandler(s.getFollowedThreads)).Methods("GET")
+	r.Handle("/api/_read", s.withHandler(s.markPostsRead)).Methods("POST")
 	r.Handle("/api/_postVote", s.withHandler(s.postVote)).Methods("POST")
 	r.Handle("/api/_uploads", s.withHandler(s.imageUpload)).Methods("POST")
 
@@ -176,15 +214,43 @@ func New(db *sql.DB, conf *config.Config) (*Server, error) {
 
 	r.Handle("/api/communities/{communityID}/mods", s.withHandler(s.getCommunityMods)).Methods("GET")
 	r.Handle("/api/communities/{communityID}/mods", s.withHandler(s.addCommunityMod)).Methods("POST")
+	r.Handle("/api/communities/{communityID}/mods", s.withHandler(s.withCommunityMod(s.reorderCommunityMods))).Methods("PUT")
 	r.Handle("/api/communities/{communityID}/mods/{mod}", s.withHandler(s.removeCommunityMod)).Methods("DELETE")
+	r.Handle("/api/communities/{communityID}/mod_transfer", s.withHandler(s.withCommunityMod(s.getModTransfer))).Methods("GET")
+	r.Handle("/api/communities/{communityID}/mod_transfer", s.withHandler(s.withCommunityMod(s.offerModTransfer))).Methods("POST")
+	r.Handle("/api/communities/{communityID}/mod_transfer", s.withHandler(s.withCommunityMod(s.acceptModTransfer))).Methods("PUT")
+	r.Handle("/api/communities/{communityID}/mod_transfer", s.withHandler(s.withCommunityMod(s.deleteModTransfer))).Methods("DELETE")
+	r.Handle("/api/communities/{communityID}/takeover_requests", s.withHandler(s.addTakeoverRequest)).Methods("POST")
+	r.Handle("/api/communities/{communityID}/rename_requests", s.withHandler(s.withCommunityMod(s.getCommunityRenameRequests))).Methods("GET")
+	r.Handle("/api/communities/{communityID}/rename_requests", s.withHandler(s.withCommunityMod(s.addRenameRequest))).Methods("POST")
 
 	r.Handle("/api/communities/{communityID}/reports", s.withHandler(s.getCommunityReports)).Methods("GET")
 	r.Handle("/api/communities/{communityID}/reports/{reportID}", s.withHandler(s.deleteReport)).Methods("DELETE")
+	r.Handle("/api/communities/{communityID}/reports/{reportID}/escalate", s.withHandler(s.withCommunityMod(s.escalateReport))).Methods("POST")
+	r.Handle("/api/communities/{communityID}/ban_appeals", s.withHandler(s.withCommunityMod(s.getCommunityBanAppeals))).Methods("GET")
 
 	r.Handle("/api/communities/{communityID}/banned", s.withHandler(s.CommunityGetBannedUsers)).Methods("GET")
 	r.Handle("/api/communities/{communityID}/banned", s.withHandler(s.CommunityBanUser)).Methods("POST")
 	r.Handle("/api/communities/{communityID}/banned", s.withHandler(s.CommunityUnbanUser)).Methods("DELETE")
 
+	r.Handle("/api/communities/{communityID}/member_requests", s.withHandler(s.withCommunityMod(s.getCommunityMemberRequests))).Methods("GET")
+	r.Handle("/api/communities/{communityID}/member_requests", s.withHandler(s.withCommunityMod(s.inviteCommunityMember))).Methods("POST")
+	r.Handle("/api/communities/{communityID}/member_requests/{username}", s.withHandler(s.withCommunityMod(s.handleCommunityMemberRequest))).Methods("PUT", "DELETE")
+	r.Handle("/api/communities/{communityID}/automod", s.withHandler(s.withCommunityMod(s.getAutomodConfig))).Methods("GET")
+	r.Handle("/api/communities/{communityID}/automod", s.withHandler(s.withCommunityMod(s.updateAutomodConfig))).Methods("PUT")
+	r.Handle("/api/communities/{communityID}/automod/hits", s.withHandler(s.withCommunityMod(s.getAutomodHits))).Methods("GET")
+	r.Handle("/api/communities/{communityID}/modlog", s.withHandler(s.getCommunityModLog)).Methods("GET")
+	r.Handle("/api/communities/{communityID}/modqueue", s.withHandler(s.withCommunityMod(s.getCommunityModQueue))).Methods("GET")
+	r.Handle("/api/modqueue", s.withHandler(s.getModQueue)).Methods("GET")
+	r.Handle("/api/modqueue", s.withHandler(s.modQueueAction)).Methods("POST")
+	r.Handle("/api/communities/{communityID}/removal_reasons", s.withHandler(s.withCommunityMod(s.getRemovalReasons))).Methods("GET")
+	r.Handle("/api/communities/{communityID}/removal_reasons", s.withHandler(s.withCommunityMod(s.addRemovalReason))).Methods("POST")
+	r.Handle("/api/communities/{communityID}/removal_reasons/{reasonID}", s.withHandler(s.withCommunityMod(s.updateRemovalReason))).Methods("PUT")
+	r.Handle("/api/communities/{communityID}/removal_reasons/{reasonID}", s.withHandler(s.withCommunityMod(s.deleteRemovalReason))).Methods("DELETE")
+	r.Handle("/api/communities/{communityID}/users/{username}/modinfo", s.withHandler(s.withCommunityMod(s.getUserModInfo))).Methods("GET")
+	r.Handle("/api/communities/{communityID}/users/{username}/notes", s.withHandler(s.withCommunityMod(s.addModNote))).Methods("POST")
+	r.Handle("/api/communities/{communityID}/users/{username}/notes/{noteID}", s.withHandler(s.withCommunityMod(s.deleteModNote))).Methods("DELETE")
+
 	r.Handle("/api/communities/{communityID}/pro_pic", s.withHandler(s.CommunityUploadProPic)).Methods("POST")
 	r.Handle("/api/communities/{communityID}/pro_pic", s.withHandler(s.CommunityDeleteProPic)).Methods("DELETE")
 	r.Handle("/api/communities/{communityID}/banner_image", s.withHandler(s.CommunityUploadBannerImage)).Methods("POST")
@@ -192,6 +258,9 @@ func New(db *sql.DB, conf *config.Config) (*Server, error) {
 
 	r.Handle("/api/notifications", s.withHandler(s.getNotifications)).Methods("GET")
 	r.Handle("/api/notifications", s.withHandler(s.updateNotifications)).Methods("POST")
+	r.Handle("/api/notifications/preferences", s.withHandler(s.getNotificationPreferences)).Methods("GET")
+	r.Handle("/api/notifications/preferences", s.withHandler(s.updateNotificationPreferences)).Methods("PUT")
+	r.Handle("/api/notifications/mutes", s.withHandler(s.muteNotifications)).Methods("POST", "DELETE")
 	r.Handle("/api/notifications/{notificationID}", s.withHandler(s.getNotification)).Methods("GET")
 	r.Handle("/api/notifications/{notificationID}", s.withHandler(s.markAllNotificationAsSeen)).Methods("PUT")
 	r.Handle("/api/notifications/{notificationID}", s.withHandler(s.deleteNotification)).Methods("DELETE")
@@ -203,10 +272,22 @@ func New(db *sql.DB, conf *config.Config) (*Server, error) {
 	r.Handle("/api/community_requests/{requestID}", s.withHandler(s.deleteCommunityRequest)).Methods("DELTE")
 
 	r.Handle("/api/_report", s.withHandler(s.report)).Methods("POST")
+	r.Handle("/api/ban_appeals", s.withHandler(s.addBanAppeal)).Methods("POST")
+	r.Handle("/api/ban_appeals", s.withHandler(s.getUserBanAppeals)).Methods("GET")
+	r.Handle("/api/ban_appeals/{appealID}", s.withHandler(s.reviewBanAppeal)).Methods("POST")
+	r.Handle("/api/takeover_requests", s.withHandler(s.getUserTakeoverRequests)).Methods("GET")
+	r.Handle("/api/takeover_requests/{requestID}", s.withHandler(s.reviewTakeoverRequest)).Methods("POST")
+	r.Handle("/api/rename_requests/{requestID}", s.withHandler(s.reviewRenameRequest)).Methods("POST")
 
 	r.Handle("/api/_settings", s.withHandler(s.updateUserSettings)).Methods("POST")
+	r.HandleFunc("/api/_unsubscribe", s.unsubscribeFromEmailDigests).Methods("GET", "POST")
 
 	r.Handle("/api/_admin", s.withHandler(s.adminActions)).Methods("POST")
+	r.Handle("/api/_admin/modlog", s.withHandler(s.getAdminModLog)).Methods("GET")
+	r.Handle("/api/_admin/reports", s.withHandler(s.getAdminReports)).Methods("GET")
+	r.Handle("/api/_admin/ban_appeals", s.withHandler(s.getSiteBanAppeals)).Methods("GET")
+	r.Handle("/api/_admin/takeover_requests", s.withHandler(s.getTakeoverRequests)).Methods("GET")
+	r.Handle("/api/_admin/rename_requests", s.withHandler(s.getRenameRequests)).Methods("GET")
 
 	r.Handle("/api/_link_info", s.withHandler(s.getLinkInfo)).Methods("GET")
 
@@ -214,6 +295,7 @@ func New(db *sql.DB, conf *config.Config) (*Server, error) {
 
 	// r.Handle("/api/feed/u/{username}", s.withHandler(s.getUserFeed)).Methods("GET")
 	r.Handle("/api/feed/c/{communityName}", s.withHandler(s.getCommunityFeed)).Methods("GET")
+	r.Handle("/api/feed/f/{username}/{feedname}", s.withHandler(s.withCustomFeed(s.getCustomFeedSyndicationFeed))).Methods("GET")
 
 	r.NotFoundHandler = http.HandlerFunc(s.apiNotFoundHandler)
 	r.MethodNotAllowedHandler = http.HandlerFunc(s.apiMethodNotAllowedHandler)
@@ -791,7 +873,12 @@ func (s *Server) insertMetaTags(doc *html.Node, r *http.Request) {
 	} else if len(list) == 3 && list[1] == "post" {
 		// post page
 		post, err := core.GetPost(ctx, s.db, nil, list[2], nil, true)
-		if err == nil {
+		if err == nil && post.CheckViewable(nil) == nil {
+			// Meta tags are for crawlers, which cannot see the posts of
+			// private communities.
+			if ok, err := core.CommunityViewableBy(ctx, s.db, post.CommunityID, nil); err != nil || !ok {
+				return
+			}
 			appendTitle(post.Title, "")
 			sep := " • "
 			upVotes := strconv.Itoa(post.Upvotes) + " upvote"
@@ -825,6 +912,29 @@ func (s *Server) insertMetaTags(doc *html.Node, r *http.Request) {
 	}
 }
 
+// renamedCommunityURL returns the URL that r should be redirected to if the
+// path of r is that of a community page under a former name of the community.
+// Otherwise it returns an empty string.
+func (s *Server) renamedCommunityURL(r *http.Request) string {
+	list := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	first := list[0]
+	if first == "" || strings.HasPrefix(first, "@") || !strings.HasPrefix(first, s.config.CommunityPrefix) {
+		return ""
+	}
+	name, err := core.RenamedCommunityName(r.Context(), s.db, strings.TrimPrefix(first, s.config.CommunityPrefix))
+	if err != nil {
+		log.Printf("Error getting renamed community name (path: %s): %v\n", r.URL.Path, err)
+		return ""
+	}
+	if name == "" {
+		return ""
+	}
+	list[0] = s.config.CommunityPrefix + name
+	u := *r.URL
+	u.Path, u.RawPath = "/"+strings.Join(list, "/"), ""
+	return u.String()
+}
+
 // Serves React static files and serves index.html for all routes that doesn't
 // match a file.
 func (s *Server) serveSPA(w http.ResponseWriter, r *http.Request) {
@@ -881,6 +991,12 @@ func (s *Server) serveSPA(w http.ResponseWriter, r *http.Request) {
 	fpath := filepath.Join(s.reactPath, path)
 	_, err = os.Stat(fpath)
 	if os.IsNotExist(err) {
+		if to := s.renamedCommunityURL(r); to != "" {
+			// Not a permanent redirect, for a community can be renamed back
+			// to a former name.
+			http.Redirect(w, r, to, http.StatusFound)
+			return
+		}
 		serveIndexFile()
 		return
 	} else if err != nil {
@@ -921,7 +1037,14 @@ func userSessionsSetRedisKey(username string) string {
 // loginUser persists the authenticated user onto the session.
 func (s *Server) loginUser(u *core.User, ses *sessions.Session, w http.ResponseWriter, r *http.Request) error {
 	if u.Banned {
-		return httperr.NewForbidden("account_suspended", "User account suspended.")
+		message := "User account suspended"
+		if u.BanExpires.Valid {
+			message += " until " + u.BanExpires.Time.UTC().Format(time.RFC1123)
+		}
+		if u.BanReason.Valid {
+			message += " (reason: " + u.BanReason.String + ")"
+		}
+		return httperr.NewForbidden("account_suspended", message+".")
 	}
 
 	conn := s.redisPool.Get()
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/httperr"
+)
+
+// @Summary		Request to take over a community.
+// @Description	Request to become the top mod of an abandoned community, that is, a community none of whose mods have been active in the last 90 days. The request is reviewed by the admins. A user can only have one open request per community.
+// @Router			/api/communities/{communityID}/takeover_requests [POST]
+// @Success		200	{object}	core.TakeoverRequest
+// @Tags			Community
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			communityID		path	string	true	"Community ID"
+// @Param			body			body	object{body=string}	true	"Body"
+func (s *Server) addTakeoverRequest(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	cid, err := strToID(r.muxVar("communityID"))
+	if err != nil {
+		return err
+	}
+	comm, err := core.GetCommunityByID(r.ctx, s.db, cid, r.viewer)
+	if err != nil {
+		return err
+	}
+
+	values, err := r.unmarshalJSONBodyToStringsMap(true)
+	if err != nil {
+		return err
+	}
+
+	if err := s.rateLimit(r, "takeover_request_"+r.viewer.String(), time.Hour, 5); err != nil {
+		return err
+	}
+
+	request, err := comm.NewTakeoverRequest(r.ctx, *r.viewer, values["body"])
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(request)
+}
+
+// @Summary		Get the user's takeover requests.
+// @Description	Get all the community takeover requests made by the logged in user, the latest ones first.
+// @Router			/api/takeover_requests [GET]
+// @Success		200	{array}	core.TakeoverRequest
+// @Tags			User
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func (s *Server) getUserTakeoverRequests(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+	requests, err := core.GetUserTakeoverRequests(r.ctx, s.db, *r.viewer)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(requests)
+}
+
+// @Summary		Get community takeover requests.
+// @Description	Get the community takeover requests of all communities, the oldest ones first. Only admins can view them.
+// @Router			/api/_admin/takeover_requests [GET]
+// @Success		200	{array}	core.TakeoverRequest
+// @Tags			Admin
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			status			query	string	false	"Only requests of this status"	Enums(open, approved, rejected)
+// @Param			limit			query	int		false	"Number of requests per page"
+// @Param			page			query	int		false	"Page number"
+func (s *Server) getTakeoverRequests(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+	if is, err := core.IsAdmin(s.db, r.viewer); err != nil {
+		return err
+	} else if !is {
+		return httperr.NewForbidden("not_admin", "You are not an admin.")
+	}
+
+	query := r.urlQueryParams()
+	limit, err := getFeedLimit(query, s.config.PaginationLimit, s.config.PaginationLimitMax)
+	if err != nil {
+		return err
+	}
+	page := 1
+	if spage := query.Get("page"); spage != "" {
+		if page, err = strconv.Atoi(spage); err != nil || page < 1 {
+			return httperr.NewBadRequest("invalid_page", "Invalid page.")
+		}
+	}
+
+	status := core.TakeoverRequestStatus(query.Get("status"))
+	requests, err := core.GetTakeoverRequests(r.ctx, s.db, status, limit, page)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(requests)
+}
+
+// @Summary		Review a takeover request.
+// @Description	Approve a community takeover request (which makes the user the top mod of the community) or reject it. Only admins can review takeover requests. The user is notified of the outcome, along with the optional message.
+// @Router			/api/takeover_requests/{requestID} [POST]
+// @Success		200	{object}	core.TakeoverRequest
+// @Tags			Admin
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			requestID		path	string	true	"Takeover request ID"
+// @Param			body			body	object{action=string,message=string}	true	"Body (action is approve or reject)"
+func (s *Server) reviewTakeoverRequest(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	id, err := strconv.ParseUint(r.muxVar("requestID"), 10, 32)
+	if err != nil {
+		return httperr.NewBadRequest("invalid_request_id", "Invalid takeover request ID.")
+	}
+	request, err := core.GetTakeoverRequest(r.ctx, s.db, uint(id))
+	if err != nil {
+		return err
+	}
+
+	values, err := r.unmarshalJSONBodyToStringsMap(true)
+	if err != nil {
+		return err
+	}
+	message := values["message"]
+	switch values["action"] {
+	case "approve":
+		err = request.Approve(r.ctx, *r.viewer, message)
+	case "reject":
+		err = request.Reject(r.ctx, *r.viewer, message)
+	default:
+		return httperr.NewBadRequest("invalid_action", "Unsupported action.")
+	}
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(request)
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"github.com/discuitnet/discuit/core"
+	"github.com/discuitnet/discuit/internal/uid"
+)
+
+// @Summary		Follow or unfollow a thread.
+// @Description	Follow (POST) or unfollow (DELETE) a post, to get notified of its new comments. If commentId is set, only the replies of that comment are followed.
+// @Router			/api/posts/{postID}/follow [POST]
+// @Router			/api/posts/{postID}/follow [DELETE]
+// @Success		200
+// @Tags			Posts
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			postID			path	string	true	"The public ID of the post"
+// @Param			commentId		query	string	false	"Comment ID"
+func (s *Server) followThread(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	post, err := core.GetPost(r.ctx, s.db, nil, r.muxVar("postID"), r.viewer, false)
+	if err != nil {
+		return err
+	}
+	var comment *uid.ID
+	if commentID := r.urlQueryParamsValue("commentId"); commentID != "" {
+		id, err := strToID(commentID)
+		if err != nil {
+			return err
+		}
+		comment = &id
+	}
+
+	if r.req.Method == "POST" {
+		err = core.FollowThread(r.ctx, s.db, *r.viewer, post.ID, comment)
+	} else {
+		err = core.UnfollowThread(r.ctx, s.db, *r.viewer, post.ID, comment)
+	}
+	if err != nil {
+		return err
+	}
+	return w.writeString(`{"success":true}`)
+}
+
+// @Summary		Get followed threads.
+// @Description	Get the posts and the comments that the logged in user follows, the most recently followed first.
+// @Router			/api/followed_threads [GET]
+// @Success		200	{object}	core.ThreadFollowsResultSet
+// @Tags			Posts
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			limit			query	int		false	"Limit"
+// @Param			next			query	string	false	"Next"
+func (s *Server) getFollowedThreads(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	limit, err := getFeedLimit(r.urlQueryParams(), s.config.PaginationLimit, s.config.PaginationLimitMax)
+	if err != nil {
+		return err
+	}
+	var next *string
+	if nextString := r.urlQueryParamsValue("next"); nextString != "" {
+		next = &nextString
+	}
+
+	set, err := core.GetFollowedThreads(r.ctx, s.db, *r.viewer, limit, next)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(set)
+}
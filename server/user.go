@@ -2,6 +2,7 @@ package server
 
 import (
 	"database/sql"
+	"html"
 	"io"
 	"net/http"
 	"strconv"
@@ -140,6 +141,7 @@ func (s *Server) initial(w *responseWriter, r *request) error {
 		Mutes          struct {
 			CommunityMutes []*core.Mute `json:"communityMutes"`
 			UserMutes      []*core.Mute `json:"userMutes"`
+			FilterMutes    []*core.Mute `json:"filterMutes"`
 		} `json:"mutes"`
 	}{
 		Lists:          []*core.List{},
@@ -148,6 +150,7 @@ func (s *Server) initial(w *responseWriter, r *request) error {
 
 	response.Mutes.CommunityMutes = []*core.Mute{}
 	response.Mutes.UserMutes = []*core.Mute{}
+	response.Mutes.FilterMutes = []*core.Mute{}
 
 	if r.loggedIn {
 		if response.User, err = core.GetUser(r.ctx, s.db, *r.viewer, r.viewer); err != nil {
@@ -172,6 +175,11 @@ func (s *Server) initial(w *responseWriter, r *request) error {
 		} else if userMutes != nil {
 			response.Mutes.UserMutes = userMutes
 		}
+		if filterMutes, err := core.GetMutedFilters(r.ctx, s.db, *r.viewer); err != nil {
+			return err
+		} else if filterMutes != nil {
+			response.Mutes.FilterMutes = filterMutes
+		}
 		if lists, err := core.GetUsersLists(r.ctx, s.db, *r.viewer, "", ""); err != nil {
 			return err
 		} else if lists != nil {
@@ -326,6 +334,9 @@ func (s *Server) getLoggedInUser(w *responseWriter, r *request) error {
 	if err := user.LoadModdingList(r.ctx); err != nil {
 		return err
 	}
+	if err := user.LoadModQueueCounts(r.ctx); err != nil {
+		return err
+	}
 
 	return w.writeJSON(user)
 }
@@ -751,3 +762,40 @@ func (s *Server) addBadge(w *responseWriter, r *request) error {
 
 	return w.writeJSON(user.Badges)
 }
+
+// @Summary		Unsubscribe from email digests.
+// @Description	The unsubscribe link included in email digests. It needs no session (and no CSRF token), as the link is signed. A GET request only renders a confirmation page (so that link prefetchers don't unsubscribe users); the unsubscribe happens on POST, either from that page or from an email client (RFC 8058 one-click unsubscribe).
+// @Router			/api/_unsubscribe [GET]
+// @Router			/api/_unsubscribe [POST]
+// @Success		200
+// @Tags			Users
+// @Param			user	query	string	true	"User ID"
+// @Param			token	query	string	true	"Unsubscribe token"
+func (s *Server) unsubscribeFromEmailDigests(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<!DOCTYPE html><html><head><meta charset="utf-8"><title>Unsubscribe</title></head>` +
+			`<body><form method="POST" action="` + html.EscapeString(r.URL.RequestURI()) + `">` +
+			`<p>Unsubscribe from email digests?</p><button type="submit">Unsubscribe</button></form></body></html>`))
+		return
+	}
+
+	query := r.URL.Query()
+	user, err := strToID(query.Get("user"))
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	if err := core.UnsubscribeFromEmailDigests(r.Context(), s.db, user, query.Get("token"), s.config.HMACSecret); err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	if r.PostFormValue("List-Unsubscribe") == "One-Click" {
+		// RFC 8058 one-click unsubscribe from an email client.
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(`<!DOCTYPE html><html><head><meta charset="utf-8"><title>Unsubscribed</title></head>` +
+		`<body><p>You have been unsubscribed from email digests. You can turn them back on from your settings.</p></body></html>`))
+}
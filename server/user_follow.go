@@ -0,0 +1,57 @@
+package server
+
+import (
+	"github.com/discuitnet/discuit/core"
+)
+
+// @Summary		Follow or unfollow a user.
+// @Description	Follow (POST) or unfollow (DELETE) a user. The posts of followed users appear in the following feed. If notify is true, the logged in user is notified of the user's new posts. Returns the followed user.
+// @Router			/api/users/{username}/follow [POST]
+// @Router			/api/users/{username}/follow [DELETE]
+// @Success		200	{object}	core.User
+// @Tags			Users
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+// @Param			username		path	string	true	"Username"
+// @Param			notify			query	bool	false	"Notify of new posts"
+func (s *Server) followUser(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	user, err := core.GetUserByUsername(r.ctx, s.db, r.muxVar("username"), r.viewer)
+	if err != nil {
+		return err
+	}
+
+	if r.req.Method == "POST" {
+		err = core.FollowUser(r.ctx, s.db, *r.viewer, user.ID, r.urlQueryParamsValue("notify") == "true")
+	} else {
+		err = core.UnfollowUser(r.ctx, s.db, *r.viewer, user.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if user, err = core.GetUser(r.ctx, s.db, user.ID, r.viewer); err != nil {
+		return err
+	}
+	return w.writeJSON(user)
+}
+
+// @Summary		Get followed users.
+// @Description	Get the users that the logged in user follows, the most recently followed first.
+// @Router			/api/_following [GET]
+// @Success		200	{array}	core.User
+// @Tags			Users
+// @Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func (s *Server) getFollowedUsers(w *responseWriter, r *request) error {
+	if !r.loggedIn {
+		return errNotLoggedIn
+	}
+
+	users, err := core.GetFollowedUsers(r.ctx, s.db, *r.viewer)
+	if err != nil {
+		return err
+	}
+	return w.writeJSON(users)
+}
@@ -199,6 +199,35 @@ function getNotificationInfo(notification, csrfToken) {
         setToUrl(to);
       }
       break;
+    case "thread_comment":
+      {
+        let to = `/${CONFIG.communityPrefix}${notif.post.communityName}/post/${notif.post.publicId}`;
+        if (notif.noComments === 1) {
+          ret.title = `@${notif.commentAuthor} commented in a thread you follow on '${notif.post.title}'`;
+          to += `/${notif.commentId}`;
+        } else {
+          ret.title = `${notif.noComments} new comments in a thread you follow on '${notif.post.title}'`;
+        }
+        setToUrl(to);
+      }
+      break;
+    case "new_post":
+      ret.title = `@${notif.author} submitted a new post '${notif.post.title}'`;
+      setToUrl(
+        `/${CONFIG.communityPrefix}${notif.post.communityName}/post/${notif.post.publicId}`,
+      );
+      break;
+    case "mention": {
+      let to = `/${CONFIG.communityPrefix}${notif.post.communityName}/post/${notif.post.publicId}`;
+      if (notif.targetType === "comment") {
+        ret.title = `@${notif.mentionedBy} mentioned you in a comment on '${notif.post.title}'`;
+        to += `/${notif.targetId}`;
+      } else {
+        ret.title = `@${notif.mentionedBy} mentioned you in the post '${notif.post.title}'`;
+      }
+      setToUrl(to);
+      break;
+    }
     case "new_votes":
       if (notif.targetType === "post") {
         ret.title = `${stringCount(notif.noVotes, false, "new upvote")} on your post '${
@@ -217,14 +246,31 @@ function getNotificationInfo(notification, csrfToken) {
       }
       break;
     case "deleted_post": {
+      const target = notif.post || notif.comment;
       const by =
         notif.deletedAs === "mods"
-          ? `moderators of ${notif.post.communityName}`
+          ? `moderators of ${target.communityName}`
           : "admins";
-      ret.title = `Your post '${notif.post.title}' has been removed by the ${by}`;
-      setToUrl(
-        `/${CONFIG.communityPrefix}${notif.post.communityName}/post/${notif.post.publicId}`,
-      );
+      const what = notif.post ? `post '${notif.post.title}'` : "comment";
+      ret.title = `Your ${what} has been removed by the ${by}`;
+      if (notif.reason) {
+        ret.title += `: ${notif.reason}`;
+      }
+      if (notif.deletedAs === "mods") {
+        const params = new URLSearchParams({
+          community: target.communityName,
+          subject: `Removal of my ${notif.post ? "post" : "comment"}`,
+        });
+        setToUrl(`/modmail/new?${params.toString()}`);
+      } else if (notif.post) {
+        setToUrl(
+          `/${CONFIG.communityPrefix}${notif.post.communityName}/post/${notif.post.publicId}`,
+        );
+      } else {
+        setToUrl(
+          `/${CONFIG.communityPrefix}${target.communityName}/post/${target.postPublicId}/${target.id}`,
+        );
+      }
       break;
     }
 
@@ -235,6 +281,111 @@ function getNotificationInfo(notification, csrfToken) {
       );
       break;
     }
+    case "community_invite": {
+      ret.title = `You are invited to join /${notif.communityName} by @${notif.invitedBy}`;
+      setToUrl(`/${CONFIG.communityPrefix}${notif.communityName}`);
+      break;
+    }
+    case "automod_message": {
+      ret.title = `Message from the moderators of /${notif.communityName}: ${notif.message}`;
+      if (notif.targetType === "post") {
+        setToUrl(
+          `/${CONFIG.communityPrefix}${notif.post.communityName}/post/${notif.post.publicId}`,
+        );
+      } else {
+        setToUrl(
+          `/${CONFIG.communityPrefix}${notif.comment.communityName}/post/${notif.comment.postPublicId}/${notif.comment.id}`,
+        );
+      }
+      break;
+    }
+    case "ban_appeal": {
+      const ban = notif.communityName
+        ? `ban from /${notif.communityName}`
+        : "suspension";
+      ret.title =
+        notif.status === "shortened"
+          ? `Your ${ban} is shortened`
+          : `Your appeal of your ${ban} is ${notif.status}`;
+      if (notif.message) {
+        ret.title += `: ${notif.message}`;
+      }
+      if (notif.communityName) {
+        setToUrl(`/${CONFIG.communityPrefix}${notif.communityName}`);
+      }
+      break;
+    }
+    case "ban_expired": {
+      ret.title = notif.communityName
+        ? `Your ban from /${notif.communityName} has expired`
+        : "Your suspension has expired";
+      if (notif.communityName) {
+        setToUrl(`/${CONFIG.communityPrefix}${notif.communityName}`);
+      }
+      break;
+    }
+    case "community_ban": {
+      ret.title = `You are banned from /${notif.communityName}`;
+      const params = new URLSearchParams({
+        community: notif.communityName,
+        subject: `Ban from ${notif.communityName}`,
+      });
+      setToUrl(`/modmail/new?${params.toString()}`);
+      break;
+    }
+    case "modmail": {
+      const what =
+        notif.noMessages > 1
+          ? `${notif.noMessages} new messages`
+          : "New message";
+      ret.title = notif.toMods
+        ? `${what} in the modmail of /${notif.communityName}: ${notif.subject}`
+        : `${what} from the moderators of /${notif.communityName}: ${notif.subject}`;
+      setToUrl(
+        notif.toMods
+          ? `/${CONFIG.communityPrefix}${notif.communityName}/modtools/modmail/${notif.threadId}`
+          : `/modmail/${notif.threadId}`,
+      );
+      break;
+    }
+    case "mod_transfer": {
+      ret.title = `@${notif.offeredBy} offered to make you the top moderator of /${notif.communityName}`;
+      setToUrl(
+        `/${CONFIG.communityPrefix}${notif.communityName}/modtools/mods`,
+      );
+      break;
+    }
+    case "community_takeover": {
+      ret.title = `Your request to take over /${notif.communityName} is ${notif.status}`;
+      if (notif.message) {
+        ret.title += `: ${notif.message}`;
+      }
+      setToUrl(`/${CONFIG.communityPrefix}${notif.communityName}`);
+      break;
+    }
+    case "community_rename": {
+      ret.title = `/${notif.oldName} is now called /${notif.communityName}`;
+      setToUrl(`/${CONFIG.communityPrefix}${notif.communityName}`);
+      break;
+    }
+    case "rename_rejected": {
+      ret.title = `Your request to rename /${notif.communityName} to /${notif.newName} is rejected`;
+      if (notif.message) {
+        ret.title += `: ${notif.message}`;
+      }
+      setToUrl(
+        `/${CONFIG.communityPrefix}${notif.communityName}/modtools/settings`,
+      );
+      break;
+    }
+    case "new_message": {
+      ret.title =
+        notif.noMessages === 1
+          ? `@${notif.sender} sent you a message`
+          : `${notif.noMessages} new messages in a conversation`;
+      setToUrl(`/messages/${notif.conversationId}`);
+      break;
+    }
     case "new_badge": {
       ret.title =
         "You are awarded the 'supporter' badge for your contribution to Discuit and for sheer awesomeness!";
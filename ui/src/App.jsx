@@ -27,17 +27,26 @@ import Sidebar from "./components/Sidebar";
 import Signup from "./components/Signup";
 import Snacks from "./components/Snacks";
 import { isDeviceStandalone, mfetchjson } from "./helper";
-import { useCanonicalTag, useLoading, useWindowWidth } from "./hooks";
+import {
+  useCanonicalTag,
+  useEvents,
+  useLoading,
+  useWindowWidth,
+} from "./hooks";
 import About from "./pages/About";
 import AllCommunities from "./pages/AllCommunities";
 import AppLoading from "./pages/AppLoading";
 import Community from "./pages/Community";
 import Guidelines from "./pages/Guidelines";
 import Home from "./pages/Home";
+import { CustomFeed, CustomFeeds } from "./pages/CustomFeeds";
 import { List, Lists } from "./pages/Lists";
 import Login from "./pages/Login";
 import MarkdownGuide from "./pages/MarkdownGuide";
 import Modtools from "./pages/Modtools";
+import Messages from "./pages/Messages";
+import Modmail from "./pages/Modmail";
+import ModQueue from "./pages/Modtools/ModQueue";
 import NewPost from "./pages/NewPost";
 import NotFound from "./pages/NotFound";
 import Offline from "./pages/Offline";
@@ -55,6 +64,7 @@ import {
   loginModalOpened,
   mutesAdded,
   noUsersUpdated,
+  notificationsNewCountUpdated,
   reportReasonsUpdated,
   sidebarCommunitiesUpdated,
   signupModalOpened,
@@ -163,11 +173,17 @@ const App = () => {
     f();
   }, [isOnline]);
 
-  // Check if there're new notifications every 5 secs.
+  // Receive the new notifications count as it changes, or, if the browser
+  // doesn't support server-sent events, check for it every 5 secs.
   const user = useSelector((state) => state.main.user);
   const loggedIn = user !== null;
+  useEvents(loggedIn, null, (event) => {
+    if (event.type === "notifications_count") {
+      dispatch(notificationsNewCountUpdated(event.data.count));
+    }
+  });
   useEffect(() => {
-    if (loggedIn) {
+    if (loggedIn && !window.EventSource) {
       const timerId = setInterval(async () => {
         try {
           const rUser = await mfetchjson("/api/_user");
@@ -320,6 +336,17 @@ const AppSwitch = () => {
             <NotificationsView />
           </div>
         </ProtectedRoute>
+        <ProtectedRoute path="/modqueue">
+          <div className="page-content wrap modtools page-modqueue">
+            <ModQueue />
+          </div>
+        </ProtectedRoute>
+        <ProtectedRoute path="/modmail">
+          <Modmail />
+        </ProtectedRoute>
+        <ProtectedRoute path="/messages">
+          <Messages />
+        </ProtectedRoute>
         <ProtectedRoute path="/new">
           <NewPost />
         </ProtectedRoute>
@@ -329,7 +356,7 @@ const AppSwitch = () => {
         <Route exact path="/login">
           <Login />
         </Route>
-        <Route exact path={["/", "/subscriptions", "/all"]}>
+        <Route exact path={["/", "/subscriptions", "/following", "/all"]}>
           <Home />
         </Route>
         <Route exact path="/communities">
@@ -359,6 +386,12 @@ const AppSwitch = () => {
         <Route exact path="/@:username/lists/:listName">
           <List />
         </Route>
+        <Route exact path="/@:username/feeds">
+          <CustomFeeds />
+        </Route>
+        <Route exact path="/@:username/feeds/:feedName">
+          <CustomFeed />
+        </Route>
         <Route exact path={`/${CONFIG.communityPrefix}:name`}>
           <Community />
         </Route>
@@ -0,0 +1,87 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useDispatch } from "react-redux";
+import { mfetchjson } from "../helper";
+import { snackAlert, snackAlertError } from "../slices/mainSlice";
+import { ButtonClose } from "./Button";
+import { InputWithCount, useInputMaxLength } from "./Input";
+import Modal from "./Modal";
+
+// BanAppealModal lets a banned user appeal their ban from community or, if
+// community is not set, their site ban. Since site banned users cannot log in,
+// site ban appeals are made with the username and password of credentials.
+const BanAppealModal = ({ open, onClose, community = null, credentials }) => {
+  const dispatch = useDispatch();
+
+  const maxLength = 5000;
+  const [body, setBody] = useInputMaxLength(maxLength);
+
+  const handleSubmit = async () => {
+    try {
+      await mfetchjson("/api/ban_appeals", {
+        method: "POST",
+        body: JSON.stringify({
+          ...credentials,
+          community: community ? community.id : "",
+          body,
+        }),
+      });
+      dispatch(snackAlert("Appeal submitted."));
+      setBody("");
+      onClose();
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const reviewers = community
+    ? `the moderators of ${community.name}`
+    : "the admins";
+
+  return (
+    <Modal open={open} onClose={onClose}>
+      <div className="modal-card">
+        <div className="modal-card-head">
+          <div className="modal-card-title">Appeal ban</div>
+          <ButtonClose onClick={onClose} />
+        </div>
+        <div className="modal-card-content">
+          <InputWithCount
+            textarea
+            rows="8"
+            label="Appeal"
+            description={`Your appeal is reviewed by ${reviewers}.`}
+            maxLength={maxLength}
+            value={body}
+            onChange={setBody}
+            style={{ resize: "vertical" }}
+            autoFocus
+          />
+        </div>
+        <div className="modal-card-actions">
+          <button
+            type="button"
+            className="button-main"
+            disabled={body.trim() === ""}
+            onClick={handleSubmit}
+          >
+            Submit
+          </button>
+          <button type="button" onClick={onClose}>
+            Cancel
+          </button>
+        </div>
+      </div>
+    </Modal>
+  );
+};
+
+BanAppealModal.propTypes = {
+  open: PropTypes.bool.isRequired,
+  onClose: PropTypes.func.isRequired,
+  community: PropTypes.object,
+  credentials: PropTypes.object,
+};
+
+export default BanAppealModal;
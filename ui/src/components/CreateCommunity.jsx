@@ -76,7 +76,7 @@ const CreateCommunity = ({ open, onClose }) => {
             value={name}
             onChange={handleNameChange}
             label="Community name"
-            description="Community name cannot be changed."
+            description="Community name can only be changed with the approval of the admins."
             maxLength={communityNameMaxLength}
             style={{ marginBottom: "0" }}
             autoFocus
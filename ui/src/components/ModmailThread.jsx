@@ -0,0 +1,208 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useEffect, useState } from "react";
+import { useDispatch } from "react-redux";
+import { mfetchjson } from "../helper";
+import { snackAlertError } from "../slices/mainSlice";
+import Link from "./Link";
+import MarkdownBody from "./MarkdownBody";
+import TimeAgo from "./TimeAgo";
+
+// ModmailThread shows a modmail thread, either to the user who opened it or,
+// if the viewer is a mod of the community, to the mod team (with the controls
+// for assigning and archiving the thread and for adding internal notes).
+const ModmailThread = ({ threadId }) => {
+  const dispatch = useDispatch();
+
+  const [thread, setThread] = useState(null);
+  const [mods, setMods] = useState([]);
+  useEffect(() => {
+    (async () => {
+      try {
+        const rthread = await mfetchjson(`/api/modmail/${threadId}`);
+        setThread(rthread);
+        if (rthread.viewerIsMod) {
+          const rmods = await mfetchjson(
+            `/api/communities/${rthread.communityId}/mods`,
+          );
+          setMods(rmods || []);
+        }
+      } catch (error) {
+        dispatch(snackAlertError(error));
+      }
+    })();
+  }, [threadId]);
+
+  const [body, setBody] = useState("");
+  const [asMods, setAsMods] = useState(true);
+  const [internal, setInternal] = useState(false);
+  const handleReply = async () => {
+    try {
+      const message = await mfetchjson(`/api/modmail/${threadId}/messages`, {
+        method: "POST",
+        body: JSON.stringify({
+          body,
+          asMods: thread.viewerIsMod && asMods && !internal,
+          internal: thread.viewerIsMod && internal,
+        }),
+      });
+      setThread((thread) => ({
+        ...thread,
+        messages: [...thread.messages, message],
+      }));
+      setBody("");
+      setInternal(false);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const handleUpdate = async (action, reqBody) => {
+    try {
+      const rthread = await mfetchjson(
+        `/api/modmail/${threadId}?action=${action}`,
+        {
+          method: "PUT",
+          body: reqBody ? JSON.stringify(reqBody) : undefined,
+        },
+      );
+      setThread((thread) => ({ ...thread, ...rthread }));
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  if (thread === null) {
+    return null;
+  }
+
+  const authorText = (message) => {
+    if (message.asMods) {
+      const team = `Mods of ${thread.communityName}`;
+      return message.authorUsername
+        ? `${team} (@${message.authorUsername})`
+        : team;
+    }
+    return `@${message.authorUsername}`;
+  };
+
+  return (
+    <div className="modmail-thread">
+      <div className="modmail-thread-head">
+        <div className="modmail-thread-subject">{thread.subject}</div>
+        <div className="modmail-thread-meta">
+          {thread.viewerIsMod ? (
+            <>
+              From{" "}
+              <Link to={`/@${thread.username}`}>@{thread.username}</Link>
+            </>
+          ) : (
+            <>
+              To the mods of{" "}
+              <Link to={`/${CONFIG.communityPrefix}${thread.communityName}`}>
+                {thread.communityName}
+              </Link>
+            </>
+          )}
+          {thread.archived && " • Archived"}
+        </div>
+        {thread.viewerIsMod && (
+          <div className="modmail-thread-actions">
+            <select
+              value={thread.assignedToUsername || ""}
+              onChange={(e) =>
+                handleUpdate("assign", { username: e.target.value })
+              }
+            >
+              <option value="">Unassigned</option>
+              {mods.map((mod) => (
+                <option key={mod.id} value={mod.username}>
+                  Assigned to @{mod.username}
+                </option>
+              ))}
+            </select>
+            <button
+              type="button"
+              onClick={() =>
+                handleUpdate(thread.archived ? "unarchive" : "archive")
+              }
+            >
+              {thread.archived ? "Unarchive" : "Archive"}
+            </button>
+          </div>
+        )}
+      </div>
+      <div className="modmail-thread-messages">
+        {thread.messages.map((message) => (
+          <div
+            key={message.id}
+            className={`card card-padding modmail-message${message.internal ? " is-internal" : ""}`}
+          >
+            <div className="modmail-message-head">
+              <span>{authorText(message)}</span>
+              {message.internal && <span> • Internal note</span>}
+              <span>
+                {" • "}
+                <TimeAgo time={message.createdAt} />
+              </span>
+            </div>
+            <MarkdownBody>{message.body}</MarkdownBody>
+          </div>
+        ))}
+      </div>
+      <form
+        className="modmail-thread-reply"
+        onSubmit={(e) => {
+          e.preventDefault();
+          handleReply();
+        }}
+      >
+        <textarea
+          rows="5"
+          placeholder={internal ? "Internal note" : "Reply"}
+          value={body}
+          onChange={(e) => setBody(e.target.value)}
+        />
+        {thread.viewerIsMod && (
+          <div className="modmail-thread-reply-options">
+            <div className="checkbox">
+              <input
+                id="modmail-as-mods"
+                type="checkbox"
+                checked={asMods}
+                disabled={internal}
+                onChange={(e) => setAsMods(e.target.checked)}
+              />
+              <label htmlFor="modmail-as-mods">Reply as the mod team</label>
+            </div>
+            <div className="checkbox">
+              <input
+                id="modmail-internal"
+                type="checkbox"
+                checked={internal}
+                onChange={(e) => setInternal(e.target.checked)}
+              />
+              <label htmlFor="modmail-internal">
+                Internal note (only the mods can see it)
+              </label>
+            </div>
+          </div>
+        )}
+        <button
+          type="submit"
+          className="button-main"
+          disabled={body.trim() === ""}
+        >
+          {internal ? "Add note" : "Send"}
+        </button>
+      </form>
+    </div>
+  );
+};
+
+ModmailThread.propTypes = {
+  threadId: PropTypes.string.isRequired,
+};
+
+export default ModmailThread;
@@ -73,6 +73,30 @@ const NotificationItem = ({ notification, ...rest }) => {
           </>
         );
       }
+      case "thread_comment": {
+        if (notif.noComments === 1) {
+          return (
+            <>
+              <b>@{notif.commentAuthor}</b> commented in a thread you follow on post{" "}
+              <b>{notif.post.title}</b>.
+            </>
+          );
+        }
+        return (
+          <>
+            {notif.noComments} new comments in a thread you follow on post{" "}
+            <b>{notif.post.title}</b>.
+          </>
+        );
+      }
+      case "new_post": {
+        return (
+          <>
+            <b>@{notif.author}</b> submitted a new post <b>{notif.post.title}</b>{" "}
+            in <b>{notif.post.communityName}</b>.
+          </>
+        );
+      }
       case "new_votes": {
         if (notif.targetType === "post") {
           return (
@@ -90,17 +114,25 @@ const NotificationItem = ({ notification, ...rest }) => {
         );
       }
       case "deleted_post": {
+        const target = notif.post || notif.comment;
         return (
           <>
-            Your post <b>{notif.post.title}</b> has been removed by{" "}
+            {notif.post ? (
+              <>
+                Your post <b>{notif.post.title}</b>
+              </>
+            ) : (
+              "Your comment"
+            )}{" "}
+            has been removed by{" "}
             {notif.deletedAs === "mods" ? (
               <>
-                moderators of <b>{notif.post.communityName}</b>
+                moderators of <b>{target.communityName}</b>
               </>
             ) : (
               "the admins"
             )}
-            .
+            {notif.reason ? `: ${notif.reason}` : "."}
           </>
         );
       }
@@ -112,6 +144,129 @@ const NotificationItem = ({ notification, ...rest }) => {
           </>
         );
       }
+      case "community_invite": {
+        return (
+          <>
+            You are invited to join <b>{notif.communityName}</b> by{" "}
+            <b>@{notif.invitedBy}.</b>
+          </>
+        );
+      }
+      case "automod_message": {
+        return (
+          <>
+            Message from the moderators of <b>{notif.communityName}</b>:{" "}
+            {notif.message}
+          </>
+        );
+      }
+      case "ban_appeal": {
+        const ban = notif.communityName ? (
+          <>
+            ban from <b>{notif.communityName}</b>
+          </>
+        ) : (
+          "suspension"
+        );
+        const text =
+          notif.status === "shortened" ? (
+            <>Your {ban} is shortened</>
+          ) : (
+            <>
+              Your appeal of your {ban} is {notif.status}
+            </>
+          );
+        return (
+          <>
+            {text}
+            {notif.message ? `: ${notif.message}` : "."}
+          </>
+        );
+      }
+      case "ban_expired": {
+        if (notif.communityName) {
+          return (
+            <>
+              Your ban from <b>{notif.communityName}</b> has expired.
+            </>
+          );
+        }
+        return "Your suspension has expired.";
+      }
+      case "community_ban": {
+        return (
+          <>
+            You are banned from <b>{notif.communityName}</b>
+            {notif.expires
+              ? ` until ${new Date(notif.expires).toLocaleString()}`
+              : ""}
+            . Message the moderators to talk about it.
+          </>
+        );
+      }
+      case "modmail": {
+        const what =
+          notif.noMessages > 1
+            ? `${notif.noMessages} new messages`
+            : "New message";
+        if (notif.toMods) {
+          return (
+            <>
+              {what} in the modmail of <b>{notif.communityName}</b>:{" "}
+              {notif.subject}
+            </>
+          );
+        }
+        return (
+          <>
+            {what} from the moderators of <b>{notif.communityName}</b>:{" "}
+            {notif.subject}
+          </>
+        );
+      }
+      case "mod_transfer": {
+        return (
+          <>
+            <b>@{notif.offeredBy}</b> offered to make you the top moderator
+            of <b>{notif.communityName}</b>.
+          </>
+        );
+      }
+      case "community_takeover": {
+        return (
+          <>
+            Your request to take over <b>{notif.communityName}</b> is{" "}
+            {notif.status}
+            {notif.message ? `: ${notif.message}` : "."}
+          </>
+        );
+      }
+      case "community_rename": {
+        return (
+          <>
+            <b>{notif.oldName}</b> is now called <b>{notif.communityName}</b>.
+          </>
+        );
+      }
+      case "rename_rejected": {
+        return (
+          <>
+            Your request to rename <b>{notif.communityName}</b> to{" "}
+            <b>{notif.newName}</b> is rejected
+            {notif.message ? `: ${notif.message}` : "."}
+          </>
+        );
+      }
+      case "new_message": {
+        if (notif.noMessages === 1) {
+          return (
+            <>
+              <b>@{notif.sender}</b> sent you a message.
+            </>
+          );
+        }
+        return <>{notif.noMessages} new messages in a conversation.</>;
+      }
       case "new_badge": {
         return (
           <>
@@ -120,6 +275,15 @@ const NotificationItem = ({ notification, ...rest }) => {
           </>
         );
       }
+      case "mention": {
+        return (
+          <>
+            <b>@{notif.mentionedBy}</b> mentioned you in{" "}
+            {notif.targetType === "post" ? "the post" : "a comment on"}{" "}
+            <b>{notif.post.title}</b>.
+          </>
+        );
+      }
       default: {
         return null; // unknown notification type
       }
@@ -180,6 +344,21 @@ const NotificationItem = ({ notification, ...rest }) => {
       image = getNotifImage(notif);
       break;
     }
+    case "thread_comment": {
+      to = `/${CONFIG.communityPrefix}${notif.post.communityName}/post/${notif.post.publicId}`;
+      if (notif.noComments === 1) {
+        to += `/${notif.commentId}`;
+      } else if (notif.threadId) {
+        to += `/${notif.threadId}`;
+      }
+      image = getNotifImage(notif);
+      break;
+    }
+    case "new_post": {
+      to = `/${CONFIG.communityPrefix}${notif.post.communityName}/post/${notif.post.publicId}`;
+      image = getNotifImage(notif);
+      break;
+    }
     case "new_votes": {
       if (notif.targetType === "post") {
         to = `/${CONFIG.communityPrefix}${notif.post.communityName}/post/${notif.post.publicId}`;
@@ -190,8 +369,31 @@ const NotificationItem = ({ notification, ...rest }) => {
       break;
     }
     case "deleted_post": {
-      // Currently only deleted post notifications get here.
+      if (notif.post) {
+        to = `/${CONFIG.communityPrefix}${notif.post.communityName}/post/${notif.post.publicId}`;
+      } else {
+        const { comment } = notif;
+        to = `/${CONFIG.communityPrefix}${comment.communityName}/post/${comment.postPublicId}/${comment.id}`;
+      }
+      if (notif.deletedAs === "mods") {
+        // Link to modmail, so that the user can talk to the mods about the
+        // removal.
+        const target = notif.post || notif.comment;
+        const params = new URLSearchParams({
+          community: target.communityName,
+          subject: `Removal of my ${notif.post ? "post" : "comment"}`,
+          body: `${window.location.origin}${to}`,
+        });
+        to = `/modmail/new?${params.toString()}`;
+      }
+      image = getNotifImage(notif);
+      break;
+    }
+    case "mention": {
       to = `/${CONFIG.communityPrefix}${notif.post.communityName}/post/${notif.post.publicId}`;
+      if (notif.targetType === "comment") {
+        to += `/${notif.targetId}`;
+      }
       image = getNotifImage(notif);
       break;
     }
@@ -200,6 +402,63 @@ const NotificationItem = ({ notification, ...rest }) => {
       image = getNotifImage(notif);
       break;
     }
+    case "community_invite": {
+      to = `/${CONFIG.communityPrefix}${notif.communityName}`;
+      image = getNotifImage(notif);
+      break;
+    }
+    case "automod_message": {
+      if (notif.targetType === "post") {
+        to = `/${CONFIG.communityPrefix}${notif.post.communityName}/post/${notif.post.publicId}`;
+      } else {
+        to = `/${CONFIG.communityPrefix}${notif.comment.communityName}/post/${notif.comment.postPublicId}/${notif.comment.id}`;
+      }
+      image = getNotifImage(notif);
+      break;
+    }
+    case "community_ban": {
+      const params = new URLSearchParams({
+        community: notif.communityName,
+        subject: `Ban from ${notif.communityName}`,
+      });
+      to = `/modmail/new?${params.toString()}`;
+      image = getNotifImage(notif);
+      break;
+    }
+    case "modmail": {
+      to = notif.toMods
+        ? `/${CONFIG.communityPrefix}${notif.communityName}/modtools/modmail/${notif.threadId}`
+        : `/modmail/${notif.threadId}`;
+      break;
+    }
+    case "mod_transfer": {
+      to = `/${CONFIG.communityPrefix}${notif.communityName}/modtools/mods`;
+      image = getNotifImage(notif);
+      break;
+    }
+    case "community_takeover":
+    case "community_rename": {
+      to = `/${CONFIG.communityPrefix}${notif.communityName}`;
+      image = getNotifImage(notif);
+      break;
+    }
+    case "rename_rejected": {
+      to = `/${CONFIG.communityPrefix}${notif.communityName}/modtools/settings`;
+      image = getNotifImage(notif);
+      break;
+    }
+    case "ban_appeal":
+    case "ban_expired": {
+      if (notif.communityName) {
+        to = `/${CONFIG.communityPrefix}${notif.communityName}`;
+      }
+      image = getNotifImage(notif);
+      break;
+    }
+    case "new_message": {
+      to = `/messages/${notif.conversationId}`;
+      break;
+    }
     case "new_badge": {
       to = `/@${viewer.username}`;
       const { src } = badgeImage(notif.badgeType);
@@ -1,8 +1,9 @@
 import PropTypes from "prop-types";
-import React, { useEffect, useState } from "react";
+import React, { useEffect, useRef, useState } from "react";
+import { useSelector } from "react-redux";
 import { useHistory } from "react-router-dom";
 import { omitWwwFromHostname, stringCount } from "../../helper";
-import { useIsMobile } from "../../hooks";
+import { useIsMobile, useMarkPostRead } from "../../hooks";
 import Link from "../Link";
 import MarkdownBody from "../MarkdownBody";
 import PostImageGallery from "../PostImageGallery";
@@ -30,6 +31,13 @@ const PostCard = ({
     setPost(initialPost);
   }, [initialPost]);
 
+  const ref = useRef(null);
+  useMarkPostRead(post, ref);
+  const dimRead = useSelector(
+    (state) => state.main.user && state.main.user.readPosts === "dim",
+  );
+  const isDimmed = dimRead && post.read && !inModTools;
+
   const postUrl = `/${CONFIG.communityPrefix}${post.communityName}/post/${post.publicId}`;
   const target = openInTab ? "_blank" : "_self";
   const disabled = inModTools || post.locked;
@@ -80,12 +88,23 @@ const PostCard = ({
   } = getEmbedComponent(post.link);
   const isEmbed = !disableEmbeds && _isEmbed;
 
-  const showImage = !post.deletedContent && post.type === "image" && post.image;
+  // Posts matched by the viewer's content filters are shown collapsed (only
+  // the title) until expanded.
+  const [filterCollapsed, setFilterCollapsed] = useState(
+    Boolean(initialPost.filtered),
+  );
+
+  const showImage =
+    !filterCollapsed &&
+    !post.deletedContent &&
+    post.type === "image" &&
+    post.image;
   const imageLoadingStyle = index < 3 ? "eager" : "lazy";
 
   return (
     <div
-      className={`post-card ${inModTools ? "is-in-modtools" : ""} ${hideVoting ? "no-voting" : ""} ${compact ? "is-compact" : ""} ${isPinned ? "is-pinned" : ""}`}
+      className={`post-card ${inModTools ? "is-in-modtools" : ""} ${hideVoting ? "no-voting" : ""} ${compact ? "is-compact" : ""} ${isPinned ? "is-pinned" : ""} ${isDimmed ? "is-read" : ""}`}
+      ref={ref}
     >
       {!hideVoting && <PostVotes post={post} disabled={disabled} />}
       <div
@@ -134,7 +153,7 @@ const PostCard = ({
                 </a>
               )}
             </div>
-            {showLink && !isEmbed && post.link.image && (
+            {showLink && !filterCollapsed && !isEmbed && post.link.image && (
               <Link
                 className="post-card-link-image"
                 to={postUrl}
@@ -144,8 +163,16 @@ const PostCard = ({
               </Link>
             )}
           </div>
-          {isEmbed && <Embed url={embedUrl} />}
-          {post.type === "text" && (
+          {filterCollapsed && (
+            <button
+              className="button-text post-card-filtered"
+              onClick={() => setFilterCollapsed(false)}
+            >
+              This post matches your filters. Click to show it.
+            </button>
+          )}
+          {!filterCollapsed && isEmbed && <Embed url={embedUrl} />}
+          {!filterCollapsed && post.type === "text" && (
             <div className="post-card-text">
               <ShowMoreBox maxHeight="200px">
                 <MarkdownBody noLinks>{post.body}</MarkdownBody>
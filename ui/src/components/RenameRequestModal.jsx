@@ -0,0 +1,91 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useDispatch } from "react-redux";
+import { communityNameMaxLength } from "../config";
+import { mfetchjson } from "../helper";
+import { useInputUsername } from "../hooks";
+import { snackAlert, snackAlertError } from "../slices/mainSlice";
+import { ButtonClose } from "./Button";
+import { InputWithCount, useInputMaxLength } from "./Input";
+import Modal from "./Modal";
+
+// RenameRequestModal lets a mod of community request a new name for it.
+const RenameRequestModal = ({ open, onClose, community, onSubmitted }) => {
+  const dispatch = useDispatch();
+
+  const [name, handleNameChange] = useInputUsername(communityNameMaxLength);
+  const reasonMaxLength = 2000;
+  const [reason, setReason] = useInputMaxLength(reasonMaxLength);
+
+  const handleSubmit = async () => {
+    try {
+      const request = await mfetchjson(
+        `/api/communities/${community.id}/rename_requests`,
+        {
+          method: "POST",
+          body: JSON.stringify({ name, reason }),
+        },
+      );
+      dispatch(snackAlert("Request submitted."));
+      setReason("");
+      onSubmitted(request);
+      onClose();
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  return (
+    <Modal open={open} onClose={onClose}>
+      <div className="modal-card modal-form">
+        <div className="modal-card-head">
+          <div className="modal-card-title">Rename community</div>
+          <ButtonClose onClick={onClose} />
+        </div>
+        <div className="modal-card-content flex-column inner-gap-1">
+          <InputWithCount
+            value={name}
+            onChange={handleNameChange}
+            label="New name"
+            description={`The old name, ${community.name}, will keep redirecting to the community. Members are notified of the new name.`}
+            maxLength={communityNameMaxLength}
+            autoFocus
+          />
+          <InputWithCount
+            textarea
+            rows="5"
+            label="Reason"
+            description="Your request is reviewed by the admins."
+            maxLength={reasonMaxLength}
+            value={reason}
+            onChange={setReason}
+            style={{ resize: "vertical" }}
+          />
+        </div>
+        <div className="modal-card-actions">
+          <button
+            type="button"
+            className="button-main"
+            disabled={name.length < 3 || reason.trim() === ""}
+            onClick={handleSubmit}
+          >
+            Submit
+          </button>
+          <button type="button" onClick={onClose}>
+            Cancel
+          </button>
+        </div>
+      </div>
+    </Modal>
+  );
+};
+
+RenameRequestModal.propTypes = {
+  open: PropTypes.bool.isRequired,
+  onClose: PropTypes.func.isRequired,
+  community: PropTypes.object.isRequired,
+  onSubmitted: PropTypes.func.isRequired,
+};
+
+export default RenameRequestModal;
@@ -68,6 +68,18 @@ const ReportModal = ({
     }
   };
 
+  const renderReason = (r) => (
+    <div key={r.id} className="radio" style={{ margin: "0.7rem 0" }}>
+      <input
+        id={`report-reason${r.id}`}
+        type="radio"
+        name="reason"
+        value={r.id}
+      />
+      <label htmlFor={`report-reason${r.id}`}>{r.title}</label>
+    </div>
+  );
+
   return (
     <>
       {noButton ? null : (
@@ -92,21 +104,13 @@ const ReportModal = ({
               onChange={handleRadioChange}
               style={{ minWidth: "340px" }}
             >
-              {reasons.map((r) => (
-                <div
-                  key={r.id}
-                  className="radio"
-                  style={{ margin: "0.7rem 0" }}
-                >
-                  <input
-                    id={`report-reason${r.id}`}
-                    type="radio"
-                    name="reason"
-                    value={r.id}
-                  />
-                  <label htmlFor={`report-reason${r.id}`}>{r.title}</label>
+              {reasons.filter((r) => !r.forAdmins).map(renderReason)}
+              {reasons.some((r) => r.forAdmins) && (
+                <div style={{ marginTop: "0.7rem", fontWeight: "bold" }}>
+                  Report to the admins of {CONFIG.siteName}
                 </div>
-              ))}
+              )}
+              {reasons.filter((r) => r.forAdmins).map(renderReason)}
             </div>
           </div>
           <div className="modal-card-actions">
@@ -18,6 +18,9 @@ const Sidebar = ({ isMobile = false }) => {
   const loggedIn = user !== null;
 
   const homeFeed = loggedIn ? user.homeFeed : "all";
+  const modQueueCount = loggedIn
+    ? (user.moddingList || []).reduce((n, c) => n + (c.modQueueCount || 0), 0)
+    : 0;
   const communities = useSelector((state) => state.main.sidebarCommunities);
 
   // Two variables to track visibility because CSS transitions
@@ -176,6 +179,27 @@ const Sidebar = ({ isMobile = false }) => {
               <span>{homeFeed === "all" ? "Subscriptions" : "All"}</span>
             </Link>
           )}
+          {loggedIn && homeFeed !== "following" && (
+            <Link
+              to={homePageLink("/following")}
+              className="sidebar-item with-image"
+              onClick={handleClose}
+            >
+              <svg
+                width="24"
+                height="24"
+                viewBox="0 0 24 24"
+                fill="none"
+                xmlns="http://www.w3.org/2000/svg"
+              >
+                <path
+                  d="M9 2C6.38 2 4.25 4.13 4.25 6.75C4.25 9.32 6.26 11.4 8.88 11.49C8.96 11.48 9.04 11.48 9.1 11.49C9.12 11.49 9.13 11.49 9.15 11.49C11.71 11.4 13.72 9.32 13.73 6.75C13.73 4.13 11.6 2 9 2ZM14.08 14.15C11.29 12.29 6.74 12.29 3.93 14.15C2.66 15 1.96 16.15 1.96 17.38C1.96 18.61 2.66 19.75 3.92 20.59C5.32 21.53 7.16 22 9 22C10.84 22 12.68 21.53 14.08 20.59C15.34 19.74 16.04 18.6 16.04 17.36C16.03 16.13 15.34 14.99 14.08 14.15ZM19.99 7.34C20.15 9.28 18.77 10.98 16.86 11.21C16.85 11.21 16.85 11.21 16.84 11.21H16.81C16.75 11.21 16.69 11.21 16.64 11.23C15.67 11.28 14.78 10.97 14.11 10.4C15.14 9.48 15.73 8.1 15.61 6.6C15.54 5.79 15.26 5.05 14.84 4.42C15.22 4.23 15.66 4.11 16.11 4.07C18.07 3.9 19.82 5.36 19.99 7.34ZM21.99 16.59C21.91 17.56 21.29 18.4 20.25 18.97C19.25 19.52 17.99 19.78 16.74 19.75C17.46 19.1 17.88 18.29 17.96 17.43C18.06 16.19 17.47 15 16.29 14.05C15.62 13.52 14.84 13.1 13.99 12.79C16.2 12.15 18.98 12.58 20.69 13.96C21.61 14.7 22.08 15.63 21.99 16.59Z"
+                  fill="currentColor"
+                />
+              </svg>
+              <span>Following</span>
+            </Link>
+          )}
           <Link
             to="/communities"
             className="sidebar-item with-image"
@@ -313,6 +337,11 @@ const Sidebar = ({ isMobile = false }) => {
             My lists
           </Link>
           */}
+          {loggedIn && (
+            <Link className="sidebar-item" to="/messages">
+              Messages
+            </Link>
+          )}
           {loggedIn && lists.length > 0 && (
             <>
               <div className="sidebar-topic">My Lists</div>
@@ -355,6 +384,22 @@ const Sidebar = ({ isMobile = false }) => {
               ))}
             </>
           )}
+          {loggedIn && (
+            <>
+              <div className="sidebar-topic">Moderation</div>
+              {user.moddingList?.length > 0 && (
+                <Link className="sidebar-item" to="/modqueue">
+                  Mod queue
+                  {modQueueCount > 0 && (
+                    <span className="sidebar-item-count">{modQueueCount}</span>
+                  )}
+                </Link>
+              )}
+              <Link className="sidebar-item" to="/modmail">
+                Modmail
+              </Link>
+            </>
+          )}
           <div className="sidebar-topic">
             {loggedIn ? "My communities" : "Communities"}
           </div>
@@ -0,0 +1,77 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useDispatch } from "react-redux";
+import { mfetchjson } from "../helper";
+import { snackAlert, snackAlertError } from "../slices/mainSlice";
+import { ButtonClose } from "./Button";
+import { InputWithCount, useInputMaxLength } from "./Input";
+import Modal from "./Modal";
+
+// TakeoverRequestModal lets a user request to become the top moderator of
+// community, whose moderators are all inactive.
+const TakeoverRequestModal = ({ open, onClose, community }) => {
+  const dispatch = useDispatch();
+
+  const maxLength = 5000;
+  const [body, setBody] = useInputMaxLength(maxLength);
+
+  const handleSubmit = async () => {
+    try {
+      await mfetchjson(`/api/communities/${community.id}/takeover_requests`, {
+        method: "POST",
+        body: JSON.stringify({ body }),
+      });
+      dispatch(snackAlert("Request submitted."));
+      setBody("");
+      onClose();
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  return (
+    <Modal open={open} onClose={onClose}>
+      <div className="modal-card">
+        <div className="modal-card-head">
+          <div className="modal-card-title">Request to moderate</div>
+          <ButtonClose onClick={onClose} />
+        </div>
+        <div className="modal-card-content">
+          <InputWithCount
+            textarea
+            rows="8"
+            label="Why do you want to moderate this community?"
+            description={`The moderators of ${community.name} are inactive. Your request is reviewed by the admins.`}
+            maxLength={maxLength}
+            value={body}
+            onChange={setBody}
+            style={{ resize: "vertical" }}
+            autoFocus
+          />
+        </div>
+        <div className="modal-card-actions">
+          <button
+            type="button"
+            className="button-main"
+            disabled={body.trim() === ""}
+            onClick={handleSubmit}
+          >
+            Submit
+          </button>
+          <button type="button" onClick={onClose}>
+            Cancel
+          </button>
+        </div>
+      </div>
+    </Modal>
+  );
+};
+
+TakeoverRequestModal.propTypes = {
+  open: PropTypes.bool.isRequired,
+  onClose: PropTypes.func.isRequired,
+  community: PropTypes.object.isRequired,
+};
+
+export default TakeoverRequestModal;
@@ -469,3 +469,83 @@ export function useFetchUsersLists(username, showSnackAlertOnError = true) {
     error,
   };
 }
+
+// Posts scrolled past, waiting to be reported to the server as read.
+const readPostsQueue = new Set();
+let readPostsTimer = null;
+
+function flushReadPosts() {
+  readPostsTimer = null;
+  const postIds = [...readPostsQueue].slice(0, 100);
+  postIds.forEach((id) => readPostsQueue.delete(id));
+  if (postIds.length === 0) {
+    return;
+  }
+  mfetch("/api/_read", {
+    method: "POST",
+    body: JSON.stringify({ postIds }),
+  }).catch((error) => console.error(error));
+  if (readPostsQueue.size > 0) {
+    readPostsTimer = setTimeout(flushReadPosts, 5000);
+  }
+}
+
+// useMarkPostRead marks post as read once the element ref is scrolled past
+// (that is, it leaves the viewport from the top), if the logged in user has
+// read tracking turned on.
+export function useMarkPostRead(post, ref) {
+  const user = useSelector((state) => state.main.user);
+  const tracking = user && user.readPosts && user.readPosts !== "off";
+  useEffect(() => {
+    if (
+      !tracking ||
+      post.read ||
+      !ref.current ||
+      !window.IntersectionObserver
+    ) {
+      return;
+    }
+    const observer = new IntersectionObserver((entries) => {
+      const entry = entries[0];
+      if (!entry.isIntersecting && entry.boundingClientRect.top < 0) {
+        readPostsQueue.add(post.id);
+        if (readPostsTimer === null) {
+          readPostsTimer = setTimeout(flushReadPosts, 5000);
+        }
+        observer.disconnect();
+      }
+    });
+    observer.observe(ref.current);
+    return () => observer.disconnect();
+  }, [tracking, post.id, post.read]);
+}
+
+// useEvents subscribes to the real-time events of the server (sent from
+// /api/_events) while enabled is true. If post (a public ID) is set, the
+// events of that post are received as well. The onEvent callback is called
+// with each event ({ type, data }).
+export function useEvents(enabled, post, onEvent) {
+  const onEventRef = useRef(onEvent);
+  onEventRef.current = onEvent;
+  useEffect(() => {
+    if (!enabled || !window.EventSource) {
+      return;
+    }
+    const params = new URLSearchParams();
+    if (post) {
+      params.set("post", post);
+    }
+    const source = new EventSource(`/api/_events?${params.toString()}`);
+    source.onmessage = (e) => {
+      let event;
+      try {
+        event = JSON.parse(e.data);
+      } catch (error) {
+        console.error(error);
+        return;
+      }
+      onEventRef.current(event);
+    };
+    return () => source.close();
+  }, [enabled, post]);
+}
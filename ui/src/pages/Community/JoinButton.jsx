@@ -11,6 +11,9 @@ const JoinButton = ({ className, community, ...rest }) => {
   const dispatch = useDispatch();
 
   const joined = community ? community.userJoined : false;
+  const requested = community ? community.userRequested : false;
+  const invited = community ? community.userInvited : false;
+  const open = !community || community.visibility === "public";
   const handleFollow = async () => {
     if (!loggedIn) {
       dispatch(loginPromptToggled());
@@ -23,7 +26,10 @@ const JoinButton = ({ className, community, ...rest }) => {
     try {
       const rcomm = await mfetchjson("/api/_joinCommunity", {
         method: "POST",
-        body: JSON.stringify({ communityId: community.id, leave: joined }),
+        body: JSON.stringify({
+          communityId: community.id,
+          leave: joined || requested,
+        }),
       });
       dispatch(communityAdded(rcomm));
     } catch (error) {
@@ -31,14 +37,23 @@ const JoinButton = ({ className, community, ...rest }) => {
     }
   };
 
-  let cls = joined ? "" : "button-main";
+  let cls = joined || requested ? "" : "button-main";
   if (className) {
     cls += ` ${className}`;
   }
 
+  let text = joined ? "Joined" : "Join";
+  if (!joined && !open) {
+    if (requested) {
+      text = "Requested";
+    } else {
+      text = invited ? "Accept invite" : "Request to join";
+    }
+  }
+
   return (
     <button onClick={handleFollow} className={cls} {...rest}>
-      {joined ? "Joined" : "Join"}
+      {text}
     </button>
   );
 };
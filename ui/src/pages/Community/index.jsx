@@ -5,6 +5,7 @@ import { useEffect, useState } from "react";
 import { Helmet } from "react-helmet-async";
 import { useDispatch, useSelector } from "react-redux";
 import { useHistory, useLocation, useParams } from "react-router-dom";
+import BanAppealModal from "../../components/BanAppealModal";
 import { ButtonMore } from "../../components/Button";
 import CommunityProPic from "../../components/CommunityProPic";
 import Dropdown from "../../components/Dropdown";
@@ -14,7 +15,14 @@ import MiniFooter from "../../components/MiniFooter";
 import PageLoading from "../../components/PageLoading";
 import ShowMoreBox from "../../components/ShowMoreBox";
 import Sidebar from "../../components/Sidebar";
-import { ApiError, dateString1, mfetch, stringCount } from "../../helper";
+import TakeoverRequestModal from "../../components/TakeoverRequestModal";
+import {
+  ApiError,
+  dateString1,
+  mfetch,
+  mfetchjson,
+  stringCount,
+} from "../../helper";
 import { useMuteCommunity } from "../../hooks";
 import { communityAdded, selectCommunity } from "../../slices/communitiesSlice";
 import { snackAlertError } from "../../slices/mainSlice";
@@ -76,7 +84,19 @@ const Community = () => {
     community &&
     bannedFrom.find((id) => id === community.id) !== undefined;
 
+  // Only approved members can post in restricted and private communities, and
+  // only they can see the posts of private communities.
+  const isMember = community && (community.userJoined || user?.isAdmin);
+  const canPost =
+    !isBanned &&
+    community &&
+    !community.archivedAt &&
+    (community.visibility === "public" || isMember);
+  const canView = community && (community.visibility !== "private" || isMember);
+
   const [tab, setTab] = useState("posts");
+  const [appealOpen, setAppealOpen] = useState(false);
+  const [takeoverOpen, setTakeoverOpen] = useState(false);
   useEffect(() => {
     setTab("posts");
   }, [location]);
@@ -88,6 +108,44 @@ const Community = () => {
         : {},
     );
 
+  const handleAdminAction = async (action, confirmText) => {
+    if (!confirm(confirmText)) {
+      return;
+    }
+    try {
+      await mfetchjson("/api/_admin", {
+        method: "POST",
+        body: JSON.stringify({ action, name: community.name }),
+      });
+      window.location.reload();
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const handleRename = async () => {
+    const newName = prompt(
+      `New name for ${community.name}? The old name will redirect to it.`,
+      community.name,
+    );
+    if (!newName || newName === community.name) {
+      return;
+    }
+    try {
+      await mfetchjson("/api/_admin", {
+        method: "POST",
+        body: JSON.stringify({
+          action: "rename_community",
+          name: community.name,
+          newName,
+        }),
+      });
+      window.location.assign(`/${CONFIG.communityPrefix}${newName}`);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
   if (loading) {
     if (error === "notfound") {
       return <NotFound />;
@@ -116,7 +174,30 @@ const Community = () => {
               </li>
             ))}
           </ul>
-          {/*loggedIn && <button className="card-mods-message-btn">Message mods</button>*/}
+          {loggedIn && (
+            <Link
+              className="button card-mods-message-btn"
+              to={`/modmail/new?community=${community.name}`}
+            >
+              Message mods
+            </Link>
+          )}
+          {loggedIn && community.isAbandoned && !community.userMod && (
+            <>
+              <button
+                type="button"
+                className="button-link card-mods-takeover-btn"
+                onClick={() => setTakeoverOpen(true)}
+              >
+                Request to moderate
+              </button>
+              <TakeoverRequestModal
+                open={takeoverOpen}
+                onClose={() => setTakeoverOpen(false)}
+                community={community}
+              />
+            </>
+          )}
         </div>
       </div>
     );
@@ -126,14 +207,14 @@ const Community = () => {
     const url = `/new?community=${community.name}`;
     const handleClick = (e) => {
       e.preventDefault();
-      if (!isBanned) {
+      if (canPost) {
         history.push(url);
       }
     };
     return (
       <>
         <a
-          className={`button button-main border-radius-0 ${isBanned ? " is-disabled" : ""}`}
+          className={`button button-main border-radius-0 ${canPost ? "" : " is-disabled"}`}
           href={url}
           onClick={handleClick}
         >
@@ -191,6 +272,51 @@ const Community = () => {
                     >
                       {muteDisplayText}
                     </button>
+                    {user.isAdmin && !community.deletedAt && (
+                      <button
+                        type="button"
+                        className="button-clear dropdown-item"
+                        onClick={handleRename}
+                      >
+                        Rename
+                      </button>
+                    )}
+                    {user.isAdmin && !community.deletedAt && (
+                      <button
+                        type="button"
+                        className="button-clear dropdown-item"
+                        onClick={() =>
+                          handleAdminAction(
+                            community.archivedAt
+                              ? "unarchive_community"
+                              : "archive_community",
+                            community.archivedAt
+                              ? `Unarchive ${community.name}?`
+                              : `Archive ${community.name}? It will become read-only.`,
+                          )
+                        }
+                      >
+                        {community.archivedAt ? "Unarchive" : "Archive"}
+                      </button>
+                    )}
+                    {user.isAdmin && (
+                      <button
+                        type="button"
+                        className="button-clear dropdown-item"
+                        onClick={() =>
+                          handleAdminAction(
+                            community.deletedAt
+                              ? "restore_community"
+                              : "delete_community",
+                            community.deletedAt
+                              ? `Restore ${community.name}?`
+                              : `Delete ${community.name}? It can be restored within 30 days.`,
+                          )
+                        }
+                      >
+                        {community.deletedAt ? "Restore" : "Delete"}
+                      </button>
+                    )}
                   </div>
                 </Dropdown>
               )}
@@ -200,6 +326,9 @@ const Community = () => {
             <h1>{community.name}</h1>
             <div className="comm-main-followers">
               {stringCount(community.noMembers, false, "member")}
+              {community.visibility === "restricted" && " • Restricted"}
+              {community.visibility === "private" && " • Private"}
+              {community.archivedAt && " • Archived"}
             </div>
             <div className="comm-main-description">
               <ShowMoreBox showButton maxHeight="120px">
@@ -231,7 +360,43 @@ const Community = () => {
           <div className="comm-action-buttons-m">{renderActionButtons()}</div>
         )}
         <div className="comm-posts">
-          {tab === "posts" && <PostsFeed communityId={community.id} />}
+          {community.deletedAt && (
+            <div className="card card-padding comm-notice">
+              This community was deleted on{" "}
+              {dateString1(community.deletedAt)}. Only admins can see it.
+            </div>
+          )}
+          {community.archivedAt && (
+            <div className="card card-padding comm-notice">
+              This community is archived. You can browse it, but you can no
+              longer post, comment, or vote in it.
+            </div>
+          )}
+          {isBanned && (
+            <div className="card card-padding comm-banned">
+              You are banned from this community.{" "}
+              <button
+                type="button"
+                className="button-link"
+                onClick={() => setAppealOpen(true)}
+              >
+                Appeal the ban
+              </button>
+              <BanAppealModal
+                open={appealOpen}
+                onClose={() => setAppealOpen(false)}
+                community={community}
+              />
+            </div>
+          )}
+          {tab === "posts" && canView && (
+            <PostsFeed communityId={community.id} />
+          )}
+          {tab === "posts" && !canView && (
+            <div className="card card-padding">
+              This community is private. Only its members can see its posts.
+            </div>
+          )}
           {tab === "about" && (
             <div className="comm-about">
               {renderRules()}
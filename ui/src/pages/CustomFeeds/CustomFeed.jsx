@@ -0,0 +1,185 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import { useEffect, useState } from "react";
+import { Helmet } from "react-helmet-async";
+import { useDispatch, useSelector } from "react-redux";
+import { useHistory, useParams } from "react-router-dom";
+import { ButtonClose } from "../../components/Button";
+import Link from "../../components/Link";
+import MarkdownBody from "../../components/MarkdownBody";
+import Modal from "../../components/Modal";
+import PageLoading from "../../components/PageLoading";
+import Sidebar from "../../components/Sidebar";
+import { ApiError, mfetchjson, stringCount } from "../../helper";
+import { snackAlert, snackAlertError } from "../../slices/mainSlice";
+import PostsFeed from "../../views/PostsFeed";
+import NotFound from "../NotFound";
+import EditCustomFeedForm from "./EditCustomFeedForm";
+
+const CustomFeed = () => {
+  const dispatch = useDispatch();
+  const history = useHistory();
+  const { username, feedName } = useParams();
+  const viewer = useSelector((state) => state.main.user);
+
+  const [feed, setFeed] = useState(null);
+  const [notFound, setNotFound] = useState(false);
+  useEffect(() => {
+    setFeed(null);
+    (async () => {
+      try {
+        setFeed(await mfetchjson(`/api/users/${username}/feeds/${feedName}`));
+      } catch (error) {
+        if (error instanceof ApiError && error.status === 404) {
+          setNotFound(true);
+          return;
+        }
+        dispatch(snackAlertError(error));
+      }
+    })();
+  }, [username, feedName]);
+
+  const [isEditOpen, setIsEditOpen] = useState(false);
+  const handleEdited = (res) => {
+    setIsEditOpen(false);
+    setFeed(res);
+    if (res.name !== feedName) {
+      history.replace(`/@${res.username}/feeds/${res.name}`);
+    }
+  };
+
+  const handleDelete = async () => {
+    if (!confirm("Delete this feed?")) {
+      return;
+    }
+    try {
+      await mfetchjson(`/api/users/${feed.username}/feeds/${feed.name}`, {
+        method: "DELETE",
+      });
+      history.replace(`/@${feed.username}/feeds`);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const handleFollow = async () => {
+    try {
+      const res = await mfetchjson(
+        `/api/users/${feed.username}/feeds/${feed.name}/follow`,
+        { method: feed.following ? "DELETE" : "POST" },
+      );
+      setFeed({ ...feed, following: res.following });
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const handleCopyLink = async () => {
+    try {
+      await navigator.clipboard.writeText(window.location.href);
+      dispatch(snackAlert("Link copied to clipboard."));
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  if (notFound) {
+    return <NotFound />;
+  }
+  if (feed === null) {
+    return <PageLoading />;
+  }
+
+  const isOwner = viewer !== null && viewer.id === feed.userId;
+
+  return (
+    <div className="page-content wrap page-grid page-custom-feed">
+      <Helmet>
+        <title>{feed.displayName}</title>
+      </Helmet>
+      <Sidebar />
+      <main>
+        <div className="card card-padding custom-feed-head">
+          <h1>{feed.displayName}</h1>
+          <div>
+            By <Link to={`/@${feed.username}`}>@{feed.username}</Link>
+            {" · "}
+            {stringCount(
+              feed.numCommunities,
+              false,
+              "community",
+              "communities",
+            )}
+            {" · "}
+            {stringCount(feed.numFollowers, false, "follower")}
+            {!feed.public && " · Private"}
+          </div>
+          {feed.description && <MarkdownBody>{feed.description}</MarkdownBody>}
+          <div className="custom-feed-comms">
+            {(feed.communities || []).map((comm) => (
+              <Link key={comm.id} to={`/${CONFIG.communityPrefix}${comm.name}`}>
+                {comm.name}
+              </Link>
+            ))}
+          </div>
+          <div className="custom-feed-buttons">
+            {viewer && !isOwner && feed.public && (
+              <button
+                type="button"
+                className={feed.following ? "" : "button-main"}
+                onClick={handleFollow}
+              >
+                {feed.following ? "Unfollow" : "Follow"}
+              </button>
+            )}
+            {feed.public && (
+              <>
+                <button type="button" onClick={handleCopyLink}>
+                  Copy link
+                </button>
+                <a
+                  className="button"
+                  href={`/api/feed/f/${feed.username}/${feed.name}`}
+                  target="_blank"
+                  rel="noreferrer"
+                >
+                  RSS
+                </a>
+              </>
+            )}
+            {isOwner && (
+              <>
+                <button type="button" onClick={() => setIsEditOpen(true)}>
+                  Edit
+                </button>
+                <button type="button" onClick={handleDelete}>
+                  Delete
+                </button>
+              </>
+            )}
+          </div>
+        </div>
+        <Modal open={isEditOpen} onClose={() => setIsEditOpen(false)}>
+          <div className="modal-card is-compact-mobile is-page-new">
+            <div className="modal-card-head">
+              <div className="modal-card-title">Edit feed</div>
+              <ButtonClose onClick={() => setIsEditOpen(false)} />
+            </div>
+            <EditCustomFeedForm
+              feed={feed}
+              onSuccess={handleEdited}
+              onCancel={() => setIsEditOpen(false)}
+            />
+          </div>
+        </Modal>
+        <PostsFeed
+          feedType="custom"
+          name={feed.displayName}
+          customFeedUrl={`/api/users/${feed.username}/feeds/${feed.name}/posts`}
+        />
+      </main>
+    </div>
+  );
+};
+
+export default CustomFeed;
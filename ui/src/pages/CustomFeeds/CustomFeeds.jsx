@@ -0,0 +1,110 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import { useEffect, useState } from "react";
+import { useDispatch, useSelector } from "react-redux";
+import { useHistory, useParams } from "react-router-dom";
+import { ButtonClose } from "../../components/Button";
+import Link from "../../components/Link";
+import Modal from "../../components/Modal";
+import PageLoading from "../../components/PageLoading";
+import Sidebar from "../../components/Sidebar";
+import { mfetchjson, stringCount } from "../../helper";
+import { snackAlertError } from "../../slices/mainSlice";
+import EditCustomFeedForm from "./EditCustomFeedForm";
+
+const CustomFeeds = () => {
+  const dispatch = useDispatch();
+  const history = useHistory();
+  const { username } = useParams();
+  const viewer = useSelector((state) => state.main.user);
+  const isOwner = viewer !== null && viewer.username === username;
+
+  const [feeds, setFeeds] = useState(null);
+  const [followed, setFollowed] = useState([]);
+  useEffect(() => {
+    (async () => {
+      try {
+        setFeeds(await mfetchjson(`/api/users/${username}/feeds`));
+        if (isOwner) {
+          setFollowed(await mfetchjson("/api/_followed_feeds"));
+        }
+      } catch (error) {
+        dispatch(snackAlertError(error));
+      }
+    })();
+  }, [username, isOwner]);
+
+  const [isNewFeedOpen, setIsNewFeedOpen] = useState(false);
+  const handleCreated = (feed) => {
+    setIsNewFeedOpen(false);
+    history.push(`/@${feed.username}/feeds/${feed.name}`);
+  };
+
+  if (feeds === null) {
+    return <PageLoading />;
+  }
+
+  const renderFeed = (feed) => (
+    <Link
+      key={feed.id}
+      className="list-thumb"
+      to={`/@${feed.username}/feeds/${feed.name}`}
+    >
+      <div className="list-thumb-bottom">
+        <div className="list-thumb-name">
+          <span className="is-name">{feed.displayName}</span>
+          {!feed.public && <span className="is-age">Private</span>}
+        </div>
+        <div className="list-thumb-count">
+          {stringCount(feed.numCommunities, false, "community", "communities")}
+        </div>
+      </div>
+    </Link>
+  );
+
+  return (
+    <div className="page-content wrap page-grid page-lists">
+      <Sidebar />
+      <main>
+        <div className="lists-head">
+          <h1>
+            <Link to={`/@${username}`}>@{username}</Link>
+            {"'s feeds"}
+          </h1>
+        </div>
+        <section className="lists-main">
+          {isOwner && (
+            <div className="lists-main-head">
+              <div className="left">
+                <button type="button" onClick={() => setIsNewFeedOpen(true)}>
+                  New feed
+                </button>
+              </div>
+            </div>
+          )}
+          <Modal open={isNewFeedOpen} onClose={() => setIsNewFeedOpen(false)}>
+            <div className="modal-card is-compact-mobile is-page-new">
+              <div className="modal-card-head">
+                <div className="modal-card-title">Create feed</div>
+                <ButtonClose onClick={() => setIsNewFeedOpen(false)} />
+              </div>
+              <EditCustomFeedForm
+                onSuccess={handleCreated}
+                onCancel={() => setIsNewFeedOpen(false)}
+              />
+            </div>
+          </Modal>
+          <div className="lists-main-main">{feeds.map(renderFeed)}</div>
+          {followed.length > 0 && (
+            <>
+              <h2>Followed feeds</h2>
+              <div className="lists-main-main">{followed.map(renderFeed)}</div>
+            </>
+          )}
+        </section>
+      </main>
+    </div>
+  );
+};
+
+export default CustomFeeds;
@@ -0,0 +1,157 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useEffect, useState } from "react";
+import { useDispatch, useSelector } from "react-redux";
+import { mfetchjson } from "../../helper";
+import { snackAlertError } from "../../slices/mainSlice";
+
+const EditCustomFeedForm = ({ feed = null, onSuccess, onCancel }) => {
+  const dispatch = useDispatch();
+  const user = useSelector((state) => state.main.user);
+
+  const [name, setName] = useState(feed ? feed.name : "");
+  const [displayName, setDisplayName] = useState(feed ? feed.displayName : "");
+  const [description, setDescription] = useState(
+    feed?.description ? feed.description : "",
+  );
+  const [isPublic, setIsPublic] = useState(feed ? feed.public : false);
+  const [selected, setSelected] = useState(
+    feed?.communities ? feed.communities.map((comm) => comm.id) : [],
+  );
+
+  const [communities, setCommunities] = useState([]);
+  const [filter, setFilter] = useState("");
+  useEffect(() => {
+    (async () => {
+      try {
+        setCommunities(await mfetchjson("/api/communities"));
+      } catch (error) {
+        dispatch(snackAlertError(error));
+      }
+    })();
+  }, []);
+
+  const handleToggle = (id) => {
+    setSelected((prev) =>
+      prev.includes(id) ? prev.filter((x) => x !== id) : [...prev, id],
+    );
+  };
+
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+    const body = JSON.stringify({
+      name,
+      displayName,
+      description,
+      public: isPublic,
+      communities: selected,
+    });
+    try {
+      const res = feed
+        ? await mfetchjson(`/api/users/${feed.username}/feeds/${feed.name}`, {
+            method: "PUT",
+            body,
+          })
+        : await mfetchjson(`/api/users/${user.username}/feeds`, {
+            method: "POST",
+            body,
+          });
+      onSuccess(res);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const filtered = communities.filter((comm) =>
+    comm.name.toLowerCase().includes(filter.toLowerCase()),
+  );
+
+  return (
+    <form
+      className="modal-card-content custom-feed-form"
+      onSubmit={handleSubmit}
+    >
+      <div className="input-with-label">
+        <div className="input-label-box">
+          <div className="label">Name</div>
+        </div>
+        <input
+          type="text"
+          value={name}
+          onChange={(e) => setName(e.target.value)}
+        />
+      </div>
+      <div className="input-with-label">
+        <div className="input-label-box">
+          <div className="label">Display name</div>
+        </div>
+        <input
+          type="text"
+          value={displayName}
+          onChange={(e) => setDisplayName(e.target.value)}
+        />
+      </div>
+      <div className="input-with-label">
+        <div className="input-label-box">
+          <div className="label">Description</div>
+        </div>
+        <textarea
+          value={description}
+          onChange={(e) => setDescription(e.target.value)}
+        />
+      </div>
+      <div className="checkbox">
+        <input
+          id="cfeed-public"
+          type="checkbox"
+          checked={isPublic}
+          onChange={(e) => setIsPublic(e.target.checked)}
+        />
+        <label htmlFor="cfeed-public">
+          Public (anyone with the link can view it)
+        </label>
+      </div>
+      <div className="input-with-label">
+        <div className="input-label-box">
+          <div className="label">{`Communities (${selected.length})`}</div>
+        </div>
+        <input
+          type="text"
+          placeholder="Filter communities"
+          value={filter}
+          onChange={(e) => setFilter(e.target.value)}
+        />
+        <div className="custom-feed-form-comms">
+          {filtered.map((comm) => (
+            <div key={comm.id} className="checkbox">
+              <input
+                id={`cfeed-comm-${comm.id}`}
+                type="checkbox"
+                checked={selected.includes(comm.id)}
+                onChange={() => handleToggle(comm.id)}
+              />
+              <label htmlFor={`cfeed-comm-${comm.id}`}>{comm.name}</label>
+            </div>
+          ))}
+        </div>
+      </div>
+      <div className="modal-card-actions">
+        <button type="submit" className="button-main">
+          {feed ? "Save" : "Create"}
+        </button>
+        <button type="button" onClick={onCancel}>
+          Cancel
+        </button>
+      </div>
+    </form>
+  );
+};
+
+EditCustomFeedForm.propTypes = {
+  feed: PropTypes.object,
+  onSuccess: PropTypes.func.isRequired,
+  onCancel: PropTypes.func.isRequired,
+};
+
+export default EditCustomFeedForm;
@@ -0,0 +1,6 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import CustomFeed from "./CustomFeed";
+import CustomFeeds from "./CustomFeeds";
+
+export { CustomFeeds, CustomFeed };
@@ -0,0 +1,353 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useEffect, useState } from "react";
+import { Helmet } from "react-helmet-async";
+import { useDispatch, useSelector } from "react-redux";
+import {
+  Route,
+  Switch,
+  useHistory,
+  useLocation,
+  useParams,
+  useRouteMatch,
+} from "react-router-dom";
+import Input from "../../components/Input";
+import Link from "../../components/Link";
+import MarkdownBody from "../../components/MarkdownBody";
+import Sidebar from "../../components/Sidebar";
+import TimeAgo from "../../components/TimeAgo";
+import { mfetchjson } from "../../helper";
+import { snackAlertError } from "../../slices/mainSlice";
+
+// conversationTitle returns the title of the conversation, or, if it has none,
+// the usernames of its members other than the viewer.
+const conversationTitle = (conv, viewer) => {
+  if (conv.title) {
+    return conv.title;
+  }
+  const others = conv.members.filter((m) => m.userId !== viewer.id);
+  return others.map((m) => `@${m.username}`).join(", ");
+};
+
+const ConversationsList = () => {
+  const dispatch = useDispatch();
+  const viewer = useSelector((state) => state.main.user);
+
+  const [convs, setConvs] = useState([]);
+  const [next, setNext] = useState(null);
+  const fetchConvs = async (cursor) => {
+    try {
+      const params = new URLSearchParams();
+      if (cursor) {
+        params.set("next", cursor);
+      }
+      const res = await mfetchjson(`/api/conversations?${params.toString()}`);
+      setConvs((convs) =>
+        cursor ? [...convs, ...res.conversations] : res.conversations,
+      );
+      setNext(res.next);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+  useEffect(() => {
+    fetchConvs(null);
+  }, []);
+
+  return (
+    <div className="conversations">
+      {convs.length === 0 && <div>No messages.</div>}
+      <div className="table">
+        {convs.map((conv) => (
+          <div
+            key={conv.id}
+            className={`table-row${conv.noUnread > 0 ? " is-unread" : ""}`}
+          >
+            <div className="table-column">
+              <Link to={`/messages/${conv.id}`}>
+                {conversationTitle(conv, viewer)}
+              </Link>
+            </div>
+            <div className="table-column">
+              {conv.noUnread > 0 && `${conv.noUnread} unread`}
+            </div>
+            <div className="table-column">
+              <TimeAgo time={conv.lastMessageAt} />
+            </div>
+          </div>
+        ))}
+      </div>
+      {next && (
+        <button type="button" onClick={() => fetchConvs(next)}>
+          Load more
+        </button>
+      )}
+    </div>
+  );
+};
+
+// NewConversation is the form for starting a conversation. The to URL query
+// parameter, if set, prefills the usernames.
+const NewConversation = () => {
+  const dispatch = useDispatch();
+  const history = useHistory();
+  const query = new URLSearchParams(useLocation().search);
+
+  const [usernames, setUsernames] = useState(query.get("to") || "");
+  const [title, setTitle] = useState("");
+  const [body, setBody] = useState("");
+
+  const list = usernames
+    .split(",")
+    .map((username) => username.trim().replace(/^@/, ""))
+    .filter((username) => username !== "");
+
+  const handleSubmit = async () => {
+    try {
+      const conv = await mfetchjson("/api/conversations", {
+        method: "POST",
+        body: JSON.stringify({ usernames: list, title }),
+      });
+      await mfetchjson(`/api/conversations/${conv.id}/messages`, {
+        method: "POST",
+        body: JSON.stringify({ body }),
+      });
+      history.replace(`/messages/${conv.id}`);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  return (
+    <form
+      className="conversation-new"
+      onSubmit={(e) => {
+        e.preventDefault();
+        handleSubmit();
+      }}
+    >
+      <Input
+        label="To"
+        description="Usernames, separated by commas."
+        value={usernames}
+        onChange={(e) => setUsernames(e.target.value)}
+      />
+      {list.length > 1 && (
+        <Input
+          label="Title (optional)"
+          value={title}
+          maxLength={128}
+          onChange={(e) => setTitle(e.target.value)}
+        />
+      )}
+      <div className="input-with-label">
+        <div className="input-label-box">
+          <div className="label">Message</div>
+        </div>
+        <textarea
+          rows="6"
+          value={body}
+          onChange={(e) => setBody(e.target.value)}
+        />
+      </div>
+      <button
+        type="submit"
+        className="button-main"
+        disabled={list.length === 0 || body.trim() === ""}
+      >
+        Send
+      </button>
+    </form>
+  );
+};
+
+const Conversation = ({ conversationId }) => {
+  const dispatch = useDispatch();
+  const viewer = useSelector((state) => state.main.user);
+
+  const [conv, setConv] = useState(null);
+  const [messages, setMessages] = useState([]); // Oldest first.
+  const [next, setNext] = useState(null);
+  const fetchMessages = async (cursor) => {
+    const params = new URLSearchParams();
+    if (cursor) {
+      params.set("next", cursor);
+    }
+    const res = await mfetchjson(
+      `/api/conversations/${conversationId}/messages?${params.toString()}`,
+    );
+    const older = [...res.messages].reverse();
+    setMessages((messages) => (cursor ? [...older, ...messages] : older));
+    setNext(res.next);
+  };
+  useEffect(() => {
+    (async () => {
+      try {
+        setConv(await mfetchjson(`/api/conversations/${conversationId}`));
+        await fetchMessages(null);
+        await mfetchjson(
+          `/api/conversations/${conversationId}?action=markRead`,
+          { method: "PUT" },
+        );
+      } catch (error) {
+        dispatch(snackAlertError(error));
+      }
+    })();
+  }, [conversationId]);
+
+  const handleLoadOlder = async () => {
+    try {
+      await fetchMessages(next);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const [body, setBody] = useState("");
+  const handleSend = async () => {
+    try {
+      const message = await mfetchjson(
+        `/api/conversations/${conversationId}/messages`,
+        {
+          method: "POST",
+          body: JSON.stringify({ body }),
+        },
+      );
+      setMessages((messages) => [...messages, message]);
+      setBody("");
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const handleMute = async () => {
+    try {
+      const action = conv.viewerMuted ? "unmute" : "mute";
+      const rconv = await mfetchjson(
+        `/api/conversations/${conversationId}?action=${action}`,
+        { method: "PUT" },
+      );
+      setConv(rconv);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  if (conv === null) {
+    return null;
+  }
+
+  return (
+    <div className="conversation">
+      <div className="conversation-head">
+        <div className="conversation-title">
+          {conversationTitle(conv, viewer)}
+        </div>
+        <button type="button" onClick={handleMute}>
+          {conv.viewerMuted ? "Unmute" : "Mute"}
+        </button>
+      </div>
+      {next && (
+        <button type="button" onClick={handleLoadOlder}>
+          Load older messages
+        </button>
+      )}
+      <div className="conversation-messages">
+        {messages.map((message) => (
+          <div key={message.id} className="card card-padding message">
+            <div className="message-head">
+              <Link to={`/@${message.authorUsername}`}>
+                @{message.authorUsername}
+              </Link>
+              <span>
+                {" • "}
+                <TimeAgo time={message.createdAt} />
+              </span>
+            </div>
+            {message.deleted ? (
+              <div className="message-deleted">Message deleted.</div>
+            ) : (
+              <>
+                {message.body && <MarkdownBody>{message.body}</MarkdownBody>}
+                {message.image && (
+                  <img
+                    className="message-image"
+                    src={message.image.url}
+                    alt=""
+                  />
+                )}
+              </>
+            )}
+          </div>
+        ))}
+      </div>
+      <form
+        className="conversation-reply"
+        onSubmit={(e) => {
+          e.preventDefault();
+          handleSend();
+        }}
+      >
+        <textarea
+          rows="4"
+          placeholder="Message"
+          value={body}
+          onChange={(e) => setBody(e.target.value)}
+        />
+        <button
+          type="submit"
+          className="button-main"
+          disabled={body.trim() === ""}
+        >
+          Send
+        </button>
+      </form>
+    </div>
+  );
+};
+
+Conversation.propTypes = {
+  conversationId: PropTypes.string.isRequired,
+};
+
+const ConversationPage = () => {
+  const { conversationId } = useParams();
+  return <Conversation conversationId={conversationId} />;
+};
+
+const Messages = () => {
+  const { path } = useRouteMatch();
+
+  return (
+    <div className="page-content wrap page-messages">
+      <Helmet>
+        <title>Messages</title>
+      </Helmet>
+      <Sidebar />
+      <main>
+        <div className="messages-head">
+          <h1>
+            <Link to="/messages">Messages</Link>
+          </h1>
+          <Link className="button button-main" to="/messages/new">
+            New message
+          </Link>
+        </div>
+        <Switch>
+          <Route exact path={path}>
+            <ConversationsList />
+          </Route>
+          <Route exact path={`${path}/new`}>
+            <NewConversation />
+          </Route>
+          <Route path={`${path}/:conversationId`}>
+            <ConversationPage />
+          </Route>
+        </Switch>
+      </main>
+    </div>
+  );
+};
+
+export default Messages;
@@ -0,0 +1,193 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useEffect, useState } from "react";
+import { Helmet } from "react-helmet-async";
+import { useDispatch } from "react-redux";
+import {
+  Route,
+  Switch,
+  useHistory,
+  useLocation,
+  useParams,
+  useRouteMatch,
+} from "react-router-dom";
+import Input from "../../components/Input";
+import Link from "../../components/Link";
+import ModmailThread from "../../components/ModmailThread";
+import Sidebar from "../../components/Sidebar";
+import TimeAgo from "../../components/TimeAgo";
+import { mfetchjson } from "../../helper";
+import { snackAlertError } from "../../slices/mainSlice";
+
+// ModmailThreadsList lists modmail threads, fetched from url, that link to
+// baseTo/{threadId}.
+export const ModmailThreadsList = ({ url, baseTo, showUser = false }) => {
+  const dispatch = useDispatch();
+
+  const [threads, setThreads] = useState([]);
+  const [next, setNext] = useState(null);
+  const fetchThreads = async (cursor) => {
+    try {
+      const params = new URLSearchParams();
+      if (cursor) {
+        params.set("next", cursor);
+      }
+      const sep = url.includes("?") ? "&" : "?";
+      const res = await mfetchjson(`${url}${sep}${params.toString()}`);
+      setThreads((threads) =>
+        cursor ? [...threads, ...res.threads] : res.threads,
+      );
+      setNext(res.next);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+  useEffect(() => {
+    fetchThreads(null);
+  }, [url]);
+
+  return (
+    <div className="modmail-threads">
+      {threads.length === 0 && <div>No messages.</div>}
+      <div className="table">
+        {threads.map((thread) => (
+          <div
+            key={thread.id}
+            className={`table-row${thread.unread ? " is-unread" : ""}`}
+          >
+            <div className="table-column">
+              <Link to={`${baseTo}/${thread.id}`}>{thread.subject}</Link>
+            </div>
+            <div className="table-column">
+              {showUser ? `@${thread.username}` : thread.communityName}
+              {thread.assignedToUsername &&
+                ` → @${thread.assignedToUsername}`}
+            </div>
+            <div className="table-column">
+              <TimeAgo time={thread.lastMessageAt} />
+            </div>
+          </div>
+        ))}
+      </div>
+      {next && (
+        <button type="button" onClick={() => fetchThreads(next)}>
+          Load more
+        </button>
+      )}
+    </div>
+  );
+};
+
+ModmailThreadsList.propTypes = {
+  url: PropTypes.string.isRequired,
+  baseTo: PropTypes.string.isRequired,
+  showUser: PropTypes.bool,
+};
+
+// NewModmailThread is the form for messaging the mods of the community given
+// in the community URL query parameter. The subject and body parameters, if
+// set, prefill the form.
+const NewModmailThread = () => {
+  const dispatch = useDispatch();
+  const history = useHistory();
+  const query = new URLSearchParams(useLocation().search);
+
+  const [community, setCommunity] = useState(query.get("community") || "");
+  const [subject, setSubject] = useState(query.get("subject") || "");
+  const [body, setBody] = useState(query.get("body") || "");
+
+  const handleSubmit = async () => {
+    try {
+      const comm = await mfetchjson(
+        `/api/communities/${community}?byName=true`,
+      );
+      const thread = await mfetchjson(`/api/communities/${comm.id}/modmail`, {
+        method: "POST",
+        body: JSON.stringify({ subject, body }),
+      });
+      history.replace(`/modmail/${thread.id}`);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const disabled =
+    community.trim() === "" || subject.trim() === "" || body.trim() === "";
+
+  return (
+    <form
+      className="modmail-new"
+      onSubmit={(e) => {
+        e.preventDefault();
+        handleSubmit();
+      }}
+    >
+      <Input
+        label="Community"
+        value={community}
+        onChange={(e) => setCommunity(e.target.value)}
+      />
+      <Input
+        label="Subject"
+        value={subject}
+        maxLength={255}
+        onChange={(e) => setSubject(e.target.value)}
+      />
+      <div className="input-with-label">
+        <div className="input-label-box">
+          <div className="label">Message</div>
+        </div>
+        <textarea
+          rows="8"
+          value={body}
+          onChange={(e) => setBody(e.target.value)}
+        />
+      </div>
+      <button type="submit" className="button-main" disabled={disabled}>
+        Send
+      </button>
+    </form>
+  );
+};
+
+const ModmailThreadPage = () => {
+  const { threadId } = useParams();
+  return <ModmailThread threadId={threadId} />;
+};
+
+const Modmail = () => {
+  const { path } = useRouteMatch();
+
+  return (
+    <div className="page-content wrap page-modmail">
+      <Helmet>
+        <title>Modmail</title>
+      </Helmet>
+      <Sidebar />
+      <main>
+        <div className="modmail-head">
+          <h1>
+            <Link to="/modmail">Modmail</Link>
+          </h1>
+          <Link className="button button-main" to="/modmail/new">
+            Message the mods
+          </Link>
+        </div>
+        <Switch>
+          <Route exact path={path}>
+            <ModmailThreadsList url="/api/modmail" baseTo="/modmail" />
+          </Route>
+          <Route exact path={`${path}/new`}>
+            <NewModmailThread />
+          </Route>
+          <Route path={`${path}/:threadId`}>
+            <ModmailThreadPage />
+          </Route>
+        </Switch>
+      </main>
+    </div>
+  );
+};
+
+export default Modmail;
@@ -0,0 +1,174 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useEffect, useState } from "react";
+import { useDispatch } from "react-redux";
+import { Link } from "react-router-dom";
+import { InputWithCount, useInputMaxLength } from "../../components/Input";
+import TimeAgo from "../../components/TimeAgo";
+import { ApiError, mfetch, mfetchjson } from "../../helper";
+import { useLoading } from "../../hooks";
+import { snackAlert, snackAlertError } from "../../slices/mainSlice";
+
+const configPlaceholder = `rules:
+  - name: No link shorteners
+    type: post
+    conditions:
+      domains: [bit.ly, tinyurl.com]
+    actions:
+      remove: true
+      notify: Link shorteners are not allowed here.`;
+
+const hitTarget = (hit) => {
+  const prefix = `/${CONFIG.communityPrefix}`;
+  if (hit.post) {
+    return {
+      text: hit.post.title,
+      to: `${prefix}${hit.post.communityName}/post/${hit.post.publicId}`,
+    };
+  }
+  if (hit.comment) {
+    const { comment } = hit;
+    return {
+      text: comment.body,
+      to: `${prefix}${comment.communityName}/post/${comment.postPublicId}/${comment.id}`,
+    };
+  }
+  return { text: "(not found)", to: null };
+};
+
+const Automod = ({ community }) => {
+  const dispatch = useDispatch();
+
+  const baseUrl = `/api/communities/${community.id}/automod`;
+  const configMaxLength = 32 * 1024;
+  const [config, setConfig] = useInputMaxLength(configMaxLength);
+  const [rules, setRules] = useState([]);
+  const [error, setError] = useState("");
+
+  const [hits, setHits] = useState([]);
+  const [ruleFilter, setRuleFilter] = useState("");
+
+  const [loading, setLoading] = useLoading();
+  useEffect(() => {
+    (async () => {
+      try {
+        const res = await mfetchjson(baseUrl);
+        setConfig(res.config);
+        setRules(res.rules);
+        setLoading("loaded");
+      } catch (error) {
+        dispatch(snackAlertError(error));
+        setLoading("failed");
+      }
+    })();
+  }, [community.id]);
+
+  useEffect(() => {
+    (async () => {
+      try {
+        const rule = encodeURIComponent(ruleFilter);
+        setHits(await mfetchjson(`${baseUrl}/hits?rule=${rule}`));
+      } catch (error) {
+        dispatch(snackAlertError(error));
+      }
+    })();
+  }, [community.id, ruleFilter]);
+
+  const handleSave = async () => {
+    try {
+      const res = await mfetch(baseUrl, {
+        method: "PUT",
+        body: JSON.stringify({ config }),
+      });
+      const json = await res.json();
+      if (res.status === 400) {
+        setError(json.message);
+        return;
+      }
+      if (!res.ok) {
+        throw new ApiError(res.status, json);
+      }
+      setError("");
+      setConfig(json.config);
+      setRules(json.rules);
+      dispatch(snackAlert("Automod rules saved."));
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  if (loading !== "loaded") {
+    return null;
+  }
+
+  return (
+    <div className="modtools-content modtools-automod">
+      <div className="modtools-content-head">
+        <div className="modtools-title">Automod</div>
+        <button type="button" className="button-main" onClick={handleSave}>
+          Save
+        </button>
+      </div>
+      <InputWithCount
+        textarea
+        rows="16"
+        label="Rules"
+        description="YAML or JSON. Rules are run when posts and comments are created, edited, or reported. Actions are taken on your behalf."
+        placeholder={configPlaceholder}
+        maxLength={configMaxLength}
+        value={config}
+        error={error}
+        onChange={(e) => {
+          setError("");
+          setConfig(e);
+        }}
+        spellCheck={false}
+      />
+      <div className="modtools-content-head modtools-automod-hits-head">
+        <div className="modtools-title">Hits</div>
+        <select
+          value={ruleFilter}
+          onChange={(e) => setRuleFilter(e.target.value)}
+        >
+          <option value="">All rules</option>
+          {rules.map((rule) => (
+            <option key={rule.name} value={rule.name}>
+              {rule.name}
+            </option>
+          ))}
+        </select>
+      </div>
+      {hits.length === 0 && <div>No hits.</div>}
+      <div className="table">
+        {hits.map((hit) => {
+          const { text, to } = hitTarget(hit);
+          return (
+            <div key={hit.id} className="table-row">
+              <div className="table-column">
+                {hit.ruleName}
+                {hit.dryRun && " (dry run)"}
+              </div>
+              <div className="table-column">
+                {to ? <Link to={to}>{text}</Link> : text}
+              </div>
+              <div className="table-column">
+                {hit.actions.join(", ")}
+                {hit.error && <div className="form-error">{hit.error}</div>}
+              </div>
+              <div className="table-column">
+                <TimeAgo time={hit.createdAt} />
+              </div>
+            </div>
+          );
+        })}
+      </div>
+    </div>
+  );
+};
+
+Automod.propTypes = {
+  community: PropTypes.object.isRequired,
+};
+
+export default Automod;
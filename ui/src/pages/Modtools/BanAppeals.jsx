@@ -0,0 +1,199 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useEffect, useState } from "react";
+import { useDispatch } from "react-redux";
+import { ButtonClose } from "../../components/Button";
+import { InputWithCount, useInputMaxLength } from "../../components/Input";
+import Link from "../../components/Link";
+import Modal from "../../components/Modal";
+import TimeAgo from "../../components/TimeAgo";
+import { mfetchjson } from "../../helper";
+import { snackAlertError } from "../../slices/mainSlice";
+
+const statusTexts = {
+  open: "Open",
+  accepted: "Accepted",
+  rejected: "Rejected",
+  shortened: "Shortened",
+};
+
+const actionTexts = {
+  accept: "Accept appeal (unban user)",
+  reject: "Reject appeal",
+  shorten: "Shorten ban",
+};
+
+const BanAppeals = ({ community }) => {
+  const dispatch = useDispatch();
+
+  const baseUrl = `/api/communities/${community.id}/ban_appeals`;
+  const [status, setStatus] = useState("open");
+  const [appeals, setAppeals] = useState([]);
+  const [page, setPage] = useState(1);
+  const [hasMore, setHasMore] = useState(false);
+  const limit = 20;
+
+  const fetchAppeals = async (page) => {
+    try {
+      const params = new URLSearchParams({ status, limit, page });
+      const res = await mfetchjson(`${baseUrl}?${params.toString()}`);
+      setAppeals((appeals) => (page > 1 ? [...appeals, ...res] : res));
+      setPage(page);
+      setHasMore(res.length === limit);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+  useEffect(() => {
+    fetchAppeals(1);
+  }, [baseUrl, status]);
+
+  const [reviewing, setReviewing] = useState(null); // { appeal, action }
+  const messageMaxLength = 2000;
+  const [message, setMessage] = useInputMaxLength(messageMaxLength);
+  const [expires, setExpires] = useState("");
+
+  const handleReviewClose = () => {
+    setReviewing(null);
+    setMessage("");
+    setExpires("");
+  };
+
+  const handleReview = async () => {
+    const { appeal, action } = reviewing;
+    const body = { action, message };
+    if (action === "shorten") {
+      body.expires = new Date(expires).toISOString();
+    }
+    try {
+      const rappeal = await mfetchjson(`/api/ban_appeals/${appeal.id}`, {
+        method: "POST",
+        body: JSON.stringify(body),
+      });
+      setAppeals((appeals) =>
+        status === "" || rappeal.status === status
+          ? appeals.map((a) => (a.id === rappeal.id ? rappeal : a))
+          : appeals.filter((a) => a.id !== rappeal.id),
+      );
+      handleReviewClose();
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const reviewDisabled =
+    reviewing && reviewing.action === "shorten" && expires === "";
+
+  return (
+    <div className="modtools-content modtools-ban-appeals">
+      <Modal open={reviewing !== null} onClose={handleReviewClose}>
+        <div className="modal-card">
+          <div className="modal-card-head">
+            <div className="modal-card-title">
+              {reviewing && actionTexts[reviewing.action]}
+            </div>
+            <ButtonClose onClick={handleReviewClose} />
+          </div>
+          <div className="modal-card-content">
+            {reviewing && reviewing.action === "shorten" && (
+              <div className="input-with-label">
+                <div className="input-label-box">
+                  <div className="label">New expiry</div>
+                </div>
+                <input
+                  type="datetime-local"
+                  value={expires}
+                  onChange={(e) => setExpires(e.target.value)}
+                />
+              </div>
+            )}
+            <InputWithCount
+              textarea
+              rows="5"
+              label="Message (optional)"
+              description="Sent to the user."
+              maxLength={messageMaxLength}
+              value={message}
+              onChange={setMessage}
+              style={{ resize: "vertical" }}
+            />
+          </div>
+          <div className="modal-card-actions">
+            <button
+              type="button"
+              className="button-main"
+              disabled={reviewDisabled}
+              onClick={handleReview}
+            >
+              Submit
+            </button>
+            <button type="button" onClick={handleReviewClose}>
+              Cancel
+            </button>
+          </div>
+        </div>
+      </Modal>
+      <div className="modtools-content-head">
+        <div className="modtools-title">Ban appeals</div>
+        <select value={status} onChange={(e) => setStatus(e.target.value)}>
+          <option value="">All appeals</option>
+          {Object.keys(statusTexts).map((key) => (
+            <option key={key} value={key}>
+              {statusTexts[key]}
+            </option>
+          ))}
+        </select>
+      </div>
+      {appeals.length === 0 && <div>No appeals.</div>}
+      <div className="table">
+        {appeals.map((appeal) => (
+          <div key={appeal.id} className="table-row">
+            <div className="table-column">
+              <div>
+                <Link to={`/@${appeal.username}`}>@{appeal.username}</Link>
+                {" • "}
+                <TimeAgo time={appeal.createdAt} />
+                {" • "}
+                {statusTexts[appeal.status]}
+                {appeal.banExpires &&
+                  ` • Ban expires ${new Date(appeal.banExpires).toLocaleString()}`}
+              </div>
+              <div className="modtools-ban-appeals-body">{appeal.body}</div>
+              {appeal.response && (
+                <div className="modtools-ban-appeals-response">
+                  Response: {appeal.response}
+                </div>
+              )}
+            </div>
+            {appeal.status === "open" && (
+              <div className="table-column modtools-ban-appeals-actions">
+                {Object.keys(actionTexts).map((action) => (
+                  <button
+                    key={action}
+                    type="button"
+                    className={action === "accept" ? "button-main" : ""}
+                    onClick={() => setReviewing({ appeal, action })}
+                  >
+                    {action[0].toUpperCase() + action.slice(1)}
+                  </button>
+                ))}
+              </div>
+            )}
+          </div>
+        ))}
+      </div>
+      {hasMore && (
+        <button type="button" onClick={() => fetchAppeals(page + 1)}>
+          Load more
+        </button>
+      )}
+    </div>
+  );
+};
+
+BanAppeals.propTypes = {
+  community: PropTypes.object.isRequired,
+};
+
+export default BanAppeals;
@@ -3,30 +3,40 @@ import React from "react";
 import PropTypes from "prop-types";
 import { useEffect, useState } from "react";
 import { useDispatch } from "react-redux";
+import { Link } from "react-router-dom";
 import { ButtonClose } from "../../components/Button";
 import Input from "../../components/Input";
 import Modal from "../../components/Modal";
-import { ApiError, mfetch, mfetchjson } from "../../helper";
+import { ApiError, mfetch, mfetchjson, timeAgo } from "../../helper";
 import { useLoading } from "../../hooks";
 import { snackAlert, snackAlertError } from "../../slices/mainSlice";
 
+// Ban durations, in days (0 is permanent).
+const durations = {
+  0: "Permanent",
+  1: "1 day",
+  3: "3 days",
+  7: "7 days",
+  30: "30 days",
+};
+
 const Banned = ({ community }) => {
   const dispatch = useDispatch();
 
   const baseUrl = `/api/communities/${community.id}`;
-  const [users, setUsers] = useState([]);
+  const [bans, setBans] = useState([]);
   const [loading, setLoading] = useLoading();
+  const fetchBans = async () => {
+    try {
+      setBans(await mfetchjson(`${baseUrl}/banned`));
+      setLoading("loaded");
+    } catch (error) {
+      console.error(error);
+      setLoading("failed");
+    }
+  };
   useEffect(() => {
-    (async () => {
-      try {
-        const banned = await mfetchjson(`${baseUrl}/banned`);
-        setUsers(banned);
-        setLoading("loaded");
-      } catch (error) {
-        console.error(error);
-        setLoading("failed");
-      }
-    })();
+    fetchBans();
   }, [community.id]);
 
   const [modalError, setModalError] = useState("");
@@ -37,23 +47,27 @@ const Banned = ({ community }) => {
     }
     _setUsername(name);
   };
+  const [duration, setDuration] = useState("0");
   const [banModalOpen, setBanModalOpen] = useState(false);
   const handleBanModalClose = () => {
     setBanModalOpen(false);
     setUsername("");
+    setDuration("0");
   };
   const handleBanClick = async () => {
+    const body = { username };
+    if (duration !== "0") {
+      const days = Number.parseInt(duration, 10);
+      body.expires = new Date(Date.now() + days * 24 * 3600 * 1000);
+    }
     try {
       const res = await mfetch(`${baseUrl}/banned`, {
         method: "POST",
-        body: JSON.stringify({
-          username,
-        }),
+        body: JSON.stringify(body),
       });
       if (res.ok) {
         dispatch(snackAlert(`@${username} is banned.`));
-        const user = await res.json();
-        setUsers((users) => [...users, user]);
+        await fetchBans();
         handleBanModalClose();
       } else if (res.status === 404) {
         setModalError("No user with username exists.");
@@ -77,7 +91,9 @@ const Banned = ({ community }) => {
           username,
         }),
       });
-      setUsers((users) => users.filter((u) => u.username !== user.username));
+      setBans((bans) =>
+        bans.filter((ban) => ban.user.username !== user.username),
+      );
     } catch (error) {
       dispatch(snackAlertError(error));
     }
@@ -109,6 +125,21 @@ const Banned = ({ community }) => {
               onChange={(e) => setUsername(e.target.value)}
               autoFocus
             />
+            <div className="input-with-label">
+              <div className="input-label-box">
+                <div className="label">Duration</div>
+              </div>
+              <select
+                value={duration}
+                onChange={(e) => setDuration(e.target.value)}
+              >
+                {Object.keys(durations).map((key) => (
+                  <option key={key} value={key}>
+                    {durations[key]}
+                  </option>
+                ))}
+              </select>
+            </div>
           </form>
           <div className="modal-card-actions">
             <button
@@ -126,7 +157,7 @@ const Banned = ({ community }) => {
         </div>
       </Modal>
       <div className="modtools-content-head">
-        <div className="modtools-title">Banned ({users.length})</div>
+        <div className="modtools-title">Banned ({bans.length})</div>
         <button
           type="button"
           className="button-main"
@@ -137,10 +168,20 @@ const Banned = ({ community }) => {
       </div>
       <div className="modtools-banned-users">
         <div className="table">
-          {users.map((user) => (
+          {bans.map(({ user, expiresIn }) => (
             <div key={user.id} className="table-row">
-              <div className="table-column">@{user.username}</div>
-              <div className="table-column" />
+              <div className="table-column">
+                <Link
+                  to={`/${CONFIG.communityPrefix}${community.name}/modtools/users/${user.username}`}
+                >
+                  @{user.username}
+                </Link>
+              </div>
+              <div className="table-column">
+                {expiresIn === null
+                  ? "Permanent"
+                  : timeAgo(Date.now() - expiresIn * 1000, " left", false)}
+              </div>
               <div className="table-column">
                 <button
                   type="button"
@@ -0,0 +1,200 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useEffect, useState } from "react";
+import { useDispatch } from "react-redux";
+import { ButtonClose } from "../../components/Button";
+import Input from "../../components/Input";
+import Modal from "../../components/Modal";
+import { ApiError, mfetch, mfetchjson } from "../../helper";
+import { useLoading } from "../../hooks";
+import { snackAlert, snackAlertError } from "../../slices/mainSlice";
+
+const Members = ({ community }) => {
+  const dispatch = useDispatch();
+
+  const baseUrl = `/api/communities/${community.id}/member_requests`;
+  const [requests, setRequests] = useState([]);
+  const [loading, setLoading] = useLoading();
+  useEffect(() => {
+    (async () => {
+      try {
+        setRequests(await mfetchjson(baseUrl));
+        setLoading("loaded");
+      } catch (error) {
+        console.error(error);
+        setLoading("failed");
+      }
+    })();
+  }, [community.id]);
+
+  const [modalError, setModalError] = useState("");
+  const [username, _setUsername] = useState("");
+  const setUsername = (name) => {
+    if (name === "") {
+      setModalError("");
+    }
+    _setUsername(name);
+  };
+  const [inviteModalOpen, setInviteModalOpen] = useState(false);
+  const handleInviteModalClose = () => {
+    setInviteModalOpen(false);
+    setUsername("");
+  };
+  const handleInviteClick = async () => {
+    try {
+      const res = await mfetch(baseUrl, {
+        method: "POST",
+        body: JSON.stringify({
+          username,
+        }),
+      });
+      if (res.ok) {
+        dispatch(snackAlert(`@${username} is invited.`));
+        setRequests(await mfetchjson(baseUrl));
+        handleInviteModalClose();
+      } else if (res.status === 404) {
+        setModalError("No user with username exists.");
+      } else if (res.status === 400) {
+        setModalError((await res.json()).message);
+      } else {
+        throw new ApiError(res.status, await res.json());
+      }
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const handleRequest = async (username, approve) => {
+    try {
+      await mfetchjson(`${baseUrl}/${username}`, {
+        method: approve ? "PUT" : "DELETE",
+      });
+      setRequests((reqs) =>
+        reqs.filter((req) => req.user.username !== username),
+      );
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  if (loading !== "loaded") {
+    return null;
+  }
+
+  const pending = requests.filter((req) => !req.invite);
+  const invites = requests.filter((req) => req.invite);
+
+  return (
+    <div className="modtools-content modtools-banned">
+      <Modal open={inviteModalOpen} onClose={handleInviteModalClose}>
+        <div className="modal-card">
+          <div className="modal-card-head">
+            <div className="modal-card-title">Invite user</div>
+            <ButtonClose onClick={handleInviteModalClose} />
+          </div>
+          <form
+            className="modal-card-content"
+            onSubmit={(e) => {
+              e.preventDefault();
+              handleInviteClick();
+            }}
+          >
+            <Input
+              label="Username"
+              value={username}
+              error={modalError}
+              onChange={(e) => setUsername(e.target.value)}
+              autoFocus
+            />
+          </form>
+          <div className="modal-card-actions">
+            <button
+              type="button"
+              className="button-main"
+              disabled={username === ""}
+              onClick={handleInviteClick}
+            >
+              Invite
+            </button>
+            <button type="button" onClick={handleInviteModalClose}>
+              Cancel
+            </button>
+          </div>
+        </div>
+      </Modal>
+      <div className="modtools-content-head">
+        <div className="modtools-title">
+          Membership requests ({pending.length})
+        </div>
+        <button
+          type="button"
+          className="button-main"
+          onClick={() => setInviteModalOpen(true)}
+        >
+          Invite user
+        </button>
+      </div>
+      <div className="modtools-banned-users">
+        <div className="table">
+          {pending.map((req) => (
+            <div key={req.user.id} className="table-row">
+              <div className="table-column">@{req.user.username}</div>
+              <div className="table-column" />
+              <div className="table-column">
+                <button
+                  type="button"
+                  className="button-main"
+                  onClick={() => handleRequest(req.user.username, true)}
+                >
+                  Approve
+                </button>
+                <button
+                  type="button"
+                  onClick={() => handleRequest(req.user.username, false)}
+                >
+                  Reject
+                </button>
+              </div>
+            </div>
+          ))}
+        </div>
+      </div>
+      {invites.length > 0 && (
+        <>
+          <div className="modtools-content-head">
+            <div className="modtools-title">
+              Pending invitations ({invites.length})
+            </div>
+          </div>
+          <div className="modtools-banned-users">
+            <div className="table">
+              {invites.map((req) => (
+                <div key={req.user.id} className="table-row">
+                  <div className="table-column">@{req.user.username}</div>
+                  <div className="table-column">
+                    {req.invitedBy && `Invited by @${req.invitedBy.username}`}
+                  </div>
+                  <div className="table-column">
+                    <button
+                      type="button"
+                      onClick={() => handleRequest(req.user.username, false)}
+                    >
+                      Revoke
+                    </button>
+                  </div>
+                </div>
+              ))}
+            </div>
+          </div>
+        </>
+      )}
+    </div>
+  );
+};
+
+Members.propTypes = {
+  community: PropTypes.object.isRequired,
+};
+
+export default Members;
@@ -0,0 +1,138 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useEffect, useState } from "react";
+import { useDispatch } from "react-redux";
+import { Link } from "react-router-dom";
+import TimeAgo from "../../components/TimeAgo";
+import { mfetchjson } from "../../helper";
+import { snackAlertError } from "../../slices/mainSlice";
+
+export const actionTexts = {
+  remove_post: "removed post",
+  remove_post_content: "deleted the content of post",
+  remove_comment: "removed comment",
+  lock_post: "locked post",
+  unlock_post: "unlocked post",
+  pin_post: "pinned post",
+  unpin_post: "unpinned post",
+  ban_user: "banned",
+  unban_user: "unbanned",
+  add_mod: "added as a moderator",
+  remove_mod: "removed as a moderator",
+  add_rule: "added a rule",
+  edit_rule: "edited a rule",
+  delete_rule: "deleted a rule",
+  dismiss_report: "dismissed a report",
+  update_community: "updated the community settings",
+  update_automod: "updated the automod rules",
+  approve_post: "approved post",
+  approve_comment: "approved comment",
+  escalate_report: "escalated a report to the admins",
+  accept_ban_appeal: "accepted the ban appeal of",
+  reject_ban_appeal: "rejected the ban appeal of",
+  shorten_ban: "shortened the ban of",
+  ban_expired: "ban expired for",
+  reorder_mods: "reordered the moderators",
+  transfer_ownership: "transferred the community to",
+  approve_takeover: "approved the takeover request of",
+  reject_takeover: "rejected the takeover request of",
+  delete_community: "deleted the community",
+  restore_community: "restored the community",
+  archive_community: "archived the community",
+  unarchive_community: "unarchived the community",
+  rename_community: "renamed the community",
+  reject_rename: "rejected the rename request of",
+};
+
+export const entryTarget = (entry) => {
+  if (entry.postPublicId) {
+    let to = `/${CONFIG.communityPrefix}${entry.communityName}/post/${entry.postPublicId}`;
+    if (entry.targetType === "comment") {
+      to += `/${entry.targetId}`;
+    }
+    return <Link to={to}>{entry.postTitle}</Link>;
+  }
+  if (entry.targetUsername) {
+    return (
+      <Link to={`/@${entry.targetUsername}`}>@{entry.targetUsername}</Link>
+    );
+  }
+  if (entry.metadata && entry.metadata.rule) {
+    return entry.metadata.rule;
+  }
+  return null;
+};
+
+const ModLog = ({ community }) => {
+  const dispatch = useDispatch();
+
+  const [action, setAction] = useState("");
+  const [entries, setEntries] = useState([]);
+  const [next, setNext] = useState(null);
+  const fetchEntries = async (cursor) => {
+    try {
+      const params = new URLSearchParams({ action });
+      if (cursor) {
+        params.set("next", cursor);
+      }
+      const res = await mfetchjson(
+        `/api/communities/${community.id}/modlog?${params.toString()}`,
+      );
+      setEntries((entries) =>
+        cursor ? [...entries, ...res.entries] : res.entries,
+      );
+      setNext(res.next);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+  useEffect(() => {
+    fetchEntries(null);
+  }, [community.id, action]);
+
+  return (
+    <div className="modtools-content modtools-modlog">
+      <div className="modtools-content-head">
+        <div className="modtools-title">Mod log</div>
+        <select value={action} onChange={(e) => setAction(e.target.value)}>
+          <option value="">All actions</option>
+          {Object.keys(actionTexts).map((key) => (
+            <option key={key} value={key}>
+              {actionTexts[key]}
+            </option>
+          ))}
+        </select>
+      </div>
+      {entries.length === 0 && <div>No actions.</div>}
+      <div className="table">
+        {entries.map((entry) => (
+          <div key={entry.id} className="table-row">
+            <div className="table-column">
+              @{entry.actorUsername}
+              {entry.actorGroup === "admins" && " (admin)"}
+            </div>
+            <div className="table-column">
+              {actionTexts[entry.action] || entry.action} {entryTarget(entry)}
+              {entry.reason && <div>Reason: {entry.reason}</div>}
+            </div>
+            <div className="table-column">
+              <TimeAgo time={entry.createdAt} />
+            </div>
+          </div>
+        ))}
+      </div>
+      {next && (
+        <button type="button" onClick={() => fetchEntries(next)}>
+          Load more
+        </button>
+      )}
+    </div>
+  );
+};
+
+ModLog.propTypes = {
+  community: PropTypes.object.isRequired,
+};
+
+export default ModLog;
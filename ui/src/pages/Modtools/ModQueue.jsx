@@ -0,0 +1,148 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useEffect, useState } from "react";
+import { useDispatch } from "react-redux";
+import { Link } from "react-router-dom";
+import TimeAgo from "../../components/TimeAgo";
+import { mfetchjson } from "../../helper";
+import { snackAlertError } from "../../slices/mainSlice";
+
+const reasonTexts = {
+  reported: "Reported",
+  filtered: "Removed by automod",
+  edited: "Edited after report",
+};
+
+const itemTarget = (item) => {
+  const prefix = `/${CONFIG.communityPrefix}`;
+  if (item.post) {
+    return {
+      text: item.post.title,
+      to: `${prefix}${item.post.communityName}/post/${item.post.publicId}`,
+    };
+  }
+  if (item.comment) {
+    const { comment } = item;
+    return {
+      text: comment.body,
+      to: `${prefix}${comment.communityName}/post/${comment.postPublicId}/${comment.id}`,
+    };
+  }
+  return { text: "(not found)", to: null };
+};
+
+// ModQueue shows the mod queue of community, or, if community is not set, of
+// all the communities the user mods.
+const ModQueue = ({ community = null }) => {
+  const dispatch = useDispatch();
+
+  const baseUrl = community
+    ? `/api/communities/${community.id}/modqueue`
+    : "/api/modqueue";
+  const [reason, setReason] = useState("");
+  const [items, setItems] = useState([]);
+  const [page, setPage] = useState(1);
+  const [hasMore, setHasMore] = useState(false);
+  const limit = 20;
+
+  const fetchItems = async (page) => {
+    try {
+      const params = new URLSearchParams({ reason, limit, page });
+      const res = await mfetchjson(`${baseUrl}?${params.toString()}`);
+      setItems((items) => (page > 1 ? [...items, ...res] : res));
+      setPage(page);
+      setHasMore(res.length === limit);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+  useEffect(() => {
+    fetchItems(1);
+  }, [baseUrl, reason]);
+
+  const handleAction = async (item, action) => {
+    try {
+      await mfetchjson("/api/modqueue", {
+        method: "POST",
+        body: JSON.stringify({
+          targetType: item.targetType,
+          targetId: item.targetId,
+          action,
+        }),
+      });
+      setItems((items) => items.filter((i) => i.targetId !== item.targetId));
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  return (
+    <div className="modtools-content modtools-modqueue">
+      <div className="modtools-content-head">
+        <div className="modtools-title">Mod queue</div>
+        <select value={reason} onChange={(e) => setReason(e.target.value)}>
+          <option value="">All items</option>
+          {Object.keys(reasonTexts).map((key) => (
+            <option key={key} value={key}>
+              {reasonTexts[key]}
+            </option>
+          ))}
+        </select>
+      </div>
+      {items.length === 0 && <div>The queue is empty.</div>}
+      <div className="table">
+        {items.map((item) => {
+          const { text, to } = itemTarget(item);
+          return (
+            <div key={item.targetId} className="table-row">
+              <div className="table-column">
+                <div>{to ? <Link to={to}>{text}</Link> : text}</div>
+                <div className="modtools-modqueue-reasons">
+                  {item.reasons.map((r) => reasonTexts[r] || r).join(", ")}
+                  {item.noReports > 0 && ` (${item.noReports} reports)`}
+                  {item.note && ` (${item.note})`}
+                </div>
+              </div>
+              <div className="table-column">
+                <TimeAgo time={item.createdAt} />
+              </div>
+              <div className="table-column modtools-modqueue-actions">
+                <button
+                  type="button"
+                  onClick={() => handleAction(item, "approve")}
+                >
+                  Approve
+                </button>
+                <button
+                  type="button"
+                  className="button-red"
+                  onClick={() => handleAction(item, "remove")}
+                >
+                  Remove
+                </button>
+                <button
+                  type="button"
+                  onClick={() => handleAction(item, "ignore")}
+                >
+                  Ignore
+                </button>
+              </div>
+            </div>
+          );
+        })}
+      </div>
+      {hasMore && (
+        <button type="button" onClick={() => fetchItems(page + 1)}>
+          Load more
+        </button>
+      )}
+    </div>
+  );
+};
+
+ModQueue.propTypes = {
+  community: PropTypes.object,
+};
+
+export default ModQueue;
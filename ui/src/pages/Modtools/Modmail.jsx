@@ -0,0 +1,55 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useState } from "react";
+import { useSelector } from "react-redux";
+import { Route, Switch, useParams, useRouteMatch } from "react-router-dom";
+import ModmailThread from "../../components/ModmailThread";
+import { ModmailThreadsList } from "../Modmail";
+
+const ThreadPage = () => {
+  const { threadId } = useParams();
+  return <ModmailThread threadId={threadId} />;
+};
+
+const Modmail = ({ community }) => {
+  const { path } = useRouteMatch();
+  const user = useSelector((state) => state.main.user);
+
+  const [filter, setFilter] = useState("inbox");
+  const params = new URLSearchParams();
+  if (filter === "archived") {
+    params.set("archived", "true");
+  } else if (filter === "mine") {
+    params.set("assignedTo", user.id);
+  }
+  const url = `/api/communities/${community.id}/modmail?${params.toString()}`;
+  const baseTo = `/${CONFIG.communityPrefix}${community.name}/modtools/modmail`;
+
+  return (
+    <div className="modtools-content modtools-modmail">
+      <Switch>
+        <Route exact path={path}>
+          <div className="modtools-content-head">
+            <div className="modtools-title">Modmail</div>
+            <select value={filter} onChange={(e) => setFilter(e.target.value)}>
+              <option value="inbox">Inbox</option>
+              <option value="mine">Assigned to me</option>
+              <option value="archived">Archived</option>
+            </select>
+          </div>
+          <ModmailThreadsList url={url} baseTo={baseTo} showUser />
+        </Route>
+        <Route path={`${path}/:threadId`}>
+          <ThreadPage />
+        </Route>
+      </Switch>
+    </div>
+  );
+};
+
+Modmail.propTypes = {
+  community: PropTypes.object.isRequired,
+};
+
+export default Modmail;
@@ -1,13 +1,13 @@
 // biome-ignore lint: This is necessary for it to work
 import React from "react";
 import PropTypes from "prop-types";
-import { useState } from "react";
+import { useEffect, useState } from "react";
 import { useSelector } from "react-redux";
 import { useDispatch } from "react-redux";
 import { ButtonClose } from "../../components/Button";
 import Input from "../../components/Input";
 import Modal from "../../components/Modal";
-import { mfetch } from "../../helper";
+import { mfetch, mfetchjson } from "../../helper";
 import { snackAlertError } from "../../slices/mainSlice";
 
 const Mods = ({ community }) => {
@@ -67,7 +67,7 @@ const Mods = ({ community }) => {
     }
   };
 
-  const { mods } = community;
+  const [mods, setMods] = useState(community.mods);
   let myPos;
   mods.forEach((mod, index) => {
     if (mod.id === user.id) {
@@ -75,6 +75,106 @@ const Mods = ({ community }) => {
     }
   });
 
+  // A mod can only move the mods below them.
+  const canMove = (index) => user.isAdmin || index > myPos;
+  const handleMove = async (index, by) => {
+    const order = mods.map((mod) => mod.id);
+    [order[index], order[index + by]] = [order[index + by], order[index]];
+    try {
+      const rmods = await mfetchjson(baseUrl, {
+        method: "PUT",
+        body: JSON.stringify({ mods: order }),
+      });
+      setMods(rmods);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const transferUrl = `/api/communities/${community.id}/mod_transfer`;
+  const [transfer, setTransfer] = useState(null);
+  useEffect(() => {
+    (async () => {
+      try {
+        const res = await mfetch(transferUrl);
+        if (res.ok) {
+          setTransfer(await res.json());
+        } else if (res.status !== 404) {
+          throw new Error(await res.text());
+        }
+      } catch (error) {
+        dispatch(snackAlertError(error));
+      }
+    })();
+  }, [community.id]);
+
+  const handleOfferTransfer = async (username) => {
+    if (
+      !confirm(
+        `Are you sure you want to make ${username} the top moderator of ${community.name}? They will have to accept it.`,
+      )
+    ) {
+      return;
+    }
+    try {
+      const rtransfer = await mfetchjson(transferUrl, {
+        method: "POST",
+        body: JSON.stringify({ username }),
+      });
+      setTransfer(rtransfer);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const handleAcceptTransfer = async () => {
+    try {
+      const rmods = await mfetchjson(transferUrl, { method: "PUT" });
+      setMods(rmods);
+      setTransfer(null);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const handleDeleteTransfer = async () => {
+    try {
+      await mfetchjson(transferUrl, { method: "DELETE" });
+      setTransfer(null);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const renderTransfer = () => {
+    if (!transfer) {
+      return null;
+    }
+    const isReceiver = transfer.toUserId === user.id;
+    return (
+      <div className="modtools-mods-transfer">
+        <div>
+          @{transfer.fromUsername} offered to make @{transfer.toUsername} the
+          top moderator.
+        </div>
+        <div className="modtools-mods-transfer-actions">
+          {isReceiver && (
+            <button
+              type="button"
+              className="button-main"
+              onClick={handleAcceptTransfer}
+            >
+              Accept
+            </button>
+          )}
+          <button type="button" onClick={handleDeleteTransfer}>
+            {isReceiver ? "Decline" : "Cancel"}
+          </button>
+        </div>
+      </div>
+    );
+  };
+
   return (
     <div className="modtools-content modtools-mods">
       <Modal open={addModOpen} onClose={handleAddModClose}>
@@ -117,13 +217,32 @@ const Mods = ({ community }) => {
           Add mod
         </button>
       </div>
+      {renderTransfer()}
       <div className="modtools-mods-list">
         <div className="table">
           {mods.map((mod, index) => (
             <div className="table-row" key={mod.id}>
               <div className="table-column">{index}</div>
               <div className="table-column">{mod.username}</div>
-              <div className="table-column">
+              <div className="table-column modtools-mods-actions">
+                {canMove(index) && index > 0 && canMove(index - 1) && (
+                  <button type="button" onClick={() => handleMove(index, -1)}>
+                    Up
+                  </button>
+                )}
+                {canMove(index) && index < mods.length - 1 && (
+                  <button type="button" onClick={() => handleMove(index, 1)}>
+                    Down
+                  </button>
+                )}
+                {index > 0 && (myPos === 0 || user.isAdmin) && (
+                  <button
+                    type="button"
+                    onClick={() => handleOfferTransfer(mod.username)}
+                  >
+                    Make top mod
+                  </button>
+                )}
                 {(myPos <= index || user.isAdmin) && (
                   <button
                     type="button"
@@ -0,0 +1,218 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useEffect, useState } from "react";
+import { useDispatch } from "react-redux";
+import { ButtonClose } from "../../components/Button";
+import { InputWithCount, useInputMaxLength } from "../../components/Input";
+import Modal from "../../components/Modal";
+import { mfetchjson } from "../../helper";
+import { useLoading } from "../../hooks";
+import { snackAlertError } from "../../slices/mainSlice";
+
+const RemovalReasons = ({ community }) => {
+  const dispatch = useDispatch();
+  const baseUrl = `/api/communities/${community.id}/removal_reasons`;
+
+  const [reasons, _setReasons] = useState([]);
+  const setReasons = (reasons) => {
+    _setReasons(reasons.sort((a, b) => a.zIndex - b.zIndex));
+  };
+  const [rules, setRules] = useState([]);
+
+  const [loading, setLoading] = useLoading();
+  useEffect(() => {
+    (async () => {
+      try {
+        setReasons(await mfetchjson(baseUrl));
+        setRules(await mfetchjson(`/api/communities/${community.id}/rules`));
+        setLoading("loaded");
+      } catch (error) {
+        dispatch(snackAlertError(error));
+        setLoading("failed");
+      }
+    })();
+  }, [community.id]);
+
+  const [editOpen, setEditOpen] = useState(false);
+  const [reasonEditing, setReasonEditing] = useState(null);
+
+  const titleMaxLength = 128;
+  const [title, setTitle] = useInputMaxLength(titleMaxLength);
+  const messageMaxLength = 1000;
+  const [message, setMessage] = useInputMaxLength(messageMaxLength);
+  const [ruleId, setRuleId] = useState("");
+
+  const handleEditClose = () => {
+    setEditOpen(false);
+    setReasonEditing(null);
+    setTitle("");
+    setMessage("");
+    setRuleId("");
+  };
+
+  const handleEdit = (reason) => {
+    setReasonEditing(reason);
+    setTitle(reason.title);
+    setMessage(reason.message);
+    setRuleId(reason.ruleId === null ? "" : String(reason.ruleId));
+    setEditOpen(true);
+  };
+
+  const handleSave = async () => {
+    const body = {
+      title,
+      message,
+      ruleId: ruleId === "" ? null : parseInt(ruleId, 10),
+    };
+    try {
+      if (reasonEditing) {
+        const rreason = await mfetchjson(`${baseUrl}/${reasonEditing.id}`, {
+          method: "PUT",
+          body: JSON.stringify({ ...body, zIndex: reasonEditing.zIndex }),
+        });
+        setReasons([...reasons.filter((r) => r.id !== rreason.id), rreason]);
+      } else {
+        const rreason = await mfetchjson(baseUrl, {
+          method: "POST",
+          body: JSON.stringify(body),
+        });
+        setReasons([...reasons, rreason]);
+      }
+      handleEditClose();
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const handleDelete = async (reason) => {
+    if (confirm("Are you certain?")) {
+      try {
+        await mfetchjson(`${baseUrl}/${reason.id}`, { method: "DELETE" });
+        setReasons(reasons.filter((r) => r.id !== reason.id));
+      } catch (error) {
+        dispatch(snackAlertError(error));
+      }
+    }
+  };
+
+  if (loading !== "loaded") {
+    return null;
+  }
+
+  const modalTitle = reasonEditing
+    ? "Edit removal reason"
+    : "Add removal reason";
+  const modalDisabled = title === "" || message === "";
+
+  return (
+    <div className="modtools-content modtools-removal-reasons">
+      <Modal open={editOpen} onClose={handleEditClose}>
+        <div className="modal-card">
+          <div className="modal-card-head">
+            <div className="modal-card-title">{modalTitle}</div>
+            <ButtonClose onClick={handleEditClose} />
+          </div>
+          <form
+            className="modal-card-content"
+            onSubmit={(e) => {
+              e.preventDefault();
+              if (!modalDisabled) {
+                handleSave();
+              }
+            }}
+          >
+            <InputWithCount
+              label="Title"
+              maxLength={titleMaxLength}
+              value={title}
+              onChange={setTitle}
+              autoFocus
+            />
+            <InputWithCount
+              textarea
+              rows="5"
+              label="Message"
+              description="Sent to the author of the removed post or comment."
+              maxLength={messageMaxLength}
+              value={message}
+              onChange={setMessage}
+              style={{ resize: "vertical" }}
+            />
+            <div className="input-with-label">
+              <div className="input-label-box">
+                <div className="label">Rule</div>
+              </div>
+              <select
+                value={ruleId}
+                onChange={(e) => setRuleId(e.target.value)}
+              >
+                <option value="">None</option>
+                {rules.map((rule) => (
+                  <option key={rule.id} value={rule.id}>
+                    {rule.rule}
+                  </option>
+                ))}
+              </select>
+            </div>
+          </form>
+          <div className="modal-card-actions">
+            <button
+              type="button"
+              className="button-main"
+              disabled={modalDisabled}
+              onClick={handleSave}
+            >
+              Save
+            </button>
+            <button type="button" onClick={handleEditClose}>
+              Cancel
+            </button>
+          </div>
+        </div>
+      </Modal>
+      <div className="modtools-content-head">
+        <div className="modtools-title">Removal reasons</div>
+        <button
+          type="button"
+          className="button-main"
+          onClick={() => setEditOpen(true)}
+        >
+          Add reason
+        </button>
+      </div>
+      {reasons.length === 0 && <div>No removal reasons.</div>}
+      <div className="table">
+        {reasons.map((reason) => (
+          <div className="table-row" key={reason.id}>
+            <div className="table-column">{reason.title}</div>
+            <div className="table-column">
+              {reason.message}
+              {reason.rule && <div>Rule: {reason.rule}</div>}
+            </div>
+            <div className="table-column">
+              <button
+                type="button"
+                className="button-red"
+                onClick={() => handleDelete(reason)}
+              >
+                Delete
+              </button>
+            </div>
+            <div className="table-column">
+              <button type="button" onClick={() => handleEdit(reason)}>
+                Edit
+              </button>
+            </div>
+          </div>
+        ))}
+      </div>
+    </div>
+  );
+};
+
+RemovalReasons.propTypes = {
+  community: PropTypes.object.isRequired,
+};
+
+export default RemovalReasons;
@@ -52,6 +52,24 @@ const Reports = ({ community }) => {
     }
   };
 
+  const handleEscalate = async (report) => {
+    if (!confirm("Hand this report over to the admins?")) {
+      return;
+    }
+    try {
+      await mfetchjson(
+        `/api/communities/${community.id}/reports/${report.id}/escalate`,
+        {
+          method: "POST",
+        },
+      );
+      setReports((reports) => reports.filter((r) => r.id !== report.id));
+      dispatch(snackAlert("Report escalated to the admins."));
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
   if (loading !== "loaded") {
     return <div className="modtools-content" />;
   }
@@ -112,6 +130,9 @@ const Reports = ({ community }) => {
                 <button type="button" onClick={() => handleIgnore(report)}>
                   Ignore
                 </button>
+                <button type="button" onClick={() => handleEscalate(report)}>
+                  Escalate
+                </button>
                 <Link className="button button-red" to={handleUrl}>
                   Handle
                 </Link>
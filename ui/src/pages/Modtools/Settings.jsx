@@ -5,6 +5,7 @@ import { useEffect, useRef, useState } from "react";
 import { useDispatch, useSelector } from "react-redux";
 import CommunityProPic from "../../components/CommunityProPic";
 import { InputWithCount, useInputMaxLength } from "../../components/Input";
+import RenameRequestModal from "../../components/RenameRequestModal";
 import { mfetch, mfetchjson } from "../../helper";
 import { communityAdded } from "../../slices/communitiesSlice";
 import { snackAlert, snackAlertError } from "../../slices/mainSlice";
@@ -24,6 +25,20 @@ const Settings = ({ community }) => {
     community.about || "",
   );
   const [nsfw, setNsfw] = useState(community.nsfw);
+  const [visibility, setVisibility] = useState(community.visibility);
+  const [modLogPublic, setModLogPublic] = useState(community.modLogPublic);
+
+  const reqs = community.postingRequirements || {};
+  const [postTypes, setPostTypes] = useState(reqs.postTypes || []);
+  const [minAccountAge, setMinAccountAge] = useState(reqs.minAccountAge || 0);
+  const [minPoints, setMinPoints] = useState(reqs.minPoints || 0);
+  const [titleRegex, setTitleRegex] = useState(reqs.titleRegex || "");
+  const [minBodyLength, setMinBodyLength] = useState(reqs.minBodyLength || 0);
+  const togglePostType = (type) => {
+    setPostTypes((types) =>
+      types.includes(type) ? types.filter((t) => t !== type) : [...types, type],
+    );
+  };
 
   const handleSave = async () => {
     try {
@@ -32,7 +47,16 @@ const Settings = ({ community }) => {
         body: JSON.stringify({
           ...community,
           nsfw,
+          visibility,
+          modLogPublic,
           about: description,
+          postingRequirements: {
+            postTypes,
+            minAccountAge: Number(minAccountAge),
+            minPoints: Number(minPoints),
+            titleRegex,
+            minBodyLength: Number(minBodyLength),
+          },
         }),
       });
       dispatch(communityAdded(rcomm));
@@ -46,7 +70,17 @@ const Settings = ({ community }) => {
   const changed = _changed > 0;
   useEffect(() => {
     setChanged((c) => c + 1);
-  }, [description, nsfw]);
+  }, [
+    description,
+    nsfw,
+    visibility,
+    modLogPublic,
+    postTypes,
+    minAccountAge,
+    minPoints,
+    titleRegex,
+    minBodyLength,
+  ]);
 
   const proPicFileInputRef = useRef(null);
   const bannerFileInputRef = useRef(null);
@@ -126,6 +160,22 @@ const Settings = ({ community }) => {
     }
   };
 
+  const [renameRequest, setRenameRequest] = useState(null);
+  const [renameModalOpen, setRenameModalOpen] = useState(false);
+  useEffect(() => {
+    (async () => {
+      try {
+        const requests = await mfetchjson(
+          `/api/communities/${community.id}/rename_requests`,
+        );
+        setRenameRequest(requests.length > 0 ? requests[0] : null);
+      } catch (error) {
+        dispatch(snackAlertError(error));
+      }
+    })();
+  }, [community.id]);
+  const renamePending = renameRequest && renameRequest.status === "open";
+
   const handleChangeDefault = () => {
     try {
       dispatch(
@@ -148,9 +198,27 @@ const Settings = ({ community }) => {
         <div className="input-with-label width-50">
           <div className="input-label-box">
             <div className="label">Community name</div>
+            <div className="input-desc">
+              {renamePending
+                ? `A request to rename the community to ${renameRequest.newName} is awaiting review by the admins.`
+                : "Changing the name of the community requires the approval of the admins."}
+            </div>
             <input type="text" value={community.name} disabled />
           </div>
+          <button
+            type="button"
+            onClick={() => setRenameModalOpen(true)}
+            disabled={renamePending}
+          >
+            Request a new name
+          </button>
         </div>
+        <RenameRequestModal
+          open={renameModalOpen}
+          onClose={() => setRenameModalOpen(false)}
+          community={community}
+          onSubmitted={setRenameRequest}
+        />
         <div className="modtools-change-propic">
           <div className="label">Profile picture</div>
           <div className="flex">
@@ -238,6 +306,111 @@ const Settings = ({ community }) => {
             />
           </div>
         </div>
+        <div className="input-with-label">
+          <div className="input-label-box">
+            <div className="label">Visibility</div>
+            <div className="input-desc">
+              Anyone can read restricted communities, but only approved members
+              can post and comment. Only approved members can see the posts of
+              private communities.
+            </div>
+          </div>
+          <select
+            value={visibility}
+            onChange={(e) => setVisibility(e.target.value)}
+          >
+            <option value="public">Public</option>
+            <option value="restricted">Restricted</option>
+            <option value="private">Private</option>
+          </select>
+        </div>
+        <div className="input-with-label">
+          <div className="input-label-box">
+            <div className="label">Public mod log</div>
+          </div>
+          <div className="checkbox is-check-last">
+            <label htmlFor="c2" style={{ width: "calc(100% - 25px)" }}>
+              Tick this box to let anyone who can view the community see the
+              log of moderator actions.
+            </label>
+            <input
+              className="switch"
+              id="c2"
+              type="checkbox"
+              checked={modLogPublic}
+              onChange={(e) => setModLogPublic(e.target.checked)}
+            />
+          </div>
+        </div>
+        <div className="modtools-title">Posting requirements</div>
+        <div className="input-with-label">
+          <div className="input-label-box">
+            <div className="label">Allowed post types</div>
+            <div className="input-desc">
+              If none are ticked, all post types are allowed.
+            </div>
+          </div>
+          {["text", "image", "link"].map((type) => (
+            <div key={type} className="checkbox">
+              <input
+                id={`pt-${type}`}
+                type="checkbox"
+                checked={postTypes.includes(type)}
+                onChange={() => togglePostType(type)}
+              />
+              <label htmlFor={`pt-${type}`}>{type}</label>
+            </div>
+          ))}
+        </div>
+        <div className="input-with-label width-50">
+          <div className="input-label-box">
+            <div className="label">Minimum account age (in days)</div>
+            <div className="input-desc">Applies to comments too.</div>
+          </div>
+          <input
+            type="number"
+            min="0"
+            value={minAccountAge}
+            onChange={(e) => setMinAccountAge(e.target.value)}
+          />
+        </div>
+        <div className="input-with-label width-50">
+          <div className="input-label-box">
+            <div className="label">Minimum points</div>
+            <div className="input-desc">Applies to comments too.</div>
+          </div>
+          <input
+            type="number"
+            min="0"
+            value={minPoints}
+            onChange={(e) => setMinPoints(e.target.value)}
+          />
+        </div>
+        <div className="input-with-label">
+          <div className="input-label-box">
+            <div className="label">Title format</div>
+            <div className="input-desc">
+              A regular expression that post titles have to match. Leave it
+              empty to allow any title.
+            </div>
+          </div>
+          <input
+            type="text"
+            value={titleRegex}
+            onChange={(e) => setTitleRegex(e.target.value)}
+          />
+        </div>
+        <div className="input-with-label width-50">
+          <div className="input-label-box">
+            <div className="label">Minimum body length of text posts</div>
+          </div>
+          <input
+            type="number"
+            min="0"
+            value={minBodyLength}
+            onChange={(e) => setMinBodyLength(e.target.value)}
+          />
+        </div>
         {user.isAdmin && (
           <button type="button" onClick={handleChangeDefault}>
             {community.isDefault
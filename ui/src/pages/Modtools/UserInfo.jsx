@@ -0,0 +1,212 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useEffect, useState } from "react";
+import { useDispatch } from "react-redux";
+import { Link, useParams } from "react-router-dom";
+import TimeAgo from "../../components/TimeAgo";
+import { mfetchjson, stringCount, timeAgo } from "../../helper";
+import { useLoading } from "../../hooks";
+import { snackAlertError } from "../../slices/mainSlice";
+import { actionTexts, entryTarget } from "./ModLog";
+
+const categories = {
+  note: "Note",
+  warning: "Warning",
+  spam: "Spam",
+  ban: "Ban",
+};
+
+const UserInfo = ({ community }) => {
+  const dispatch = useDispatch();
+  const { username } = useParams();
+
+  const baseUrl = `/api/communities/${community.id}/users/${username}`;
+  const [info, setInfo] = useState(null);
+  const [loading, setLoading] = useLoading();
+  useEffect(() => {
+    (async () => {
+      try {
+        setInfo(await mfetchjson(`${baseUrl}/modinfo`));
+        setLoading("loaded");
+      } catch (error) {
+        setLoading("failed");
+        dispatch(snackAlertError(error));
+      }
+    })();
+  }, [baseUrl]);
+
+  const [category, setCategory] = useState("note");
+  const [body, setBody] = useState("");
+  const handleAddNote = async () => {
+    try {
+      const note = await mfetchjson(`${baseUrl}/notes`, {
+        method: "POST",
+        body: JSON.stringify({ category, body }),
+      });
+      setInfo((info) => ({ ...info, notes: [note, ...info.notes] }));
+      setCategory("note");
+      setBody("");
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+  const handleDeleteNote = async (note) => {
+    if (!confirm("Are you sure you want to delete the note?")) {
+      return;
+    }
+    try {
+      await mfetchjson(`${baseUrl}/notes/${note.id}`, { method: "DELETE" });
+      setInfo((info) => ({
+        ...info,
+        notes: info.notes.filter((n) => n.id !== note.id),
+      }));
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  if (loading === "failed") {
+    return (
+      <div className="modtools-content flex flex-center">User not found.</div>
+    );
+  }
+  if (loading !== "loaded") {
+    return null;
+  }
+
+  const postsUrl = `/${CONFIG.communityPrefix}${community.name}/post`;
+
+  return (
+    <div className="modtools-content modtools-userinfo">
+      <div className="modtools-content-head">
+        <div className="modtools-title">
+          <Link to={`/@${info.user.username}`}>@{info.user.username}</Link>
+        </div>
+      </div>
+      <div className="modtools-userinfo-stats">
+        <div>{stringCount(info.noPosts, false, "post")}</div>
+        <div>{stringCount(info.noComments, false, "comment")}</div>
+        <div>{stringCount(info.noPostsRemoved, false, "removed post")}</div>
+        <div>
+          {stringCount(info.noCommentsRemoved, false, "removed comment")}
+        </div>
+        <div>{stringCount(info.noReports, false, "report")}</div>
+        <div>{stringCount(info.points, false, "point")}</div>
+      </div>
+      {info.ban && (
+        <div className="modtools-userinfo-ban">
+          Banned{" "}
+          {info.ban.expiresIn === null
+            ? "permanently"
+            : `(${timeAgo(
+                Date.now() - info.ban.expiresIn * 1000,
+                " left",
+                false,
+              )})`}
+          .
+        </div>
+      )}
+      <div className="modtools-userinfo-section">
+        <div className="modtools-userinfo-section-title">Notes</div>
+        <form
+          className="modtools-userinfo-note-form"
+          onSubmit={(e) => {
+            e.preventDefault();
+            handleAddNote();
+          }}
+        >
+          <select
+            value={category}
+            onChange={(e) => setCategory(e.target.value)}
+          >
+            {Object.keys(categories).map((key) => (
+              <option key={key} value={key}>
+                {categories[key]}
+              </option>
+            ))}
+          </select>
+          <textarea
+            rows="3"
+            placeholder="Only the moderators can see the notes."
+            value={body}
+            onChange={(e) => setBody(e.target.value)}
+          />
+          <button
+            type="submit"
+            className="button-main"
+            disabled={body.trim() === ""}
+          >
+            Add note
+          </button>
+        </form>
+        {info.notes.length === 0 && <div>No notes.</div>}
+        <div className="table">
+          {info.notes.map((note) => (
+            <div key={note.id} className="table-row">
+              <div className="table-column">
+                {categories[note.category] || note.category}
+              </div>
+              <div className="table-column">
+                <div className="modtools-userinfo-note-body">{note.body}</div>
+                <div className="modtools-userinfo-note-meta">
+                  @{note.createdByUsername}, <TimeAgo time={note.createdAt} />
+                </div>
+              </div>
+              <div className="table-column">
+                <button type="button" onClick={() => handleDeleteNote(note)}>
+                  Delete
+                </button>
+              </div>
+            </div>
+          ))}
+        </div>
+      </div>
+      <div className="modtools-userinfo-section">
+        <div className="modtools-userinfo-section-title">Mod actions</div>
+        {info.modActions.length === 0 && <div>No actions.</div>}
+        <div className="table">
+          {info.modActions.map((entry) => (
+            <div key={entry.id} className="table-row">
+              <div className="table-column">@{entry.actorUsername}</div>
+              <div className="table-column">
+                {actionTexts[entry.action] || entry.action} {entryTarget(entry)}
+                {entry.reason && <div>Reason: {entry.reason}</div>}
+              </div>
+              <div className="table-column">
+                <TimeAgo time={entry.createdAt} />
+              </div>
+            </div>
+          ))}
+        </div>
+      </div>
+      <div className="modtools-userinfo-section">
+        <div className="modtools-userinfo-section-title">Latest posts</div>
+        {info.posts.length === 0 && <div>No posts.</div>}
+        {info.posts.map((post) => (
+          <div key={post.id}>
+            <Link to={`${postsUrl}/${post.publicId}`}>{post.title}</Link>
+            {post.deleted && " (deleted)"}
+          </div>
+        ))}
+      </div>
+      <div className="modtools-userinfo-section">
+        <div className="modtools-userinfo-section-title">Latest comments</div>
+        {info.comments.length === 0 && <div>No comments.</div>}
+        {info.comments.map((comment) => (
+          <div key={comment.id} className="modtools-userinfo-comment">
+            <Link to={`${postsUrl}/${comment.postPublicId}/${comment.id}`}>
+              {comment.body}
+            </Link>
+          </div>
+        ))}
+      </div>
+    </div>
+  );
+};
+
+UserInfo.propTypes = {
+  community: PropTypes.object.isRequired,
+};
+
+export default UserInfo;
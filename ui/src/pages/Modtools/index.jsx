@@ -17,12 +17,20 @@ import { ApiError, mfetch } from "../../helper";
 import { communityAdded, selectCommunity } from "../../slices/communitiesSlice";
 import { snackAlertError } from "../../slices/mainSlice";
 import PageNotLoaded from "../PageNotLoaded";
+import Automod from "./Automod";
+import BanAppeals from "./BanAppeals";
 import Banned from "./Banned";
+import Members from "./Members";
+import ModLog from "./ModLog";
+import Modmail from "./Modmail";
+import ModQueue from "./ModQueue";
 import Mods from "./Mods";
+import RemovalReasons from "./RemovalReasons";
 import Removed from "./Removed";
 import Reports from "./Reports";
 import Rules from "./Rules";
 import Settings from "./Settings";
+import UserInfo from "./UserInfo";
 
 function isActiveCls(className, isActive, activeClass = "is-active") {
   return className + (isActive ? ` ${activeClass}` : "");
@@ -107,6 +115,15 @@ const Modtools = () => {
             Community settings
           </Link>
           <div className="sidebar-topic">Content</div>
+          <Link
+            className={isActiveCls(
+              "sidebar-item",
+              pathname === "/modtools/queue",
+            )}
+            to={`/${CONFIG.communityPrefix}${communityName}/modtools/queue`}
+          >
+            Mod queue
+          </Link>
           <Link
             className={isActiveCls(
               "sidebar-item",
@@ -134,6 +151,24 @@ const Modtools = () => {
           >
             Locked
           </Link>
+          <Link
+            className={isActiveCls(
+              "sidebar-item",
+              pathname === "/modtools/modlog",
+            )}
+            to={`/${CONFIG.communityPrefix}${communityName}/modtools/modlog`}
+          >
+            Mod log
+          </Link>
+          <Link
+            className={isActiveCls(
+              "sidebar-item",
+              pathname === "/modtools/modmail",
+            )}
+            to={`/${CONFIG.communityPrefix}${communityName}/modtools/modmail`}
+          >
+            Modmail
+          </Link>
           <div className="sidebar-topic">Users</div>
           <Link
             className={isActiveCls(
@@ -144,6 +179,26 @@ const Modtools = () => {
           >
             Banned
           </Link>
+          <Link
+            className={isActiveCls(
+              "sidebar-item",
+              pathname === "/modtools/ban_appeals",
+            )}
+            to={`/${CONFIG.communityPrefix}${communityName}/modtools/ban_appeals`}
+          >
+            Ban appeals
+          </Link>
+          {community.visibility !== "public" && (
+            <Link
+              className={isActiveCls(
+                "sidebar-item",
+                pathname === "/modtools/members",
+              )}
+              to={`/${CONFIG.communityPrefix}${communityName}/modtools/members`}
+            >
+              Membership requests
+            </Link>
+          )}
           <Link
             className={isActiveCls(
               "sidebar-item",
@@ -163,6 +218,24 @@ const Modtools = () => {
           >
             Rules
           </Link>
+          <Link
+            className={isActiveCls(
+              "sidebar-item",
+              pathname === "/modtools/removal_reasons",
+            )}
+            to={`/${CONFIG.communityPrefix}${communityName}/modtools/removal_reasons`}
+          >
+            Removal reasons
+          </Link>
+          <Link
+            className={isActiveCls(
+              "sidebar-item",
+              pathname === "/modtools/automod",
+            )}
+            to={`/${CONFIG.communityPrefix}${communityName}/modtools/automod`}
+          >
+            Automod
+          </Link>
         </div>
         <Switch>
           <Route exact path={path}>
@@ -173,6 +246,9 @@ const Modtools = () => {
           <Route exact path={`${path}/settings`}>
             <Settings community={community} />
           </Route>
+          <Route path={`${path}/queue`}>
+            <ModQueue community={community} />
+          </Route>
           <Route path={`${path}/reports`}>
             <Reports community={community} />
           </Route>
@@ -182,15 +258,36 @@ const Modtools = () => {
           <Route path={`${path}/locked`}>
             <Removed community={community} filter="locked" title="Locked" />
           </Route>
+          <Route path={`${path}/modlog`}>
+            <ModLog community={community} />
+          </Route>
+          <Route path={`${path}/modmail`}>
+            <Modmail community={community} />
+          </Route>
           <Route path={`${path}/banned`}>
             <Banned community={community} />
           </Route>
+          <Route path={`${path}/ban_appeals`}>
+            <BanAppeals community={community} />
+          </Route>
+          <Route path={`${path}/members`}>
+            <Members community={community} />
+          </Route>
           <Route path={`${path}/mods`}>
             <Mods community={community} />
           </Route>
           <Route path={`${path}/rules`}>
             <Rules community={community} />
           </Route>
+          <Route path={`${path}/removal_reasons`}>
+            <RemovalReasons community={community} />
+          </Route>
+          <Route path={`${path}/automod`}>
+            <Automod community={community} />
+          </Route>
+          <Route path={`${path}/users/:username`}>
+            <UserInfo community={community} />
+          </Route>
           <Route path="*">
             <div className="modtools-content flex flex-center">Not found.</div>
           </Route>
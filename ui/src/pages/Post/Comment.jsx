@@ -36,6 +36,10 @@ import AddComment from "./AddComment";
 import CommentShareButton, {
   CommentShareDropdownItems,
 } from "./CommentShareButton";
+import RemovalReasonPicker, {
+  emptyRemoval,
+  removalQuery,
+} from "./RemovalReasonPicker";
 
 const Diagnostics = false; // process.env.NODE_ENV !== 'production';
 const MaxCommentDepth = 15;
@@ -110,10 +114,12 @@ const Comment = ({
   };
 
   const [deleteAs, setDeleteAs] = useState("normal");
+  const [removal, setRemoval] = useState(emptyRemoval);
   const [confirmDeleteOpen, _setConfirmDeleteOpen] = useState(false);
   const setConfirmDeleteOpen = (newConfirm, deleteAs = "normal") => {
     if (newConfirm === false) {
       setDeleteAs("normal");
+      setRemoval(emptyRemoval);
     } else {
       setDeleteAs(deleteAs);
     }
@@ -122,7 +128,7 @@ const Comment = ({
   const handleOnDelete = async () => {
     try {
       const rcomm = await mfetchjson(
-        `/api/posts/${comment.postId}/comments/${comment.id}?deleteAs=${deleteAs}`,
+        `/api/posts/${comment.postId}/comments/${comment.id}?deleteAs=${deleteAs}${removalQuery(removal)}`,
         {
           method: "DELETE",
         },
@@ -207,7 +213,7 @@ const Comment = ({
     setCollapsed(collapsed);
   };
   useEffect(() => {
-    if (comment.isAuthorMuted) {
+    if (comment.isAuthorMuted || comment.filtered) {
       setCollapsed(true);
     }
   }, []);
@@ -249,8 +255,12 @@ const Comment = ({
 
   const [reportModalOpen, setReportModalOpen] = useState(false); // for mobile
 
-  const [mutedUserHidden, setMutedUserHidden] = useState(comment.isAuthorMuted);
-  const mutedText = "You've muted this user. Click here to see this comment.";
+  const [mutedUserHidden, setMutedUserHidden] = useState(
+    comment.isAuthorMuted || comment.filtered === "hide",
+  );
+  const mutedText = comment.isAuthorMuted
+    ? "You've muted this user. Click here to see this comment."
+    : "This comment matches your filters. Click here to see it.";
   const handleCommentTextClick = () => {
     if (mutedUserHidden) {
       setMutedUserHidden(false);
@@ -330,7 +340,11 @@ const Comment = ({
   };
 
   const isAuthorSupporter = userHasSupporterBadge(comment.author);
-  const topDivClassname = `post-comment ${showAuthorProPic ? "has-propics" : ""}`;
+  const isNew =
+    post.lastVisitedAt &&
+    comment.userId !== (user && user.id) &&
+    new Date(comment.createdAt) > new Date(post.lastVisitedAt);
+  const topDivClassname = `post-comment ${showAuthorProPic ? "has-propics" : ""} ${isNew ? "is-new" : ""}`;
   if (collapsed) {
     return (
       <div
@@ -384,6 +398,26 @@ const Comment = ({
     dispatch(saveToListModalOpened(comment.id, "comment"));
   };
 
+  const [following, setFollowing] = useState(
+    (post.followedComments || []).includes(comment.id),
+  );
+  const handleFollow = async () => {
+    try {
+      await mfetchjson(
+        `/api/posts/${postId}/follow?commentId=${comment.id}`,
+        {
+          method: following ? "DELETE" : "POST",
+        },
+      );
+      setFollowing(!following);
+      if (!following) {
+        dispatch(snackAlert("You'll be notified of new replies to this comment."));
+      }
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
   const upCls = {};
   const downCls = {};
   if (vote === true) {
@@ -506,8 +540,18 @@ const Comment = ({
         open={confirmDeleteOpen}
         onClose={() => setConfirmDeleteOpen(false)}
         onConfirm={handleOnDelete}
+        disableEnter={deleteAs !== "normal"}
       >
-        Are you sure you want to delete the comment?
+        <>
+          Are you sure you want to delete the comment?
+          {confirmDeleteOpen && deleteAs !== "normal" && (
+            <RemovalReasonPicker
+              communityId={comment.communityId}
+              value={removal}
+              onChange={setRemoval}
+            />
+          )}
+        </>
       </ModalConfirm>
       <div className="post-comment-left">
         <div className="post-comment-collapse" onClick={handleLineClick}>
@@ -712,6 +756,11 @@ const Comment = ({
                       Save to list
                     </div>
                   )}
+                  {loggedIn && (
+                    <div className="dropdown-item" onClick={handleFollow}>
+                      {following ? "Unfollow replies" : "Follow replies"}
+                    </div>
+                  )}
                   {isAdmin && (
                     <>
                       <div className="dropdown-item is-topic">
@@ -767,6 +816,15 @@ const Comment = ({
                   Save
                 </button>
               )}
+              {loggedIn && (
+                <button
+                  type="button"
+                  className="button-text"
+                  onClick={handleFollow}
+                >
+                  {following ? "Unfollow replies" : "Follow replies"}
+                </button>
+              )}
               {isAdmin && (
                 <Dropdown
                   target={
@@ -4,6 +4,7 @@ import PropTypes from "prop-types";
 import { useState } from "react";
 import { ButtonClose } from "../../components/Button";
 import Modal from "../../components/Modal";
+import RemovalReasonPicker, { emptyRemoval } from "./RemovalReasonPicker";
 
 const PostDeleteModal = ({
   open,
@@ -11,8 +12,11 @@ const PostDeleteModal = ({
   onDelete,
   postType,
   canDeleteContent = false,
+  communityId,
+  deleteAs = "normal",
 }) => {
   const [deleteContent, setDeleteContent] = useState(false);
+  const [removal, setRemoval] = useState(emptyRemoval);
 
   const showCheckbox =
     canDeleteContent && (postType === "image" || postType === "link");
@@ -43,12 +47,19 @@ const PostDeleteModal = ({
               <label htmlFor="post_del_content">{label}</label>
             </div>
           )}
+          {open && deleteAs !== "normal" && (
+            <RemovalReasonPicker
+              communityId={communityId}
+              value={removal}
+              onChange={setRemoval}
+            />
+          )}
         </div>
         <div className="modal-card-actions">
           <button
             type="button"
             className="button-main"
-            onClick={() => onDelete(deleteContent)}
+            onClick={() => onDelete(deleteContent, removal)}
           >
             Yes
           </button>
@@ -67,6 +78,8 @@ PostDeleteModal.propTypes = {
   onDelete: PropTypes.func.isRequired,
   postType: PropTypes.string.isRequired,
   canDeleteContent: PropTypes.bool,
+  communityId: PropTypes.string,
+  deleteAs: PropTypes.string,
 };
 
 export const PostContentDeleteModal = ({ open, onClose, onDelete, post }) => {
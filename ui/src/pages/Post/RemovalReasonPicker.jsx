@@ -0,0 +1,95 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import PropTypes from "prop-types";
+import { useEffect, useState } from "react";
+import { useDispatch } from "react-redux";
+import { mfetchjson } from "../../helper";
+import { snackAlertError } from "../../slices/mainSlice";
+
+export const emptyRemoval = { reasonId: "", reason: "", comment: false };
+
+// removalQuery returns the URL query parameters, of the delete post and the
+// delete comment APIs, of removal (starting with an '&').
+export const removalQuery = (removal) => {
+  if (!removal.reasonId && !removal.reason) {
+    return "";
+  }
+  const params = new URLSearchParams({
+    reasonId: removal.reasonId,
+    reason: removal.reason,
+    reasonComment: removal.comment,
+  });
+  return `&${params.toString()}`;
+};
+
+// RemovalReasonPicker lets mods and admins pick the reason for removing a post
+// or a comment.
+const RemovalReasonPicker = ({ communityId, value, onChange }) => {
+  const dispatch = useDispatch();
+
+  const [reasons, setReasons] = useState([]);
+  useEffect(() => {
+    (async () => {
+      try {
+        setReasons(
+          await mfetchjson(`/api/communities/${communityId}/removal_reasons`),
+        );
+      } catch (error) {
+        dispatch(snackAlertError(error));
+      }
+    })();
+  }, [communityId]);
+
+  const set = (key, val) => onChange({ ...value, [key]: val });
+
+  return (
+    <div className="removal-reason-picker">
+      <div className="input-with-label">
+        <div className="input-label-box">
+          <div className="label">Reason</div>
+        </div>
+        <select
+          value={value.reasonId}
+          onChange={(e) => set("reasonId", e.target.value)}
+        >
+          <option value="">No reason</option>
+          {reasons.map((reason) => (
+            <option key={reason.id} value={reason.id}>
+              {reason.title}
+            </option>
+          ))}
+        </select>
+      </div>
+      <div className="input-with-label">
+        <div className="input-label-box">
+          <div className="label">Note (optional)</div>
+        </div>
+        <textarea
+          rows="3"
+          maxLength={1000}
+          value={value.reason}
+          onChange={(e) => set("reason", e.target.value)}
+        />
+      </div>
+      <div className="checkbox">
+        <input
+          id="removal_reason_comment"
+          type="checkbox"
+          checked={value.comment}
+          onChange={(e) => set("comment", e.target.checked)}
+        />
+        <label htmlFor="removal_reason_comment">
+          Also post the reason as a public comment.
+        </label>
+      </div>
+    </div>
+  );
+};
+
+RemovalReasonPicker.propTypes = {
+  communityId: PropTypes.string.isRequired,
+  value: PropTypes.object.isRequired,
+  onChange: PropTypes.func.isRequired,
+};
+
+export default RemovalReasonPicker;
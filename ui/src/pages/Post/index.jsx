@@ -24,15 +24,19 @@ import {
   stringCount,
   userGroupSingular,
 } from "../../helper";
-import { useIsMobile } from "../../hooks";
-import { commentsAdded, newCommentAdded } from "../../slices/commentsSlice";
+import { useEvents, useIsMobile } from "../../hooks";
+import {
+  commentReceived,
+  commentsAdded,
+  newCommentAdded,
+} from "../../slices/commentsSlice";
 import { communityAdded } from "../../slices/communitiesSlice";
 import {
   saveToListModalOpened,
   snackAlert,
   snackAlertError,
 } from "../../slices/mainSlice";
-import { postAdded } from "../../slices/postsSlice";
+import { postAdded, postVotesUpdated } from "../../slices/postsSlice";
 import PageNotLoaded from "../PageNotLoaded";
 import AddComment from "./AddComment";
 import CommentSection from "./CommentSection";
@@ -42,6 +46,7 @@ import PostImage from "./PostImage";
 import PostShareButton from "./PostShareButton";
 // import CommentsSortButton from './CommentsSortButton';
 import PostVotesBar from "./PostVotesBar";
+import { emptyRemoval, removalQuery } from "./RemovalReasonPicker";
 
 const Post = () => {
   const { id, commentId, communityName } = useParams(); // id is post.publicId
@@ -95,7 +100,7 @@ const Post = () => {
     (async () => {
       try {
         const res = await mfetch(
-          `/api/posts/${id}?fetchCommunity=${fetchCommunity}`,
+          `/api/posts/${id}?fetchCommunity=${fetchCommunity}&visit=true`,
         );
         const rpost = await res.json();
         if (res.ok) {
@@ -108,7 +113,9 @@ const Post = () => {
           }
           setCommunityLoading("loaded");
         } else {
-          setPostLoading(res.status === 404 ? "notfound" : "failed");
+          const notFound =
+            res.status === 404 || rpost.code === "private-community";
+          setPostLoading(notFound ? "notfound" : "failed");
         }
       } catch (error) {
         dispatch(snackAlertError(error));
@@ -142,6 +149,16 @@ const Post = () => {
     dispatch(newCommentAdded(post.publicId, comment));
   };
 
+  // Receive the new comments and the vote counts of the post as they happen.
+  useEvents(postLoading === "loaded", id, (event) => {
+    if (event.type === "new_comment") {
+      dispatch(commentReceived(id, event.data));
+    } else if (event.type === "post_votes") {
+      const { upvotes, downvotes } = event.data;
+      dispatch(postVotesUpdated(id, upvotes, downvotes));
+    }
+  });
+
   const [deleteAs, setDeleteAs] = useState("normal");
   const [deleteModalOpen, _setDeleteModalOpen] = useState(false);
   const [canDeletePostContent, setCanDeletePostContent] = useState(false);
@@ -156,10 +173,13 @@ const Post = () => {
     }
     _setDeleteModalOpen(open);
   };
-  const handleDelete = async (deleteContent = false) => {
+  const handleDelete = async (
+    deleteContent = false,
+    removal = emptyRemoval,
+  ) => {
     try {
       await mfetchjson(
-        `/api/posts/${post.publicId}?deleteAs=${deleteAs}&deleteContent=${deleteContent}`,
+        `/api/posts/${post.publicId}?deleteAs=${deleteAs}&deleteContent=${deleteContent}${removalQuery(removal)}`,
         { method: "DELETE" },
       );
       setDeleteModalOpen(false);
@@ -180,6 +200,32 @@ const Post = () => {
   };
   const handleContentDelete = () => handleDelete(true);
 
+  const handleMuteNotifications = async () => {
+    try {
+      await mfetchjson(`/api/notifications/mutes?postId=${post.publicId}`, {
+        method: "POST",
+      });
+      dispatch(
+        snackAlert(
+          "You won't get notifications from this thread. Unmute it from your settings.",
+        ),
+      );
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const handleFollow = async () => {
+    try {
+      await mfetchjson(`/api/posts/${post.publicId}/follow`, {
+        method: post.following ? "DELETE" : "POST",
+      });
+      dispatch(postAdded({ ...post, following: !post.following }));
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
   const handleLock = async (userGroup = "mods") => {
     const params = new URLSearchParams();
     params.set("action", isLocked ? "unlock" : "lock");
@@ -352,6 +398,8 @@ const Post = () => {
             onClose={() => setDeleteModalOpen(false)}
             onDelete={handleDelete}
             canDeleteContent={canDeletePostContent}
+            communityId={post.communityId}
+            deleteAs={deleteAs}
           />
           <PostContentDeleteModal
             post={post}
@@ -659,6 +707,24 @@ const Post = () => {
                     disabled={isBanned}
                   />
                 )}
+                {loggedIn && (
+                  <button
+                    type="button"
+                    className="button-text"
+                    onClick={handleFollow}
+                  >
+                    {post.following ? "Unfollow" : "Follow"}
+                  </button>
+                )}
+                {loggedIn && (
+                  <button
+                    type="button"
+                    className="button-text"
+                    onClick={handleMuteNotifications}
+                  >
+                    Mute notifications
+                  </button>
+                )}
               </div>
               <div className="right">
                 <PostVotesBar up={post.upvotes} down={post.downvotes} />
@@ -669,6 +735,11 @@ const Post = () => {
                 <div className="post-comments-count">
                   {stringCount(post.noComments, false, "comment")}
                 </div>
+                {post.newComments > 0 && (
+                  <div className="post-comments-new">
+                    {`${stringCount(post.newComments, false, "new comment")} since your last visit`}
+                  </div>
+                )}
               </div>
               {/* <CommentsSortButton /> */}
               <AddComment
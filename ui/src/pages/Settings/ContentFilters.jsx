@@ -0,0 +1,107 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import { useState } from "react";
+import { useDispatch, useSelector } from "react-redux";
+import { mfetchjson } from "../../helper";
+import { mutesAdded, snackAlertError } from "../../slices/mainSlice";
+
+const filterTypes = {
+  keyword: "Keyword",
+  regex: "Regex",
+  domain: "Domain",
+  post_type: "Post type",
+};
+
+const ContentFilters = () => {
+  const dispatch = useDispatch();
+  const mutes = useSelector((state) => state.main.mutes);
+  const filterMutes = mutes.filterMutes || [];
+
+  const [type, setType] = useState("keyword");
+  const [pattern, setPattern] = useState("");
+  const [action, setAction] = useState("hide");
+
+  const handleAdd = async (e) => {
+    e.preventDefault();
+    try {
+      const res = await mfetchjson("/api/mutes", {
+        method: "POST",
+        body: JSON.stringify({ filter: { type, pattern, action } }),
+      });
+      dispatch(mutesAdded(res));
+      setPattern("");
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const handleDelete = async (mute) => {
+    try {
+      await mfetchjson(`/api/mutes/${mute.id}`, { method: "DELETE" });
+      dispatch(
+        mutesAdded({
+          filterMutes: filterMutes.filter((m) => m.id !== mute.id),
+        }),
+      );
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  return (
+    <div className="input-with-label settings-prefs">
+      <div className="input-label-box">
+        <div className="label">Content filters</div>
+      </div>
+      <div className="settings-list">
+        {filterMutes.length === 0 && <div>None</div>}
+        {filterMutes.map((mute) => (
+          <div key={mute.id}>
+            <span>
+              {`${filterTypes[mute.filter.type]}: ${mute.filter.pattern} (${mute.filter.action})`}
+            </span>
+            <button type="button" onClick={() => handleDelete(mute)}>
+              Remove
+            </button>
+          </div>
+        ))}
+        <form className="settings-content-filter-form" onSubmit={handleAdd}>
+          <select value={type} onChange={(e) => setType(e.target.value)}>
+            {Object.keys(filterTypes).map((t) => (
+              <option key={t} value={t}>
+                {filterTypes[t]}
+              </option>
+            ))}
+          </select>
+          {type === "post_type" ? (
+            <select
+              value={pattern}
+              onChange={(e) => setPattern(e.target.value)}
+            >
+              <option value="">Select a type</option>
+              <option value="text">Text</option>
+              <option value="image">Image</option>
+              <option value="link">Link</option>
+            </select>
+          ) : (
+            <input
+              type="text"
+              placeholder={type === "domain" ? "example.com" : "Pattern"}
+              value={pattern}
+              onChange={(e) => setPattern(e.target.value)}
+            />
+          )}
+          <select value={action} onChange={(e) => setAction(e.target.value)}>
+            <option value="hide">Hide</option>
+            <option value="collapse">Collapse</option>
+          </select>
+          <button type="submit" disabled={pattern === ""}>
+            Add filter
+          </button>
+        </form>
+      </div>
+    </div>
+  );
+};
+
+export default ContentFilters;
@@ -0,0 +1,77 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import { useEffect, useState } from "react";
+import { useDispatch } from "react-redux";
+import { Link } from "react-router-dom";
+import { mfetchjson } from "../../helper";
+import { snackAlertError } from "../../slices/mainSlice";
+
+const FollowedThreads = () => {
+  const dispatch = useDispatch();
+  const [threads, setThreads] = useState(null);
+  const [next, setNext] = useState(null);
+
+  const fetchThreads = async (cursor = null) => {
+    try {
+      const res = await mfetchjson(
+        `/api/followed_threads${cursor ? `?next=${cursor}` : ""}`,
+      );
+      setThreads((prev) => [...(cursor ? prev : []), ...res.threads]);
+      setNext(res.next);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  useEffect(() => {
+    fetchThreads();
+  }, []);
+
+  const handleUnfollow = async (thread) => {
+    let url = `/api/posts/${thread.post.publicId}/follow`;
+    if (thread.commentId) {
+      url += `?commentId=${thread.commentId}`;
+    }
+    try {
+      await mfetchjson(url, { method: "DELETE" });
+      setThreads((prev) => prev.filter((t) => t.id !== thread.id));
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  if (threads === null || threads.length === 0) {
+    return null;
+  }
+
+  return (
+    <div className="settings-notifs-mutes">
+      <div>Followed threads</div>
+      {threads.map((thread) => {
+        let to = `/${CONFIG.communityPrefix}${thread.post.communityName}/post/${thread.post.publicId}`;
+        if (thread.commentId) {
+          to += `/${thread.commentId}`;
+        }
+        return (
+          <div key={thread.id}>
+            <Link to={to}>
+              {thread.comment
+                ? `Replies to @${thread.comment.username} on '${thread.post.title}'`
+                : thread.post.title}
+            </Link>
+            <button type="button" onClick={() => handleUnfollow(thread)}>
+              Unfollow
+            </button>
+          </div>
+        );
+      })}
+      {next && (
+        <button type="button" onClick={() => fetchThreads(next)}>
+          Load more
+        </button>
+      )}
+    </div>
+  );
+};
+
+export default FollowedThreads;
@@ -0,0 +1,157 @@
+// biome-ignore lint: This is necessary for it to work
+import React from "react";
+import { useEffect, useState } from "react";
+import { useDispatch } from "react-redux";
+import { mfetchjson } from "../../helper";
+import { snackAlertError } from "../../slices/mainSlice";
+
+const notificationTypes = {
+  new_comment: "Comments on your posts",
+  comment_reply: "Replies to your comments",
+  thread_comment: "Comments in threads you follow",
+  mention: "Mentions",
+  new_votes: "Upvotes",
+  new_message: "Messages",
+  new_post: "New posts of followed users",
+  deleted_post: "Removed posts and comments",
+  mod_add: "Added as a moderator",
+  community_invite: "Community invitations",
+  automod_message: "Automod messages",
+  ban_appeal: "Ban appeal reviews",
+  ban_expired: "Expired bans",
+  community_ban: "Community bans",
+  modmail: "Modmail",
+  mod_transfer: "Community transfer offers",
+  community_takeover: "Community takeover request reviews",
+  community_rename: "Renamed communities",
+  rename_rejected: "Rejected community rename requests",
+  new_badge: "New badges",
+};
+
+const channels = {
+  inApp: "In-app",
+  push: "Push",
+  email: "Email",
+};
+
+const NotificationPreferences = () => {
+  const dispatch = useDispatch();
+  const [prefs, setPrefs] = useState(null);
+  const [mutes, setMutes] = useState(null);
+
+  useEffect(() => {
+    (async () => {
+      try {
+        const res = await mfetchjson("/api/notifications/preferences");
+        setPrefs(res.preferences);
+        setMutes(res.mutes);
+      } catch (error) {
+        dispatch(snackAlertError(error));
+      }
+    })();
+  }, []);
+
+  const handleChange = async (type, channel, checked) => {
+    const channelsOfType = { ...prefs[type], [channel]: checked };
+    setPrefs((prev) => ({ ...prev, [type]: channelsOfType }));
+    try {
+      const res = await mfetchjson("/api/notifications/preferences", {
+        method: "PUT",
+        body: JSON.stringify({ [type]: channelsOfType }),
+      });
+      setPrefs(res);
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  const handleUnmute = async (key, id) => {
+    try {
+      await mfetchjson(`/api/notifications/mutes?${key}=${id}`, {
+        method: "DELETE",
+      });
+      if (key === "postId") {
+        setMutes((prev) => ({
+          ...prev,
+          posts: prev.posts.filter((post) => post.publicId !== id),
+        }));
+      } else {
+        setMutes((prev) => ({
+          ...prev,
+          communities: prev.communities.filter((comm) => comm.id !== id),
+        }));
+      }
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
+  if (prefs === null) {
+    return null;
+  }
+
+  return (
+    <>
+      <table className="settings-notifs-table">
+        <thead>
+          <tr>
+            <th />
+            {Object.keys(channels).map((channel) => (
+              <th key={channel}>{channels[channel]}</th>
+            ))}
+          </tr>
+        </thead>
+        <tbody>
+          {Object.keys(notificationTypes)
+            .filter((type) => prefs[type])
+            .map((type) => (
+              <tr key={type}>
+                <td>{notificationTypes[type]}</td>
+                {Object.keys(channels).map((channel) => (
+                  <td key={channel}>
+                    <input
+                      type="checkbox"
+                      aria-label={`${notificationTypes[type]} (${channels[channel]})`}
+                      checked={prefs[type][channel]}
+                      onChange={(e) =>
+                        handleChange(type, channel, e.target.checked)
+                      }
+                    />
+                  </td>
+                ))}
+              </tr>
+            ))}
+        </tbody>
+      </table>
+      {mutes && (mutes.posts.length > 0 || mutes.communities.length > 0) && (
+        <div className="settings-notifs-mutes">
+          <div>Muted threads and communities</div>
+          {mutes.posts.map((post) => (
+            <div key={post.id}>
+              <div>{post.title}</div>
+              <button
+                type="button"
+                onClick={() => handleUnmute("postId", post.publicId)}
+              >
+                Unmute
+              </button>
+            </div>
+          ))}
+          {mutes.communities.map((comm) => (
+            <div key={comm.id}>
+              <div>{comm.name}</div>
+              <button
+                type="button"
+                onClick={() => handleUnmute("communityId", comm.id)}
+              >
+                Unmute
+              </button>
+            </div>
+          ))}
+        </div>
+      )}
+    </>
+  );
+};
+
+export default NotificationPreferences;
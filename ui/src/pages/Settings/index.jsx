@@ -26,6 +26,9 @@ import {
 } from "../../slices/mainSlice";
 import ChangePassword from "./ChangePassword";
 import DeleteAccount from "./DeleteAccount";
+import ContentFilters from "./ContentFilters";
+import FollowedThreads from "./FollowedThreads";
+import NotificationPreferences from "./NotificationPreferences";
 import { getDevicePreference, setDevicePreference } from "./devicePrefs";
 
 const Settings = () => {
@@ -37,25 +40,28 @@ const Settings = () => {
   const [aboutMe, setAboutMe] = useState(user.aboutMe || "");
   const [email, setEmail] = useState(user.email || "");
 
-  const [notifsSettings, _setNotifsSettings] = useState({
-    upvoteNotifs: !user.upvoteNotificationsOff,
-    replyNotifs: !user.replyNotificationsOff,
-  });
-  const setNotifsSettings = (key, val) => {
-    _setNotifsSettings((prev) => {
-      return {
-        ...prev,
-        [key]: val,
-      };
-    });
-  };
-
   const homeFeedOptions = {
     all: "All",
     subscriptions: "Subscriptions",
+    following: "Following",
   };
   const [homeFeed, setHomeFeed] = useState(user.homeFeed);
 
+  const emailDigestOptions = {
+    off: "Off",
+    daily: "Daily",
+    weekly: "Weekly",
+  };
+  const [emailDigest, setEmailDigest] = useState(user.emailDigest || "off");
+
+  const readPostsOptions = {
+    off: "Don't track",
+    show: "Track only",
+    dim: "Dim",
+    hide: "Hide",
+  };
+  const [readPosts, setReadPosts] = useState(user.readPosts || "off");
+
   const [rememberFeedSort, setRememberFeedSort] = useState(
     user.rememberFeedSort,
   );
@@ -73,7 +79,8 @@ const Settings = () => {
 
   const [changed, resetChanged] = useIsChanged([
     aboutMe /*, email*/,
-    notifsSettings,
+    emailDigest,
+    readPosts,
     homeFeed,
     rememberFeedSort,
     enableEmbeds,
@@ -147,8 +154,8 @@ const Settings = () => {
         method: "POST",
         body: JSON.stringify({
           aboutMe,
-          upvoteNotificationsOff: !notifsSettings.upvoteNotifs,
-          replyNotificationsOff: !notifsSettings.replyNotifs,
+          emailDigest,
+          readPosts,
           homeFeed,
           rememberFeedSort,
           embedsOff: !enableEmbeds,
@@ -387,6 +394,31 @@ const Settings = () => {
                 onChange={(e) => setRememberFeedSort(e.target.checked)}
               />
             </div>
+            <div>
+              <div>Read posts</div>
+              <Dropdown
+                aligned="right"
+                target={
+                  <button type="button" className="select-bar-dp-target">
+                    {readPostsOptions[readPosts]}
+                  </button>
+                }
+              >
+                <div className="dropdown-list">
+                  {Object.keys(readPostsOptions)
+                    .filter((key) => key !== readPosts)
+                    .map((key) => (
+                      <div
+                        key={key}
+                        className="dropdown-item"
+                        onClick={() => setReadPosts(key)}
+                      >
+                        {readPostsOptions[key]}
+                      </div>
+                    ))}
+                </div>
+              </Dropdown>
+            </div>
             <div className="checkbox is-check-last">
               <label htmlFor="c4">Enable embeds</label>
               <input
@@ -444,29 +476,32 @@ const Settings = () => {
             <div className="label">Notifications</div>
           </div>
           <div className="settings-list">
-            <div className="checkbox is-check-last">
-              <label htmlFor="c1">Enable upvote notifications</label>
-              <input
-                className="switch"
-                id="c1"
-                type="checkbox"
-                checked={notifsSettings.upvoteNotifs}
-                onChange={(e) =>
-                  setNotifsSettings("upvoteNotifs", e.target.checked)
-                }
-              />
-            </div>
-            <div className="checkbox is-check-last">
-              <label htmlFor="c2">Enable reply notifications</label>
-              <input
-                className="switch"
-                id="c2"
-                type="checkbox"
-                checked={notifsSettings.replyNotifs}
-                onChange={(e) =>
-                  setNotifsSettings("replyNotifs", e.target.checked)
+            <NotificationPreferences />
+            <FollowedThreads />
+            <div>
+              <div>Email digest</div>
+              <Dropdown
+                aligned="right"
+                target={
+                  <button type="button" className="select-bar-dp-target">
+                    {emailDigestOptions[emailDigest]}
+                  </button>
                 }
-              />
+              >
+                <div className="dropdown-list">
+                  {Object.keys(emailDigestOptions)
+                    .filter((key) => key !== emailDigest)
+                    .map((key) => (
+                      <div
+                        key={key}
+                        className="dropdown-item"
+                        onClick={() => setEmailDigest(key)}
+                      >
+                        {emailDigestOptions[key]}
+                      </div>
+                    ))}
+                </div>
+              </Dropdown>
             </div>
             {/*notificationsPermissions === 'granted' && (
               <button onClick={handleDisablePushNotifications} style={{ alignSelf: 'flex-start' }}>
@@ -520,6 +555,7 @@ const Settings = () => {
             )}
           </div>
         </div>
+        <ContentFilters />
         <button
           type="button"
           className="button-main"
@@ -174,6 +174,20 @@ const User = () => {
   };
 
   const hasSupporterBadge = userHasSupporterBadge(user);
+  const handleFollow = async (follow, notify = false) => {
+    try {
+      const res = await mfetchjson(
+        `/api/users/${user.username}/follow?notify=${notify}`,
+        {
+          method: follow ? "POST" : "DELETE",
+        },
+      );
+      dispatch(userAdded(res));
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
   const handleGiveSupporterBadge = async () => {
     try {
       if (hasSupporterBadge) {
@@ -197,6 +211,22 @@ const User = () => {
     }
   };
 
+  const handleShadowban = async () => {
+    const action = user.isShadowbanned ? "unshadowban_user" : "shadowban_user";
+    if (!user.isShadowbanned && !window.confirm("Are you sure?")) {
+      return;
+    }
+    try {
+      await mfetchjson("/api/_admin", {
+        method: "POST",
+        body: JSON.stringify({ action, username: user.username }),
+      });
+      await refetchUser();
+    } catch (error) {
+      dispatch(snackAlertError(error));
+    }
+  };
+
   const [tab, setTab] = useState("content"); // content or about
   useEffect(() => {
     setTab("content");
@@ -220,6 +250,18 @@ const User = () => {
 
   const { lists, error: listsError } = useFetchUsersLists(username, false);
 
+  const [customFeeds, setCustomFeeds] = useState(null);
+  useEffect(() => {
+    setCustomFeeds(null);
+    (async () => {
+      try {
+        setCustomFeeds(await mfetchjson(`/api/users/${username}/feeds`));
+      } catch (error) {
+        console.error(error);
+      }
+    })();
+  }, [username]);
+
   if (userLoading === "notfound") {
     return <NotFound />;
   }
@@ -341,6 +383,12 @@ const User = () => {
             </svg>
             <div>{stringCount(user.noComments, false, "comment")}</div>
           </div>
+          <div className="card-list-item user-summary-item">
+            <div>
+              {stringCount(user.noFollowers, false, "follower")},{" "}
+              {user.noFollowing.toLocaleString()} following
+            </div>
+          </div>
         </div>
       </div>
     );
@@ -469,6 +517,33 @@ const User = () => {
     );
   };
 
+  const renderCustomFeeds = () => {
+    if (!customFeeds || customFeeds.length === 0) {
+      return null;
+    }
+    return (
+      <div className="card card-sub page-user-modlist">
+        <div className="card-head">
+          <div className="card-title">Feeds</div>
+          <div className="card-link">
+            <Link to={`/@${username}/feeds`}>View all</Link>
+          </div>
+        </div>
+        <div className="card-content">
+          <div className="card-list">
+            {customFeeds.map((cfeed) => (
+              <div className="card-list-item user-list-item" key={cfeed.id}>
+                <Link to={`/@${username}/feeds/${cfeed.name}`}>
+                  <span>{cfeed.displayName}</span>
+                </Link>
+              </div>
+            ))}
+          </div>
+        </div>
+      </div>
+    );
+  };
+
   const items = feed ? feed.items : [];
 
   return (
@@ -519,6 +594,25 @@ const User = () => {
           )}
           {loggedIn && !user.deleted && (
             <div className="user-card-buttons">
+              {viewer.id !== user.id && !user.isBanned && (
+                <button
+                  type="button"
+                  className={user.isFollowing ? "" : "button-main"}
+                  onClick={() => handleFollow(!user.isFollowing)}
+                >
+                  {user.isFollowing ? "Unfollow" : "Follow"}
+                </button>
+              )}
+              {viewer.id !== user.id && user.isFollowing && (
+                <button
+                  type="button"
+                  onClick={() => handleFollow(true, !user.followNotify)}
+                >
+                  {user.followNotify
+                    ? "Turn off post notifications"
+                    : "Notify me of new posts"}
+                </button>
+              )}
               {viewer.id !== user.id && (
                 <button type="button" onClick={toggleMute}>
                   {isMuted ? "Unmute user" : "Mute user"}
@@ -527,6 +621,17 @@ const User = () => {
               {viewerAdmin && (
                 <>
                   {viewer.id !== user.id && <BanUserButton user={user} />}
+                  {viewer.id !== user.id && (
+                    <button
+                      type="button"
+                      className="button-red"
+                      onClick={handleShadowban}
+                    >
+                      {user.isShadowbanned
+                        ? "Lift shadowban"
+                        : "Shadowban user"}
+                    </button>
+                  )}
                   <button
                     type="button"
                     className="button-green"
@@ -543,6 +648,12 @@ const User = () => {
                   User banned on: {new Date(user.bannedAt).toLocaleString()}
                 </div>
               )}
+              {viewerAdmin && user.isShadowbanned && (
+                <div style={{ marginTop: "1rem" }}>
+                  User is shadowbanned. Their content is hidden from everyone
+                  else.
+                </div>
+              )}
             </div>
           )}
           <div className="tabs is-m">
@@ -590,6 +701,7 @@ const User = () => {
               {renderBadges()}
               {renderModOf()}
               {renderLists()}
+              {renderCustomFeeds()}
             </>
           )}
         </div>
@@ -599,6 +711,7 @@ const User = () => {
         {renderBadges()}
         {renderModOf()}
         {renderLists()}
+        {renderCustomFeeds()}
         {/* <div className="card card-sub user-moderates">
           <div className="card-head">
             <div className="card-title">Moderates</div>
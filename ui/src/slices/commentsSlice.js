@@ -137,11 +137,32 @@ export const commentsAdded = (postId, comments, next) => {
   };
 };
 
-export const newCommentAdded = (postId, comment) => (dispatch) => {
+export const newCommentAdded = (postId, comment) => (dispatch, getState) => {
+  // The comment may have already arrived as a real-time event.
+  const item = getState().comments.items[postId];
+  if (item && searchTree(item.comments, comment.id) !== null) {
+    return;
+  }
   dispatch({ type: typeNewCommentAdded, payload: { postId, comment } });
   dispatch(commentsCountIncremented(postId));
 };
 
+// commentReceived adds a comment received as a real-time event, if the
+// comments of the post are loaded and so is the parent of the comment.
+export const commentReceived = (postId, comment) => (dispatch, getState) => {
+  const item = getState().comments.items[postId];
+  if (!item) {
+    return;
+  }
+  if (
+    comment.parentId !== null &&
+    searchTree(item.comments, comment.parentId) === null
+  ) {
+    return;
+  }
+  dispatch(newCommentAdded(postId, comment));
+};
+
 export const replyCommentsAdded = (postId, comments) => {
   return { type: typeReplyCommentsAdded, payload: { postId, comments } };
 };
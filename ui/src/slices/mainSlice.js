@@ -37,6 +37,7 @@ const initialState = {
   mutes: {
     userMutes: [],
     communityMutes: [],
+    filterMutes: [],
   },
   // listsLoading: true,
   // lists: [],
@@ -158,6 +159,19 @@ export default function mainReducer(state = initialState, action = undefined) {
         },
       };
     }
+    case "main/notificationsNewCountUpdated": {
+      return {
+        ...state,
+        user: {
+          ...state.user,
+          notificationsNewCount: action.payload,
+        },
+        notifications: {
+          ...state.notifications,
+          newCount: action.payload,
+        },
+      };
+    }
     case "main/notificationsNewCountReset": {
       return {
         ...state,
@@ -304,6 +318,7 @@ export default function mainReducer(state = initialState, action = undefined) {
       return {
         ...state,
         mutes: {
+          ...state.mutes,
           communityMutes,
           userMutes,
         },
@@ -322,6 +337,7 @@ export default function mainReducer(state = initialState, action = undefined) {
       return {
         ...state,
         mutes: {
+          ...state.mutes,
           communityMutes: filter(state.mutes.communityMutes),
           userMutes: filter(state.mutes.userMutes),
         },
@@ -502,6 +518,10 @@ export const markNotificationAsSeen =
     }
   };
 
+export const notificationsNewCountUpdated = (count) => {
+  return { type: "main/notificationsNewCountUpdated", payload: count };
+};
+
 export const notificationsNewCountReset = () => {
   return { type: "main/notificationsNewCountReset" };
 };
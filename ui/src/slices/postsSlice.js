@@ -7,6 +7,7 @@ const initialState = {
 const typePostsAdded = "posts/typePostsAdded";
 const typeCommentsCountIncremented = "posts/commentsCountIncremented";
 const typeImageGalleryIndexUpdated = "posts/imageGalleryIndexUpdated";
+const typeVotesUpdated = "posts/votesUpdated";
 
 export default function postsReducer(state = initialState, action = undefined) {
   switch (action.type) {
@@ -59,6 +60,24 @@ export default function postsReducer(state = initialState, action = undefined) {
         },
       };
     }
+    case typeVotesUpdated: {
+      const { postId, upvotes, downvotes } = action.payload;
+      const post = state.items[postId];
+      if (!post) {
+        return state;
+      }
+      return {
+        ...state,
+        items: {
+          ...state.items,
+          [post.publicId]: {
+            ...post,
+            upvotes,
+            downvotes,
+          },
+        },
+      };
+    }
     default:
       return state;
   }
@@ -93,3 +112,7 @@ export const postImageGalleryIndexUpdated = (postId, newIndex) => {
     payload: { postId, imageGalleryIndex: newIndex },
   };
 };
+
+export const postVotesUpdated = (postId, upvotes, downvotes) => {
+  return { type: typeVotesUpdated, payload: { postId, upvotes, downvotes } };
+};
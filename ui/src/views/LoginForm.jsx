@@ -4,6 +4,7 @@ import PropTypes from "prop-types";
 import { useEffect, useRef, useState } from "react";
 import { useDispatch } from "react-redux";
 import { useLocation } from "react-router-dom";
+import BanAppealModal from "../components/BanAppealModal";
 import Input, { InputPassword } from "../components/Input";
 import { ApiError, mfetch } from "../helper";
 import {
@@ -18,8 +19,11 @@ const LoginForm = ({ isModal = false }) => {
   const [username, setUsername] = useState("");
   const [password, setPassword] = useState("");
   const [loginError, setLoginError] = useState(null);
+  const [suspended, setSuspended] = useState(false);
+  const [appealOpen, setAppealOpen] = useState(false);
   useEffect(() => {
     setLoginError(null);
+    setSuspended(false);
   }, [username, password]);
   const handleLoginSubmit = async (e) => {
     e.preventDefault();
@@ -51,6 +55,7 @@ const LoginForm = ({ isModal = false }) => {
         const json = await res.json();
         if (json.code === "account_suspended") {
           setLoginError(`@${username} is suspended.`);
+          setSuspended(true);
         } else {
           throw new ApiError(res.status, json);
         }
@@ -93,6 +98,22 @@ const LoginForm = ({ isModal = false }) => {
         autoComplete="current-password"
       />
       {loginError && <div className="form-error text-center">{loginError}</div>}
+      {suspended && (
+        <>
+          <button
+            type="button"
+            className="button-link"
+            onClick={() => setAppealOpen(true)}
+          >
+            Appeal the suspension
+          </button>
+          <BanAppealModal
+            open={appealOpen}
+            onClose={() => setAppealOpen(false)}
+            credentials={{ username, password }}
+          />
+        </>
+      )}
       <input type="submit" className="button button-main" value="Login" />
       <button
         type="button"
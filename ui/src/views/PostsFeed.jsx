@@ -45,6 +45,8 @@ export const homeReloaded = (homeFeed = "all", rememberFeedSort = false) => {
   params.set("sort", sort);
   if (homeFeed === "subscriptions") {
     params.set("feed", "home");
+  } else if (homeFeed === "following") {
+    params.set("feed", "following");
   }
   return feedReloaded(`${baseUrl}?${params.toString()}`);
 };
@@ -100,7 +102,12 @@ function useFeedSort(rememberLastSort = false) {
   return [sort, setSort];
 }
 
-const PostsFeed = ({ feedType = "all", communityId = null }) => {
+const PostsFeed = ({
+  feedType = "all",
+  communityId = null,
+  customFeedUrl = null,
+  name: feedName = null,
+}) => {
   const dispatch = useDispatch();
   // const history = useHistory();
 
@@ -118,11 +125,14 @@ const PostsFeed = ({ feedType = "all", communityId = null }) => {
   urlParams.set("sort", sort);
   if (loggedIn && feedType === "subscriptions") {
     urlParams.set("feed", "home");
+  } else if (loggedIn && feedType === "following") {
+    urlParams.set("feed", "following");
   }
   if (communityId !== null) {
     urlParams.set("communityId", communityId);
   }
-  const endpoint = `${baseUrl}?${urlParams.toString()}`; // api endpoint.
+  const feedBaseUrl = customFeedUrl ?? baseUrl;
+  const endpoint = `${feedBaseUrl}?${urlParams.toString()}`; // api endpoint.
 
   // Only called on button clicks (not history API changes)
   const handleSortChange = (value) => {
@@ -164,7 +174,7 @@ const PostsFeed = ({ feedType = "all", communityId = null }) => {
     try {
       const params = new URLSearchParams(urlParams.toString());
       params.set("next", feed.next);
-      const res = await mfetchjson(`${baseUrl}?${params.toString()}`);
+      const res = await mfetchjson(`${feedBaseUrl}?${params.toString()}`);
       setFeed(res, endpoint);
     } catch (error) {
       dispatch(snackAlertError(error));
@@ -201,12 +211,14 @@ const PostsFeed = ({ feedType = "all", communityId = null }) => {
   useCanonicalTag(canonicalUrl(), [location]);
 
   const posts = feed ? feed.items : [];
-  let name = "Posts";
-  if (!communityId) {
+  let name = feedName ?? "Posts";
+  if (!communityId && !customFeedUrl) {
     if (feedType === "all") {
       name = "Home";
     } else if (feedType === "subscriptions") {
       name = "Subscriptions";
+    } else if (feedType === "following") {
+      name = "Following";
     }
   }
 
@@ -237,7 +249,15 @@ const PostsFeed = ({ feedType = "all", communityId = null }) => {
 
 PostsFeed.propTypes = {
   communityId: PropTypes.string,
-  feedType: PropTypes.oneOf(["all", "subscriptions", "community"]),
+  customFeedUrl: PropTypes.string,
+  name: PropTypes.string,
+  feedType: PropTypes.oneOf([
+    "all",
+    "subscriptions",
+    "following",
+    "community",
+    "custom",
+  ]),
 };
 
 export default PostsFeed;